@@ -0,0 +1,106 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// dracpu-scheduler-extender is a kube-scheduler extender preferring the nodes whose free dra.cpu CPUs
+// are the least fragmented, see pkg/schedulerextender.
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/nodestatus"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/schedulerextender"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+)
+
+var (
+	kubeconfig   string
+	bindAddress  string
+	resyncPeriod time.Duration
+)
+
+func init() {
+	flag.StringVar(&kubeconfig, "kubeconfig", "", "absolute path to the kubeconfig file")
+	flag.StringVar(&bindAddress, "bind-address", ":8888", "The IP address and port for the extender server to serve on")
+	flag.DurationVar(&resyncPeriod, "resync-period", 10*time.Minute, "period of the full resync of the DRACPUNodeStatus informer")
+}
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+
+	var config *rest.Config
+	var err error
+	if kubeconfig != "" {
+		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	} else {
+		// creates the in-cluster config
+		config, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		klog.Fatalf("can not create client-go configuration: %v", err)
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		klog.Fatalf("can not create dynamic client: %v", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, resyncPeriod)
+	informer := factory.ForResource(nodestatus.GroupVersionResource)
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.Informer().HasSynced) {
+		klog.Fatalf("failed to sync the DRACPUNodeStatus informer")
+	}
+	extender := schedulerextender.New(informer.Lister())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/prioritize", extender.ServePrioritize)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := &http.Server{
+		Addr:              bindAddress,
+		Handler:           mux,
+		IdleTimeout:       120 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	klog.Infof("serving the scheduler extender on %s", bindAddress)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		klog.Fatalf("HTTP server failed: %v", err)
+	}
+}
@@ -17,19 +17,37 @@ limitations under the License.
 package main
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/http/pprof"
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"strconv"
+	"strings"
 	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/containerd/nri/pkg/api"
+	"github.com/go-logr/logr"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cgroupfs"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
 	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/driver"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/httpauth"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/preflight"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/systemd"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"golang.org/x/sys/unix"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -40,6 +58,10 @@ import (
 
 const (
 	driverName = "dra.cpu"
+	// kubeletPluginRegistryPath is the directory the kubelet watches for plugin registrations.
+	kubeletPluginRegistryPath = "/var/lib/kubelet/plugins_registry"
+	// maxSimulatedClaimSize is the maximum size of the claims posted to /admin/simulate-prepare.
+	maxSimulatedClaimSize = 1 << 20
 )
 
 var (
@@ -50,6 +72,50 @@ var (
 	ready            atomic.Bool
 	cpuDeviceMode    string
 	groupBy          string
+	confineToNUMA    bool
+	kubeletCPUState  string
+	orphanedClaimTTL time.Duration
+	strategy         string
+	healthCheck      time.Duration
+	replaceDegraded  bool
+	nfdLabels        string
+	extendedResource string
+	chaosProbability float64
+	loggingFormat    string
+	sandboxHandlers  string
+	sandboxPolicy    string
+	resctrlPath      string
+	podFailure       time.Duration
+	metricsAuthz     bool
+	tlsCertFile      string
+	tlsKeyFile       string
+	debugEndpoints   bool
+	adminEndpoints   bool
+	smtIsolation     string
+	cpuPoolsConfig   string
+	nodeConfigName   string
+	nodeStatus       bool
+	publishQPS       float64
+	publishBurst     int
+	publishResync    time.Duration
+	reportAlloc      bool
+	enforcement      string
+	housekeepingCPUs string
+	cgroupRoot       string
+	cgroupDriver     string
+	prewarmClaims    bool
+	journalPath      string
+	journalMaxSize   int64
+	requireLimits    bool
+	standaloneDir    string
+	snapshotPath     string
+	// debugDriver is the started driver, whose state is served by /debug/state and claims released by /admin/release.
+	debugDriver atomic.Pointer[driver.CPUDriver]
+)
+
+const (
+	loggingFormatText = "text"
+	loggingFormatJSON = "json"
 )
 
 type cpuDeviceModeValue struct {
@@ -87,35 +153,264 @@ func (v *groupByValue) String() string {
 }
 
 func (v *groupByValue) Set(s string) error {
-	if s != driver.GROUP_BY_SOCKET && s != driver.GROUP_BY_NUMA_NODE {
-		return fmt.Errorf("invalid value: %q, must be %s or %s", s, driver.GROUP_BY_SOCKET, driver.GROUP_BY_NUMA_NODE)
+	if s != driver.GROUP_BY_SOCKET && s != driver.GROUP_BY_NUMA_NODE && s != driver.GROUP_BY_NODE {
+		return fmt.Errorf("invalid value: %q, must be %s, %s or %s", s, driver.GROUP_BY_SOCKET, driver.GROUP_BY_NUMA_NODE, driver.GROUP_BY_NODE)
+	}
+	*v.value = s
+	return nil
+}
+
+type strategyValue struct {
+	value *string
+}
+
+func newStrategyValue(val *string, def string) *strategyValue {
+	*val = def
+	return &strategyValue{value: val}
+}
+
+func (v *strategyValue) String() string {
+	return *v.value
+}
+
+func (v *strategyValue) Set(s string) error {
+	if _, err := cpumanager.NewStrategy(s); err != nil {
+		return err
+	}
+	*v.value = s
+	return nil
+}
+
+type cgroupDriverValue struct {
+	value *string
+}
+
+func newCgroupDriverValue(val *string, def string) *cgroupDriverValue {
+	*val = def
+	return &cgroupDriverValue{value: val}
+}
+
+func (v *cgroupDriverValue) String() string {
+	return *v.value
+}
+
+func (v *cgroupDriverValue) Set(s string) error {
+	if _, err := cgroupfs.NewCgroupDriver(s); err != nil {
+		return err
+	}
+	*v.value = s
+	return nil
+}
+
+type sandboxedRuntimePolicyValue struct {
+	value *string
+}
+
+func newSandboxedRuntimePolicyValue(val *string, def string) *sandboxedRuntimePolicyValue {
+	*val = def
+	return &sandboxedRuntimePolicyValue{value: val}
+}
+
+func (v *sandboxedRuntimePolicyValue) String() string {
+	return *v.value
+}
+
+func (v *sandboxedRuntimePolicyValue) Set(s string) error {
+	if s != driver.SANDBOXED_RUNTIME_POLICY_PIN && s != driver.SANDBOXED_RUNTIME_POLICY_ANNOTATE && s != driver.SANDBOXED_RUNTIME_POLICY_REJECT {
+		return fmt.Errorf("invalid value: %q, must be %s, %s or %s", s, driver.SANDBOXED_RUNTIME_POLICY_PIN, driver.SANDBOXED_RUNTIME_POLICY_ANNOTATE, driver.SANDBOXED_RUNTIME_POLICY_REJECT)
+	}
+	*v.value = s
+	return nil
+}
+
+type smtIsolationValue struct {
+	value *string
+}
+
+func newSMTIsolationValue(val *string, def string) *smtIsolationValue {
+	*val = def
+	return &smtIsolationValue{value: val}
+}
+
+func (v *smtIsolationValue) String() string {
+	return *v.value
+}
+
+func (v *smtIsolationValue) Set(s string) error {
+	if s != driver.SMT_ISOLATION_NONE && s != driver.SMT_ISOLATION_CLAIM && s != driver.SMT_ISOLATION_NAMESPACE {
+		return fmt.Errorf("invalid value: %q, must be %s, %s or %s", s, driver.SMT_ISOLATION_NONE, driver.SMT_ISOLATION_CLAIM, driver.SMT_ISOLATION_NAMESPACE)
+	}
+	*v.value = s
+	return nil
+}
+
+type enforcementBackendValue struct {
+	value *string
+}
+
+func newEnforcementBackendValue(val *string, def string) *enforcementBackendValue {
+	*val = def
+	return &enforcementBackendValue{value: val}
+}
+
+func (v *enforcementBackendValue) String() string {
+	return *v.value
+}
+
+func (v *enforcementBackendValue) Set(s string) error {
+	if s != driver.ENFORCEMENT_BACKEND_AUTO && s != driver.ENFORCEMENT_BACKEND_NRI && s != driver.ENFORCEMENT_BACKEND_CGROUPFS && s != driver.ENFORCEMENT_BACKEND_NONE {
+		return fmt.Errorf("invalid value: %q, must be %s, %s, %s or %s", s, driver.ENFORCEMENT_BACKEND_AUTO, driver.ENFORCEMENT_BACKEND_NRI, driver.ENFORCEMENT_BACKEND_CGROUPFS, driver.ENFORCEMENT_BACKEND_NONE)
+	}
+	*v.value = s
+	return nil
+}
+
+type loggingFormatValue struct {
+	value *string
+}
+
+func newLoggingFormatValue(val *string, def string) *loggingFormatValue {
+	*val = def
+	return &loggingFormatValue{value: val}
+}
+
+func (v *loggingFormatValue) String() string {
+	return *v.value
+}
+
+func (v *loggingFormatValue) Set(s string) error {
+	if s != loggingFormatText && s != loggingFormatJSON {
+		return fmt.Errorf("invalid value: %q, must be %s or %s", s, loggingFormatText, loggingFormatJSON)
 	}
 	*v.value = s
 	return nil
 }
 
+// setupLogging routes the klog output through a JSON logger when the json format is requested.
+// The klog -v verbosity keeps applying to the structured V(n) logs.
+func setupLogging(format string) error {
+	if format != loggingFormatJSON {
+		return nil
+	}
+	verbosity, err := strconv.Atoi(flag.Lookup("v").Value.String())
+	if err != nil {
+		return fmt.Errorf("invalid verbosity: %w", err)
+	}
+	// logr maps V(n) to the slog level -n.
+	handler := slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.Level(-verbosity)})
+	klog.SetLogger(logr.FromSlogHandler(handler))
+	return nil
+}
+
 func init() {
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "absolute path to the kubeconfig file")
 	flag.StringVar(&hostnameOverride, "hostname-override", "", "If non-empty, will be used as the name of the Node that kube-network-policies is running on. If unset, the node name is assumed to be the same as the node's hostname.")
 	flag.StringVar(&bindAddress, "bind-address", ":8080", "The address to bind the HTTP server for /healthz and /metrics endpoints")
+	flag.BoolVar(&metricsAuthz, "metrics-authorization", false, "Requires the /metrics, --debug-endpoints and --admin-endpoints requests to carry a bearer token authenticated with a TokenReview, whose user is allowed to get their non-resource URL by a SubjectAccessReview. /healthz stays open for the probes.")
+	flag.StringVar(&tlsCertFile, "tls-cert-file", "", "File with the x509 certificate the HTTP server serves HTTPS with, keeping the bearer tokens of --metrics-authorization off the wire. Empty serves plain HTTP.")
+	flag.StringVar(&tlsKeyFile, "tls-private-key-file", "", "File with the x509 private key matching --tls-cert-file.")
+	flag.BoolVar(&debugEndpoints, "debug-endpoints", false, "Serves the Go profiles on /debug/pprof/ and the allocator state, with the shared, allocatable and degraded CPUs, the prepared claims and the checkpoint and publication state, as JSON on /debug/state, to troubleshoot stuck allocations. Use with --metrics-authorization outside of test clusters.")
+	flag.BoolVar(&adminEndpoints, "admin-endpoints", false, "Serves POST /admin/release?claim=<uid>, used by 'dracpuctl node release', force releasing a prepared claim the kubelet can't unprepare: its CPUs go back to the shared pool, its running container is moved off them and its CDI device is removed. Also serves POST /admin/simulate-prepare, used by 'dracpuctl node simulate-prepare', running the allocator for the posted allocated claim against the current state of the node without committing anything. Requires --metrics-authorization, the callers must be allowed to post the non-resource URL.")
 	flag.StringVar(&reservedCPUs, "reserved-cpus", "", "cpuset of CPUs to be excluded from ResourceSlice.")
-	flag.Var(newCPUDeviceModeValue(&cpuDeviceMode, driver.CPU_DEVICE_MODE_GROUPED), "cpu-device-mode", "Sets the mode for exposing CPU devices. 'grouped' exposes a single device per socket or numa node (based on --group-by). 'individual' exposes each CPU as a separate device.")
-	flag.Var(newGroupByValue(&groupBy, driver.GROUP_BY_NUMA_NODE), "group-by", "When --cpu-device-mode=grouped, sets the criteria for grouping CPUs. Can be set to 'socket' or 'numanode'.")
+	flag.StringVar(&housekeepingCPUs, "housekeeping-cpus", "", "cpuset of CPUs, or 'first-core-per-numa' for the CPUs of the first physical core of each NUMA node, left to the OS housekeeping and interrupts, e.g. core 0. Unlike the --reserved-cpus, the claims can still get them: in grouped mode they are left out of the device capacity and only the claims with the 'useHousekeepingCPUs' parameter get them, in individual mode their devices are published with the NoSchedule dra.cpu/housekeeping taint only the claims tolerating it get them. Empty disables it.")
+	flag.Var(newCPUDeviceModeValue(&cpuDeviceMode, driver.CPU_DEVICE_MODE_GROUPED), "cpu-device-mode", "Sets the mode for exposing CPU devices. 'grouped' exposes a single device per socket, numa node or node (based on --group-by). 'individual' exposes each CPU as a separate device.")
+	flag.Var(newGroupByValue(&groupBy, driver.GROUP_BY_NUMA_NODE), "group-by", "When --cpu-device-mode=grouped, sets the criteria for grouping CPUs. Can be set to 'socket', 'numanode' or 'node'.")
+	flag.StringVar(&kubeletCPUState, "kubelet-cpu-manager-state", "", "If non-empty, path of the kubelet CPU Manager checkpoint (usually "+cpumanager.DefaultKubeletCheckpointPath+"). CPUs the kubelet static policy pins to Guaranteed pods not using claims are excluded from the driver allocatable pool, allowing mixed operation while migrating from the CPU Manager to DRA.")
+	flag.DurationVar(&healthCheck, "cpu-health-check-period", 0, "How often the CPUs are checked for machine check exceptions and thermal throttling. Degraded CPUs are removed from the allocatable and shared CPUs. Set to 0 to disable the checks.")
+	flag.BoolVar(&replaceDegraded, "replace-claims-on-degraded-cpus", false, "When --cpu-device-mode=grouped and --cpu-health-check-period is set, moves the claims off the CPUs found degraded, replacing them with free CPUs of the same device.")
+	flag.DurationVar(&orphanedClaimTTL, "orphaned-claim-ttl", 10*time.Minute, "How long a prepared claim whose consumer pods no longer exist is kept before its CPUs are released. Set to 0 to disable the cleanup.")
+	flag.Var(newStrategyValue(&strategy, cpumanager.StrategyPacked), "allocation-strategy", "When --cpu-device-mode=grouped, sets the default placement strategy picking the CPUs of a claim. Can be set to "+strings.Join(cpumanager.Strategies, ", ")+". Claims can override it with the 'strategy' opaque parameter.")
+	flag.StringVar(&nfdLabels, "nfd-labels", "", "Comma-separated names, without the feature.node.kubernetes.io/ prefix, of the node-feature-discovery labels of the node republished as attributes of the CPU devices, e.g. 'cpu-model.vendor_id,cpu-cpuid.AVX512F'. Empty disables the bridging.")
+	flag.StringVar(&extendedResource, "extended-resource-name", "", "Name of the node extended resource mirroring the CPUs claims can get, for schedulers, autoscalers and quota systems not aware of DRA, e.g. 'dra.cpu/exclusive-cpus'. Empty disables it.")
+	flag.Float64Var(&chaosProbability, "chaos-probability", 0, "Testing only: enables the chaos mode for soak tests, randomly delaying kubelet RPCs, failing container cpuset updates and restarting the internal controllers with this probability, between 0 and 1, while checking the CPU allocation invariants. 0 disables it.")
+	flag.StringVar(&sandboxHandlers, "sandboxed-runtime-handlers", strings.Join(driver.DefaultSandboxedRuntimeHandlers, ","), "Comma-separated RuntimeClass handlers of the VM-based and user space kernel runtimes, like kata or gVisor, whose container cgroups aren't the ones the workload runs in on the host. Empty handles all pods alike.")
+	flag.Var(newSandboxedRuntimePolicyValue(&sandboxPolicy, driver.SANDBOXED_RUNTIME_POLICY_ANNOTATE), "sandboxed-runtime-policy", "Sets how the CPUs of the pods using --sandboxed-runtime-handlers are enforced. 'pin' writes their container cpusets like for any pod. 'annotate' passes the CPUs to the runtime in the dra.cpu/cpuset.cpus container annotation instead. 'reject' fails to prepare their claims.")
+	flag.Var(newSMTIsolationValue(&smtIsolation, driver.SMT_ISOLATION_NONE), "smt-isolation", "Sets which claims may share the hyperthread siblings of a physical core, to mitigate the side channels across hyperthreads. 'none' lets any claims share them. 'claim' never gives the siblings of a core to two claims. 'namespace' never gives them to the claims of two namespaces. Claims which can only get CPUs breaking the isolation fail to prepare.")
+	flag.Var(newEnforcementBackendValue(&enforcement, driver.ENFORCEMENT_BACKEND_AUTO), "enforcement-backend", "Sets how the containers are pinned to the CPUs of their claims. 'nri' uses the NRI plugin of the container runtime. 'cgroupfs' finds the containers in the OCI bundles of containerd or CRI-O and writes their cgroup cpuset directly, for the runtimes without NRI. 'none' only accounts for the claims, and verifies whether something else pinned the containers to the CPUs of their claims, reporting the mismatches with metrics and events. 'auto' uses nri if the runtime serves the NRI socket, cgroupfs if the cgroup v2 cpuset controller and the container bundles are available, none otherwise.")
+	flag.StringVar(&cgroupRoot, "cgroup-root", cgroupfs.DefaultCgroupRoot, "Mount point of the cgroup v2 hierarchy of the host, checked by the preflight and where the cgroupfs and none enforcement backends find the cgroups of the containers, e.g. /host/sys/fs/cgroup when the driver runs in a private cgroup namespace.")
+	flag.Var(newCgroupDriverValue(&cgroupDriver, cgroupfs.CgroupDriverAuto), "cgroup-driver", "Sets how the cgroupfs and none enforcement backends name the cgroups of the containers, like the cgroup driver of the kubelet and the runtime. Can be set to "+strings.Join(cgroupfs.CgroupDrivers, ", ")+". 'auto' follows the form of the cgroupsPath of each container.")
+	flag.StringVar(&cpuPoolsConfig, "cpu-pools-config", "", "Path of a YAML file defining named CPU pools of the node, e.g. 'pools: [{name: telecom, cpus: 0-31, namespaces: [ran]}, {name: batch, cpus: 32-63, priorityClassNames: [batch-low]}]'. The claims of the namespaces, or of the pods with the priority classes, of a pool only get CPUs of the pool, and the other claims only get CPUs outside of all the pools. Empty disables the pools.")
+	flag.StringVar(&nodeConfigName, "node-config-name", "", "Name of the cluster-scoped DRACPUConfig whose settings, and overrides matching the labels of the node, override the reserved CPUs, device mode, grouping, SMT isolation and CPU pools flags. The SMT isolation and CPU pools changes apply live, the other changes restart the driver. Empty disables it.")
+	flag.BoolVar(&nodeStatus, "publish-node-status", false, "Writes the CPU allocation summary of the node, with the allocated and free CPUs and cores of each NUMA node, the prepared claims and the last error, in the cluster-scoped DRACPUNodeStatus named after the node, every minute.")
+	flag.Float64Var(&publishQPS, "publish-qps", driver.DefaultPublishQPS, "Maximum rate of the ResourceSlice publications of the node, per second. The publication requests made while one waits for the rate limiter, when the topology or the allocation state churns, are coalesced into a single publication.")
+	flag.IntVar(&publishBurst, "publish-burst", driver.DefaultPublishBurst, "Maximum burst of ResourceSlice publications above --publish-qps.")
+	flag.DurationVar(&publishResync, "publish-resync-period", 0, "How often the ResourceSlices are republished from the current state, with a 20% jitter spreading the publications of the nodes. Set to 0 to only publish on changes.")
+	flag.BoolVar(&prewarmClaims, "prewarm-claims", false, "When --cpu-device-mode=grouped, watches the pods bound to the node and pre-computes the placements of their claims allocated to the node while the pods start, so the prepare of the kubelet reuses them. A placement is recomputed if the free CPUs changed since.")
+	flag.StringVar(&journalPath, "allocation-journal", "", "Path of the file of the node the allocation decisions are appended to, one JSON line each with the CPUs of the claim, its devices, placement strategy and CPU pool and the shared CPUs they were picked from, and the releases of the CPUs, to reconstruct the CPU layout of the claims after the fact, e.g. /var/lib/kubelet/plugins/dra.cpu/allocations.journal. --debug-endpoints serves it on /debug/journal, read by 'dracpuctl node journal'. Empty disables it.")
+	flag.Int64Var(&journalMaxSize, "allocation-journal-max-size", driver.DefaultAllocationJournalMaxSize, "Size in bytes the --allocation-journal is rotated at, keeping the previous file with the .1 suffix.")
+	flag.StringVar(&snapshotPath, "allocation-snapshot", "", "Path of the file of the node the CPUs of the prepared claims are written to on shutdown, with the boot ID of the node, e.g. /var/lib/kubelet/plugins/dra.cpu/allocations.snapshot. After a reboot, the CPUs of the claims are held for 10 minutes and given back to the claims the kubelet prepares again for the pods it restarts, instead of new placements. Empty disables it.")
+	flag.BoolVar(&requireLimits, "require-cpu-limits", false, "Fails to prepare the claims consumed by containers whose cpu limit is missing or differs from their cpu request, naming each such container, so the containers pinned to exclusive CPUs aren't throttled by a lower CFS quota and their pods keep the Guaranteed QoS class.")
+	flag.StringVar(&standaloneDir, "standalone-claims-dir", "", "Runs the driver without an API server nor the DRA support of the kubelet, e.g. on air-gapped edge nodes, preparing the allocated ResourceClaims of the YAML and JSON files of the directory and unpreparing them when their file is removed. The containers reference the CDI device dra.k8s.io/cpu=claim-<uid> of a claim to be pinned. Empty uses the kubelet and the API server.")
+	flag.BoolVar(&reportAlloc, "report-allocations", false, "Records the CPUs picked for every claim, with their cores and NUMA nodes, in the data of the claim device status when it is prepared, as if all the claims set the reportAllocation parameter. 'dracpuctl describe claim' shows them.")
+	flag.StringVar(&resctrlPath, "resctrl-path", "", "Path of the resctrl filesystem, e.g. /sys/fs/resctrl, to give the claims their share of the last level cache and memory bandwidth with the cacheWays and memoryBandwidthPercent parameters. Empty disables it.")
+	flag.DurationVar(&podFailure, "pod-failure-threshold", 5*time.Minute, "How long the consumer pods of a claim with the onPodFailure=release parameter must be failed or in CrashLoopBackOff before the CPUs of the claim are released to the shared pool, until one of its containers restarts. Set to 0 to keep the CPUs of all the claims reserved.")
+	flag.Var(newLoggingFormatValue(&loggingFormat, loggingFormatText), "logging-format", "Sets the log format. Can be set to 'text' or 'json'.")
+	flag.BoolVar(&confineToNUMA, "confine-to-numa-node", false, "When --cpu-device-mode=grouped and --group-by=socket or node, allocate the CPUs of a claim from a single NUMA node (sub-NUMA cluster) within the socket.")
+}
+
+// newRestConfig creates the configuration of the API server clients, from --kubeconfig or the in-cluster config.
+func newRestConfig() *rest.Config {
+	var config *rest.Config
+	var err error
+	if kubeconfig != "" {
+		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	} else {
+		// creates the in-cluster config
+		config, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		klog.Fatalf("can not create client-go configuration: %v", err)
+	}
+
+	// use protobuf for better performance at scale
+	// https://kubernetes.io/docs/reference/using-api/api-concepts/#alternate-representations-of-resources
+	config.AcceptContentTypes = "application/vnd.kubernetes.protobuf,application/json"
+	config.ContentType = "application/vnd.kubernetes.protobuf"
+	return config
 }
 
 func main() {
 	klog.InitFlags(nil)
 	flag.Parse()
+	if err := setupLogging(loggingFormat); err != nil {
+		klog.Fatalf("failed to set up logging: %v", err)
+	}
 
-	printVersion()
+	logger := klog.Background()
+	version := printVersion(logger)
 	flag.VisitAll(func(f *flag.Flag) {
-		klog.Infof("FLAG: --%s=%q", f.Name, f.Value)
+		logger.Info("Flag", "name", f.Name, "value", f.Value.String())
 	})
 
 	reservedCPUSet, err := cpuset.Parse(reservedCPUs)
 	if err != nil {
 		klog.Fatalf("failed to parse reserved CPUs: %v", err)
 	}
+	if chaosProbability < 0 || chaosProbability > 1 {
+		klog.Fatalf("invalid chaos probability %v, must be between 0 and 1", chaosProbability)
+	}
+	if adminEndpoints && !metricsAuthz {
+		klog.Fatalf("--admin-endpoints requires --metrics-authorization")
+	}
+	if (tlsCertFile == "") != (tlsKeyFile == "") {
+		klog.Fatalf("--tls-cert-file and --tls-private-key-file must be set together")
+	}
+
+	if standaloneDir != "" && (metricsAuthz || nodeConfigName != "" || nodeStatus) {
+		klog.Fatalf("--standalone-claims-dir has no API server for --metrics-authorization, --node-config-name and --publish-node-status")
+	}
+
+	// the standalone mode has no API server, so no client.
+	var config *rest.Config
+	var clientset kubernetes.Interface
+	if standaloneDir == "" {
+		config = newRestConfig()
+		// creates the clientset
+		clientset, err = kubernetes.NewForConfig(config)
+		if err != nil {
+			klog.Fatalf("can not create client-go client: %v", err)
+		}
+	}
 
 	mux := http.NewServeMux()
 	// Add healthz handler
@@ -127,7 +422,27 @@ func main() {
 		}
 	})
 	// Add metrics handler
-	mux.Handle("/metrics", promhttp.Handler())
+	protect := func(h http.Handler) http.Handler { return h }
+	if metricsAuthz {
+		protect = httpauth.NewDelegating(clientset, httpauth.DefaultCacheTTL).Wrap
+	}
+	mux.Handle("/metrics", protect(promhttp.Handler()))
+	// Add debug handlers
+	if debugEndpoints {
+		mux.Handle("/debug/pprof/", protect(withoutWriteTimeout(http.HandlerFunc(pprof.Index))))
+		mux.Handle("/debug/pprof/cmdline", protect(http.HandlerFunc(pprof.Cmdline)))
+		mux.Handle("/debug/pprof/profile", protect(withoutWriteTimeout(http.HandlerFunc(pprof.Profile))))
+		mux.Handle("/debug/pprof/symbol", protect(http.HandlerFunc(pprof.Symbol)))
+		mux.Handle("/debug/pprof/trace", protect(withoutWriteTimeout(http.HandlerFunc(pprof.Trace))))
+		mux.Handle("/debug/state", protect(http.HandlerFunc(serveDebugState)))
+		mux.Handle("/debug/journal", protect(http.HandlerFunc(serveAllocationJournal)))
+		logger.Info("Debug endpoints enabled")
+	}
+	if adminEndpoints {
+		mux.Handle("/admin/release", protect(http.HandlerFunc(serveReleaseClaim)))
+		mux.Handle("/admin/simulate-prepare", protect(http.HandlerFunc(serveSimulatePrepare)))
+		logger.Info("Admin endpoints enabled")
+	}
 	server := &http.Server{
 		Addr:              bindAddress,
 		Handler:           mux,
@@ -137,86 +452,205 @@ func main() {
 		WriteTimeout:      10 * time.Second,
 	}
 
-	go func() {
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			klog.Errorf("HTTP server failed: %v", err)
-		}
-	}()
-
-	var config *rest.Config
-	if kubeconfig != "" {
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
-	} else {
-		// creates the in-cluster config
-		config, err = rest.InClusterConfig()
-	}
-	if err != nil {
-		klog.Fatalf("can not create client-go configuration: %v", err)
-	}
-
-	// use protobuf for better performance at scale
-	// https://kubernetes.io/docs/reference/using-api/api-concepts/#alternate-representations-of-resources
-	config.AcceptContentTypes = "application/vnd.kubernetes.protobuf,application/json"
-	config.ContentType = "application/vnd.kubernetes.protobuf"
-
-	// creates the clientset
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		klog.Fatalf("can not create client-go client: %v", err)
-	}
-
 	nodeName, err := nodeutil.GetHostname(hostnameOverride)
 	if err != nil {
 		klog.Fatalf("can not obtain the node name, use the hostname-override flag if you want to set it to a specific value: %v", err)
 	}
+	logger = klog.LoggerWithValues(logger, "node", nodeName)
 
 	// trap Ctrl+C and call cancel on the context
 	ctx := context.Background()
 	ctx, cancel := context.WithCancel(ctx)
 
+	preflightResults := preflight.Run(ctx, preflight.Config{
+		CgroupRoot:            cgroupRoot,
+		NRISocketPath:         api.DefaultSocketPath,
+		NRIOptional:           enforcement != driver.ENFORCEMENT_BACKEND_NRI,
+		PluginRegistryPath:    kubeletPluginRegistryPath,
+		ProcRoot:              "/proc",
+		KubeletCheckpointPath: cmp.Or(kubeletCPUState, cpumanager.DefaultKubeletCheckpointPath),
+		KubeletCoexistence:    kubeletCPUState != "",
+		KubeClient:            clientset,
+	})
+	if flag.Arg(0) == "preflight" {
+		if err := preflight.PrintReport(os.Stdout, preflightResults); err != nil {
+			klog.Fatalf("failed to print the preflight report: %v", err)
+		}
+		if len(preflight.Failed(preflightResults)) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+	for _, result := range preflightResults {
+		if result.Status == preflight.StatusFail || result.Status == preflight.StatusWarn {
+			logger.Info("Preflight check not passed", "check", result.Name, "status", result.Status, "message", result.Message)
+		}
+	}
+	if clientset != nil {
+		if err := preflight.SetNodeCondition(ctx, clientset, nodeName, preflightResults); err != nil {
+			logger.Error(err, "Failed to report the preflight checks on the node")
+		}
+	}
+
+	// a socket activated service serves the socket systemd listens on instead of --bind-address.
+	listeners, err := systemd.Listeners()
+	if err != nil {
+		klog.Fatalf("invalid systemd socket activation: %v", err)
+	}
+	if len(listeners) > 1 {
+		klog.Fatalf("systemd passed %d sockets, only one can be served", len(listeners))
+	}
+	go func() {
+		var err error
+		switch {
+		case len(listeners) == 1 && tlsCertFile != "":
+			err = server.ServeTLS(listeners[0], tlsCertFile, tlsKeyFile)
+		case len(listeners) == 1:
+			err = server.Serve(listeners[0])
+		case tlsCertFile != "":
+			err = server.ListenAndServeTLS(tlsCertFile, tlsKeyFile)
+		default:
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			logger.Error(err, "HTTP server failed")
+		}
+	}()
+
 	// Enable signal handler
 	signalCh := make(chan os.Signal, 2)
 	defer func() {
+		signal.Stop(signalCh)
 		close(signalCh)
 		cancel()
 	}()
-	signal.Notify(signalCh, os.Interrupt, unix.SIGINT)
+	// SIGTERM is what the kubelet, systemctl stop and the node shutdown send, Stop() must run on it
+	// to write the allocation snapshot.
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
 
 	driverConfig := &driver.Config{
-		DriverName:       driverName,
-		NodeName:         nodeName,
-		ReservedCPUs:     reservedCPUSet,
-		CpuDeviceMode:    cpuDeviceMode,
-		CPUDeviceGroupBy: groupBy,
+		DriverName:               driverName,
+		NodeName:                 nodeName,
+		ReservedCPUs:             reservedCPUSet,
+		CpuDeviceMode:            cpuDeviceMode,
+		CPUDeviceGroupBy:         groupBy,
+		ConfineToNUMANode:        confineToNUMA,
+		AllocationStrategy:       strategy,
+		KubeletCheckpointPath:    kubeletCPUState,
+		OrphanedClaimTTL:         orphanedClaimTTL,
+		CPUHealthCheckPeriod:     healthCheck,
+		ReplaceDegradedClaims:    replaceDegraded,
+		DriverVersion:            version,
+		ExtendedResourceName:     extendedResource,
+		ChaosProbability:         chaosProbability,
+		SandboxedRuntimePolicy:   sandboxPolicy,
+		ResctrlPath:              resctrlPath,
+		PodFailureThreshold:      podFailure,
+		SMTIsolation:             smtIsolation,
+		NodeConfigName:           nodeConfigName,
+		PublishNodeStatus:        nodeStatus,
+		PublishQPS:               float32(publishQPS),
+		PublishBurst:             publishBurst,
+		PublishResyncPeriod:      publishResync,
+		ReportAllocations:        reportAlloc,
+		EnforcementBackend:       enforcement,
+		HousekeepingCPUs:         housekeepingCPUs,
+		CgroupRoot:               cgroupRoot,
+		CgroupDriver:             cgroupDriver,
+		PrewarmClaims:            prewarmClaims,
+		AllocationJournalPath:    journalPath,
+		AllocationJournalMaxSize: journalMaxSize,
+		RequireCPULimits:         requireLimits,
+		AllocationSnapshotPath:   snapshotPath,
+		PublishClientConfig:      config,
+	}
+	if nfdLabels != "" {
+		driverConfig.NFDLabels = strings.Split(nfdLabels, ",")
+	}
+	if sandboxHandlers != "" {
+		driverConfig.SandboxedRuntimeHandlers = strings.Split(sandboxHandlers, ",")
+	}
+	if nodeConfigName != "" || nodeStatus {
+		driverConfig.DynamicClient, err = dynamic.NewForConfig(config)
+		if err != nil {
+			klog.Fatalf("can not create dynamic client: %v", err)
+		}
+	}
+	if cpuPoolsConfig != "" {
+		driverConfig.CPUPools, err = driver.LoadCPUPools(cpuPoolsConfig)
+		if err != nil {
+			klog.Fatalf("invalid --cpu-pools-config: %v", err)
+		}
+	}
+	var dracpu *driver.CPUDriver
+	if standaloneDir != "" {
+		dracpu, err = driver.StartStandalone(ctx, driverConfig, standaloneDir)
+	} else {
+		dracpu, err = driver.Start(ctx, clientset, driverConfig)
 	}
-	dracpu, err := driver.Start(ctx, clientset, driverConfig)
 	if err != nil {
 		klog.Fatalf("driver failed to start: %v", err)
 	}
 	defer dracpu.Stop()
+	debugDriver.Store(dracpu)
 	ready.Store(true)
-	klog.Info("driver started")
+	logger.Info("Driver started")
+	notifySystemd(klog.NewContext(ctx, logger))
 
 	select {
 	case <-signalCh:
-		klog.Infof("Exiting: received signal")
+		logger.Info("Exiting: received signal")
 		cancel()
 	case <-ctx.Done():
-		klog.Infof("Exiting: context cancelled")
+		logger.Info("Exiting: context cancelled")
+	case reason := <-dracpu.RestartRequired():
+		logger.Info("Exiting to restart with the new configuration", "reason", reason)
+		cancel()
 	}
 
+	if _, err := systemd.Notify(systemd.StateStopping); err != nil {
+		logger.Error(err, "Failed to notify systemd of the shutdown")
+	}
 	// Gracefully shutdown HTTP server
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer shutdownCancel()
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		klog.Errorf("HTTP server shutdown failed: %v", err)
+		logger.Error(err, "HTTP server shutdown failed")
 	}
 }
 
-func printVersion() {
+// notifySystemd tells systemd the driver is ready when it runs as a notify service, and pings its
+// watchdog, if enabled, while the driver is ready, until the context is done.
+func notifySystemd(ctx context.Context) {
+	logger := klog.FromContext(ctx)
+	if _, err := systemd.Notify(systemd.StateReady); err != nil {
+		logger.Error(err, "Failed to notify systemd of the readiness")
+		return
+	}
+	interval, err := systemd.WatchdogInterval()
+	if err != nil {
+		logger.Error(err, "Systemd watchdog disabled")
+		return
+	}
+	if interval == 0 {
+		return
+	}
+	go wait.UntilWithContext(ctx, func(context.Context) {
+		if !ready.Load() {
+			return
+		}
+		if _, err := systemd.Notify(systemd.StateWatchdog); err != nil {
+			logger.Error(err, "Failed to ping the systemd watchdog")
+		}
+	}, interval/2)
+}
+
+// printVersion logs the build information and returns the version of the driver:
+// the VCS revision it was built from, or the module version if not built from a checkout.
+func printVersion(logger klog.Logger) string {
 	info, ok := debug.ReadBuildInfo()
 	if !ok {
-		return
+		return "unknown"
 	}
 	var vcsRevision, vcsTime string
 	for _, f := range info.Settings {
@@ -227,5 +661,117 @@ func printVersion() {
 			vcsTime = f.Value
 		}
 	}
-	klog.Infof("dracpu go %s build: %s time: %s", info.GoVersion, vcsRevision, vcsTime)
+	logger.Info("Build information", "goVersion", info.GoVersion, "vcsRevision", vcsRevision, "vcsTime", vcsTime)
+	if vcsRevision == "" {
+		return info.Main.Version
+	}
+	return vcsRevision
+}
+
+// withoutWriteTimeout lifts the write timeout of the server for the profiles collected over a duration,
+// 30 seconds by default, which would be cut by the timeout.
+func withoutWriteTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			klog.FromContext(r.Context()).Error(err, "Failed to lift the write timeout", "path", r.URL.Path)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveDebugState writes the state of the allocator as JSON, once the driver is started.
+func serveDebugState(w http.ResponseWriter, r *http.Request) {
+	dracpu := debugDriver.Load()
+	if dracpu == nil {
+		http.Error(w, "driver not started", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(dracpu.DebugState()); err != nil {
+		klog.FromContext(r.Context()).Error(err, "Failed to write the debug state")
+	}
+}
+
+// serveAllocationJournal serves the entries of the allocation journal, of the claim whose UID is in the
+// claim parameter if set.
+func serveAllocationJournal(w http.ResponseWriter, r *http.Request) {
+	dracpu := debugDriver.Load()
+	if dracpu == nil {
+		http.Error(w, "driver not started", http.StatusServiceUnavailable)
+		return
+	}
+	entries, err := dracpu.AllocationJournal(types.UID(r.URL.Query().Get("claim")))
+	if errors.Is(err, driver.ErrJournalDisabled) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		klog.FromContext(r.Context()).Error(err, "Failed to write the allocation journal")
+	}
+}
+
+// serveReleaseClaim force releases the prepared claim whose UID is in the claim parameter.
+func serveReleaseClaim(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	claimUID := r.URL.Query().Get("claim")
+	if claimUID == "" {
+		http.Error(w, "missing claim parameter", http.StatusBadRequest)
+		return
+	}
+	dracpu := debugDriver.Load()
+	if dracpu == nil {
+		http.Error(w, "driver not started", http.StatusServiceUnavailable)
+		return
+	}
+	cpus, err := dracpu.ForceReleaseClaim(r.Context(), types.UID(claimUID))
+	if errors.Is(err, driver.ErrClaimNotPrepared) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("claim %s released with errors: %v", claimUID, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"claimUID": claimUID, "cpus": cpus.String()}); err != nil {
+		klog.FromContext(r.Context()).Error(err, "Failed to write the released claim", "claimUID", claimUID)
+	}
+}
+
+// serveSimulatePrepare writes the CPUs the posted claim, in JSON, would get if it was prepared, or why not.
+func serveSimulatePrepare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	claim := &resourceapi.ResourceClaim{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSimulatedClaimSize)).Decode(claim); err != nil {
+		http.Error(w, fmt.Sprintf("invalid claim: %v", err), http.StatusBadRequest)
+		return
+	}
+	if claim.Status.Allocation == nil {
+		http.Error(w, "the claim has no allocation", http.StatusBadRequest)
+		return
+	}
+	dracpu := debugDriver.Load()
+	if dracpu == nil {
+		http.Error(w, "driver not started", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(dracpu.SimulatePrepare(r.Context(), claim)); err != nil {
+		klog.FromContext(r.Context()).Error(err, "Failed to write the simulated prepare", "claim", klog.KObj(claim))
+	}
 }
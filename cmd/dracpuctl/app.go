@@ -0,0 +1,461 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// dracpuctl inspects the CPUs the dra.cpu driver allocated, simulates their allocation and releases stuck claims.
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/driver"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/migrateplan"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+)
+
+const usage = `Usage: dracpuctl [flags] <command>
+
+Commands:
+  describe claim [-n namespace] <name>   Shows the CPUs the driver picked for a claim.
+  node release --claim <uid> <node>      Force releases a claim prepared on a node, see --admin-endpoints.
+  node simulate-prepare --claim <file> <node>
+                                         Shows the CPUs an allocated claim would get on a node, or why it can't be prepared.
+  node journal [--claim uid] <node>      Shows the allocation decisions of a node, see --allocation-journal.
+  migrate-plan [--checkpoint path] <node>
+                                         Plans the migration of the pods pinned by the kubelet CPU Manager to claims.
+
+Flags:
+`
+
+var (
+	kubeconfig string
+	driverName string
+)
+
+func init() {
+	flag.StringVar(&kubeconfig, "kubeconfig", "", "absolute path to the kubeconfig file, defaults to $KUBECONFIG or ~/.kube/config")
+	flag.StringVar(&driverName, "driver-name", "dra.cpu", "name of the DRA driver whose devices are described")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	args := flag.Args()
+	var err error
+	switch {
+	case len(args) > 0 && args[0] == "migrate-plan":
+		err = migratePlanCommand(context.Background(), args[1:])
+	case len(args) > 1 && args[0] == "describe" && args[1] == "claim":
+		err = describeClaimCommand(context.Background(), args[2:])
+	case len(args) > 1 && args[0] == "node" && args[1] == "release":
+		err = releaseClaimCommand(context.Background(), args[2:])
+	case len(args) > 1 && args[0] == "node" && args[1] == "simulate-prepare":
+		err = simulatePrepareCommand(context.Background(), args[2:])
+	case len(args) > 1 && args[0] == "node" && args[1] == "journal":
+		err = journalCommand(context.Background(), args[2:])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newClientConfig loads the kubeconfig of the --kubeconfig flag, or the default one.
+func newClientConfig() clientcmd.ClientConfig {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{})
+}
+
+func newClientset(clientConfig clientcmd.ClientConfig) (*rest.Config, kubernetes.Interface, error) {
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("can not create client-go configuration: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can not create client-go client: %w", err)
+	}
+	return config, clientset, nil
+}
+
+func describeClaimCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("describe claim", flag.ExitOnError)
+	namespace := flags.String("n", "", "namespace of the claim, defaults to the namespace of the kubeconfig context")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("describe claim takes the name of a single claim")
+	}
+
+	clientConfig := newClientConfig()
+	if *namespace == "" {
+		ns, _, err := clientConfig.Namespace()
+		if err != nil {
+			return fmt.Errorf("can not get the namespace of the kubeconfig context: %w", err)
+		}
+		*namespace = ns
+	}
+	_, clientset, err := newClientset(clientConfig)
+	if err != nil {
+		return err
+	}
+	claim, err := clientset.ResourceV1().ResourceClaims(*namespace).Get(ctx, flags.Arg(0), metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	return describeClaim(os.Stdout, claim)
+}
+
+// describeClaim writes the devices of the driver allocated to the claim, with the CPUs recorded in their status.
+func describeClaim(out io.Writer, claim *resourceapi.ResourceClaim) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", claim.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", claim.Namespace)
+	fmt.Fprintf(w, "UID:\t%s\n", claim.UID)
+	if claim.Status.Allocation == nil {
+		fmt.Fprintf(w, "Allocation:\t<none>\n")
+		return w.Flush()
+	}
+
+	fmt.Fprintf(w, "Devices:\n")
+	for _, result := range claim.Status.Allocation.Devices.Results {
+		if result.Driver != driverName {
+			continue
+		}
+		fmt.Fprintf(w, "  %s/%s\trequest %s", result.Pool, result.Device, result.Request)
+		for _, name := range slices.Sorted(maps.Keys(result.ConsumedCapacity)) {
+			quantity := result.ConsumedCapacity[name]
+			fmt.Fprintf(w, ", %s %s", name, quantity.String())
+		}
+		fmt.Fprintln(w)
+	}
+
+	// the driver records the CPUs of the whole claim in the status of each of its devices.
+	var status *driver.ClaimAllocationStatus
+	for _, device := range claim.Status.Devices {
+		if device.Driver != driverName || device.Data == nil || len(device.Data.Raw) == 0 {
+			continue
+		}
+		status = &driver.ClaimAllocationStatus{}
+		if err := json.Unmarshal(device.Data.Raw, status); err != nil {
+			return fmt.Errorf("can not decode the status of device %s/%s: %w", device.Pool, device.Device, err)
+		}
+		break
+	}
+	if status == nil {
+		fmt.Fprintf(w, "CPUs:\t<not reported, set the reportAllocation parameter or --report-allocations>\n")
+		return w.Flush()
+	}
+	fmt.Fprintf(w, "CPUs:\t%s\n", status.CPUs)
+	fmt.Fprintf(w, "NUMA Nodes:\t%s\n", status.NUMANodes)
+	if status.PollingCPUs != "" {
+		fmt.Fprintf(w, "Polling CPUs:\t%s\n", status.PollingCPUs)
+	}
+	if status.EmulatorThreadCPUs != "" {
+		fmt.Fprintf(w, "Emulator Thread CPUs:\t%s\n", status.EmulatorThreadCPUs)
+	}
+	if len(status.NUMA) > 0 {
+		fmt.Fprintf(w, "NUMA Breakdown:\n")
+		fmt.Fprintf(w, "  NUMA NODE\tSOCKET\tCPUS\tCORES\n")
+		for _, numa := range status.NUMA {
+			fmt.Fprintf(w, "  %d\t%d\t%s\t%s\n", numa.NUMANode, numa.Socket, numa.CPUs, numa.Cores)
+		}
+	}
+	return w.Flush()
+}
+
+func releaseClaimCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("node release", flag.ExitOnError)
+	claimUID := flags.String("claim", "", "UID of the prepared claim to release")
+	admin := newAdminFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *claimUID == "" {
+		return fmt.Errorf("node release takes the --claim UID and the name of a single node")
+	}
+
+	body, err := admin.post(ctx, flags.Arg(0), "/admin/release?claim="+url.QueryEscape(*claimUID), nil)
+	if err != nil {
+		return fmt.Errorf("releasing claim %s on node %s: %w", *claimUID, flags.Arg(0), err)
+	}
+	released := struct {
+		CPUs string `json:"cpus"`
+	}{}
+	if err := json.Unmarshal(body, &released); err != nil {
+		return fmt.Errorf("can not decode the response: %w", err)
+	}
+	fmt.Printf("claim %s released on node %s, CPUs %s returned to the shared pool\n", *claimUID, flags.Arg(0), released.CPUs)
+	return nil
+}
+
+func simulatePrepareCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("node simulate-prepare", flag.ExitOnError)
+	claimFile := flags.String("claim", "", "YAML or JSON file of the allocated claim, e.g. from kubectl get resourceclaim -o yaml, - for the standard input")
+	admin := newAdminFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *claimFile == "" {
+		return fmt.Errorf("node simulate-prepare takes the --claim file and the name of a single node")
+	}
+
+	var data []byte
+	var err error
+	if *claimFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*claimFile)
+	}
+	if err != nil {
+		return fmt.Errorf("can not read the claim: %w", err)
+	}
+	claim := &resourceapi.ResourceClaim{}
+	if err := yaml.Unmarshal(data, claim); err != nil {
+		return fmt.Errorf("can not decode the claim: %w", err)
+	}
+	if claim.Status.Allocation == nil {
+		return fmt.Errorf("claim %s/%s has no allocation, the scheduler must have allocated its devices", claim.Namespace, claim.Name)
+	}
+	claimJSON, err := json.Marshal(claim)
+	if err != nil {
+		return err
+	}
+
+	body, err := admin.post(ctx, flags.Arg(0), "/admin/simulate-prepare", claimJSON)
+	if err != nil {
+		return fmt.Errorf("simulating the prepare of claim %s/%s on node %s: %w", claim.Namespace, claim.Name, flags.Arg(0), err)
+	}
+	simulated := driver.SimulatedPrepare{}
+	if err := json.Unmarshal(body, &simulated); err != nil {
+		return fmt.Errorf("can not decode the response: %w", err)
+	}
+	if simulated.Error != "" {
+		return fmt.Errorf("claim %s/%s can not be prepared on node %s: %s", claim.Namespace, claim.Name, flags.Arg(0), simulated.Error)
+	}
+	fmt.Printf("claim %s/%s would get CPUs %s on node %s\n", claim.Namespace, claim.Name, simulated.CPUs, flags.Arg(0))
+	for _, claimUID := range slices.Sorted(maps.Keys(simulated.Preempted)) {
+		fmt.Printf("  preempting claim %s, moved to CPUs %s\n", claimUID, simulated.Preempted[claimUID])
+	}
+	return nil
+}
+
+func journalCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("node journal", flag.ExitOnError)
+	claimUID := flags.String("claim", "", "UID of the claim whose allocation decisions are shown, all the claims if empty")
+	admin := newAdminFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("node journal takes the name of a single node")
+	}
+
+	path := "/debug/journal"
+	if *claimUID != "" {
+		path += "?claim=" + url.QueryEscape(*claimUID)
+	}
+	body, err := admin.get(ctx, flags.Arg(0), path)
+	if err != nil {
+		return fmt.Errorf("reading the allocation journal of node %s: %w", flags.Arg(0), err)
+	}
+	entries := []driver.JournalEntry{}
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return fmt.Errorf("can not decode the response: %w", err)
+	}
+	return printJournal(os.Stdout, entries)
+}
+
+// printJournal prints the journal entries, one per line, with the inputs of the prepares.
+func printJournal(out io.Writer, entries []driver.JournalEntry) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "TIME\tEVENT\tCLAIM\tUID\tCPUS\tDEVICES\tSTRATEGY\tPOOL\tSHARED CPUS\n")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s\t%s\t%s\t%s\t%s\t%s\n", entry.Time.Format(time.RFC3339), entry.Event, entry.Namespace, entry.Name, entry.ClaimUID,
+			entry.CPUs, cmp.Or(strings.Join(entry.Devices, ","), "-"), cmp.Or(entry.Strategy, "-"), cmp.Or(entry.Pool, "-"), cmp.Or(entry.SharedCPUs, "-"))
+	}
+	return w.Flush()
+}
+
+// adminFlags are the flags of the commands calling the --admin-endpoints or --debug-endpoints of the driver on a node.
+type adminFlags struct {
+	endpoint *string
+	scheme   *string
+	port     *int
+	insecure *bool
+}
+
+func newAdminFlags(flags *flag.FlagSet) *adminFlags {
+	return &adminFlags{
+		endpoint: flags.String("endpoint", "", "base URL of the driver HTTP server on the node, e.g. https://10.0.0.1:8080, defaults to the internal IP of the node with --scheme and --port"),
+		scheme:   flags.String("scheme", "http", "scheme of the driver HTTP server, https when it serves --tls-cert-file"),
+		port:     flags.Int("port", 8080, "port of the driver HTTP server, the one of its --bind-address"),
+		insecure: flags.Bool("insecure-skip-tls-verify", false, "skips the verification of the certificate of the driver HTTP server"),
+	}
+}
+
+// post posts the body to the admin endpoint path of the driver on the node, with the bearer token of
+// the kubeconfig, and returns the body of the response.
+func (a *adminFlags) post(ctx context.Context, nodeName, path string, body []byte) ([]byte, error) {
+	return a.do(ctx, http.MethodPost, nodeName, path, body)
+}
+
+// get gets the endpoint path of the driver on the node like post.
+func (a *adminFlags) get(ctx context.Context, nodeName, path string) ([]byte, error) {
+	return a.do(ctx, http.MethodGet, nodeName, path, nil)
+}
+
+func (a *adminFlags) do(ctx context.Context, method, nodeName, path string, body []byte) ([]byte, error) {
+	config, clientset, err := newClientset(newClientConfig())
+	if err != nil {
+		return nil, err
+	}
+	endpoint := *a.endpoint
+	if endpoint == "" {
+		node, err := clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, address := range node.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP {
+				endpoint = fmt.Sprintf("%s://%s", *a.scheme, net.JoinHostPort(address.Address, strconv.Itoa(*a.port)))
+				break
+			}
+		}
+		if endpoint == "" {
+			return nil, fmt.Errorf("node %s has no internal IP, set --endpoint", node.Name)
+		}
+	}
+
+	// the driver authenticates and authorizes the bearer token of the user with the API server.
+	token := config.BearerToken
+	if token == "" && config.BearerTokenFile != "" {
+		data, err := os.ReadFile(config.BearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("can not read the bearer token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token == "" {
+		return nil, fmt.Errorf("the kubeconfig has no bearer token, the driver only accepts bearer tokens")
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(endpoint, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		// the driver usually serves a self-signed certificate, skipping its verification is opt-in.
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: *a.insecure}}, // #nosec G402
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", response.Status, strings.TrimSpace(string(responseBody)))
+	}
+	return responseBody, nil
+}
+
+func migratePlanCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("migrate-plan", flag.ExitOnError)
+	checkpointPath := flags.String("checkpoint", cpumanager.DefaultKubeletCheckpointPath, "path of the kubelet CPU Manager checkpoint of the node, copied from the node when not running on it")
+	deviceMode := flags.String("cpu-device-mode", driver.CPU_DEVICE_MODE_GROUPED, "device mode of the driver the claims are written for, grouped or individual")
+	manifests := flags.String("manifests", "", "file the DeviceClass and ResourceClaimTemplate manifests are written to, defaults to the standard output after the plan")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("migrate-plan takes the name of a single node")
+	}
+	nodeName := flags.Arg(0)
+
+	checkpoint, err := cpumanager.ReadKubeletCheckpoint(*checkpointPath)
+	if err != nil {
+		return err
+	}
+	_, clientset, err := newClientset(newClientConfig())
+	if err != nil {
+		return err
+	}
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{FieldSelector: "spec.nodeName=" + nodeName})
+	if err != nil {
+		return err
+	}
+	plan, err := migrateplan.New(nodeName, driverName, *deviceMode, checkpoint, pods.Items)
+	if err != nil {
+		return err
+	}
+
+	if err := plan.WriteSteps(os.Stdout); err != nil {
+		return err
+	}
+	if *manifests == "" {
+		fmt.Println()
+		return plan.WriteManifests(os.Stdout)
+	}
+	file, err := os.Create(*manifests)
+	if err != nil {
+		return err
+	}
+	if err := plan.WriteManifests(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cgroupfs
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	// CgroupDriverAuto names the cgroup of each container after the form of its cgroupsPath.
+	CgroupDriverAuto = "auto"
+	// CgroupDriverSystemd names the cgroups like the systemd cgroup driver: slices and scopes.
+	CgroupDriverSystemd = "systemd"
+	// CgroupDriverCgroupfs names the cgroups like the cgroupfs cgroup driver: plain paths.
+	CgroupDriverCgroupfs = "cgroupfs"
+)
+
+// CgroupDrivers are the names of the supported cgroup drivers.
+var CgroupDrivers = []string{CgroupDriverAuto, CgroupDriverSystemd, CgroupDriverCgroupfs}
+
+// podQOSClasses are the kubepods sub-cgroups of the QoS classes, the guaranteed pods being directly under kubepods.
+var podQOSClasses = []string{"", "burstable", "besteffort"}
+
+// CgroupDriver names the cgroups of the pods and containers like the cgroup driver of the kubelet and the runtime.
+type CgroupDriver interface {
+	// ContainerDir is the cgroup of a container, relative to the cgroup root, from the cgroupsPath of its OCI config.
+	ContainerDir(cgroupsPath string) (string, error)
+	// PodDirs are the cgroups the pod with the UID may have, relative to the cgroup root, one per QoS class.
+	PodDirs(podUID string) []string
+}
+
+// NewCgroupDriver returns the cgroup driver with the name, one of CgroupDrivers. Empty is CgroupDriverAuto.
+func NewCgroupDriver(name string) (CgroupDriver, error) {
+	switch name {
+	case "", CgroupDriverAuto:
+		return autoCgroupDriver{}, nil
+	case CgroupDriverSystemd:
+		return systemdCgroupDriver{}, nil
+	case CgroupDriverCgroupfs:
+		return cgroupfsCgroupDriver{}, nil
+	default:
+		return nil, fmt.Errorf("unknown cgroup driver %q, must be one of %s", name, strings.Join(CgroupDrivers, ", "))
+	}
+}
+
+type systemdCgroupDriver struct{}
+
+// ContainerDir expands the slice:prefix:name cgroupsPath of the systemd cgroup driver, e.g.
+// kubepods-pod1234.slice:cri-containerd:abcd is kubepods.slice/kubepods-pod1234.slice/cri-containerd-abcd.scope.
+func (systemdCgroupDriver) ContainerDir(cgroupsPath string) (string, error) {
+	parts := strings.Split(cgroupsPath, ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("cgroupsPath %q is not in the slice:prefix:name form of the systemd cgroup driver", cgroupsPath)
+	}
+	slice, prefix, name := parts[0], parts[1], parts[2]
+	if strings.Contains(slice, "/") || (slice != "" && !strings.HasSuffix(slice, ".slice")) {
+		return "", fmt.Errorf("invalid slice %q in cgroupsPath %q", slice, cgroupsPath)
+	}
+	scope := name
+	if prefix != "" {
+		scope = prefix + "-" + name
+	}
+	if !strings.HasSuffix(scope, ".slice") {
+		scope += ".scope"
+	}
+	return path.Join(expandSlice(slice), escapeUnitName(scope)), nil
+}
+
+// PodDirs are the kubepods-<qos>-pod<uid>.slice of the pod, the dashes of the UID replaced by underscores
+// like the kubelet does since the dashes of a slice name separate its parents.
+func (systemdCgroupDriver) PodDirs(podUID string) []string {
+	uid := strings.ReplaceAll(podUID, "-", "_")
+	dirs := make([]string, 0, len(podQOSClasses))
+	for _, qos := range podQOSClasses {
+		slice := "kubepods-pod" + uid + ".slice"
+		if qos != "" {
+			slice = "kubepods-" + qos + "-pod" + uid + ".slice"
+		}
+		dirs = append(dirs, expandSlice(slice))
+	}
+	return dirs
+}
+
+// expandSlice is the cgroup of a slice, nested in the cgroups of its parents: a-b.slice is a.slice/a-b.slice.
+func expandSlice(slice string) string {
+	dir := ""
+	if slice == "" || slice == "-.slice" {
+		return dir
+	}
+	components := strings.Split(strings.TrimSuffix(slice, ".slice"), "-")
+	for i := range components {
+		dir = path.Join(dir, strings.Join(components[:i+1], "-")+".slice")
+	}
+	return dir
+}
+
+// escapeUnitName escapes the characters systemd doesn't allow in a unit name as \xNN, like
+// systemd-escape does, so the scope is found under the name systemd gave to its cgroup.
+func escapeUnitName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == ':', c == '_', c == '-', c == '\\':
+			b.WriteByte(c)
+		case c == '.' && i > 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		}
+	}
+	return b.String()
+}
+
+type cgroupfsCgroupDriver struct{}
+
+// ContainerDir is the cgroupsPath of the cgroupfs cgroup driver, a path relative to the cgroup root.
+func (cgroupfsCgroupDriver) ContainerDir(cgroupsPath string) (string, error) {
+	if cgroupsPath == "" {
+		return "", fmt.Errorf("empty cgroupsPath")
+	}
+	return strings.TrimPrefix(path.Clean("/"+cgroupsPath), "/"), nil
+}
+
+// PodDirs are the kubepods/<qos>/pod<uid> of the pod.
+func (cgroupfsCgroupDriver) PodDirs(podUID string) []string {
+	dirs := make([]string, 0, len(podQOSClasses))
+	for _, qos := range podQOSClasses {
+		dirs = append(dirs, path.Join("kubepods", qos, "pod"+podUID))
+	}
+	return dirs
+}
+
+type autoCgroupDriver struct{}
+
+// ContainerDir uses the systemd cgroup driver for the cgroupsPath in the slice:prefix:name form, the
+// cgroupfs one otherwise.
+func (autoCgroupDriver) ContainerDir(cgroupsPath string) (string, error) {
+	if strings.Count(cgroupsPath, ":") == 2 {
+		return systemdCgroupDriver{}.ContainerDir(cgroupsPath)
+	}
+	return cgroupfsCgroupDriver{}.ContainerDir(cgroupsPath)
+}
+
+// PodDirs are the pod cgroups of both cgroup drivers.
+func (autoCgroupDriver) PodDirs(podUID string) []string {
+	return append(systemdCgroupDriver{}.PodDirs(podUID), cgroupfsCgroupDriver{}.PodDirs(podUID)...)
+}
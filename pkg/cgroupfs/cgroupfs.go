@@ -0,0 +1,245 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cgroupfs finds the containers of the pods in the OCI bundles of the container runtime and
+// writes their CPUs in their cgroups, for the runtimes running without NRI.
+package cgroupfs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/hostfs"
+	"k8s.io/utils/cpuset"
+)
+
+// DefaultCgroupRoot is where the cgroup v2 hierarchy is usually mounted.
+const DefaultCgroupRoot = "/sys/fs/cgroup"
+
+// BundleDir is a directory holding an OCI bundle per container, in <Path>/<container ID>/<Config>.
+type BundleDir struct {
+	Path   string
+	Config string
+}
+
+// DefaultBundleDirs are the bundles of the Kubernetes containers of containerd and CRI-O.
+var DefaultBundleDirs = []BundleDir{
+	{Path: "/run/containerd/io.containerd.runtime.v2.task/k8s.io", Config: "config.json"},
+	{Path: "/run/containers/storage/overlay-containers", Config: "userdata/config.json"},
+}
+
+// The annotations the CRI runtimes add to the OCI config of the containers, containerd first then CRI-O.
+var (
+	containerTypeAnnotations = []string{"io.kubernetes.cri.container-type", "io.kubernetes.cri-o.ContainerType"}
+	containerNameAnnotations = []string{"io.kubernetes.cri.container-name", "io.kubernetes.container.name"}
+	sandboxIDAnnotations     = []string{"io.kubernetes.cri.sandbox-id", "io.kubernetes.cri-o.SandboxID"}
+	podUIDAnnotations        = []string{"io.kubernetes.cri.sandbox-uid", "io.kubernetes.pod.uid"}
+	podNameAnnotations       = []string{"io.kubernetes.cri.sandbox-name", "io.kubernetes.pod.name"}
+	podNamespaceAnnotations  = []string{"io.kubernetes.cri.sandbox-namespace", "io.kubernetes.pod.namespace"}
+)
+
+// Container is a running container of a pod, the pod sandboxes are left out.
+type Container struct {
+	ID           string
+	Name         string
+	SandboxID    string
+	PodUID       string
+	PodName      string
+	PodNamespace string
+	Env          []string
+	// CgroupDir is the cgroup of the container, relative to the cgroup root.
+	CgroupDir string
+}
+
+// ociConfig is the subset of the OCI runtime configuration of a container read by the driver.
+type ociConfig struct {
+	Process *struct {
+		Env []string `json:"env"`
+	} `json:"process"`
+	Annotations map[string]string `json:"annotations"`
+	Linux       *struct {
+		CgroupsPath string `json:"cgroupsPath"`
+	} `json:"linux"`
+}
+
+// Runtime reads the containers of a container runtime and sets their CPUs.
+type Runtime struct {
+	fsys         hostfs.FS
+	cgroupRoot   string
+	cgroupDriver CgroupDriver
+	bundleDirs   []BundleDir
+	writeFile    func(name string, data []byte) error
+}
+
+// New returns a Runtime reading the DefaultBundleDirs and the cgroups, named by the cgroup driver, under
+// the cgroup root. An empty cgroup root is DefaultCgroupRoot.
+func New(cgroupRoot string, cgroupDriver CgroupDriver) *Runtime {
+	if cgroupRoot == "" {
+		cgroupRoot = DefaultCgroupRoot
+	}
+	return &Runtime{
+		fsys:         hostfs.OS{},
+		cgroupRoot:   cgroupRoot,
+		cgroupDriver: cgroupDriver,
+		bundleDirs:   DefaultBundleDirs,
+		writeFile: func(name string, data []byte) error {
+			// #nosec G306 -- the cgroup interface files keep their mode when written.
+			return os.WriteFile(name, data, 0644)
+		},
+	}
+}
+
+// Available reports whether the cgroup v2 cpuset controller can be used and a bundle directory exists.
+func (r *Runtime) Available() error {
+	controllers, err := r.fsys.ReadFile(path.Join(r.cgroupRoot, "cgroup.controllers"))
+	if err != nil {
+		return fmt.Errorf("no cgroup v2 hierarchy at %s: %w", r.cgroupRoot, err)
+	}
+	if !slices.Contains(strings.Fields(string(controllers)), "cpuset") {
+		return fmt.Errorf("cpuset controller not available in %s", r.cgroupRoot)
+	}
+	for _, dir := range r.bundleDirs {
+		if _, err := r.fsys.Stat(dir.Path); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("no container bundles found")
+}
+
+// ListContainers returns the running containers, those whose cgroup exists.
+func (r *Runtime) ListContainers() ([]Container, error) {
+	var containers []Container
+	for _, dir := range r.bundleDirs {
+		entries, err := r.fsys.ReadDir(dir.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the container bundles of %s: %w", dir.Path, err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			ctr, ok, err := r.readContainer(entry.Name(), path.Join(dir.Path, entry.Name(), dir.Config))
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				containers = append(containers, ctr)
+			}
+		}
+	}
+	return containers, nil
+}
+
+// readContainer reads the OCI config of a container, it reports false for the sandboxes, the containers
+// of other clients of the runtime, and the containers without cgroup, not started or already stopped.
+func (r *Runtime) readContainer(id, configPath string) (Container, bool, error) {
+	data, err := r.fsys.ReadFile(configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		// The bundle is being created or removed.
+		return Container{}, false, nil
+	}
+	if err != nil {
+		return Container{}, false, fmt.Errorf("failed to read the OCI config of container %s: %w", id, err)
+	}
+	config := &ociConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return Container{}, false, fmt.Errorf("failed to parse the OCI config of container %s: %w", id, err)
+	}
+	if annotation(config.Annotations, containerTypeAnnotations) != "container" || config.Linux == nil {
+		return Container{}, false, nil
+	}
+	ctr := Container{
+		ID:           id,
+		Name:         annotation(config.Annotations, containerNameAnnotations),
+		SandboxID:    annotation(config.Annotations, sandboxIDAnnotations),
+		PodUID:       annotation(config.Annotations, podUIDAnnotations),
+		PodName:      annotation(config.Annotations, podNameAnnotations),
+		PodNamespace: annotation(config.Annotations, podNamespaceAnnotations),
+	}
+	if config.Process != nil {
+		ctr.Env = config.Process.Env
+	}
+	dir, found := r.containerDir(ctr, config.Linux.CgroupsPath)
+	if !found {
+		return Container{}, false, nil
+	}
+	ctr.CgroupDir = dir
+	return ctr, true, nil
+}
+
+// containerDir finds the cgroup of a container: the one of its cgroupsPath or, when the cgroup driver
+// doesn't name it like the runtime did, the child of the cgroup of its pod named after its ID.
+func (r *Runtime) containerDir(ctr Container, cgroupsPath string) (string, bool) {
+	if dir, err := r.cgroupDriver.ContainerDir(cgroupsPath); err == nil {
+		if _, err := r.fsys.Stat(path.Join(r.cgroupRoot, dir)); err == nil {
+			return dir, true
+		}
+	}
+	if ctr.PodUID == "" {
+		return "", false
+	}
+	for _, podDir := range r.cgroupDriver.PodDirs(ctr.PodUID) {
+		entries, err := r.fsys.ReadDir(path.Join(r.cgroupRoot, podDir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && strings.Contains(entry.Name(), ctr.ID) {
+				return path.Join(podDir, entry.Name()), true
+			}
+		}
+	}
+	return "", false
+}
+
+func annotation(annotations map[string]string, keys []string) string {
+	for _, key := range keys {
+		if value, ok := annotations[key]; ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// ReadCPUs reads the CPUs a container runs on, the effective cpuset of its cgroup.
+func (r *Runtime) ReadCPUs(ctr Container) (cpuset.CPUSet, error) {
+	data, err := r.fsys.ReadFile(path.Join(r.cgroupRoot, ctr.CgroupDir, "cpuset.cpus.effective"))
+	if err != nil {
+		return cpuset.New(), fmt.Errorf("failed to read the CPUs of container %s: %w", ctr.ID, err)
+	}
+	cpus, err := cpuset.Parse(strings.TrimSpace(string(data)))
+	if err != nil {
+		return cpuset.New(), fmt.Errorf("failed to parse the CPUs of container %s: %w", ctr.ID, err)
+	}
+	return cpus, nil
+}
+
+// SetCPUs writes the cpuset of a container.
+func (r *Runtime) SetCPUs(ctr Container, cpus string) error {
+	if err := r.writeFile(path.Join(r.cgroupRoot, ctr.CgroupDir, "cpuset.cpus"), []byte(cpus)); err != nil {
+		return fmt.Errorf("failed to set the CPUs of container %s: %w", ctr.ID, err)
+	}
+	return nil
+}
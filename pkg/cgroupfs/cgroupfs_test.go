@@ -0,0 +1,228 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cgroupfs
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/hostfs"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	containerdBundles = "/run/containerd/io.containerd.runtime.v2.task/k8s.io"
+	crioBundles       = "/run/containers/storage/overlay-containers"
+)
+
+func newTestRuntime(fake *hostfs.Fake) *Runtime {
+	return &Runtime{
+		fsys:         fake,
+		cgroupRoot:   DefaultCgroupRoot,
+		cgroupDriver: autoCgroupDriver{},
+		bundleDirs:   DefaultBundleDirs,
+		writeFile: func(name string, data []byte) error {
+			fake.AddFile(name, string(data))
+			return nil
+		},
+	}
+}
+
+func TestContainerDir(t *testing.T) {
+	testCases := []struct {
+		cgroupDriver string
+		cgroupsPath  string
+		want         string
+		wantErr      string
+	}{
+		{
+			cgroupsPath: "kubepods-besteffort-pod1234.slice:cri-containerd:abcd",
+			want:        "kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod1234.slice/cri-containerd-abcd.scope",
+		},
+		{
+			cgroupsPath: "kubepods-pod1234.slice:crio:abcd",
+			want:        "kubepods.slice/kubepods-pod1234.slice/crio-abcd.scope",
+		},
+		{cgroupsPath: "-.slice:crio:abcd", want: "crio-abcd.scope"},
+		{cgroupsPath: "/kubepods/burstable/pod1234/abcd", want: "kubepods/burstable/pod1234/abcd"},
+		{
+			cgroupDriver: CgroupDriverSystemd,
+			cgroupsPath:  "kubepods-pod1234.slice:cri-containerd:ab cd",
+			want:         `kubepods.slice/kubepods-pod1234.slice/cri-containerd-ab\x20cd.scope`,
+		},
+		{
+			cgroupDriver: CgroupDriverSystemd,
+			cgroupsPath:  "/kubepods/burstable/pod1234/abcd",
+			wantErr:      "not in the slice:prefix:name form",
+		},
+		{
+			cgroupDriver: CgroupDriverSystemd,
+			cgroupsPath:  "kubepods/pod1234:crio:abcd",
+			wantErr:      "invalid slice",
+		},
+		{
+			cgroupDriver: CgroupDriverCgroupfs,
+			cgroupsPath:  "kubepods-pod1234.slice:crio:abcd",
+			want:         "kubepods-pod1234.slice:crio:abcd",
+		},
+		{cgroupDriver: CgroupDriverCgroupfs, wantErr: "empty cgroupsPath"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.cgroupDriver+"/"+tc.cgroupsPath, func(t *testing.T) {
+			cgroupDriver, err := NewCgroupDriver(tc.cgroupDriver)
+			require.NoError(t, err)
+			dir, err := cgroupDriver.ContainerDir(tc.cgroupsPath)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, dir)
+		})
+	}
+}
+
+func TestPodDirs(t *testing.T) {
+	systemd, _ := NewCgroupDriver(CgroupDriverSystemd)
+	require.Equal(t, []string{
+		"kubepods.slice/kubepods-pod12_34.slice",
+		"kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod12_34.slice",
+		"kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod12_34.slice",
+	}, systemd.PodDirs("12-34"))
+	cgroupfs, _ := NewCgroupDriver(CgroupDriverCgroupfs)
+	require.Equal(t, []string{"kubepods/pod12-34", "kubepods/burstable/pod12-34", "kubepods/besteffort/pod12-34"}, cgroupfs.PodDirs("12-34"))
+
+	_, err := NewCgroupDriver("openrc")
+	require.ErrorContains(t, err, "unknown cgroup driver")
+}
+
+func TestListContainers(t *testing.T) {
+	fake := hostfs.NewFake(map[string]string{
+		containerdBundles + "/sandbox1/config.json": `{"annotations": {"io.kubernetes.cri.container-type": "sandbox"}, "linux": {"cgroupsPath": "kubepods-pod1.slice:cri-containerd:sandbox1"}}`,
+		containerdBundles + "/ctr1/config.json": `{
+			"process": {"env": ["PATH=/bin", "DRA_CPUSET_claim1=2-3"]},
+			"annotations": {
+				"io.kubernetes.cri.container-type": "container",
+				"io.kubernetes.cri.container-name": "app",
+				"io.kubernetes.cri.sandbox-id": "sandbox1",
+				"io.kubernetes.cri.sandbox-uid": "pod-uid-1",
+				"io.kubernetes.cri.sandbox-name": "pod1",
+				"io.kubernetes.cri.sandbox-namespace": "ns"
+			},
+			"linux": {"cgroupsPath": "kubepods-pod1.slice:cri-containerd:ctr1"}
+		}`,
+		// The container has exited, its cgroup is gone.
+		containerdBundles + "/ctr2/config.json": `{"annotations": {"io.kubernetes.cri.container-type": "container"}, "linux": {"cgroupsPath": "kubepods-pod1.slice:cri-containerd:ctr2"}}`,
+		crioBundles + "/ctr3/userdata/config.json": `{
+			"annotations": {
+				"io.kubernetes.cri-o.ContainerType": "container",
+				"io.kubernetes.container.name": "sidecar",
+				"io.kubernetes.cri-o.SandboxID": "sandbox2",
+				"io.kubernetes.pod.uid": "pod-uid-2",
+				"io.kubernetes.pod.name": "pod2",
+				"io.kubernetes.pod.namespace": "ns"
+			},
+			"linux": {"cgroupsPath": "/kubepods/pod-uid-2/ctr3"}
+		}`,
+		DefaultCgroupRoot + "/kubepods.slice/kubepods-pod1.slice/cri-containerd-ctr1.scope/cpuset.cpus": "",
+		DefaultCgroupRoot + "/kubepods/pod-uid-2/ctr3/cpuset.cpus":                                      "",
+	})
+	runtime := newTestRuntime(fake)
+
+	containers, err := runtime.ListContainers()
+	require.NoError(t, err)
+	require.Equal(t, []Container{
+		{
+			ID:           "ctr1",
+			Name:         "app",
+			SandboxID:    "sandbox1",
+			PodUID:       "pod-uid-1",
+			PodName:      "pod1",
+			PodNamespace: "ns",
+			Env:          []string{"PATH=/bin", "DRA_CPUSET_claim1=2-3"},
+			CgroupDir:    "kubepods.slice/kubepods-pod1.slice/cri-containerd-ctr1.scope",
+		},
+		{
+			ID:           "ctr3",
+			Name:         "sidecar",
+			SandboxID:    "sandbox2",
+			PodUID:       "pod-uid-2",
+			PodName:      "pod2",
+			PodNamespace: "ns",
+			CgroupDir:    "kubepods/pod-uid-2/ctr3",
+		},
+	}, containers)
+
+	require.NoError(t, runtime.SetCPUs(containers[0], "2-3"))
+	data, err := fake.ReadFile(DefaultCgroupRoot + "/kubepods.slice/kubepods-pod1.slice/cri-containerd-ctr1.scope/cpuset.cpus")
+	require.NoError(t, err)
+	require.Equal(t, "2-3", string(data))
+
+	fake.AddFile(DefaultCgroupRoot+"/kubepods.slice/kubepods-pod1.slice/cri-containerd-ctr1.scope/cpuset.cpus.effective", "2-3\n")
+	cpus, err := runtime.ReadCPUs(containers[0])
+	require.NoError(t, err)
+	require.Equal(t, "2-3", cpus.String())
+	_, err = runtime.ReadCPUs(containers[1])
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	fake.Fail(containerdBundles+"/ctr1/config.json", fs.ErrPermission)
+	_, err = runtime.ListContainers()
+	require.ErrorIs(t, err, fs.ErrPermission)
+}
+
+func TestListContainersCgroupLookup(t *testing.T) {
+	const cgroupRoot = "/host/sys/fs/cgroup"
+	fake := hostfs.NewFake(map[string]string{
+		// The runtime uses the systemd cgroup driver, but the cgroupsPath of the container is a path.
+		containerdBundles + "/ctr1/config.json": `{
+			"annotations": {"io.kubernetes.cri.container-type": "container", "io.kubernetes.cri.sandbox-uid": "pod-uid-1"},
+			"linux": {"cgroupsPath": "/kubepods/burstable/podpod-uid-1/ctr1"}
+		}`,
+		// Neither the cgroupsPath nor the pod cgroup name the container.
+		containerdBundles + "/ctr2/config.json": `{
+			"annotations": {"io.kubernetes.cri.container-type": "container", "io.kubernetes.cri.sandbox-uid": "pod-uid-1"},
+			"linux": {"cgroupsPath": ""}
+		}`,
+		cgroupRoot + "/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-podpod_uid_1.slice/cri-containerd-ctr1.scope/cpuset.cpus": "",
+	})
+	runtime := newTestRuntime(fake)
+	runtime.cgroupRoot = cgroupRoot
+
+	containers, err := runtime.ListContainers()
+	require.NoError(t, err)
+	require.Len(t, containers, 1)
+	require.Equal(t, "kubepods.slice/kubepods-burstable.slice/kubepods-burstable-podpod_uid_1.slice/cri-containerd-ctr1.scope", containers[0].CgroupDir)
+
+	require.NoError(t, runtime.SetCPUs(containers[0], "4"))
+	data, err := fake.ReadFile(cgroupRoot + "/" + containers[0].CgroupDir + "/cpuset.cpus")
+	require.NoError(t, err)
+	require.Equal(t, "4", string(data))
+}
+
+func TestAvailable(t *testing.T) {
+	fake := hostfs.NewFake(map[string]string{
+		DefaultCgroupRoot + "/cgroup.controllers": "cpu io memory pids\n",
+	})
+	runtime := newTestRuntime(fake)
+	require.ErrorContains(t, runtime.Available(), "cpuset controller not available")
+
+	fake.AddFile(DefaultCgroupRoot+"/cgroup.controllers", "cpuset cpu io memory pids\n")
+	require.ErrorContains(t, runtime.Available(), "no container bundles found")
+
+	fake.AddFile(containerdBundles+"/ctr1/config.json", "{}")
+	require.NoError(t, runtime.Available())
+}
@@ -17,14 +17,17 @@ limitations under the License.
 package cpuinfo
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/hostfs"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/cpuset"
 )
@@ -111,6 +114,28 @@ type CPUInfo struct {
 
 	// UncoreCacheID is the L3 cache ID
 	UncoreCacheID int `json:"uncoreCacheID"`
+
+	// ClusterID is the ID of the CPU cluster, e.g. an ARM DynamIQ cluster sharing a DSU,
+	// unique within each SocketID. -1 if the platform doesn't report clusters.
+	ClusterID int `json:"clusterID"`
+
+	// Capacity is the relative compute capacity of the CPU, normalized by the kernel to
+	// 1024 for the most capable CPUs of the system. 0 if the platform doesn't report it.
+	Capacity int `json:"capacity,omitempty"`
+
+	// BaseFrequencyKHz is the base, non-turbo, frequency of the CPU. 0 if the platform doesn't report it.
+	BaseFrequencyKHz int `json:"baseFrequencyKHz,omitempty"`
+
+	// MaxFrequencyKHz is the maximum, turbo, frequency of the CPU. 0 if the platform doesn't report it.
+	MaxFrequencyKHz int `json:"maxFrequencyKHz,omitempty"`
+
+	// HighestPerf is the highest performance level the firmware reports for the CPU, which ranks the
+	// favored cores of Intel Turbo Boost Max 3.0 and AMD preferred cores. 0 if the platform doesn't report it.
+	HighestPerf int `json:"highestPerf,omitempty"`
+
+	// PerformanceRank ranks the CPUs from the fastest, 1, by HighestPerf and then by MaxFrequencyKHz.
+	// CPUs equally fast share the same rank. 0 if the platform doesn't report how fast the CPUs are.
+	PerformanceRank int `json:"performanceRank,omitempty"`
 }
 
 // CPUTopology contains details of node cpu, where :
@@ -184,79 +209,6 @@ func (s *SystemCPUInfo) GetCPUTopology() (*CPUTopology, error) {
 	}, nil
 }
 
-// IsSMTEnabled checks if SMT is enabled on the system by reading /sys/devices/system/cpu/smt/control.
-func (s *SystemCPUInfo) IsSMTEnabled() (bool, error) {
-	status, err := ReadFile(hostSys("devices/system/cpu/smt/control"))
-	if err != nil {
-		return false, err
-	}
-
-	status = strings.TrimSpace(strings.ToLower(status))
-	if status == "on" {
-		return true, nil
-	}
-	if status == "off" || status == "forceoff" || status == "notsupported" {
-		return false, nil
-	}
-	return false, fmt.Errorf("unknown SMT status: %s", status)
-}
-
-// GetCPUInfos returns a slice of CPUInfo structs, one for each logical CPU.
-func (s *SystemCPUInfo) GetCPUInfos() ([]CPUInfo, error) {
-	filename := hostProc("cpuinfo")
-	lines, err := ReadLines(filename)
-	if err != nil {
-		return []CPUInfo{}, err
-	}
-
-	isHybrid := false
-	var eCoreCpus cpuset.CPUSet
-	eCoreFilename := hostSys("devices/cpu_atom/cpus")
-	if _, err := os.Stat(eCoreFilename); err == nil {
-		eCoreLines, err := ReadLines(eCoreFilename)
-		if err == nil {
-			isHybrid = true
-			eCoreCpus, err = cpuset.Parse(eCoreLines[0])
-			if err != nil {
-				return []CPUInfo{}, err
-			}
-		}
-	}
-
-	cpuInfos := []CPUInfo{}
-	var cpuInfoLines []string
-	for _, line := range lines {
-		// `/proc/cpuinfo` uses empty lines to denote a new CPU block of data.
-		if strings.TrimSpace(line) == "" {
-			// Parse and reset CPU lines.
-			cpuInfo := parseCPUInfo(isHybrid, eCoreCpus, cpuInfoLines...)
-			if cpuInfo != nil {
-				cpuInfos = append(cpuInfos, *cpuInfo)
-			}
-			cpuInfoLines = []string{}
-		} else {
-			// Gather CPU info lines for later processing.
-			cpuInfoLines = append(cpuInfoLines, line)
-		}
-	}
-	// Process the last block of cpu info.
-	if len(cpuInfoLines) > 0 {
-		cpuInfo := parseCPUInfo(isHybrid, eCoreCpus, cpuInfoLines...)
-		if cpuInfo != nil {
-			cpuInfos = append(cpuInfos, *cpuInfo)
-		}
-	}
-
-	if err := populateTopologyInfo(cpuInfos); err != nil {
-		return nil, fmt.Errorf("failed to populate topology info: %w", err)
-	}
-	if err := populateL3CacheIDs(cpuInfos); err != nil {
-		return nil, fmt.Errorf("failed to populate L3 cache IDs: %w", err)
-	}
-	populateCpuSiblings(cpuInfos)
-	return cpuInfos, nil
-}
-
 func parseCPUInfo(isHybrid bool, eCoreCpus cpuset.CPUSet, lines ...string) *CPUInfo {
 	cpuInfo := &CPUInfo{
 		CpuID:                -1,
@@ -265,6 +217,7 @@ func parseCPUInfo(isHybrid bool, eCoreCpus cpuset.CPUSet, lines ...string) *CPUI
 		NUMANodeID:           -1,
 		NumaNodeAffinityMask: "",
 		UncoreCacheID:        -1,
+		ClusterID:            -1,
 		SiblingCpuID:         -1,
 		CoreType:             CoreTypeUndefined,
 	}
@@ -317,7 +270,9 @@ func parseCPUInfo(isHybrid bool, eCoreCpus cpuset.CPUSet, lines ...string) *CPUI
 		cpuInfo.CoreType = CoreTypeStandard
 	}
 
-	if cpuInfo.CpuID < 0 || cpuInfo.SocketID < 0 || cpuInfo.CoreID < 0 {
+	// ARM kernels don't report the physical and core IDs in /proc/cpuinfo,
+	// they are read from sysfs by populateTopologyInfo.
+	if cpuInfo.CpuID < 0 {
 		return nil
 	}
 
@@ -331,7 +286,7 @@ func populateL3CacheIDs(cpuInfos []CPUInfo) error {
 		}
 
 		cachePath := hostSys(fmt.Sprintf("devices/system/cpu/cpu%d/cache", cpuInfos[i].CpuID))
-		entries, err := os.ReadDir(cachePath)
+		entries, err := hostFS.ReadDir(cachePath)
 		if err != nil {
 			return fmt.Errorf("could not read cache dir %s: %w", cachePath, err)
 		}
@@ -399,12 +354,29 @@ func populateTopologyInfo(cpuInfos []CPUInfo) error {
 		} else {
 			// Overwrite with the definitive value from sysfs
 			socketID, _ := strconv.Atoi(strings.TrimSpace(socketStr))
-			cpuInfos[i].SocketID = socketID
+			// ARM platforms not describing their packages report -1: they have a single one.
+			cpuInfos[i].SocketID = max(socketID, 0)
+		}
+
+		if coreStr, err := ReadFile(hostSys(fmt.Sprintf("devices/system/cpu/cpu%d/topology/core_id", cpuID))); err == nil {
+			if coreID, err := strconv.Atoi(strings.TrimSpace(coreStr)); err == nil {
+				cpuInfos[i].CoreID = coreID
+			}
 		}
+		if clusterStr, err := ReadFile(hostSys(fmt.Sprintf("devices/system/cpu/cpu%d/topology/cluster_id", cpuID))); err == nil {
+			// kernels without cluster support report -1.
+			if clusterID, err := strconv.Atoi(strings.TrimSpace(clusterStr)); err == nil {
+				cpuInfos[i].ClusterID = clusterID
+			}
+		}
+		cpuInfos[i].Capacity = readCPUCapacity(cpuID)
+		cpuInfos[i].BaseFrequencyKHz = readSysfsInt(fmt.Sprintf("devices/system/cpu/cpu%d/cpufreq/base_frequency", cpuID))
+		cpuInfos[i].MaxFrequencyKHz = readSysfsInt(fmt.Sprintf("devices/system/cpu/cpu%d/cpufreq/cpuinfo_max_freq", cpuID))
+		cpuInfos[i].HighestPerf = readHighestPerf(cpuID)
 
 		// Get NUMA Node ID from sysfs
 		nodePath := hostSys(fmt.Sprintf("devices/system/cpu/cpu%d", cpuID))
-		files, err := os.ReadDir(nodePath)
+		files, err := hostFS.ReadDir(nodePath)
 		if err != nil {
 			return fmt.Errorf("could not read cpu dir %s: %w", nodePath, err)
 		}
@@ -441,6 +413,90 @@ func populateTopologyInfo(cpuInfos []CPUInfo) error {
 	return nil
 }
 
+// readCPUCapacity returns the normalized compute capacity of the CPU, as computed by the kernel out of the
+// capacity-dmips-mhz of the device tree, or the raw capacity-dmips-mhz on kernels not exposing the normalized one.
+// It returns 0 if neither is available, which is the case on most x86 platforms.
+func readCPUCapacity(cpuID int) int {
+	if capacityStr, err := ReadFile(hostSys(fmt.Sprintf("devices/system/cpu/cpu%d/cpu_capacity", cpuID))); err == nil {
+		if capacity, err := strconv.Atoi(strings.TrimSpace(capacityStr)); err == nil {
+			return capacity
+		}
+	}
+	// device tree properties are big endian 32-bit cells.
+	dmips, err := hostFS.ReadFile(hostSys(fmt.Sprintf("devices/system/cpu/cpu%d/of_node/capacity-dmips-mhz", cpuID)))
+	if err != nil || len(dmips) != 4 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint32(dmips))
+}
+
+// readSysfsInt returns the integer value of a sysfs file, or 0 if it can't be read.
+func readSysfsInt(path string) int {
+	valueStr, err := ReadFile(hostSys(path))
+	if err != nil {
+		return 0
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(valueStr))
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// readHighestPerf returns the highest performance level of the CPU: the AMD preferred core ranking if
+// the amd-pstate driver exposes it, the ACPI CPPC highest performance, used by Intel ITMT, otherwise.
+func readHighestPerf(cpuID int) int {
+	if ranking := readSysfsInt(fmt.Sprintf("devices/system/cpu/cpu%d/cpufreq/amd_pstate_prefcore_ranking", cpuID)); ranking > 0 {
+		return ranking
+	}
+	return readSysfsInt(fmt.Sprintf("devices/system/cpu/cpu%d/acpi_cppc/highest_perf", cpuID))
+}
+
+// populatePerformanceRanks ranks the CPUs from the fastest, by highest performance level and then by
+// maximum frequency. It does nothing if the platform reports neither.
+func populatePerformanceRanks(cpuInfos []CPUInfo) {
+	type speed struct{ highestPerf, maxFrequency int }
+	speeds := map[speed]bool{}
+	for _, info := range cpuInfos {
+		if info.HighestPerf > 0 || info.MaxFrequencyKHz > 0 {
+			speeds[speed{info.HighestPerf, info.MaxFrequencyKHz}] = true
+		}
+	}
+	if len(speeds) == 0 {
+		return
+	}
+	for i := range cpuInfos {
+		rank := 1
+		for s := range speeds {
+			if s.highestPerf > cpuInfos[i].HighestPerf || (s.highestPerf == cpuInfos[i].HighestPerf && s.maxFrequency > cpuInfos[i].MaxFrequencyKHz) {
+				rank++
+			}
+		}
+		cpuInfos[i].PerformanceRank = rank
+	}
+}
+
+// populateCoreTypesByCapacity classifies the CPUs of big.LITTLE and DynamIQ systems, which
+// don't advertise e-cores like x86 hybrid processors: the CPUs with the highest capacity are
+// performance cores, the others efficiency cores. It does nothing if the capacities are unknown or all equal.
+func populateCoreTypesByCapacity(cpuInfos []CPUInfo) {
+	maxCapacity, minCapacity := 0, math.MaxInt
+	for _, info := range cpuInfos {
+		maxCapacity = max(maxCapacity, info.Capacity)
+		minCapacity = min(minCapacity, info.Capacity)
+	}
+	if minCapacity == 0 || minCapacity == maxCapacity {
+		return
+	}
+	for i := range cpuInfos {
+		if cpuInfos[i].Capacity == maxCapacity {
+			cpuInfos[i].CoreType = CoreTypePerformance
+		} else {
+			cpuInfos[i].CoreType = CoreTypeEfficiency
+		}
+	}
+}
+
 func populateCpuSiblings(cpuInfos []CPUInfo) {
 	// Define a key struct to identify a unique physical core.
 	type coreLocation struct {
@@ -493,7 +549,7 @@ func formatAffinityMask(mask string) string {
 
 // ReadFile reads contents from a file.
 func ReadFile(filename string) (string, error) {
-	data, err := os.ReadFile(filename)
+	data, err := hostFS.ReadFile(filename)
 	if err != nil {
 		return "", err
 	}
@@ -503,7 +559,7 @@ func ReadFile(filename string) (string, error) {
 
 // ReadLines reads contents from a file and splits them by new lines.
 func ReadLines(filename string) ([]string, error) {
-	data, err := os.ReadFile(filename)
+	data, err := hostFS.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
@@ -513,6 +569,9 @@ func ReadLines(filename string) ([]string, error) {
 	return lines, nil
 }
 
+// hostFS is the filesystem the host sysfs and procfs files are read from, replaced by the tests.
+var hostFS hostfs.FS = hostfs.OS{}
+
 func hostRoot(combineWith ...string) string {
 	return GetEnv("HOST_ROOT", "/", combineWith...)
 }
@@ -576,3 +635,17 @@ func (t *CPUTopology) CPUsPerUncore() int {
 	// Note: this is an approximation that assumes all uncore caches have the same number of CPUs.
 	return t.NumCPUs / t.NumUncoreCache
 }
+
+// NUMANodesPerSocket returns the number of NUMA nodes each socket is split into.
+// A value greater than one indicates sub-NUMA clustering (Intel SNC, AMD NPS2/NPS4),
+// where every sub-NUMA domain is reported by the kernel as a separate NUMA node.
+func (t *CPUTopology) NUMANodesPerSocket() int {
+	if t.NumSockets == 0 {
+		return 0
+	}
+	// Note: NPS0 reports a single NUMA node spanning all sockets; don't round it down to zero.
+	if t.NumNUMANodes < t.NumSockets {
+		return 1
+	}
+	return t.NumNUMANodes / t.NumSockets
+}
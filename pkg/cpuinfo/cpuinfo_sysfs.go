@@ -0,0 +1,108 @@
+//go:build !windows
+
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpuinfo
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/utils/cpuset"
+)
+
+// IsSMTEnabled checks if SMT is enabled on the system by reading /sys/devices/system/cpu/smt/control.
+func (s *SystemCPUInfo) IsSMTEnabled() (bool, error) {
+	status, err := ReadFile(hostSys("devices/system/cpu/smt/control"))
+	if err != nil {
+		return false, err
+	}
+
+	status = strings.TrimSpace(strings.ToLower(status))
+	if status == "on" {
+		return true, nil
+	}
+	if status == "off" || status == "forceoff" || status == "notsupported" {
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown SMT status: %s", status)
+}
+
+// GetCPUInfos returns a slice of CPUInfo structs, one for each logical CPU.
+func (s *SystemCPUInfo) GetCPUInfos() ([]CPUInfo, error) {
+	filename := hostProc("cpuinfo")
+	lines, err := ReadLines(filename)
+	if err != nil {
+		return []CPUInfo{}, err
+	}
+
+	isHybrid := false
+	var eCoreCpus cpuset.CPUSet
+	eCoreFilename := hostSys("devices/cpu_atom/cpus")
+	if _, err := hostFS.Stat(eCoreFilename); err == nil {
+		eCoreLines, err := ReadLines(eCoreFilename)
+		if err == nil {
+			isHybrid = true
+			eCoreCpus, err = cpuset.Parse(eCoreLines[0])
+			if err != nil {
+				return []CPUInfo{}, err
+			}
+		}
+	}
+
+	cpuInfos := []CPUInfo{}
+	var cpuInfoLines []string
+	for _, line := range lines {
+		// `/proc/cpuinfo` uses empty lines to denote a new CPU block of data.
+		if strings.TrimSpace(line) == "" {
+			// Parse and reset CPU lines.
+			cpuInfo := parseCPUInfo(isHybrid, eCoreCpus, cpuInfoLines...)
+			if cpuInfo != nil {
+				cpuInfos = append(cpuInfos, *cpuInfo)
+			}
+			cpuInfoLines = []string{}
+		} else {
+			// Gather CPU info lines for later processing.
+			cpuInfoLines = append(cpuInfoLines, line)
+		}
+	}
+	// Process the last block of cpu info.
+	if len(cpuInfoLines) > 0 {
+		cpuInfo := parseCPUInfo(isHybrid, eCoreCpus, cpuInfoLines...)
+		if cpuInfo != nil {
+			cpuInfos = append(cpuInfos, *cpuInfo)
+		}
+	}
+
+	if err := populateTopologyInfo(cpuInfos); err != nil {
+		return nil, fmt.Errorf("failed to populate topology info: %w", err)
+	}
+	if err := populateL3CacheIDs(cpuInfos); err != nil {
+		return nil, fmt.Errorf("failed to populate L3 cache IDs: %w", err)
+	}
+	for _, info := range cpuInfos {
+		if info.SocketID < 0 || info.CoreID < 0 {
+			return nil, fmt.Errorf("could not determine the socket and core of CPU %d", info.CpuID)
+		}
+	}
+	if !isHybrid {
+		populateCoreTypesByCapacity(cpuInfos)
+	}
+	populatePerformanceRanks(cpuInfos)
+	populateCpuSiblings(cpuInfos)
+	return cpuInfos, nil
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package cpuinfo
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -25,6 +28,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/hostfs"
 	"k8s.io/utils/cpuset"
 )
 
@@ -138,6 +142,15 @@ type fakeCPUTopology struct {
 	coresPerL3            int
 	hybrid                bool
 	eCores                string
+	// arm omits the physical and core IDs from /proc/cpuinfo and reports the core IDs in sysfs.
+	arm             bool
+	coresPerCluster int
+	// capacities are written to cpu_capacity, dmipsCapacities to the device tree capacity-dmips-mhz.
+	capacities      []int
+	dmipsCapacities []int
+	// maxFrequencies are written to cpufreq, with a base frequency of 2 GHz, highestPerfs to acpi_cppc.
+	maxFrequencies []int
+	highestPerfs   []int
 }
 
 func createFakeCPUTopology(t *testing.T, dir string, topo fakeCPUTopology) {
@@ -154,8 +167,12 @@ func createFakeCPUTopology(t *testing.T, dir string, topo fakeCPUTopology) {
 		socketID := i / (coresPerSocket * topo.cpusPerCore)
 		coreID := i % coresPerSocket
 		cpuinfoContent.WriteString(fmt.Sprintf("processor\t: %d\n", i))
-		cpuinfoContent.WriteString(fmt.Sprintf("physical id\t: %d\n", socketID))
-		cpuinfoContent.WriteString(fmt.Sprintf("core id\t\t: %d\n", coreID))
+		if topo.arm {
+			cpuinfoContent.WriteString("BogoMIPS\t: 50.00\n")
+		} else {
+			cpuinfoContent.WriteString(fmt.Sprintf("physical id\t: %d\n", socketID))
+			cpuinfoContent.WriteString(fmt.Sprintf("core id\t\t: %d\n", coreID))
+		}
 		cpuinfoContent.WriteString("\n")
 	}
 	if err := os.WriteFile(filepath.Join(procDir, "cpuinfo"), []byte(cpuinfoContent.String()), 0600); err != nil {
@@ -199,6 +216,54 @@ func createFakeCPUTopology(t *testing.T, dir string, topo fakeCPUTopology) {
 		if err := os.WriteFile(filepath.Join(topologyDir, "physical_package_id"), []byte(fmt.Sprintf("%d\n", socketID)), 0600); err != nil {
 			t.Fatal(err)
 		}
+		if topo.arm {
+			coreID := i % coresPerSocket
+			if err := os.WriteFile(filepath.Join(topologyDir, "core_id"), []byte(fmt.Sprintf("%d\n", coreID)), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if topo.coresPerCluster > 0 {
+			clusterID := (i % coresPerSocket) / topo.coresPerCluster
+			if err := os.WriteFile(filepath.Join(topologyDir, "cluster_id"), []byte(fmt.Sprintf("%d\n", clusterID)), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(topo.capacities) > 0 {
+			if err := os.WriteFile(filepath.Join(cpuDir, "cpu_capacity"), []byte(fmt.Sprintf("%d\n", topo.capacities[i])), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(topo.dmipsCapacities) > 0 {
+			ofNodeDir := filepath.Join(cpuDir, "of_node")
+			if err := os.Mkdir(ofNodeDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			dmips := binary.BigEndian.AppendUint32(nil, uint32(topo.dmipsCapacities[i])) //nolint:gosec
+			if err := os.WriteFile(filepath.Join(ofNodeDir, "capacity-dmips-mhz"), dmips, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(topo.maxFrequencies) > 0 {
+			cpufreqDir := filepath.Join(cpuDir, "cpufreq")
+			if err := os.Mkdir(cpufreqDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(cpufreqDir, "base_frequency"), []byte("2000000\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(cpufreqDir, "cpuinfo_max_freq"), []byte(fmt.Sprintf("%d\n", topo.maxFrequencies[i])), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(topo.highestPerfs) > 0 {
+			cppcDir := filepath.Join(cpuDir, "acpi_cppc")
+			if err := os.Mkdir(cppcDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(cppcDir, "highest_perf"), []byte(fmt.Sprintf("%d\n", topo.highestPerfs[i])), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
 
 		// node
 		cpusPerNumaNode := topo.numCoresPerNumaNode * topo.cpusPerCore
@@ -287,10 +352,10 @@ func TestGetCPUInfos(t *testing.T) {
 				hybrid:                false,
 			},
 			expectedInfos: []CPUInfo{
-				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 2, CoreType: CoreTypeStandard, UncoreCacheID: 0},
-				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 3, CoreType: CoreTypeStandard, UncoreCacheID: 0},
-				{CpuID: 2, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 0, CoreType: CoreTypeStandard, UncoreCacheID: 0},
-				{CpuID: 3, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 1, CoreType: CoreTypeStandard, UncoreCacheID: 0},
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 2, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 3, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 2, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 0, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 3, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 1, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
 			},
 		},
 		{
@@ -304,8 +369,8 @@ func TestGetCPUInfos(t *testing.T) {
 				hybrid:                false,
 			},
 			expectedInfos: []CPUInfo{
-				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: 0},
-				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: 0},
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
 			},
 		},
 		{
@@ -319,14 +384,14 @@ func TestGetCPUInfos(t *testing.T) {
 				hybrid:                false,
 			},
 			expectedInfos: []CPUInfo{
-				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 2, CoreType: CoreTypeStandard, UncoreCacheID: 0},
-				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 3, CoreType: CoreTypeStandard, UncoreCacheID: 0},
-				{CpuID: 2, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 0, CoreType: CoreTypeStandard, UncoreCacheID: 0},
-				{CpuID: 3, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 1, CoreType: CoreTypeStandard, UncoreCacheID: 0},
-				{CpuID: 4, CoreID: 0, SocketID: 1, NUMANodeID: 1, NumaNodeAffinityMask: "0xf0", SiblingCpuID: 6, CoreType: CoreTypeStandard, UncoreCacheID: 1},
-				{CpuID: 5, CoreID: 1, SocketID: 1, NUMANodeID: 1, NumaNodeAffinityMask: "0xf0", SiblingCpuID: 7, CoreType: CoreTypeStandard, UncoreCacheID: 1},
-				{CpuID: 6, CoreID: 0, SocketID: 1, NUMANodeID: 1, NumaNodeAffinityMask: "0xf0", SiblingCpuID: 4, CoreType: CoreTypeStandard, UncoreCacheID: 1},
-				{CpuID: 7, CoreID: 1, SocketID: 1, NUMANodeID: 1, NumaNodeAffinityMask: "0xf0", SiblingCpuID: 5, CoreType: CoreTypeStandard, UncoreCacheID: 1},
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 2, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 3, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 2, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 0, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 3, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 1, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 4, CoreID: 0, SocketID: 1, NUMANodeID: 1, NumaNodeAffinityMask: "0xf0", SiblingCpuID: 6, CoreType: CoreTypeStandard, UncoreCacheID: 1, ClusterID: -1},
+				{CpuID: 5, CoreID: 1, SocketID: 1, NUMANodeID: 1, NumaNodeAffinityMask: "0xf0", SiblingCpuID: 7, CoreType: CoreTypeStandard, UncoreCacheID: 1, ClusterID: -1},
+				{CpuID: 6, CoreID: 0, SocketID: 1, NUMANodeID: 1, NumaNodeAffinityMask: "0xf0", SiblingCpuID: 4, CoreType: CoreTypeStandard, UncoreCacheID: 1, ClusterID: -1},
+				{CpuID: 7, CoreID: 1, SocketID: 1, NUMANodeID: 1, NumaNodeAffinityMask: "0xf0", SiblingCpuID: 5, CoreType: CoreTypeStandard, UncoreCacheID: 1, ClusterID: -1},
 			},
 		},
 		{
@@ -341,10 +406,94 @@ func TestGetCPUInfos(t *testing.T) {
 				eCores:                "2,3",
 			},
 			expectedInfos: []CPUInfo{
-				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypePerformance, UncoreCacheID: 0},
-				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypePerformance, UncoreCacheID: 0},
-				{CpuID: 2, CoreID: 2, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypeEfficiency, UncoreCacheID: 0},
-				{CpuID: 3, CoreID: 3, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypeEfficiency, UncoreCacheID: 0},
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypePerformance, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypePerformance, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 2, CoreID: 2, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypeEfficiency, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 3, CoreID: 3, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypeEfficiency, UncoreCacheID: 0, ClusterID: -1},
+			},
+		},
+		{
+			name: "arm big.LITTLE with clusters",
+			topology: fakeCPUTopology{
+				numSockets:            1,
+				numNumaNodesPerSocket: 1,
+				numCoresPerNumaNode:   4,
+				cpusPerCore:           1,
+				coresPerL3:            4,
+				arm:                   true,
+				coresPerCluster:       2,
+				capacities:            []int{1024, 1024, 446, 446},
+			},
+			expectedInfos: []CPUInfo{
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypePerformance, UncoreCacheID: 0, ClusterID: 0, Capacity: 1024},
+				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypePerformance, UncoreCacheID: 0, ClusterID: 0, Capacity: 1024},
+				{CpuID: 2, CoreID: 2, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypeEfficiency, UncoreCacheID: 0, ClusterID: 1, Capacity: 446},
+				{CpuID: 3, CoreID: 3, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypeEfficiency, UncoreCacheID: 0, ClusterID: 1, Capacity: 446},
+			},
+		},
+		{
+			name: "arm capacity from the device tree",
+			topology: fakeCPUTopology{
+				numSockets:            1,
+				numNumaNodesPerSocket: 1,
+				numCoresPerNumaNode:   2,
+				cpusPerCore:           1,
+				coresPerL3:            2,
+				arm:                   true,
+				dmipsCapacities:       []int{578, 1024},
+			},
+			expectedInfos: []CPUInfo{
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypeEfficiency, UncoreCacheID: 0, ClusterID: -1, Capacity: 578},
+				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypePerformance, UncoreCacheID: 0, ClusterID: -1, Capacity: 1024},
+			},
+		},
+		{
+			name: "arm with equal capacities",
+			topology: fakeCPUTopology{
+				numSockets:            1,
+				numNumaNodesPerSocket: 1,
+				numCoresPerNumaNode:   2,
+				cpusPerCore:           1,
+				coresPerL3:            2,
+				arm:                   true,
+				capacities:            []int{1024, 1024},
+			},
+			expectedInfos: []CPUInfo{
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1, Capacity: 1024},
+				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1, Capacity: 1024},
+			},
+		},
+		{
+			name: "turbo boost max favored cores",
+			topology: fakeCPUTopology{
+				numSockets:            1,
+				numNumaNodesPerSocket: 1,
+				numCoresPerNumaNode:   4,
+				cpusPerCore:           1,
+				coresPerL3:            4,
+				maxFrequencies:        []int{4800000, 4800000, 4800000, 4800000},
+				highestPerfs:          []int{62, 65, 62, 58},
+			},
+			expectedInfos: []CPUInfo{
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1, BaseFrequencyKHz: 2000000, MaxFrequencyKHz: 4800000, HighestPerf: 62, PerformanceRank: 2},
+				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1, BaseFrequencyKHz: 2000000, MaxFrequencyKHz: 4800000, HighestPerf: 65, PerformanceRank: 1},
+				{CpuID: 2, CoreID: 2, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1, BaseFrequencyKHz: 2000000, MaxFrequencyKHz: 4800000, HighestPerf: 62, PerformanceRank: 2},
+				{CpuID: 3, CoreID: 3, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1, BaseFrequencyKHz: 2000000, MaxFrequencyKHz: 4800000, HighestPerf: 58, PerformanceRank: 3},
+			},
+		},
+		{
+			name: "ranked by max frequency",
+			topology: fakeCPUTopology{
+				numSockets:            1,
+				numNumaNodesPerSocket: 1,
+				numCoresPerNumaNode:   2,
+				cpusPerCore:           1,
+				coresPerL3:            2,
+				maxFrequencies:        []int{3500000, 4200000},
+			},
+			expectedInfos: []CPUInfo{
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1, BaseFrequencyKHz: 2000000, MaxFrequencyKHz: 3500000, PerformanceRank: 2},
+				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1, BaseFrequencyKHz: 2000000, MaxFrequencyKHz: 4200000, PerformanceRank: 1},
 			},
 		},
 		{
@@ -359,8 +508,8 @@ func TestGetCPUInfos(t *testing.T) {
 				eCores:                "",
 			},
 			expectedInfos: []CPUInfo{
-				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypePerformance, UncoreCacheID: 0},
-				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypePerformance, UncoreCacheID: 0},
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypePerformance, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypePerformance, UncoreCacheID: 0, ClusterID: -1},
 			},
 		},
 	}
@@ -412,9 +561,18 @@ func TestGetCPUInfos_ErrorScenarios(t *testing.T) {
 			},
 			expectedErrorSubstring: "", // Should warn and continue, not error out
 			expectedInfos: []CPUInfo{
-				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x1", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: 0},
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x1", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
 			},
 		},
+		{
+			name: "arm missing core_id",
+			setup: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "proc/cpuinfo"), []byte("processor\t: 0\n\n"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			expectedErrorSubstring: "could not determine the socket and core of CPU 0",
+		},
 		{
 			name: "missing cpumap",
 			setup: func(t *testing.T, dir string) {
@@ -565,3 +723,31 @@ func TestSMTDetection(t *testing.T) {
 		})
 	}
 }
+
+// withFakeHostFS reads the host files from an in-memory filesystem for the duration of the test.
+func withFakeHostFS(t *testing.T, files map[string]string) *hostfs.Fake {
+	t.Helper()
+	t.Setenv("HOST_ROOT", "/")
+	fake := hostfs.NewFake(files)
+	previous := hostFS
+	hostFS = fake
+	t.Cleanup(func() { hostFS = previous })
+	return fake
+}
+
+func TestSMTDetectionUnreadableControl(t *testing.T) {
+	fake := withFakeHostFS(t, map[string]string{"/sys/devices/system/cpu/smt/control": "on\n"})
+	s := NewSystemCPUInfo()
+	if enabled, err := s.IsSMTEnabled(); err != nil || !enabled {
+		t.Fatalf("expected SMT enabled, got %v, %v", enabled, err)
+	}
+	fake.Fail("/sys/devices/system/cpu/smt/control", fs.ErrPermission)
+	if _, err := s.IsSMTEnabled(); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("expected a permission error, got %v", err)
+	}
+	fake.Fail("/sys/devices/system/cpu/smt/control", nil)
+	fake.Remove("/sys/devices/system/cpu/smt")
+	if _, err := s.IsSMTEnabled(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected a not exist error on kernels without SMT control, got %v", err)
+	}
+}
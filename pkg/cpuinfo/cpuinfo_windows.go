@@ -0,0 +1,67 @@
+//go:build windows
+
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpuinfo
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procGetLogicalProcessorInformationEx = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetLogicalProcessorInformationEx")
+
+// IsSMTEnabled checks if SMT is enabled on the system by looking for physical cores with more than one logical processor.
+func (s *SystemCPUInfo) IsSMTEnabled() (bool, error) {
+	cpuInfos, err := s.GetCPUInfos()
+	if err != nil {
+		return false, err
+	}
+	for _, info := range cpuInfos {
+		if info.SiblingCpuID != -1 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetCPUInfos returns a slice of CPUInfo structs, one for each logical CPU.
+func (s *SystemCPUInfo) GetCPUInfos() ([]CPUInfo, error) {
+	buf, err := getLogicalProcessorInformationEx()
+	if err != nil {
+		return nil, err
+	}
+	return parseLogicalProcessorInformation(buf)
+}
+
+func getLogicalProcessorInformationEx() ([]byte, error) {
+	var size uint32
+	// the first call only returns the size of the buffer needed.
+	r, _, err := procGetLogicalProcessorInformationEx.Call(uintptr(relationAll), 0, uintptr(unsafe.Pointer(&size)))
+	if r == 0 && !errors.Is(err, windows.ERROR_INSUFFICIENT_BUFFER) {
+		return nil, fmt.Errorf("GetLogicalProcessorInformationEx failed: %w", err)
+	}
+	buf := make([]byte, size)
+	r, _, err = procGetLogicalProcessorInformationEx.Call(uintptr(relationAll), uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)))
+	if r == 0 {
+		return nil, fmt.Errorf("GetLogicalProcessorInformationEx failed: %w", err)
+	}
+	return buf[:size], nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpuinfo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CPUHealthCounters are the cumulative counters of the hardware events signaling a failing or overheating CPU.
+type CPUHealthCounters struct {
+	// MachineChecks is the number of machine check exceptions handled by the CPU.
+	MachineChecks uint64
+	// ThermalThrottles is the number of times the core of the CPU was throttled because it was too hot.
+	ThermalThrottles uint64
+}
+
+// ReadCPUHealthCounters returns the health counters of the online CPUs. The machine check exceptions
+// are read from /proc/interrupts, the thermal throttling events from sysfs. Counters the platform
+// doesn't expose, like the thermal throttling ones on most non-x86 platforms, are left to 0.
+func ReadCPUHealthCounters() (map[int]CPUHealthCounters, error) {
+	lines, err := ReadLines(hostProc("interrupts"))
+	if err != nil {
+		return nil, err
+	}
+	counters, err := parseMachineChecks(lines)
+	if err != nil {
+		return nil, err
+	}
+	for cpuID, c := range counters {
+		countStr, err := ReadFile(hostSys(fmt.Sprintf("devices/system/cpu/cpu%d/thermal_throttle/core_throttle_count", cpuID)))
+		if err != nil {
+			continue
+		}
+		if c.ThermalThrottles, err = strconv.ParseUint(strings.TrimSpace(countStr), 10, 64); err != nil {
+			return nil, fmt.Errorf("failed to parse thermal throttle count %q for CPU %d: %w", countStr, cpuID, err)
+		}
+		counters[cpuID] = c
+	}
+	return counters, nil
+}
+
+// parseMachineChecks returns the counters of the CPUs listed in the header of /proc/interrupts,
+// with the count of machine check exceptions taken from the MCE line, when the platform reports it.
+func parseMachineChecks(lines []string) (map[int]CPUHealthCounters, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("empty interrupts file")
+	}
+	// the header lists the online CPUs, e.g. "CPU0 CPU1 CPU3".
+	var cpuIDs []int
+	for _, field := range strings.Fields(lines[0]) {
+		cpuID, err := strconv.Atoi(strings.TrimPrefix(field, "CPU"))
+		if err != nil || !strings.HasPrefix(field, "CPU") {
+			return nil, fmt.Errorf("invalid interrupts header %q", lines[0])
+		}
+		cpuIDs = append(cpuIDs, cpuID)
+	}
+	counters := make(map[int]CPUHealthCounters, len(cpuIDs))
+	for _, cpuID := range cpuIDs {
+		counters[cpuID] = CPUHealthCounters{}
+	}
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != "MCE:" {
+			continue
+		}
+		if len(fields) < len(cpuIDs)+1 {
+			return nil, fmt.Errorf("invalid machine check exceptions line %q", line)
+		}
+		for i, cpuID := range cpuIDs {
+			count, err := strconv.ParseUint(fields[i+1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse machine check exceptions count %q for CPU %d: %w", fields[i+1], cpuID, err)
+			}
+			counters[cpuID] = CPUHealthCounters{MachineChecks: count}
+		}
+		break
+	}
+	return counters, nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpuinfo
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testInterrupts = `           CPU0       CPU1       CPU3
+  0:         33          0          0   IO-APIC   2-edge      timer
+NMI:          1          2          3   Non-maskable interrupts
+MCE:          0          2          0   Machine check exceptions
+MCP:        123        123        123   Machine check polls
+`
+
+func TestReadCPUHealthCounters(t *testing.T) {
+	testCases := []struct {
+		name             string
+		interrupts       string
+		throttleCounts   map[int]string
+		expectedError    bool
+		expectedCounters map[int]CPUHealthCounters
+	}{
+		{
+			name:           "machine checks and thermal throttling",
+			interrupts:     testInterrupts,
+			throttleCounts: map[int]string{0: "5\n", 1: "0\n", 3: "0\n"},
+			expectedCounters: map[int]CPUHealthCounters{
+				0: {ThermalThrottles: 5},
+				1: {MachineChecks: 2},
+				3: {},
+			},
+		},
+		{
+			name:       "no machine check exceptions nor thermal throttling reported",
+			interrupts: "           CPU0       CPU1\n  0:         33          0   IO-APIC   2-edge      timer\n",
+			expectedCounters: map[int]CPUHealthCounters{
+				0: {},
+				1: {},
+			},
+		},
+		{
+			name:          "invalid header",
+			interrupts:    "  0:         33          0   IO-APIC   2-edge      timer\n",
+			expectedError: true,
+		},
+		{
+			name:          "truncated machine check exceptions line",
+			interrupts:    "           CPU0       CPU1\nMCE:          0\n",
+			expectedError: true,
+		},
+		{
+			name:           "invalid thermal throttle count",
+			interrupts:     testInterrupts,
+			throttleCounts: map[int]string{0: "many\n"},
+			expectedError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			t.Setenv("HOST_ROOT", tmpDir)
+			if err := os.MkdirAll(filepath.Join(tmpDir, "proc"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(tmpDir, "proc/interrupts"), []byte(tc.interrupts), 0600); err != nil {
+				t.Fatal(err)
+			}
+			for cpuID, count := range tc.throttleCounts {
+				dir := filepath.Join(tmpDir, "sys/devices/system/cpu", fmt.Sprintf("cpu%d", cpuID), "thermal_throttle")
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "core_throttle_count"), []byte(count), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			counters, err := ReadCPUHealthCounters()
+			if tc.expectedError {
+				if err == nil {
+					t.Fatal("expected an error, but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(counters, tc.expectedCounters) {
+				t.Errorf("expected %+v, got %+v", tc.expectedCounters, counters)
+			}
+		})
+	}
+}
+
+func TestReadCPUHealthCountersMissingInterrupts(t *testing.T) {
+	t.Setenv("HOST_ROOT", t.TempDir())
+	if _, err := ReadCPUHealthCounters(); err == nil || !strings.Contains(err.Error(), "interrupts") {
+		t.Errorf("expected an error about the missing interrupts file, got %v", err)
+	}
+}
+
+func TestReadCPUHealthCountersUnreadableThrottleCount(t *testing.T) {
+	fake := withFakeHostFS(t, map[string]string{
+		"/proc/interrupts": testInterrupts,
+		"/sys/devices/system/cpu/cpu0/thermal_throttle/core_throttle_count": "5\n",
+		"/sys/devices/system/cpu/cpu1/thermal_throttle/core_throttle_count": "7\n",
+	})
+	// an unreadable counter is left to 0, like one the platform doesn't expose.
+	fake.Fail("/sys/devices/system/cpu/cpu1/thermal_throttle/core_throttle_count", fs.ErrPermission)
+	counters, err := ReadCPUHealthCounters()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[int]CPUHealthCounters{
+		0: {MachineChecks: 0, ThermalThrottles: 5},
+		1: {MachineChecks: 2},
+		3: {MachineChecks: 0},
+	}
+	if !reflect.DeepEqual(counters, expected) {
+		t.Errorf("expected %+v, got %+v", expected, counters)
+	}
+
+	fake.Fail("/proc/interrupts", fs.ErrPermission)
+	if _, err := ReadCPUHealthCounters(); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("expected a permission error, got %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpuinfo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Relationships reported by GetLogicalProcessorInformationEx, see
+// https://learn.microsoft.com/en-us/windows/win32/api/winnt/ns-winnt-system_logical_processor_information_ex
+const (
+	relationProcessorCore    = 0
+	relationNumaNode         = 1
+	relationCache            = 2
+	relationProcessorPackage = 3
+	relationAll              = 0xffff
+
+	// Windows splits the logical processors in groups of at most 64.
+	processorsPerGroup = 64
+	// size of a GROUP_AFFINITY on 64-bit Windows: KAFFINITY Mask, WORD Group, WORD Reserved[3].
+	groupAffinitySize = 16
+	// PROCESSOR_RELATIONSHIP: BYTE Flags, BYTE EfficiencyClass, BYTE Reserved[20], WORD GroupCount, GROUP_AFFINITY GroupMask[].
+	processorGroupCountOffset = 22
+	// NUMA_NODE_RELATIONSHIP: DWORD NodeNumber, BYTE Reserved[18], WORD GroupCount, GROUP_AFFINITY GroupMask[].
+	numaNodeGroupCountOffset = 22
+	// CACHE_RELATIONSHIP: BYTE Level, BYTE Associativity, WORD LineSize, DWORD CacheSize, DWORD Type,
+	// BYTE Reserved[18], WORD GroupCount, GROUP_AFFINITY GroupMask[].
+	cacheGroupCountOffset = 30
+)
+
+// parseLogicalProcessorInformation builds a CPUInfo for each logical processor out of the
+// SYSTEM_LOGICAL_PROCESSOR_INFORMATION_EX records returned by GetLogicalProcessorInformationEx(RelationAll).
+// The CPU ID of a logical processor is group*64 + its index in the group, so machines with
+// more than one processor group may have gaps in the CPU IDs.
+// Windows does not number cores, packages and caches: they get increasing IDs in the order
+// they are reported.
+func parseLogicalProcessorInformation(buf []byte) ([]CPUInfo, error) {
+	infos := make(map[int]*CPUInfo)
+	getInfo := func(cpuID int) *CPUInfo {
+		info, ok := infos[cpuID]
+		if !ok {
+			info = &CPUInfo{
+				CpuID:         cpuID,
+				SocketID:      -1,
+				CoreID:        -1,
+				NUMANodeID:    -1,
+				UncoreCacheID: -1,
+				ClusterID:     -1,
+				SiblingCpuID:  -1,
+				CoreType:      CoreTypeStandard,
+			}
+			infos[cpuID] = info
+		}
+		return info
+	}
+	efficiencyClasses := make(map[int]byte)
+	numaMasks := make(map[int]*big.Int)
+	coreID, socketID, uncoreCacheID := 0, 0, 0
+
+	for offset := 0; offset < len(buf); {
+		if len(buf)-offset < 8 {
+			return nil, fmt.Errorf("truncated logical processor information record at offset %d", offset)
+		}
+		relationship := binary.LittleEndian.Uint32(buf[offset:])
+		size := int(binary.LittleEndian.Uint32(buf[offset+4:]))
+		if size < 8 || offset+size > len(buf) {
+			return nil, fmt.Errorf("invalid logical processor information record size %d at offset %d", size, offset)
+		}
+		record := buf[offset+8 : offset+size]
+		offset += size
+
+		switch relationship {
+		case relationProcessorCore:
+			cpus, err := groupAffinityCPUs(record, processorGroupCountOffset)
+			if err != nil {
+				return nil, fmt.Errorf("processor core %d: %w", coreID, err)
+			}
+			for _, cpu := range cpus {
+				getInfo(cpu).CoreID = coreID
+				efficiencyClasses[cpu] = record[1]
+			}
+			coreID++
+		case relationProcessorPackage:
+			cpus, err := groupAffinityCPUs(record, processorGroupCountOffset)
+			if err != nil {
+				return nil, fmt.Errorf("processor package %d: %w", socketID, err)
+			}
+			for _, cpu := range cpus {
+				getInfo(cpu).SocketID = socketID
+			}
+			socketID++
+		case relationNumaNode:
+			cpus, err := groupAffinityCPUs(record, numaNodeGroupCountOffset)
+			if err != nil {
+				return nil, fmt.Errorf("NUMA node: %w", err)
+			}
+			node := int(binary.LittleEndian.Uint32(record))
+			mask, ok := numaMasks[node]
+			if !ok {
+				mask = new(big.Int)
+				numaMasks[node] = mask
+			}
+			for _, cpu := range cpus {
+				getInfo(cpu).NUMANodeID = node
+				mask.SetBit(mask, cpu, 1)
+			}
+		case relationCache:
+			// only the L3 caches are uncore caches.
+			if len(record) == 0 || record[0] != 3 {
+				continue
+			}
+			cpus, err := groupAffinityCPUs(record, cacheGroupCountOffset)
+			if err != nil {
+				return nil, fmt.Errorf("L3 cache %d: %w", uncoreCacheID, err)
+			}
+			for _, cpu := range cpus {
+				getInfo(cpu).UncoreCacheID = uncoreCacheID
+			}
+			uncoreCacheID++
+		}
+	}
+
+	// hybrid processors report a higher efficiency class for the performance cores.
+	minClass, maxClass := byte(255), byte(0)
+	for _, class := range efficiencyClasses {
+		minClass = min(minClass, class)
+		maxClass = max(maxClass, class)
+	}
+
+	cpuInfos := make([]CPUInfo, 0, len(infos))
+	for cpuID, info := range infos {
+		if info.CoreID < 0 || info.SocketID < 0 {
+			return nil, fmt.Errorf("no core or package reported for logical processor %d", cpuID)
+		}
+		if minClass != maxClass {
+			if efficiencyClasses[cpuID] == minClass {
+				info.CoreType = CoreTypeEfficiency
+			} else {
+				info.CoreType = CoreTypePerformance
+			}
+		}
+		if mask, ok := numaMasks[info.NUMANodeID]; ok {
+			info.NumaNodeAffinityMask = fmt.Sprintf("0x%x", mask)
+		}
+		cpuInfos = append(cpuInfos, *info)
+	}
+	sort.Slice(cpuInfos, func(i, j int) bool {
+		return cpuInfos[i].CpuID < cpuInfos[j].CpuID
+	})
+	populateCpuSiblings(cpuInfos)
+	return cpuInfos, nil
+}
+
+// groupAffinityCPUs returns the CPU IDs of the GROUP_AFFINITY array following the group count at countOffset.
+// Windows versions older than Windows Server 2022 report a group count of 0 for NUMA nodes
+// and caches, followed by a single GROUP_AFFINITY.
+func groupAffinityCPUs(record []byte, countOffset int) ([]int, error) {
+	if len(record) < countOffset+2 {
+		return nil, fmt.Errorf("record too short: %d bytes", len(record))
+	}
+	count := int(binary.LittleEndian.Uint16(record[countOffset:]))
+	if count == 0 {
+		count = 1
+	}
+	masks := record[countOffset+2:]
+	if len(masks) < count*groupAffinitySize {
+		return nil, fmt.Errorf("record too short for %d group affinities: %d bytes", count, len(record))
+	}
+	var cpus []int
+	for i := range count {
+		entry := masks[i*groupAffinitySize:]
+		mask := binary.LittleEndian.Uint64(entry)
+		group := int(binary.LittleEndian.Uint16(entry[8:]))
+		for bit := range processorsPerGroup {
+			if mask&(1<<bit) != 0 {
+				cpus = append(cpus, group*processorsPerGroup+bit)
+			}
+		}
+	}
+	return cpus, nil
+}
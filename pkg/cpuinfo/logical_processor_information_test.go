@@ -0,0 +1,179 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpuinfo
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// groupAffinity encodes a GROUP_AFFINITY.
+func groupAffinity(group uint16, mask uint64) []byte {
+	b := make([]byte, groupAffinitySize)
+	binary.LittleEndian.PutUint64(b, mask)
+	binary.LittleEndian.PutUint16(b[8:], group)
+	return b
+}
+
+// logicalProcessorRecord encodes a SYSTEM_LOGICAL_PROCESSOR_INFORMATION_EX record.
+func logicalProcessorRecord(relationship uint32, payload []byte) []byte {
+	b := make([]byte, 8, 8+len(payload))
+	binary.LittleEndian.PutUint32(b, relationship)
+	binary.LittleEndian.PutUint32(b[4:], uint32(8+len(payload)))
+	return append(b, payload...)
+}
+
+func processorRecord(relationship uint32, efficiencyClass byte, affinities ...[]byte) []byte {
+	payload := make([]byte, processorGroupCountOffset+2)
+	payload[1] = efficiencyClass
+	binary.LittleEndian.PutUint16(payload[processorGroupCountOffset:], uint16(len(affinities)))
+	for _, affinity := range affinities {
+		payload = append(payload, affinity...)
+	}
+	return logicalProcessorRecord(relationship, payload)
+}
+
+func numaNodeRecord(node uint32, groupCount uint16, affinity []byte) []byte {
+	payload := make([]byte, numaNodeGroupCountOffset+2)
+	binary.LittleEndian.PutUint32(payload, node)
+	binary.LittleEndian.PutUint16(payload[numaNodeGroupCountOffset:], groupCount)
+	return logicalProcessorRecord(relationNumaNode, append(payload, affinity...))
+}
+
+func cacheRecord(level byte, affinity []byte) []byte {
+	payload := make([]byte, cacheGroupCountOffset+2)
+	payload[0] = level
+	binary.LittleEndian.PutUint16(payload[cacheGroupCountOffset:], 1)
+	return logicalProcessorRecord(relationCache, append(payload, affinity...))
+}
+
+func concat(records ...[]byte) []byte {
+	var b []byte
+	for _, r := range records {
+		b = append(b, r...)
+	}
+	return b
+}
+
+func TestParseLogicalProcessorInformation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		buf      []byte
+		expected []CPUInfo
+	}{
+		{
+			name: "single socket, 2 cores with hyperthreading",
+			buf: concat(
+				processorRecord(relationProcessorCore, 0, groupAffinity(0, 0b0011)),
+				processorRecord(relationProcessorCore, 0, groupAffinity(0, 0b1100)),
+				cacheRecord(2, groupAffinity(0, 0b0011)),
+				cacheRecord(2, groupAffinity(0, 0b1100)),
+				cacheRecord(3, groupAffinity(0, 0b1111)),
+				processorRecord(relationProcessorPackage, 0, groupAffinity(0, 0b1111)),
+				// older Windows versions report no group count.
+				numaNodeRecord(0, 0, groupAffinity(0, 0b1111)),
+			),
+			expected: []CPUInfo{
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 1, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 1, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 0, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 2, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 3, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
+				{CpuID: 3, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 2, CoreType: CoreTypeStandard, UncoreCacheID: 0, ClusterID: -1},
+			},
+		},
+		{
+			name: "two sockets in different processor groups",
+			buf: concat(
+				processorRecord(relationProcessorCore, 0, groupAffinity(0, 0b01)),
+				processorRecord(relationProcessorCore, 0, groupAffinity(0, 0b10)),
+				processorRecord(relationProcessorCore, 0, groupAffinity(1, 0b01)),
+				processorRecord(relationProcessorCore, 0, groupAffinity(1, 0b10)),
+				processorRecord(relationProcessorPackage, 0, groupAffinity(0, 0b11)),
+				processorRecord(relationProcessorPackage, 0, groupAffinity(1, 0b11)),
+				numaNodeRecord(0, 1, groupAffinity(0, 0b11)),
+				numaNodeRecord(1, 1, groupAffinity(1, 0b11)),
+			),
+			expected: []CPUInfo{
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: -1, ClusterID: -1},
+				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0x3", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: -1, ClusterID: -1},
+				{CpuID: 64, CoreID: 2, SocketID: 1, NUMANodeID: 1, NumaNodeAffinityMask: "0x30000000000000000", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: -1, ClusterID: -1},
+				{CpuID: 65, CoreID: 3, SocketID: 1, NUMANodeID: 1, NumaNodeAffinityMask: "0x30000000000000000", SiblingCpuID: -1, CoreType: CoreTypeStandard, UncoreCacheID: -1, ClusterID: -1},
+			},
+		},
+		{
+			name: "hybrid processor",
+			buf: concat(
+				processorRecord(relationProcessorCore, 1, groupAffinity(0, 0b0011)),
+				processorRecord(relationProcessorCore, 0, groupAffinity(0, 0b0100)),
+				processorRecord(relationProcessorCore, 0, groupAffinity(0, 0b1000)),
+				processorRecord(relationProcessorPackage, 0, groupAffinity(0, 0b1111)),
+				numaNodeRecord(0, 1, groupAffinity(0, 0b1111)),
+			),
+			expected: []CPUInfo{
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 1, CoreType: CoreTypePerformance, UncoreCacheID: -1, ClusterID: -1},
+				{CpuID: 1, CoreID: 0, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: 0, CoreType: CoreTypePerformance, UncoreCacheID: -1, ClusterID: -1},
+				{CpuID: 2, CoreID: 1, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypeEfficiency, UncoreCacheID: -1, ClusterID: -1},
+				{CpuID: 3, CoreID: 2, SocketID: 0, NUMANodeID: 0, NumaNodeAffinityMask: "0xf", SiblingCpuID: -1, CoreType: CoreTypeEfficiency, UncoreCacheID: -1, ClusterID: -1},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cpuInfos, err := parseLogicalProcessorInformation(tc.buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(cpuInfos, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, cpuInfos)
+			}
+		})
+	}
+}
+
+func TestParseLogicalProcessorInformation_ErrorScenarios(t *testing.T) {
+	core := processorRecord(relationProcessorCore, 0, groupAffinity(0, 0b1))
+	testCases := []struct {
+		name string
+		buf  []byte
+	}{
+		{
+			name: "truncated header",
+			buf:  core[:4],
+		},
+		{
+			name: "record larger than the buffer",
+			buf:  core[:len(core)-1],
+		},
+		{
+			name: "missing group affinity",
+			buf:  logicalProcessorRecord(relationProcessorCore, make([]byte, processorGroupCountOffset)),
+		},
+		{
+			name: "processor without package",
+			buf:  core,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := parseLogicalProcessorInformation(tc.buf); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
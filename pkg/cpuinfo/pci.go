@@ -0,0 +1,45 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpuinfo
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pciAddressRegexp matches the PCI addresses in the extended domain:bus:device.function format, e.g. 0000:3b:00.0.
+var pciAddressRegexp = regexp.MustCompile(`^[0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]$`)
+
+// PCIDeviceNUMANode returns the NUMA node the kernel reports for the PCI device with the given address.
+// It returns -1 if the platform doesn't report the NUMA affinity of the device, e.g. on single NUMA node machines.
+func PCIDeviceNUMANode(address string) (int, error) {
+	address = strings.ToLower(address)
+	if !pciAddressRegexp.MatchString(address) {
+		return -1, fmt.Errorf("invalid PCI address %q", address)
+	}
+	numaNodeStr, err := ReadFile(hostSys("bus/pci/devices", address, "numa_node"))
+	if err != nil {
+		return -1, fmt.Errorf("could not read NUMA node of PCI device %s: %w", address, err)
+	}
+	numaNode, err := strconv.Atoi(strings.TrimSpace(numaNodeStr))
+	if err != nil {
+		return -1, fmt.Errorf("failed to parse NUMA node %q of PCI device %s: %w", numaNodeStr, address, err)
+	}
+	return max(numaNode, -1), nil
+}
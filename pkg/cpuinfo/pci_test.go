@@ -0,0 +1,99 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpuinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPCIDeviceNUMANode(t *testing.T) {
+	testCases := []struct {
+		name             string
+		address          string
+		numaNode         string
+		expectedError    bool
+		expectedNUMANode int
+	}{
+		{
+			name:             "NUMA node reported",
+			address:          "0000:3b:00.0",
+			numaNode:         "1\n",
+			expectedNUMANode: 1,
+		},
+		{
+			name:             "upper case address",
+			address:          "0000:AF:00.1",
+			numaNode:         "0\n",
+			expectedNUMANode: 0,
+		},
+		{
+			name:             "NUMA node not reported",
+			address:          "0000:3b:00.0",
+			numaNode:         "-1\n",
+			expectedNUMANode: -1,
+		},
+		{
+			name:          "unknown device",
+			address:       "0000:5e:00.0",
+			expectedError: true,
+		},
+		{
+			name:          "invalid address",
+			address:       "../../../devices/system/cpu",
+			expectedError: true,
+		},
+		{
+			name:          "invalid NUMA node",
+			address:       "0000:3b:00.0",
+			numaNode:      "x\n",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			t.Setenv("HOST_ROOT", tmpDir)
+			if tc.numaNode != "" {
+				deviceDir := filepath.Join(tmpDir, "sys/bus/pci/devices", strings.ToLower(tc.address))
+				if err := os.MkdirAll(deviceDir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(deviceDir, "numa_node"), []byte(tc.numaNode), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			numaNode, err := PCIDeviceNUMANode(tc.address)
+			if tc.expectedError {
+				if err == nil {
+					t.Fatal("expected an error, but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if numaNode != tc.expectedNUMANode {
+				t.Errorf("expected NUMA node %d, got %d", tc.expectedNUMANode, numaNode)
+			}
+		})
+	}
+}
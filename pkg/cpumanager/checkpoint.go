@@ -0,0 +1,101 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpumanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"k8s.io/utils/cpuset"
+)
+
+// DefaultKubeletCheckpointPath is where the kubelet CPU Manager stores its state by default.
+const DefaultKubeletCheckpointPath = "/var/lib/kubelet/cpu_manager_state"
+
+// policyStatic is the CPU Manager policy pinning the Guaranteed pods, the only one keeping a shared pool.
+const policyStatic = "static"
+
+// KubeletCheckpoint is the on-disk state of the kubelet CPU Manager.
+// It mirrors the v2 format of
+// https://github.com/kubernetes/kubernetes/blob/v1.35.0/pkg/kubelet/cm/cpumanager/state/checkpoint.go
+// The checksum is intentionally not verified: the kubelet writes the file atomically and
+// we only ever read it, so a mismatch could only come from a format we don't understand anyway.
+type KubeletCheckpoint struct {
+	PolicyName    string                       `json:"policyName"`
+	DefaultCPUSet string                       `json:"defaultCpuSet"`
+	Entries       map[string]map[string]string `json:"entries,omitempty"`
+	Checksum      uint64                       `json:"checksum"`
+}
+
+// ReadKubeletCheckpoint reads and decodes the kubelet CPU Manager checkpoint file.
+func ReadKubeletCheckpoint(path string) (*KubeletCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading kubelet checkpoint %q: %w", path, err)
+	}
+	checkpoint := &KubeletCheckpoint{}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("error unmarshaling kubelet checkpoint %q: %w", path, err)
+	}
+	return checkpoint, nil
+}
+
+// ContainerCPUs returns the CPUs the kubelet exclusively assigned to the given container.
+func (c *KubeletCheckpoint) ContainerCPUs(podUID, containerName string) (cpuset.CPUSet, bool, error) {
+	containers, ok := c.Entries[podUID]
+	if !ok {
+		return cpuset.New(), false, nil
+	}
+	value, ok := containers[containerName]
+	if !ok {
+		return cpuset.New(), false, nil
+	}
+	cpus, err := cpuset.Parse(value)
+	if err != nil {
+		return cpuset.New(), false, fmt.Errorf("failed to parse cpuset %q for pod %s container %s: %w", value, podUID, containerName, err)
+	}
+	return cpus, true, nil
+}
+
+// ExclusiveCPUs returns the union of all the CPUs the kubelet exclusively assigned to containers.
+func (c *KubeletCheckpoint) ExclusiveCPUs() (cpuset.CPUSet, error) {
+	exclusive := cpuset.New()
+	for podUID, containers := range c.Entries {
+		for containerName, value := range containers {
+			cpus, err := cpuset.Parse(value)
+			if err != nil {
+				return cpuset.New(), fmt.Errorf("failed to parse cpuset %q for pod %s container %s: %w", value, podUID, containerName, err)
+			}
+			exclusive = exclusive.Union(cpus)
+		}
+	}
+	return exclusive, nil
+}
+
+// SharedCPUs returns the shared pool of the kubelet, the default cpuset of the containers it doesn't pin,
+// and false if the policy doesn't keep one, e.g. none, whose containers can run on all the CPUs.
+func (c *KubeletCheckpoint) SharedCPUs() (cpuset.CPUSet, bool, error) {
+	if c.PolicyName != policyStatic {
+		return cpuset.New(), false, nil
+	}
+	cpus, err := cpuset.Parse(c.DefaultCPUSet)
+	if err != nil {
+		return cpuset.New(), false, fmt.Errorf("failed to parse the default cpuset %q: %w", c.DefaultCPUSet, err)
+	}
+	return cpus, true, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpumanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/cpuset"
+)
+
+func TestReadKubeletCheckpoint(t *testing.T) {
+	testCases := []struct {
+		name              string
+		content           string
+		expectedError     bool
+		expectedExclusive cpuset.CPUSet
+		expectedCtrCPUs   cpuset.CPUSet
+		expectedCtrFound  bool
+		expectedShared    cpuset.CPUSet
+		expectedSharedOK  bool
+	}{
+		{
+			name:              "static policy with exclusive assignments",
+			content:           `{"policyName":"static","defaultCpuSet":"0,5-7","entries":{"pod-uid-1":{"ctr-1":"1-2"},"pod-uid-2":{"ctr-1":"3-4"}},"checksum":1234}`,
+			expectedExclusive: cpuset.New(1, 2, 3, 4),
+			expectedCtrCPUs:   cpuset.New(1, 2),
+			expectedCtrFound:  true,
+			expectedShared:    cpuset.New(0, 5, 6, 7),
+			expectedSharedOK:  true,
+		},
+		{
+			name:              "none policy",
+			content:           `{"policyName":"none","defaultCpuSet":"","checksum":1353318690}`,
+			expectedExclusive: cpuset.New(),
+			expectedCtrCPUs:   cpuset.New(),
+			expectedShared:    cpuset.New(),
+		},
+		{
+			name:          "malformed json",
+			content:       `{"policyName":`,
+			expectedError: true,
+		},
+		{
+			name:          "malformed cpuset",
+			content:       `{"policyName":"static","defaultCpuSet":"0","entries":{"pod-uid-1":{"ctr-1":"a-b"}},"checksum":1}`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cpu_manager_state")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0600))
+
+			checkpoint, err := ReadKubeletCheckpoint(path)
+			if err == nil {
+				var exclusive cpuset.CPUSet
+				exclusive, err = checkpoint.ExclusiveCPUs()
+				if err == nil {
+					require.True(t, tc.expectedExclusive.Equals(exclusive), "expected %s got %s", tc.expectedExclusive, exclusive)
+				}
+			}
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			cpus, found, err := checkpoint.ContainerCPUs("pod-uid-1", "ctr-1")
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCtrFound, found)
+			require.True(t, tc.expectedCtrCPUs.Equals(cpus), "expected %s got %s", tc.expectedCtrCPUs, cpus)
+
+			_, found, err = checkpoint.ContainerCPUs("pod-uid-1", "unknown-ctr")
+			require.NoError(t, err)
+			require.False(t, found)
+
+			shared, ok, err := checkpoint.SharedCPUs()
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedSharedOK, ok)
+			require.True(t, tc.expectedShared.Equals(shared), "expected %s got %s", tc.expectedShared, shared)
+		})
+	}
+}
+
+func TestReadKubeletCheckpointMissingFile(t *testing.T) {
+	_, err := ReadKubeletCheckpoint(filepath.Join(t.TempDir(), "does-not-exist"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -28,7 +28,10 @@ import (
 )
 
 // NOTE: This file is a copy of https://github.com/kubernetes/kubernetes/blob/master/pkg/kubelet/cm/cpumanager/cpu_assignment.go
-// as of commit https://github.com/kubernetes/kubernetes/commit/fd5b2efa76e44c5ef523cd0711f5ed23eb7e6b1a with minor modifications
+// as of commit https://github.com/kubernetes/kubernetes/commit/fd5b2efa76e44c5ef523cd0711f5ed23eb7e6b1a with minor modifications.
+// The main one is that the cpuAccumulator answers its topology queries using CPU bitmasks and per-domain
+// free CPU counts (see topology_index.go) instead of scanning the CPUDetails map, to scale on machines
+// with hundreds of CPUs. The allocation decisions are unchanged.
 
 // LoopControl controls the behavior of the cpu accumulator loop logic
 type LoopControl int
@@ -121,8 +124,8 @@ func (n *numaFirst) takeFullSecondLevel() {
 func (a *cpuAccumulator) sortAvailableUncoreCaches() []int {
 	var result []int
 	for _, numa := range a.sortAvailableNUMANodes() {
-		uncore := a.details.UncoreInNUMANodes(numa).UnsortedList()
-		a.sort(uncore, a.details.CPUsInUncoreCaches)
+		uncore := a.free.withFreeCPUs(a.idx.uncoreCaches, a.free.uncoreCaches, a.idx.numaNodes.cpus[numa])
+		a.sort(uncore, a.free.uncoreCaches)
 		result = append(result, uncore...)
 	}
 	return result
@@ -131,8 +134,8 @@ func (a *cpuAccumulator) sortAvailableUncoreCaches() []int {
 // If NUMA nodes are higher in the memory hierarchy than sockets, then just
 // sort the NUMA nodes directly, and return them.
 func (n *numaFirst) sortAvailableNUMANodes() []int {
-	numas := n.acc.details.NUMANodes().UnsortedList()
-	n.acc.sort(numas, n.acc.details.CPUsInNUMANodes)
+	numas := n.acc.free.withFreeCPUs(n.acc.idx.numaNodes, n.acc.free.numaNodes, nil)
+	n.acc.sort(numas, n.acc.free.numaNodes)
 	return numas
 }
 
@@ -142,8 +145,8 @@ func (n *numaFirst) sortAvailableNUMANodes() []int {
 func (n *numaFirst) sortAvailableSockets() []int {
 	var result []int
 	for _, numa := range n.sortAvailableNUMANodes() {
-		sockets := n.acc.details.SocketsInNUMANodes(numa).UnsortedList()
-		n.acc.sort(sockets, n.acc.details.CPUsInSockets)
+		sockets := n.acc.free.withFreeCPUs(n.acc.idx.sockets, n.acc.free.sockets, n.acc.idx.numaNodes.cpus[numa])
+		n.acc.sort(sockets, n.acc.free.sockets)
 		result = append(result, sockets...)
 	}
 	return result
@@ -154,8 +157,8 @@ func (n *numaFirst) sortAvailableSockets() []int {
 func (n *numaFirst) sortAvailableCores() []int {
 	var result []int
 	for _, socket := range n.acc.sortAvailableSockets() {
-		cores := n.acc.details.CoresInSockets(socket).UnsortedList()
-		n.acc.sort(cores, n.acc.details.CPUsInCores)
+		cores := n.acc.free.withFreeCPUs(n.acc.idx.cores, n.acc.free.cores, n.acc.idx.sockets.cpus[socket])
+		n.acc.sort(cores, n.acc.free.cores)
 		result = append(result, cores...)
 	}
 	return result
@@ -179,8 +182,8 @@ func (s *socketsFirst) takeFullSecondLevel() {
 func (s *socketsFirst) sortAvailableNUMANodes() []int {
 	var result []int
 	for _, socket := range s.sortAvailableSockets() {
-		numas := s.acc.details.NUMANodesInSockets(socket).UnsortedList()
-		s.acc.sort(numas, s.acc.details.CPUsInNUMANodes)
+		numas := s.acc.free.withFreeCPUs(s.acc.idx.numaNodes, s.acc.free.numaNodes, s.acc.idx.sockets.cpus[socket])
+		s.acc.sort(numas, s.acc.free.numaNodes)
 		result = append(result, numas...)
 	}
 	return result
@@ -189,8 +192,8 @@ func (s *socketsFirst) sortAvailableNUMANodes() []int {
 // If sockets are higher in the memory hierarchy than NUMA nodes, then just
 // sort the sockets directly, and return them.
 func (s *socketsFirst) sortAvailableSockets() []int {
-	sockets := s.acc.details.Sockets().UnsortedList()
-	s.acc.sort(sockets, s.acc.details.CPUsInSockets)
+	sockets := s.acc.free.withFreeCPUs(s.acc.idx.sockets, s.acc.free.sockets, nil)
+	s.acc.sort(sockets, s.acc.free.sockets)
 	return sockets
 }
 
@@ -199,8 +202,8 @@ func (s *socketsFirst) sortAvailableSockets() []int {
 func (s *socketsFirst) sortAvailableCores() []int {
 	var result []int
 	for _, numa := range s.acc.sortAvailableNUMANodes() {
-		cores := s.acc.details.CoresInNUMANodes(numa).UnsortedList()
-		s.acc.sort(cores, s.acc.details.CPUsInCores)
+		cores := s.acc.free.withFreeCPUs(s.acc.idx.cores, s.acc.free.cores, s.acc.idx.numaNodes.cpus[numa])
+		s.acc.sort(cores, s.acc.free.cores)
 		result = append(result, cores...)
 	}
 	return result
@@ -278,6 +281,12 @@ type cpuAccumulator struct {
 	// number of CPUS. When a CPU is claimed, it's removed from `details`.
 	details topology.CPUDetails
 
+	// `idx` indexes the CPUs of `topo` by core, socket, NUMA node and uncore cache.
+	idx *topologyIndex
+
+	// `free` mirrors `details` as a CPU bitmask, along with the number of free CPUs in each domain.
+	free *freeCPUs
+
 	// `numCPUsNeeded` is the number of CPUs that the accumulator still needs to accumulate to reach
 	// the desired number of CPUs. When the cpuAccumulator is created, `numCPUsNeeded` is set to the
 	// total number of CPUs to accumulate. Every time a CPU is claimed, `numCPUsNeeded` is decreased
@@ -301,10 +310,14 @@ type cpuAccumulator struct {
 }
 
 func newCPUAccumulator(logger logr.Logger, topo *topology.CPUTopology, availableCPUs cpuset.CPUSet, numCPUs int, cpuSortingStrategy CPUSortingStrategy) *cpuAccumulator {
+	details := topo.CPUDetails.KeepOnly(availableCPUs)
+	idx := newTopologyIndex(topo)
 	acc := &cpuAccumulator{
 		logger:        logger,
 		topo:          topo,
-		details:       topo.CPUDetails.KeepOnly(availableCPUs),
+		details:       details,
+		idx:           idx,
+		free:          newFreeCPUs(idx, details),
 		numCPUsNeeded: numCPUs,
 		result:        cpuset.New(),
 	}
@@ -327,25 +340,25 @@ func newCPUAccumulator(logger logr.Logger, topo *topology.CPUTopology, available
 // Returns true if the supplied NUMANode is fully available in `a.details`.
 // "fully available" means that all the CPUs in it are free.
 func (a *cpuAccumulator) isNUMANodeFree(numaID int) bool {
-	return a.details.CPUsInNUMANodes(numaID).Size() == a.topo.CPUDetails.CPUsInNUMANodes(numaID).Size()
+	return a.free.numaNodes[numaID] == a.idx.numaNodes.size[numaID]
 }
 
 // Returns true if the supplied socket is fully available in `a.details`.
 // "fully available" means that all the CPUs in it are free.
 func (a *cpuAccumulator) isSocketFree(socketID int) bool {
-	return a.details.CPUsInSockets(socketID).Size() == a.topo.CPUsPerSocket()
+	return a.free.sockets[socketID] == a.topo.CPUsPerSocket()
 }
 
 // Returns true if the supplied UnCoreCache is fully available,
 // "fully available" means that all the CPUs in it are free.
 func (a *cpuAccumulator) isUncoreCacheFree(uncoreID int) bool {
-	return a.details.CPUsInUncoreCaches(uncoreID).Size() == a.topo.CPUDetails.CPUsInUncoreCaches(uncoreID).Size()
+	return a.free.uncoreCaches[uncoreID] == a.idx.uncoreCaches.size[uncoreID]
 }
 
 // Returns true if the supplied core is fully available in `a.details`.
 // "fully available" means that all the CPUs in it are free.
 func (a *cpuAccumulator) isCoreFree(coreID int) bool {
-	return a.details.CPUsInCores(coreID).Size() == a.topo.CPUsPerCore()
+	return a.free.cores[coreID] == a.topo.CPUsPerCore()
 }
 
 // Returns free NUMA Node IDs as a slice sorted by sortAvailableNUMANodes().
@@ -399,19 +412,18 @@ func (a *cpuAccumulator) freeCPUs() []int {
 
 // Sorts the provided list of NUMA nodes/sockets/cores/cpus referenced in 'ids'
 // by the number of available CPUs contained within them (smallest to largest).
-// The 'getCPU()' parameter defines the function that should be called to
-// retrieve the list of available CPUs for the type being referenced. If two
-// NUMA nodes/sockets/cores/cpus have the same number of available CPUs, they
-// are sorted in ascending order by their id.
-func (a *cpuAccumulator) sort(ids []int, getCPUs func(ids ...int) cpuset.CPUSet) {
+// The 'freeCounts' parameter holds the number of available CPUs for the type
+// being referenced. If two NUMA nodes/sockets/cores/cpus have the same number
+// of available CPUs, they are sorted in ascending order by their id.
+func (a *cpuAccumulator) sort(ids []int, freeCounts map[int]int) {
 	sort.Slice(ids,
 		func(i, j int) bool {
-			iCPUs := getCPUs(ids[i])
-			jCPUs := getCPUs(ids[j])
-			if iCPUs.Size() < jCPUs.Size() {
+			iCPUs := freeCounts[ids[i]]
+			jCPUs := freeCounts[ids[j]]
+			if iCPUs < jCPUs {
 				return true
 			}
-			if iCPUs.Size() > jCPUs.Size() {
+			if iCPUs > jCPUs {
 				return false
 			}
 			return ids[i] < ids[j]
@@ -506,9 +518,7 @@ func (a *cpuAccumulator) sortAvailableCores() []int {
 func (a *cpuAccumulator) sortAvailableCPUsPacked() []int {
 	var result []int
 	for _, core := range a.sortAvailableCores() {
-		cpus := a.details.CPUsInCores(core).UnsortedList()
-		sort.Ints(cpus)
-		result = append(result, cpus...)
+		result = append(result, a.free.mask.listAnd(a.idx.cores.cpus[core])...)
 	}
 	return result
 }
@@ -519,22 +529,24 @@ func (a *cpuAccumulator) sortAvailableCPUsPacked() []int {
 func (a *cpuAccumulator) sortAvailableCPUsSpread() []int {
 	var result []int
 	for _, socket := range a.sortAvailableSockets() {
-		cpus := a.details.CPUsInSockets(socket).UnsortedList()
-		sort.Ints(cpus)
-		result = append(result, cpus...)
+		result = append(result, a.free.mask.listAnd(a.idx.sockets.cpus[socket])...)
 	}
 	return result
 }
 
 func (a *cpuAccumulator) take(cpus cpuset.CPUSet) {
 	a.result = a.result.Union(cpus)
-	a.details = a.details.KeepOnly(a.details.CPUs().Difference(a.result))
+	for _, cpu := range cpus.UnsortedList() {
+		if info, ok := a.details[cpu]; ok && a.free.remove(cpu, info) {
+			delete(a.details, cpu)
+		}
+	}
 	a.numCPUsNeeded -= cpus.Size()
 }
 
 func (a *cpuAccumulator) takeFullNUMANodes() {
 	for _, numa := range a.freeNUMANodes() {
-		cpusInNUMANode := a.topo.CPUDetails.CPUsInNUMANodes(numa)
+		cpusInNUMANode := a.idx.numaNodes.cpus[numa].toCPUSet()
 		if !a.needsAtLeast(cpusInNUMANode.Size()) {
 			continue
 		}
@@ -545,7 +557,7 @@ func (a *cpuAccumulator) takeFullNUMANodes() {
 
 func (a *cpuAccumulator) takeFullSockets() {
 	for _, socket := range a.freeSockets() {
-		cpusInSocket := a.topo.CPUDetails.CPUsInSockets(socket)
+		cpusInSocket := a.idx.sockets.cpus[socket].toCPUSet()
 		if !a.needsAtLeast(cpusInSocket.Size()) {
 			continue
 		}
@@ -556,7 +568,7 @@ func (a *cpuAccumulator) takeFullSockets() {
 
 func (a *cpuAccumulator) takeFullUncore() {
 	for _, uncore := range a.freeUncoreCache() {
-		cpusInUncore := a.topo.CPUDetails.CPUsInUncoreCaches(uncore)
+		cpusInUncore := a.idx.uncoreCaches.cpus[uncore].toCPUSet()
 		if !a.needsAtLeast(cpusInUncore.Size()) {
 			continue
 		}
@@ -572,7 +584,8 @@ func (a *cpuAccumulator) takePartialUncore(uncoreID int) {
 	// determine the N number of free cores (physical cpus) within the UncoreCache, then
 	// determine the M number of free cpus (virtual cpus) that correspond with the free cores
 	freeCores := a.details.CoresNeededInUncoreCache(numCoresNeeded, uncoreID)
-	freeCPUs := a.details.CPUsInCores(freeCores.UnsortedList()...)
+	// core IDs are only unique within a socket, the cores of the other uncore caches are left out.
+	freeCPUs := a.details.CPUsInCores(freeCores.UnsortedList()...).Intersection(a.details.CPUsInUncoreCaches(uncoreID))
 
 	// when SMT/hyperthread is enabled and remaining cpu requirement is an odd integer value:
 	// sort the free CPUs that were determined based on the cores that have available cpus.
@@ -629,7 +642,7 @@ func (a *cpuAccumulator) takeUncoreCache() {
 
 func (a *cpuAccumulator) takeFullCores() {
 	for _, core := range a.freeCores() {
-		cpusInCore := a.topo.CPUDetails.CPUsInCores(core)
+		cpusInCore := a.idx.cores.cpus[core].toCPUSet()
 		if !a.needsAtLeast(cpusInCore.Size()) {
 			continue
 		}
@@ -653,13 +666,13 @@ func (a *cpuAccumulator) takeRemainingCPUs() {
 // CPU groups have size given by the `cpuGroupSize` argument.
 func (a *cpuAccumulator) rangeNUMANodesNeededToSatisfy(cpuGroupSize int) (minNumNUMAs, maxNumNUMAs int) {
 	// Get the total number of NUMA nodes in the system.
-	numNUMANodes := a.topo.CPUDetails.NUMANodes().Size()
+	numNUMANodes := len(a.idx.numaNodes.ids)
 
 	// Get the total number of NUMA nodes that have CPUs available on them.
-	numNUMANodesAvailable := a.details.NUMANodes().Size()
+	numNUMANodesAvailable := len(a.free.withFreeCPUs(a.idx.numaNodes, a.free.numaNodes, nil))
 
 	// Get the total number of CPUs in the system.
-	numCPUs := a.topo.CPUDetails.CPUs().Size()
+	numCPUs := len(a.topo.CPUDetails)
 
 	// Get the total number of 'cpuGroups' in the system.
 	numCPUGroups := (numCPUs-1)/cpuGroupSize + 1
@@ -695,7 +708,7 @@ func (a *cpuAccumulator) isSatisfied() bool {
 // isFailed returns true if and only if there aren't enough available CPUs in the system.
 // (e.g. the accumulator needs 4 CPUs but only 3 are available).
 func (a *cpuAccumulator) isFailed() bool {
-	return a.numCPUsNeeded > a.details.CPUs().Size()
+	return a.numCPUsNeeded > len(a.details)
 }
 
 // iterateCombinations walks through all n-choose-k subsets of size k in n and
@@ -938,8 +951,7 @@ func takeByTopologyNUMADistributed(logger logr.Logger, topo *topology.CPUTopolog
 
 			// Check that this combination of NUMA nodes has enough CPUs to
 			// satisfy the allocation overall.
-			cpus := acc.details.CPUsInNUMANodes(combo...)
-			if cpus.Size() < numCPUs {
+			if sum(mapIntInt(acc.free.numaNodes).Values(combo...)) < numCPUs {
 				return Continue
 			}
 
@@ -947,7 +959,7 @@ func takeByTopologyNUMADistributed(logger logr.Logger, topo *topology.CPUTopolog
 			// 'cpuGroupSize' across the NUMA nodes in this combo.
 			numCPUGroups := 0
 			for _, numa := range combo {
-				numCPUGroups += (acc.details.CPUsInNUMANodes(numa).Size() / cpuGroupSize)
+				numCPUGroups += (acc.free.numaNodes[numa] / cpuGroupSize)
 			}
 			if (numCPUGroups * cpuGroupSize) < numCPUs {
 				return Continue
@@ -958,8 +970,7 @@ func takeByTopologyNUMADistributed(logger logr.Logger, topo *topology.CPUTopolog
 			// modulo some remainder.
 			distribution := (numCPUs / len(combo) / cpuGroupSize) * cpuGroupSize
 			for _, numa := range combo {
-				cpus := acc.details.CPUsInNUMANodes(numa)
-				if cpus.Size() < distribution {
+				if acc.free.numaNodes[numa] < distribution {
 					return Continue
 				}
 			}
@@ -971,7 +982,7 @@ func takeByTopologyNUMADistributed(logger logr.Logger, topo *topology.CPUTopolog
 			// this combo should ultimately be chosen.
 			availableAfterAllocation := make(mapIntInt, len(numas))
 			for _, numa := range numas {
-				availableAfterAllocation[numa] = acc.details.CPUsInNUMANodes(numa).Size()
+				availableAfterAllocation[numa] = acc.free.numaNodes[numa]
 			}
 			for _, numa := range combo {
 				availableAfterAllocation[numa] -= distribution
@@ -1093,7 +1104,7 @@ func takeByTopologyNUMADistributed(logger logr.Logger, topo *topology.CPUTopolog
 				if remainder == 0 {
 					break
 				}
-				if acc.details.CPUsInNUMANodes(numa).Size() < cpuGroupSize {
+				if acc.free.numaNodes[numa] < cpuGroupSize {
 					continue
 				}
 				cpus, _ := TakeByTopologyNUMAPacked(logger, acc.topo, acc.details.CPUsInNUMANodes(numa), cpuGroupSize, cpuSortingStrategy, false)
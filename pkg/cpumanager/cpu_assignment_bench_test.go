@@ -0,0 +1,124 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpumanager
+
+import (
+	"fmt"
+	"testing"
+
+	topology "github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+// newFakeTopology builds a symmetric topology whose CPU IDs are enumerated like Linux does:
+// the first hardware thread of every core, then the second, and so on. Each NUMA node has its own uncore cache.
+func newFakeTopology(sockets, numaNodesPerSocket, coresPerNUMANode, threadsPerCore int) *topology.CPUTopology {
+	numNUMANodes := sockets * numaNodesPerSocket
+	numCores := numNUMANodes * coresPerNUMANode
+	topo := &topology.CPUTopology{
+		NumCPUs:        numCores * threadsPerCore,
+		NumCores:       numCores,
+		NumUncoreCache: numNUMANodes,
+		NumSockets:     sockets,
+		NumNUMANodes:   numNUMANodes,
+		SMTEnabled:     threadsPerCore > 1,
+		CPUDetails:     make(topology.CPUDetails),
+	}
+	for thread := 0; thread < threadsPerCore; thread++ {
+		for coreID := 0; coreID < numCores; coreID++ {
+			cpuID := thread*numCores + coreID
+			numaID := coreID / coresPerNUMANode
+			topo.CPUDetails[cpuID] = topology.CPUInfo{
+				CpuID:         cpuID,
+				CoreID:        coreID,
+				SocketID:      numaID / numaNodesPerSocket,
+				NUMANodeID:    numaID,
+				UncoreCacheID: numaID,
+			}
+		}
+	}
+	return topo
+}
+
+func BenchmarkTakeByTopologyNUMAPacked(b *testing.B) {
+	logger := klog.Background()
+	topologies := []struct {
+		sockets, numaNodesPerSocket, coresPerNUMANode, threadsPerCore int
+	}{
+		{1, 1, 2, 2},
+		{1, 1, 8, 2},
+		{2, 1, 16, 2},
+		{2, 2, 24, 2},
+		{4, 2, 30, 2},
+		{2, 4, 60, 2},
+	}
+	for _, tt := range topologies {
+		topo := newFakeTopology(tt.sockets, tt.numaNodesPerSocket, tt.coresPerNUMANode, tt.threadsPerCore)
+		allCPUs := topo.CPUDetails.CPUs()
+		// half of the CPUs are already taken, every other core, to exercise the fragmented case.
+		fragmented := allCPUs.Difference(topo.CPUDetails.CPUsInCores(evenCores(topo.NumCores)...))
+		sizes := []int{1, 2}
+		if topo.NumCPUs/4 > 2 {
+			sizes = append(sizes, topo.NumCPUs/4)
+		}
+		for _, numCPUs := range sizes {
+			for _, available := range []struct {
+				name string
+				cpus cpuset.CPUSet
+			}{
+				{"free", allCPUs},
+				{"fragmented", fragmented},
+			} {
+				name := fmt.Sprintf("%dthreads/%s/take%d", topo.NumCPUs, available.name, numCPUs)
+				b.Run(name, func(b *testing.B) {
+					for b.Loop() {
+						if _, err := TakeByTopologyNUMAPacked(logger, topo, available.cpus, numCPUs, CPUSortingStrategyPacked, true); err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkTakeByTopologyNUMADistributed(b *testing.B) {
+	logger := klog.Background()
+	for _, topo := range []*topology.CPUTopology{
+		newFakeTopology(2, 1, 16, 2),
+		newFakeTopology(2, 4, 60, 2),
+	} {
+		allCPUs := topo.CPUDetails.CPUs()
+		numCPUs := topo.NumCPUs / 2
+		b.Run(fmt.Sprintf("%dthreads/take%d", topo.NumCPUs, numCPUs), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := takeByTopologyNUMADistributed(logger, topo, allCPUs, numCPUs, 2, CPUSortingStrategyPacked); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func evenCores(numCores int) []int {
+	cores := []int{}
+	for coreID := 0; coreID < numCores; coreID += 2 {
+		cores = append(cores, coreID)
+	}
+	return cores
+}
@@ -338,6 +338,13 @@ func TestCPUAccumulatorFreeCores(t *testing.T) {
 			cpuset.New(2, 3, 4, 5, 8, 9, 10, 11),
 			[]int{2, 4, 3, 5},
 		},
+		{
+			// the cores of the domains 0 and 2 are the cores 0 and 1 of socket 0, the core 0 of socket 1 isn't free.
+			"dual socket HT with core IDs repeated per socket, 2 cores free",
+			topoDualSocketPerSocketCoreIDsHT,
+			cpuset.New(0, 1, 2, 4, 5),
+			[]int{0, 2},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -683,6 +690,24 @@ func TestTakeByTopologyNUMAPacked(t *testing.T) {
 			"",
 			mustParseCPUSet(t, "0,6,2,8,4,10,1,7"),
 		},
+		{
+			"take a full core from dual socket with HT and core IDs repeated per socket - core from Socket 1",
+			topoDualSocketPerSocketCoreIDsHT,
+			StaticPolicyOptions{},
+			mustParseCPUSet(t, "0-2,4-6"),
+			2,
+			"",
+			mustParseCPUSet(t, "2,6"),
+		},
+		{
+			"take cpus from a single UncoreCache with core IDs repeated per socket",
+			topoDualSocketPerSocketCoreIDsHT,
+			StaticPolicyOptions{PreferAlignByUncoreCacheOption: true},
+			mustParseCPUSet(t, "1-3,5,6"),
+			3,
+			"",
+			mustParseCPUSet(t, "2,3,6"),
+		},
 		{
 			"allocate 32 full cores with 30 coming from the first 3 NUMA nodes (filling them up) and 2 coming from the fourth NUMA node",
 			topoDualSocketMultiNumaPerSocketHT,
@@ -0,0 +1,93 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpumanager
+
+import (
+	"math/bits"
+
+	"k8s.io/utils/cpuset"
+)
+
+// cpuMask is a fixed-size bitmask of logical CPU IDs, like the kernel cpumask.
+// Masks combined together must have been created with the same size.
+type cpuMask []uint64
+
+// newCPUMask returns an empty mask able to hold the CPU IDs in [0, numCPUIDs).
+func newCPUMask(numCPUIDs int) cpuMask {
+	return make(cpuMask, (numCPUIDs+63)/64)
+}
+
+func (m cpuMask) set(cpu int) {
+	m[cpu/64] |= 1 << (uint(cpu) % 64)
+}
+
+func (m cpuMask) clear(cpu int) {
+	m[cpu/64] &^= 1 << (uint(cpu) % 64)
+}
+
+func (m cpuMask) has(cpu int) bool {
+	if cpu < 0 || cpu/64 >= len(m) {
+		return false
+	}
+	return m[cpu/64]&(1<<(uint(cpu)%64)) != 0
+}
+
+// count returns the number of CPUs in the mask.
+func (m cpuMask) count() int {
+	n := 0
+	for _, word := range m {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+// intersects returns true if the mask has at least one CPU in common with all the other masks.
+func (m cpuMask) intersects(others ...cpuMask) bool {
+	for i, word := range m {
+		for _, other := range others {
+			word &= other[i]
+		}
+		if word != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// listAnd returns, in ascending order, the CPUs both in the mask and in the other one.
+func (m cpuMask) listAnd(other cpuMask) []int {
+	var cpus []int
+	for i, word := range m {
+		word &= other[i]
+		for word != 0 {
+			bit := bits.TrailingZeros64(word)
+			cpus = append(cpus, i*64+bit)
+			word &= word - 1
+		}
+	}
+	return cpus
+}
+
+// list returns the CPUs in the mask in ascending order.
+func (m cpuMask) list() []int {
+	return m.listAnd(m)
+}
+
+// toCPUSet converts the mask to a CPUSet.
+func (m cpuMask) toCPUSet() cpuset.CPUSet {
+	return cpuset.New(m.list()...)
+}
@@ -0,0 +1,279 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpumanager
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	topology "github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"k8s.io/utils/cpuset"
+)
+
+const (
+	// StrategyPacked packs the CPUs on as few sockets, NUMA nodes, uncore caches and cores as possible.
+	StrategyPacked = "packed"
+	// StrategySpreadAcrossNUMA distributes the CPUs evenly, in full cores, across NUMA nodes when a single NUMA node
+	// cannot fit them, like the kubelet CPU Manager distribute-cpus-across-numa policy option.
+	StrategySpreadAcrossNUMA = "spread-across-numa"
+	// StrategySpreadNUMA always distributes the CPUs evenly, in full cores when the request allows it, across as
+	// many NUMA nodes of the available CPUs as possible, for the memory bandwidth bound workloads.
+	StrategySpreadNUMA = "spread-numa"
+	// StrategyLowestNumbered takes the available CPUs with the lowest IDs.
+	StrategyLowestNumbered = "lowest-numbered"
+	// StrategySiblingFirst takes all the threads of a core before moving to the next one,
+	// visiting the fully free cores first, in ascending order of CPU ID.
+	StrategySiblingFirst = "sibling-first"
+	// StrategyClusterPacked packs the CPUs like StrategyPacked, but aligns them to CPU clusters, like the ARM
+	// DynamIQ clusters sharing a DSU, instead of uncore caches: claims fitting in a cluster are not split across clusters.
+	StrategyClusterPacked = "cluster-packed"
+	// StrategyFastestCores takes the fastest available cores first, like the favored cores of Intel Turbo Boost
+	// Max 3.0 or AMD preferred cores, visiting the fully free cores first.
+	StrategyFastestCores = "fastest-cores"
+)
+
+// Strategies lists the names of all the supported placement strategies.
+var Strategies = []string{StrategyPacked, StrategySpreadAcrossNUMA, StrategySpreadNUMA, StrategyLowestNumbered, StrategySiblingFirst, StrategyClusterPacked, StrategyFastestCores}
+
+// Strategy picks numCPUs CPUs out of the available ones. Implementations must be deterministic:
+// the same topology, available CPUs and request always yield the same CPUs.
+type Strategy interface {
+	Name() string
+	Take(logger logr.Logger, topo *topology.CPUTopology, availableCPUs cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, error)
+}
+
+// NewStrategy returns the placement strategy with the given name.
+func NewStrategy(name string) (Strategy, error) {
+	switch name {
+	case StrategyPacked:
+		return packedStrategy{}, nil
+	case StrategySpreadAcrossNUMA:
+		return spreadAcrossNUMAStrategy{}, nil
+	case StrategySpreadNUMA:
+		return spreadNUMAStrategy{}, nil
+	case StrategyLowestNumbered:
+		return lowestNumberedStrategy{}, nil
+	case StrategySiblingFirst:
+		return siblingFirstStrategy{}, nil
+	case StrategyClusterPacked:
+		return clusterPackedStrategy{}, nil
+	case StrategyFastestCores:
+		return fastestCoresStrategy{}, nil
+	}
+	return nil, fmt.Errorf("unknown placement strategy %q, must be one of: %s", name, strings.Join(Strategies, ", "))
+}
+
+type packedStrategy struct{}
+
+func (packedStrategy) Name() string { return StrategyPacked }
+
+func (packedStrategy) Take(logger logr.Logger, topo *topology.CPUTopology, availableCPUs cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, error) {
+	return TakeByTopologyNUMAPacked(logger, topo, availableCPUs, numCPUs, CPUSortingStrategyPacked, true)
+}
+
+type spreadAcrossNUMAStrategy struct{}
+
+func (spreadAcrossNUMAStrategy) Name() string { return StrategySpreadAcrossNUMA }
+
+func (spreadAcrossNUMAStrategy) Take(logger logr.Logger, topo *topology.CPUTopology, availableCPUs cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, error) {
+	return takeByTopologyNUMADistributed(logger, topo, availableCPUs, numCPUs, max(1, topo.CPUsPerCore()), CPUSortingStrategyPacked)
+}
+
+type spreadNUMAStrategy struct{}
+
+func (spreadNUMAStrategy) Name() string { return StrategySpreadNUMA }
+
+func (spreadNUMAStrategy) Take(logger logr.Logger, topo *topology.CPUTopology, availableCPUs cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, error) {
+	if availableCPUs.Size() < numCPUs {
+		return cpuset.New(), fmt.Errorf("not enough cpus available to satisfy request: requested=%d, available=%d", numCPUs, availableCPUs.Size())
+	}
+	details := topo.CPUDetails.KeepOnly(availableCPUs)
+	groupSize := max(1, topo.CPUsPerCore())
+	if numCPUs%groupSize != 0 {
+		groupSize = 1
+	}
+	numGroups := numCPUs / groupSize
+	// the NUMA nodes with the most available CPUs first, the lowest ID first on ties.
+	numaNodes := details.NUMANodes().List()
+	sort.SliceStable(numaNodes, func(i, j int) bool {
+		return details.CPUsInNUMANodes(numaNodes[i]).Size() > details.CPUsInNUMANodes(numaNodes[j]).Size()
+	})
+	// spread on as many NUMA nodes as possible, each getting at least a group and the fullest ones the remainder,
+	// fewer NUMA nodes if some can't fit their share.
+	for numNUMANodes := min(len(numaNodes), numGroups); numNUMANodes > 0; numNUMANodes-- {
+		result := cpuset.New()
+		for i, numaID := range numaNodes[:numNUMANodes] {
+			share := numGroups / numNUMANodes
+			if i < numGroups%numNUMANodes {
+				share++
+			}
+			cpus, err := TakeByTopologyNUMAPacked(logger, topo, details.CPUsInNUMANodes(numaID), share*groupSize, CPUSortingStrategyPacked, true)
+			if err != nil {
+				result = cpuset.New()
+				break
+			}
+			result = result.Union(cpus)
+		}
+		if result.Size() == numCPUs {
+			return result, nil
+		}
+	}
+	return cpuset.New(), fmt.Errorf("failed to spread %d CPUs across the NUMA nodes %s of the available CPUs %s", numCPUs, details.NUMANodes().String(), availableCPUs.String())
+}
+
+type lowestNumberedStrategy struct{}
+
+func (lowestNumberedStrategy) Name() string { return StrategyLowestNumbered }
+
+func (lowestNumberedStrategy) Take(_ logr.Logger, _ *topology.CPUTopology, availableCPUs cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, error) {
+	if availableCPUs.Size() < numCPUs {
+		return cpuset.New(), fmt.Errorf("not enough cpus available to satisfy request: requested=%d, available=%d", numCPUs, availableCPUs.Size())
+	}
+	return cpuset.New(availableCPUs.List()[:numCPUs]...), nil
+}
+
+// coreKey identifies a physical core, core IDs are only unique within a socket.
+type coreKey struct {
+	socketID int
+	coreID   int
+}
+
+type siblingFirstStrategy struct{}
+
+func (siblingFirstStrategy) Name() string { return StrategySiblingFirst }
+
+func (siblingFirstStrategy) Take(_ logr.Logger, topo *topology.CPUTopology, availableCPUs cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, error) {
+	if availableCPUs.Size() < numCPUs {
+		return cpuset.New(), fmt.Errorf("not enough cpus available to satisfy request: requested=%d, available=%d", numCPUs, availableCPUs.Size())
+	}
+	details := topo.CPUDetails.KeepOnly(availableCPUs)
+	// group the available threads by core, ordering the cores by their lowest available CPU ID.
+	threadsByCore := map[coreKey][]int{}
+	var cores []coreKey
+	for _, cpu := range availableCPUs.List() {
+		key := coreKey{socketID: details[cpu].SocketID, coreID: details[cpu].CoreID}
+		if _, ok := threadsByCore[key]; !ok {
+			cores = append(cores, key)
+		}
+		threadsByCore[key] = append(threadsByCore[key], cpu)
+	}
+	cpusPerCore := topo.CPUsPerCore()
+	sort.SliceStable(cores, func(i, j int) bool {
+		return len(threadsByCore[cores[i]]) == cpusPerCore && len(threadsByCore[cores[j]]) != cpusPerCore
+	})
+
+	result := make([]int, 0, numCPUs)
+	for _, key := range cores {
+		for _, cpu := range threadsByCore[key] {
+			if len(result) == numCPUs {
+				return cpuset.New(result...), nil
+			}
+			result = append(result, cpu)
+		}
+	}
+	return cpuset.New(result...), nil
+}
+
+type fastestCoresStrategy struct{}
+
+func (fastestCoresStrategy) Name() string { return StrategyFastestCores }
+
+func (fastestCoresStrategy) Take(_ logr.Logger, topo *topology.CPUTopology, availableCPUs cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, error) {
+	if availableCPUs.Size() < numCPUs {
+		return cpuset.New(), fmt.Errorf("not enough cpus available to satisfy request: requested=%d, available=%d", numCPUs, availableCPUs.Size())
+	}
+	details := topo.CPUDetails.KeepOnly(availableCPUs)
+	// group the available threads by core, ordering the cores by their lowest available CPU ID.
+	threadsByCore := map[coreKey][]int{}
+	var cores []coreKey
+	for _, cpu := range availableCPUs.List() {
+		key := coreKey{socketID: details[cpu].SocketID, coreID: details[cpu].CoreID}
+		if _, ok := threadsByCore[key]; !ok {
+			cores = append(cores, key)
+		}
+		threadsByCore[key] = append(threadsByCore[key], cpu)
+	}
+	// unknown ranks, 0, sort after all the known ones.
+	rank := func(key coreKey) int {
+		if r := details[threadsByCore[key][0]].PerformanceRank; r > 0 {
+			return r
+		}
+		return math.MaxInt
+	}
+	cpusPerCore := topo.CPUsPerCore()
+	sort.SliceStable(cores, func(i, j int) bool {
+		iFull, jFull := len(threadsByCore[cores[i]]) == cpusPerCore, len(threadsByCore[cores[j]]) == cpusPerCore
+		if iFull != jFull {
+			return iFull
+		}
+		return rank(cores[i]) < rank(cores[j])
+	})
+
+	result := make([]int, 0, numCPUs)
+	for _, key := range cores {
+		for _, cpu := range threadsByCore[key] {
+			if len(result) == numCPUs {
+				return cpuset.New(result...), nil
+			}
+			result = append(result, cpu)
+		}
+	}
+	return cpuset.New(result...), nil
+}
+
+type clusterPackedStrategy struct{}
+
+func (clusterPackedStrategy) Name() string { return StrategyClusterPacked }
+
+func (clusterPackedStrategy) Take(logger logr.Logger, topo *topology.CPUTopology, availableCPUs cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, error) {
+	return TakeByTopologyNUMAPacked(logger, clustersAsUncoreCaches(topo), availableCPUs, numCPUs, CPUSortingStrategyPacked, true)
+}
+
+// clustersAsUncoreCaches returns a copy of the topology where the uncore caches are replaced by the
+// CPU clusters, so the uncore cache alignment of the packed allocation aligns the CPUs to clusters instead.
+// Topologies not reporting clusters for all the CPUs are returned unchanged.
+func clustersAsUncoreCaches(topo *topology.CPUTopology) *topology.CPUTopology {
+	// cluster IDs are only unique within a socket.
+	type clusterKey struct {
+		socketID  int
+		clusterID int
+	}
+	ids := make(map[clusterKey]int)
+	details := make(topology.CPUDetails, len(topo.CPUDetails))
+	// visit the CPUs in order, so clusters are numbered deterministically.
+	for _, cpu := range topo.CPUDetails.CPUs().List() {
+		info := topo.CPUDetails[cpu]
+		if info.ClusterID < 0 {
+			return topo
+		}
+		key := clusterKey{socketID: info.SocketID, clusterID: info.ClusterID}
+		id, ok := ids[key]
+		if !ok {
+			id = len(ids)
+			ids[key] = id
+		}
+		info.UncoreCacheID = id
+		details[cpu] = info
+	}
+	clustered := *topo
+	clustered.CPUDetails = details
+	clustered.NumUncoreCache = len(ids)
+	return &clustered
+}
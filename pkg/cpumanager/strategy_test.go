@@ -0,0 +1,305 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpumanager
+
+import (
+	"testing"
+
+	topology "github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+func TestNewStrategy(t *testing.T) {
+	for _, name := range Strategies {
+		strategy, err := NewStrategy(name)
+		if err != nil {
+			t.Fatalf("unexpected error for strategy %q: %v", name, err)
+		}
+		if strategy.Name() != name {
+			t.Errorf("expected strategy %q, got %q", name, strategy.Name())
+		}
+	}
+	if _, err := NewStrategy("random"); err == nil {
+		t.Errorf("expected error for unknown strategy")
+	}
+}
+
+// TestStrategiesGolden pins the placement decisions of every strategy, so changes in the
+// allocation outcome are always deliberate. Every case is run several times to prove determinism.
+func TestStrategiesGolden(t *testing.T) {
+	logger := klog.Background()
+	testCases := []struct {
+		description   string
+		topo          *topology.CPUTopology
+		availableCPUs cpuset.CPUSet
+		numCPUs       int
+		expected      map[string]string
+	}{
+		{
+			description:   "dual socket with HT, all CPUs free",
+			topo:          topoDualSocketHT,
+			availableCPUs: mustParseCPUSet(t, "0-11"),
+			numCPUs:       4,
+			expected: map[string]string{
+				StrategyPacked:           "0-1,6-7",
+				StrategySpreadAcrossNUMA: "0,2,6,8",
+				StrategySpreadNUMA:       "0-1,6-7",
+				StrategyLowestNumbered:   "0-3",
+				StrategySiblingFirst:     "0-1,6-7",
+				StrategyFastestCores:     "0-1,6-7",
+				StrategyClusterPacked:    "0,2,6,8",
+			},
+		},
+		{
+			description:   "dual socket with HT, odd request, sibling of CPU 0 free",
+			topo:          topoDualSocketHT,
+			availableCPUs: cpuset.New(1, 2, 3, 4, 5, 7, 8, 9, 10, 11),
+			numCPUs:       3,
+			expected: map[string]string{
+				StrategyPacked:           "1-2,7",
+				StrategySpreadAcrossNUMA: "2,4,8",
+				StrategySpreadNUMA:       "1-2,7",
+				StrategyLowestNumbered:   "1-3",
+				StrategySiblingFirst:     "1-2,7",
+				StrategyFastestCores:     "1-2,7",
+				StrategyClusterPacked:    "2,4,8",
+			},
+		},
+		{
+			description:   "dual socket with 2 NUMA nodes per socket, partially used core",
+			topo:          topoDualSocketMultiNumaPerSocketHT,
+			availableCPUs: mustParseCPUSet(t, "1-79"),
+			numCPUs:       3,
+			expected: map[string]string{
+				StrategyPacked:           "1,40-41",
+				StrategySpreadAcrossNUMA: "1,40-41",
+				StrategySpreadNUMA:       "10,20,30",
+				StrategyLowestNumbered:   "1-3",
+				StrategySiblingFirst:     "1-2,41",
+				StrategyFastestCores:     "1-2,41",
+				StrategyClusterPacked:    "1,40-41",
+			},
+		},
+		{
+			description:   "dual socket with 2 NUMA nodes per socket, request larger than a NUMA node",
+			topo:          topoDualSocketMultiNumaPerSocketHT,
+			availableCPUs: mustParseCPUSet(t, "0-79"),
+			numCPUs:       24,
+			expected: map[string]string{
+				StrategyPacked:           "0-11,40-51",
+				StrategySpreadAcrossNUMA: "0-5,10-15,40-45,50-55",
+				StrategySpreadNUMA:       "0-2,10-12,20-22,30-32,40-42,50-52,60-62,70-72",
+				StrategyLowestNumbered:   "0-23",
+				StrategySiblingFirst:     "0-11,40-51",
+				StrategyFastestCores:     "0-11,40-51",
+				StrategyClusterPacked:    "0-11,40-51",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		for _, name := range Strategies {
+			t.Run(tc.description+"/"+name, func(t *testing.T) {
+				strategy, err := NewStrategy(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				expected := mustParseCPUSet(t, tc.expected[name])
+				for i := 0; i < 10; i++ {
+					result, err := strategy.Take(logger, tc.topo, tc.availableCPUs, tc.numCPUs)
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					if !result.Equals(expected) {
+						t.Fatalf("run %d: expected %s, got %s", i, expected, result)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestClusterPackedStrategy(t *testing.T) {
+	// single socket and L3 cache, two clusters of 4 cores: 0-3 and 4-7.
+	topo := &topology.CPUTopology{
+		NumCPUs:        8,
+		NumSockets:     1,
+		NumCores:       8,
+		NumNUMANodes:   1,
+		NumUncoreCache: 1,
+		CPUDetails:     topology.CPUDetails{},
+	}
+	for cpu := 0; cpu < 8; cpu++ {
+		topo.CPUDetails[cpu] = topology.CPUInfo{CpuID: cpu, CoreID: cpu, SiblingCpuID: -1, ClusterID: cpu / 4}
+	}
+	logger := klog.Background()
+
+	packed, _ := NewStrategy(StrategyPacked)
+	result, err := packed.Take(logger, topo, mustParseCPUSet(t, "2-7"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := mustParseCPUSet(t, "2-4"); !result.Equals(expected) {
+		t.Errorf("packed: expected %s, got %s", expected, result)
+	}
+
+	clusterPacked, _ := NewStrategy(StrategyClusterPacked)
+	result, err = clusterPacked.Take(logger, topo, mustParseCPUSet(t, "2-7"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := mustParseCPUSet(t, "4-6"); !result.Equals(expected) {
+		t.Errorf("cluster-packed: expected %s, got %s", expected, result)
+	}
+
+	// without clusters the topology is left untouched.
+	topo.CPUDetails[7] = topology.CPUInfo{CpuID: 7, CoreID: 7, SiblingCpuID: -1, ClusterID: -1}
+	if clustersAsUncoreCaches(topo) != topo {
+		t.Errorf("expected the topology without clusters to be returned unchanged")
+	}
+}
+
+func TestFastestCoresStrategy(t *testing.T) {
+	// single socket with HT, cores (0,4), (1,5), (2,6) and (3,7); core 2 is the favored core, core 3 has an unknown rank.
+	topo := &topology.CPUTopology{
+		NumCPUs:        8,
+		NumSockets:     1,
+		NumCores:       4,
+		NumNUMANodes:   1,
+		NumUncoreCache: 1,
+		CPUDetails:     topology.CPUDetails{},
+	}
+	ranks := []int{2, 3, 1, 0}
+	for cpu := 0; cpu < 8; cpu++ {
+		topo.CPUDetails[cpu] = topology.CPUInfo{CpuID: cpu, CoreID: cpu % 4, SiblingCpuID: (cpu + 4) % 8, PerformanceRank: ranks[cpu%4]}
+	}
+	strategy, _ := NewStrategy(StrategyFastestCores)
+
+	testCases := []struct {
+		description   string
+		availableCPUs cpuset.CPUSet
+		numCPUs       int
+		expected      string
+	}{
+		{
+			description:   "favored core first",
+			availableCPUs: mustParseCPUSet(t, "0-7"),
+			numCPUs:       2,
+			expected:      "2,6",
+		},
+		{
+			description:   "cores in rank order, unknown rank last",
+			availableCPUs: mustParseCPUSet(t, "0-7"),
+			numCPUs:       7,
+			expected:      "0-2,4-6,3",
+		},
+		{
+			description:   "full cores before the faster partial ones",
+			availableCPUs: mustParseCPUSet(t, "0-5,7"),
+			numCPUs:       4,
+			expected:      "0-1,4-5",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := strategy.Take(klog.Background(), topo, tc.availableCPUs, tc.numCPUs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := mustParseCPUSet(t, tc.expected); !result.Equals(expected) {
+				t.Errorf("expected %s, got %s", expected, result)
+			}
+		})
+	}
+}
+
+func TestSiblingFirstStrategyPerSocketCoreIDs(t *testing.T) {
+	// 2 sockets with HT and the core IDs 0 and 1 each: cores (0,4) and (1,5) on socket 0, (2,6) and (3,7) on socket 1.
+	topo := &topology.CPUTopology{
+		NumCPUs:        8,
+		NumSockets:     2,
+		NumCores:       4,
+		NumNUMANodes:   2,
+		NumUncoreCache: 2,
+		CPUDetails:     topology.CPUDetails{},
+	}
+	for cpu := 0; cpu < 8; cpu++ {
+		socketID := (cpu % 4) / 2
+		topo.CPUDetails[cpu] = topology.CPUInfo{CpuID: cpu, CoreID: cpu % 2, SocketID: socketID, NUMANodeID: socketID, UncoreCacheID: socketID, SiblingCpuID: (cpu + 4) % 8}
+	}
+	strategy, _ := NewStrategy(StrategySiblingFirst)
+
+	// CPUs 0 and 2 are the threads of the cores 0 of both sockets, not the siblings of a full core.
+	result, err := strategy.Take(klog.Background(), topo, mustParseCPUSet(t, "0-2,5"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := mustParseCPUSet(t, "1,5"); !result.Equals(expected) {
+		t.Errorf("expected %s, got %s", expected, result)
+	}
+}
+
+func TestSpreadNUMAStrategy(t *testing.T) {
+	strategy, _ := NewStrategy(StrategySpreadNUMA)
+
+	testCases := []struct {
+		description   string
+		availableCPUs cpuset.CPUSet
+		numCPUs       int
+		expected      string
+	}{
+		{
+			description:   "full cores on every NUMA node",
+			availableCPUs: mustParseCPUSet(t, "0-11"),
+			numCPUs:       8,
+			expected:      "0-3,6-9",
+		},
+		{
+			description:   "remainder on the NUMA node with the most available CPUs",
+			availableCPUs: mustParseCPUSet(t, "0-1,3,5-7,9,11"),
+			numCPUs:       6,
+			expected:      "0-1,3,6-7,9",
+		},
+		{
+			description:   "fewer NUMA nodes when one can't fit a full core",
+			availableCPUs: mustParseCPUSet(t, "0-2,4,6,8,10"),
+			numCPUs:       4,
+			expected:      "0,2,6,8",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := strategy.Take(klog.Background(), topoDualSocketHT, tc.availableCPUs, tc.numCPUs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := mustParseCPUSet(t, tc.expected); !result.Equals(expected) {
+				t.Errorf("expected %s, got %s", expected, result)
+			}
+		})
+	}
+}
+
+func TestStrategiesNotEnoughCPUs(t *testing.T) {
+	for _, name := range Strategies {
+		strategy, _ := NewStrategy(name)
+		if _, err := strategy.Take(klog.Background(), topoDualSocketHT, cpuset.New(0, 1), 3); err == nil {
+			t.Errorf("strategy %q: expected error when not enough CPUs are available", name)
+		}
+	}
+}
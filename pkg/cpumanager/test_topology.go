@@ -684,4 +684,24 @@ var (
 			255: {CoreID: 127, SocketID: 1, NUMANodeID: 7},
 		},
 	}
+
+	// topoDualSocketPerSocketCoreIDsHT numbers the cores per socket, like Linux does on most servers:
+	// the core IDs 0 and 1 are on both sockets.
+	topoDualSocketPerSocketCoreIDsHT = &topology.CPUTopology{
+		NumCPUs:        8,
+		NumSockets:     2,
+		NumCores:       4,
+		NumNUMANodes:   2,
+		NumUncoreCache: 2,
+		CPUDetails: map[int]topology.CPUInfo{
+			0: {CoreID: 0, SocketID: 0, NUMANodeID: 0, UncoreCacheID: 0},
+			1: {CoreID: 1, SocketID: 0, NUMANodeID: 0, UncoreCacheID: 0},
+			2: {CoreID: 0, SocketID: 1, NUMANodeID: 1, UncoreCacheID: 1},
+			3: {CoreID: 1, SocketID: 1, NUMANodeID: 1, UncoreCacheID: 1},
+			4: {CoreID: 0, SocketID: 0, NUMANodeID: 0, UncoreCacheID: 0},
+			5: {CoreID: 1, SocketID: 0, NUMANodeID: 0, UncoreCacheID: 0},
+			6: {CoreID: 0, SocketID: 1, NUMANodeID: 1, UncoreCacheID: 1},
+			7: {CoreID: 1, SocketID: 1, NUMANodeID: 1, UncoreCacheID: 1},
+		},
+	}
 )
@@ -0,0 +1,168 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpumanager
+
+import (
+	"sort"
+
+	topology "github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+)
+
+// domainIndex holds, for every topology domain of one kind (cores, sockets...), the mask of its CPUs.
+type domainIndex struct {
+	cpus map[int]cpuMask
+	size map[int]int
+	// ids are the domain IDs in ascending order.
+	ids []int
+}
+
+func newDomainIndex() *domainIndex {
+	return &domainIndex{
+		cpus: make(map[int]cpuMask),
+		size: make(map[int]int),
+	}
+}
+
+func (d *domainIndex) add(numCPUIDs, id, cpu int) {
+	mask, ok := d.cpus[id]
+	if !ok {
+		mask = newCPUMask(numCPUIDs)
+		d.cpus[id] = mask
+		d.ids = append(d.ids, id)
+	}
+	mask.set(cpu)
+	d.size[id]++
+}
+
+// topologyIndex indexes the CPUs of a topology by domain, so the accumulator can
+// answer its queries with bitmask operations instead of scanning all the CPUs.
+type topologyIndex struct {
+	numCPUIDs int
+	// coreIDs maps the physical cores to the IDs of the cores domains, core IDs are only unique within
+	// a socket. The IDs are the ranks of the cores by core ID then socket ID, so they are the core IDs
+	// on the topologies whose core IDs are unique and contiguous.
+	coreIDs      map[coreKey]int
+	cores        *domainIndex
+	sockets      *domainIndex
+	numaNodes    *domainIndex
+	uncoreCaches *domainIndex
+}
+
+func newTopologyIndex(topo *topology.CPUTopology) *topologyIndex {
+	numCPUIDs := 0
+	for cpu := range topo.CPUDetails {
+		numCPUIDs = max(numCPUIDs, cpu+1)
+	}
+	var keys []coreKey
+	coreIDs := make(map[coreKey]int)
+	for _, info := range topo.CPUDetails {
+		key := coreKey{socketID: info.SocketID, coreID: info.CoreID}
+		if _, ok := coreIDs[key]; !ok {
+			coreIDs[key] = 0
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].coreID != keys[j].coreID {
+			return keys[i].coreID < keys[j].coreID
+		}
+		return keys[i].socketID < keys[j].socketID
+	})
+	for id, key := range keys {
+		coreIDs[key] = id
+	}
+	idx := &topologyIndex{
+		numCPUIDs:    numCPUIDs,
+		coreIDs:      coreIDs,
+		cores:        newDomainIndex(),
+		sockets:      newDomainIndex(),
+		numaNodes:    newDomainIndex(),
+		uncoreCaches: newDomainIndex(),
+	}
+	for cpu, info := range topo.CPUDetails {
+		idx.cores.add(numCPUIDs, idx.coreID(info), cpu)
+		idx.sockets.add(numCPUIDs, info.SocketID, cpu)
+		idx.numaNodes.add(numCPUIDs, info.NUMANodeID, cpu)
+		idx.uncoreCaches.add(numCPUIDs, info.UncoreCacheID, cpu)
+	}
+	for _, domains := range []*domainIndex{idx.cores, idx.sockets, idx.numaNodes, idx.uncoreCaches} {
+		sort.Ints(domains.ids)
+	}
+	return idx
+}
+
+// coreID returns the ID of the cores domain of the CPU.
+func (idx *topologyIndex) coreID(info topology.CPUInfo) int {
+	return idx.coreIDs[coreKey{socketID: info.SocketID, coreID: info.CoreID}]
+}
+
+// freeCPUs tracks the free CPUs of a topology and how many of them each domain has,
+// kept up to date as CPUs are taken.
+type freeCPUs struct {
+	idx  *topologyIndex
+	mask cpuMask
+	// free CPUs count per domain ID, for each kind of domain
+	cores, sockets, numaNodes, uncoreCaches map[int]int
+}
+
+func newFreeCPUs(idx *topologyIndex, details topology.CPUDetails) *freeCPUs {
+	f := &freeCPUs{
+		idx:          idx,
+		mask:         newCPUMask(idx.numCPUIDs),
+		cores:        make(map[int]int),
+		sockets:      make(map[int]int),
+		numaNodes:    make(map[int]int),
+		uncoreCaches: make(map[int]int),
+	}
+	for cpu, info := range details {
+		f.mask.set(cpu)
+		f.cores[idx.coreID(info)]++
+		f.sockets[info.SocketID]++
+		f.numaNodes[info.NUMANodeID]++
+		f.uncoreCaches[info.UncoreCacheID]++
+	}
+	return f
+}
+
+// remove marks the CPU as taken. It returns false if the CPU was not free.
+func (f *freeCPUs) remove(cpu int, info topology.CPUInfo) bool {
+	if !f.mask.has(cpu) {
+		return false
+	}
+	f.mask.clear(cpu)
+	f.cores[f.idx.coreID(info)]--
+	f.sockets[info.SocketID]--
+	f.numaNodes[info.NUMANodeID]--
+	f.uncoreCaches[info.UncoreCacheID]--
+	return true
+}
+
+// withFreeCPUs returns the IDs of the domains having free CPUs, restricted to the CPUs of the
+// parent domain if given. The order of the result is not meaningful: callers sort it.
+func (f *freeCPUs) withFreeCPUs(domains *domainIndex, counts map[int]int, parent cpuMask) []int {
+	var ids []int
+	for _, id := range domains.ids {
+		if counts[id] == 0 {
+			continue
+		}
+		if parent != nil && !domains.cpus[id].intersects(parent, f.mask) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
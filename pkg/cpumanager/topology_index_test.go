@@ -0,0 +1,129 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpumanager
+
+import (
+	"reflect"
+	"testing"
+
+	topology "github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"k8s.io/utils/cpuset"
+)
+
+func TestCPUMask(t *testing.T) {
+	m := newCPUMask(130)
+	if len(m) != 3 {
+		t.Fatalf("expected 3 words for 130 CPUs, got %d", len(m))
+	}
+	for _, cpu := range []int{0, 63, 64, 129} {
+		m.set(cpu)
+	}
+	m.set(5)
+	m.clear(5)
+	if got := m.count(); got != 4 {
+		t.Errorf("expected 4 CPUs, got %d", got)
+	}
+	if !m.has(64) || m.has(5) || m.has(130) || m.has(-1) {
+		t.Errorf("unexpected membership in mask %v", m.list())
+	}
+	if got, want := m.list(), []int{0, 63, 64, 129}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected list %v, got %v", want, got)
+	}
+	if !m.toCPUSet().Equals(cpuset.New(0, 63, 64, 129)) {
+		t.Errorf("unexpected cpuset %s", m.toCPUSet())
+	}
+
+	other := newCPUMask(130)
+	other.set(63)
+	other.set(100)
+	if got, want := m.listAnd(other), []int{63}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected intersection %v, got %v", want, got)
+	}
+	third := newCPUMask(130)
+	third.set(100)
+	if !m.intersects(other) {
+		t.Errorf("expected masks to intersect")
+	}
+	if m.intersects(other, third) {
+		t.Errorf("expected the three masks not to intersect")
+	}
+}
+
+// TestTopologyIndex checks the index answers the same queries as the CPUDetails scans it replaces.
+func TestTopologyIndex(t *testing.T) {
+	for name, topo := range map[string]*topology.CPUTopology{
+		"topoSingleSocketHT":                 topoSingleSocketHT,
+		"topoDualSocketHT":                   topoDualSocketHT,
+		"topoUncoreSingleSocketMultiNuma":    topoUncoreSingleSocketMultiNuma,
+		"topoDualSocketMultiNumaPerSocketHT": topoDualSocketMultiNumaPerSocketHT,
+		"topoTripleSocketHT":                 topoTripleSocketHT,
+		"topoDualSocketPerSocketCoreIDsHT":   topoDualSocketPerSocketCoreIDsHT,
+	} {
+		t.Run(name, func(t *testing.T) {
+			idx := newTopologyIndex(topo)
+			available := topo.CPUDetails.CPUs().Difference(cpuset.New(0, 1, 5))
+			details := topo.CPUDetails.KeepOnly(available)
+			free := newFreeCPUs(idx, details)
+
+			for _, numa := range topo.CPUDetails.NUMANodes().List() {
+				if !idx.numaNodes.cpus[numa].toCPUSet().Equals(topo.CPUDetails.CPUsInNUMANodes(numa)) {
+					t.Errorf("NUMA node %d: unexpected CPUs %v", numa, idx.numaNodes.cpus[numa].list())
+				}
+				if free.numaNodes[numa] != details.CPUsInNUMANodes(numa).Size() {
+					t.Errorf("NUMA node %d: expected %d free CPUs, got %d", numa, details.CPUsInNUMANodes(numa).Size(), free.numaNodes[numa])
+				}
+				// the IDs of the cores domains aren't core IDs, so compare the CPUs of the cores with free CPUs.
+				coreCPUs := cpuset.New()
+				for _, core := range free.withFreeCPUs(idx.cores, free.cores, idx.numaNodes.cpus[numa]) {
+					cpus := idx.cores.cpus[core].toCPUSet()
+					first := topo.CPUDetails[cpus.List()[0]]
+					for _, cpu := range cpus.UnsortedList() {
+						if info := topo.CPUDetails[cpu]; info.SocketID != first.SocketID || info.CoreID != first.CoreID {
+							t.Errorf("core %d: CPUs %s are on different physical cores", core, cpus)
+						}
+					}
+					coreCPUs = coreCPUs.Union(details.CPUsInCores(first.CoreID).Intersection(cpus))
+				}
+				if !coreCPUs.Equals(details.CPUsInNUMANodes(numa)) {
+					t.Errorf("NUMA node %d: expected the cores of CPUs %s, got %s", numa, details.CPUsInNUMANodes(numa), coreCPUs)
+				}
+			}
+			for _, socket := range topo.CPUDetails.Sockets().List() {
+				numas := cpuset.New(free.withFreeCPUs(idx.numaNodes, free.numaNodes, idx.sockets.cpus[socket])...)
+				if !numas.Equals(details.NUMANodesInSockets(socket)) {
+					t.Errorf("socket %d: expected NUMA nodes %s, got %s", socket, details.NUMANodesInSockets(socket), numas)
+				}
+			}
+			sockets := cpuset.New(free.withFreeCPUs(idx.sockets, free.sockets, nil)...)
+			if !sockets.Equals(details.Sockets()) {
+				t.Errorf("expected sockets %s, got %s", details.Sockets(), sockets)
+			}
+
+			for _, cpu := range []int{2, 3} {
+				if !free.remove(cpu, topo.CPUDetails[cpu]) {
+					t.Errorf("CPU %d should be free", cpu)
+				}
+			}
+			if free.remove(0, topo.CPUDetails[0]) {
+				t.Errorf("CPU 0 should not be free")
+			}
+			if !free.mask.toCPUSet().Equals(available.Difference(cpuset.New(2, 3))) {
+				t.Errorf("unexpected free CPUs %v", free.mask.list())
+			}
+		})
+	}
+}
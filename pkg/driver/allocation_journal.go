@@ -0,0 +1,245 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/cpuset"
+)
+
+const (
+	// JOURNAL_EVENT_PREPARE records the CPUs a claim got and the inputs they were picked from.
+	JOURNAL_EVENT_PREPARE = "prepare"
+	// JOURNAL_EVENT_UNPREPARE records the CPUs a claim unprepared by the kubelet gave back to the shared pool.
+	JOURNAL_EVENT_UNPREPARE = "unprepare"
+	// JOURNAL_EVENT_FORCE_RELEASE records the CPUs of a claim force released by an administrator.
+	JOURNAL_EVENT_FORCE_RELEASE = "force-release"
+	// JOURNAL_EVENT_ORPHANED_RELEASE records the CPUs of a claim released after its consumer pods disappeared.
+	JOURNAL_EVENT_ORPHANED_RELEASE = "orphaned-release"
+
+	// DefaultAllocationJournalMaxSize is the size the journal is rotated at.
+	DefaultAllocationJournalMaxSize = 10 << 20
+)
+
+// ErrJournalDisabled is returned when reading the allocation journal of a driver without one.
+var ErrJournalDisabled = errors.New("the allocation journal is disabled")
+
+// JournalEntry is an allocation decision of the allocation journal of the node, one JSON line each.
+type JournalEntry struct {
+	Time      time.Time `json:"time"`
+	Event     string    `json:"event"`
+	ClaimUID  types.UID `json:"claimUID"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	CPUs      string    `json:"cpus"`
+	// The inputs of the prepare: the devices allocated by the scheduler, the placement strategy and
+	// the CPU pool of the grouped mode claims, the SMT isolation policy and the shared CPUs the
+	// CPUs of the claim were picked from.
+	DeviceMode   string   `json:"deviceMode,omitempty"`
+	Devices      []string `json:"devices,omitempty"`
+	Strategy     string   `json:"strategy,omitempty"`
+	Pool         string   `json:"pool,omitempty"`
+	SMTIsolation string   `json:"smtIsolation,omitempty"`
+	SharedCPUs   string   `json:"sharedCPUs,omitempty"`
+}
+
+// allocationJournal appends the allocation decisions of the driver to a file of the node, to reconstruct
+// the CPU layout of the claims after the fact. The file is rotated, keeping the previous one with the .1
+// suffix, when it reaches maxSize.
+type allocationJournal struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	file    *os.File
+	size    int64
+}
+
+func openAllocationJournal(path string, maxSize int64) (*allocationJournal, error) {
+	j := &allocationJournal{path: path, maxSize: maxSize}
+	if err := j.open(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *allocationJournal) open() error {
+	file, err := os.OpenFile(j.path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open the allocation journal: %w", err)
+	}
+	info, err := file.Stat()
+	size := int64(0)
+	if err == nil {
+		size = info.Size()
+	}
+	if err == nil && size > 0 {
+		// the last line of a journal whose write was interrupted is ended, the next entries stay readable.
+		last := make([]byte, 1)
+		if _, err = file.ReadAt(last, size-1); err == nil && last[0] != '\n' {
+			_, err = file.Write([]byte{'\n'})
+			size++
+		}
+	}
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open the allocation journal: %w", err)
+	}
+	j.file, j.size = file, size
+	return nil
+}
+
+// append writes the entry to the journal, rotating it first if the entry doesn't fit.
+func (j *allocationJournal) append(entry JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return errors.New("the allocation journal is closed")
+	}
+	if j.size > 0 && j.size+int64(len(line)) > j.maxSize {
+		if err := j.file.Close(); err != nil {
+			return fmt.Errorf("failed to rotate the allocation journal: %w", err)
+		}
+		j.file = nil
+		if err := os.Rename(j.path, j.path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate the allocation journal: %w", err)
+		}
+		if err := j.open(); err != nil {
+			return err
+		}
+	}
+	n, err := j.file.Write(line)
+	j.size += int64(n)
+	return err
+}
+
+func (j *allocationJournal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// ReadAllocationJournal returns the entries of the allocation journal at path, the rotated ones first,
+// of the claim only if claimUID is set. The lines which can't be decoded, like the last one of a
+// journal whose write was interrupted, are skipped.
+func ReadAllocationJournal(path string, claimUID types.UID) ([]JournalEntry, error) {
+	var entries []JournalEntry
+	for _, name := range []string{path + ".1", path} {
+		file, err := os.Open(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the allocation journal: %w", err)
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var entry JournalEntry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				continue
+			}
+			if claimUID == "" || entry.ClaimUID == claimUID {
+				entries = append(entries, entry)
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the allocation journal: %w", err)
+		}
+	}
+	return entries, nil
+}
+
+// AllocationJournal returns the entries of the allocation journal of the node, of the claim only if
+// claimUID is set.
+func (cp *CPUDriver) AllocationJournal(claimUID types.UID) ([]JournalEntry, error) {
+	if cp.journal == nil {
+		return nil, ErrJournalDisabled
+	}
+	return ReadAllocationJournal(cp.journal.path, claimUID)
+}
+
+// journalPrepare records the CPUs the claim got, picked from the shared CPUs with the strategy and
+// pool of the grouped mode claims, empty in individual mode.
+func (cp *CPUDriver) journalPrepare(logger logr.Logger, claim *resourceapi.ResourceClaim, cpus, sharedCPUs cpuset.CPUSet, strategy, pool string) {
+	if cp.journal == nil {
+		return
+	}
+	var devices []string
+	for _, result := range claim.Status.Allocation.Devices.Results {
+		if result.Driver == cp.driverName {
+			devices = append(devices, result.Device)
+		}
+	}
+	cp.appendJournal(logger, JournalEntry{
+		Time:         time.Now(),
+		Event:        JOURNAL_EVENT_PREPARE,
+		ClaimUID:     claim.UID,
+		Namespace:    claim.Namespace,
+		Name:         claim.Name,
+		CPUs:         cpus.String(),
+		DeviceMode:   cp.cpuDeviceMode,
+		Devices:      devices,
+		Strategy:     strategy,
+		Pool:         pool,
+		SMTIsolation: cp.smtIsolation,
+		SharedCPUs:   sharedCPUs.String(),
+	})
+}
+
+// journalRelease records the CPUs the claim gave back, on the event of the release.
+func (cp *CPUDriver) journalRelease(logger logr.Logger, event string, claimUID types.UID, namespace, name string, cpus cpuset.CPUSet) {
+	if cp.journal == nil {
+		return
+	}
+	cp.appendJournal(logger, JournalEntry{
+		Time:      time.Now(),
+		Event:     event,
+		ClaimUID:  claimUID,
+		Namespace: namespace,
+		Name:      name,
+		CPUs:      cpus.String(),
+	})
+}
+
+// appendJournal only logs the failures, the journal is a troubleshooting aid which must not fail the claims.
+func (cp *CPUDriver) appendJournal(logger logr.Logger, entry JournalEntry) {
+	if err := cp.journal.append(entry); err != nil {
+		logger.Error(err, "Failed to record the allocation in the journal", "event", entry.Event)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin"
+	"k8s.io/utils/cpuset"
+)
+
+func TestAllocationJournalRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allocations.journal")
+	entry := func(name string) JournalEntry {
+		return JournalEntry{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Event: JOURNAL_EVENT_PREPARE, ClaimUID: types.UID("uid-" + name), Name: name, CPUs: "0-3"}
+	}
+	line, err := json.Marshal(entry("claim-1"))
+	require.NoError(t, err)
+	// the journal is rotated every two entries.
+	journal, err := openAllocationJournal(path, int64(2*(len(line)+1)))
+	require.NoError(t, err)
+	for _, name := range []string{"claim-1", "claim-2", "claim-3", "claim-4", "claim-5"} {
+		require.NoError(t, journal.append(entry(name)))
+	}
+	require.NoError(t, journal.close())
+
+	entries, err := ReadAllocationJournal(path, "")
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	// the entries rotated twice are dropped.
+	require.Equal(t, []string{"claim-3", "claim-4", "claim-5"}, names)
+
+	entries, err = ReadAllocationJournal(path, "uid-claim-4")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "claim-4", entries[0].Name)
+}
+
+func TestAllocationJournalTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allocations.journal")
+	data := `{"event":"prepare","claimUID":"uid-1","cpus":"0-1"}` + "\n" + `{"event":"unprep`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0640))
+	entries, err := ReadAllocationJournal(path, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "0-1", entries[0].CPUs)
+
+	// the new entries start on their own line after a restart.
+	journal, err := openAllocationJournal(path, DefaultAllocationJournalMaxSize)
+	require.NoError(t, err)
+	require.NoError(t, journal.append(JournalEntry{Event: JOURNAL_EVENT_UNPREPARE, ClaimUID: "uid-1", CPUs: "0-1"}))
+	require.NoError(t, journal.close())
+	entries, err = ReadAllocationJournal(path, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
+
+func TestAllocationJournalPrepareUnprepare(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	journal, err := openAllocationJournal(filepath.Join(t.TempDir(), "allocations.journal"), DefaultAllocationJournalMaxSize)
+	require.NoError(t, err)
+	cp := &CPUDriver{
+		driverName:             testDriverName,
+		cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+		cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+		cpuTopology:            topo,
+		deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0, "cpudevnuma001": 1},
+		cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+		cdiMgr:                 newMockCdiMgr(),
+		journal:                journal,
+	}
+	claim := testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma000": 2})
+	claim.Namespace = "ns"
+	results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err)
+	require.NoError(t, results[claim.UID].Err)
+	_, err = cp.UnprepareResourceClaims(context.Background(), []kubeletplugin.NamespacedObject{{NamespacedName: types.NamespacedName{Namespace: "ns", Name: claim.Name}, UID: claim.UID}})
+	require.NoError(t, err)
+
+	entries, err := cp.AllocationJournal(claim.UID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, JOURNAL_EVENT_PREPARE, entries[0].Event)
+	require.Equal(t, "0,4", entries[0].CPUs)
+	require.Equal(t, []string{"cpudevnuma000"}, entries[0].Devices)
+	require.Equal(t, "packed", entries[0].Strategy)
+	require.Equal(t, defaultCPUPoolName, entries[0].Pool)
+	require.Equal(t, "0-7", entries[0].SharedCPUs)
+	require.Equal(t, JOURNAL_EVENT_UNPREPARE, entries[1].Event)
+	require.Equal(t, "0,4", entries[1].CPUs)
+
+	_, err = (&CPUDriver{}).AllocationJournal(claim.UID)
+	require.ErrorIs(t, err, ErrJournalDisabled)
+}
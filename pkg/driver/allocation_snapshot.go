@@ -0,0 +1,304 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+const (
+	// restoredClaimTTL is how long the CPUs of a claim restored after a reboot are held for the kubelet
+	// to prepare it again before they are released to the shared pool.
+	restoredClaimTTL = 10 * time.Minute
+	// restoredClaimsCheckPeriod is how often the restored claims are checked for the expired ones.
+	restoredClaimsCheckPeriod = 1 * time.Minute
+)
+
+// bootIDPath identifies the boot of the node the snapshot was written in.
+var bootIDPath = "/proc/sys/kernel/random/boot_id"
+
+// allocationSnapshot is the state of the allocator written on shutdown, with the boot it was taken in.
+type allocationSnapshot struct {
+	BootID string          `json:"bootID"`
+	Time   time.Time       `json:"time"`
+	Claims []snapshotClaim `json:"claims"`
+}
+
+// snapshotClaim is a claim allocation of the store, with the CPU sets written like the env of the CDI devices.
+type snapshotClaim struct {
+	UID                   types.UID                                    `json:"uid"`
+	Namespace             string                                       `json:"namespace"`
+	Name                  string                                       `json:"name"`
+	CPUs                  string                                       `json:"cpus"`
+	Priority              int32                                        `json:"priority,omitempty"`
+	ReservedFor           []resourceapi.ResourceClaimConsumerReference `json:"reservedFor,omitempty"`
+	PollingCPUs           string                                       `json:"pollingCPUs,omitempty"`
+	ReleaseOnPodFailure   bool                                         `json:"releaseOnPodFailure,omitempty"`
+	CPUBandwidthFromClaim bool                                         `json:"cpuBandwidthFromClaim,omitempty"`
+	BindMemoryNodes       bool                                         `json:"bindMemoryNodes,omitempty"`
+}
+
+// restoredClaims are the claims restored from the snapshot of the previous boot the kubelet did not prepare
+// again yet. Their CPUs are held in the allocation store, so no other claim takes them, and given back to
+// the placement of the claim as long as they are enough for it.
+type restoredClaims struct {
+	mu       sync.Mutex
+	restored time.Time
+	claims   map[types.UID]store.ClaimAllocation
+}
+
+func currentBootID() (string, error) {
+	data, err := os.ReadFile(bootIDPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the boot ID: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// writeAllocationSnapshot writes the claim allocations of the store to the snapshot file, replaced atomically.
+func (cp *CPUDriver) writeAllocationSnapshot(logger klog.Logger, path string) error {
+	bootID, err := currentBootID()
+	if err != nil {
+		return err
+	}
+	snapshot := allocationSnapshot{BootID: bootID, Time: time.Now().UTC(), Claims: []snapshotClaim{}}
+	for _, allocation := range cp.cpuAllocationStore.GetClaimAllocationsUsing(cp.cpuTopology.CPUDetails.CPUs()) {
+		info := allocation.ClaimInfo
+		snapshot.Claims = append(snapshot.Claims, snapshotClaim{
+			UID:                   allocation.ClaimUID,
+			Namespace:             info.Namespace,
+			Name:                  info.Name,
+			CPUs:                  allocation.CPUs.String(),
+			Priority:              info.Priority,
+			ReservedFor:           info.ReservedFor,
+			PollingCPUs:           info.PollingCPUs.String(),
+			ReleaseOnPodFailure:   info.ReleaseOnPodFailure,
+			CPUBandwidthFromClaim: info.CPUBandwidthFromClaim,
+			BindMemoryNodes:       info.BindMemoryNodes,
+		})
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return fmt.Errorf("failed to write the allocation snapshot: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write the allocation snapshot: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write the allocation snapshot: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return fmt.Errorf("failed to write the allocation snapshot: %w", err)
+	}
+	logger.Info("Wrote the allocation snapshot", "claims", len(snapshot.Claims), "path", path)
+	return nil
+}
+
+// restoreAllocationSnapshot reads the snapshot written on the last clean shutdown, then removes it so a
+// later crash doesn't restore it again. Taken in the same boot, the CDI spec and the containers the
+// runtime reports restore the claims as after any restart, so it is dropped. Taken in a previous boot,
+// the containers of the claims and their CDI devices are gone, but the kubelet prepares the claims of
+// the pods it restarts again: their CPUs are held for restoredClaimTTL, so the claims get them back
+// instead of a new placement.
+func (cp *CPUDriver) restoreAllocationSnapshot(logger klog.Logger, path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the allocation snapshot: %w", err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove the allocation snapshot: %w", err)
+	}
+	snapshot := allocationSnapshot{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("invalid allocation snapshot %s: %w", path, err)
+	}
+	bootID, err := currentBootID()
+	if err != nil {
+		return err
+	}
+	if snapshot.BootID == bootID {
+		logger.Info("Allocation snapshot taken in the current boot, restoring the claims from the CDI spec and the containers", "time", snapshot.Time)
+		return nil
+	}
+
+	restored := &restoredClaims{restored: time.Now(), claims: map[types.UID]store.ClaimAllocation{}}
+	var errs []error
+	for _, claim := range snapshot.Claims {
+		cpus, err := cpuset.Parse(claim.CPUs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("claim %s: %w", claim.UID, err))
+			continue
+		}
+		pollingCPUs, err := cpuset.Parse(claim.PollingCPUs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("claim %s: %w", claim.UID, err))
+			continue
+		}
+		restored.claims[claim.UID] = store.ClaimAllocation{
+			ClaimUID: claim.UID,
+			CPUs:     cpus,
+			ClaimInfo: store.ClaimInfo{
+				Namespace:             claim.Namespace,
+				Name:                  claim.Name,
+				Priority:              claim.Priority,
+				ReservedFor:           claim.ReservedFor,
+				PollingCPUs:           pollingCPUs,
+				ReleaseOnPodFailure:   claim.ReleaseOnPodFailure,
+				CPUBandwidthFromClaim: claim.CPUBandwidthFromClaim,
+				BindMemoryNodes:       claim.BindMemoryNodes,
+			},
+		}
+	}
+	logger.Info("Node rebooted since the allocation snapshot, holding the CPUs of the claims for the kubelet to prepare them again", "time", snapshot.Time, "claims", len(restored.claims))
+	cp.restored = restored
+	cp.holdRestoredClaims(logger, cp.cpuAllocationStore)
+	return errors.Join(errs...)
+}
+
+// holdRestoredClaims adds the restored claims not prepared yet to the allocation store, unless their CPUs
+// can't be allocated anymore or are allocated to other claims, e.g. when the CPUs or the reserved CPUs
+// of the node changed with the reboot. Those claims get a new placement.
+func (cp *CPUDriver) holdRestoredClaims(logger klog.Logger, allocationStore *store.CPUAllocation) {
+	if cp.restored == nil {
+		return
+	}
+	cp.restored.mu.Lock()
+	defer cp.restored.mu.Unlock()
+	allocatableCPUs := allocationStore.GetAllocatableCPUs()
+	for uid, claim := range cp.restored.claims {
+		if _, ok := allocationStore.GetResourceClaimAllocation(uid); ok {
+			continue
+		}
+		if !claim.CPUs.IsSubsetOf(allocatableCPUs) || len(allocationStore.GetClaimAllocationsUsing(claim.CPUs)) > 0 {
+			logger.Info("CPUs of the claim restored after the reboot are not free anymore, the claim is placed again", "claim", klog.KRef(claim.Namespace, claim.Name), "claimUID", uid, "cpus", claim.CPUs.String())
+			delete(cp.restored.claims, uid)
+			continue
+		}
+		allocationStore.AddResourceClaimAllocation(uid, claim.CPUs)
+		allocationStore.SetResourceClaimInfo(uid, claim.ClaimInfo)
+	}
+}
+
+// releaseRestoredClaim gives the CPUs held for a restored claim back before it is placed, so its placement
+// can pick them again.
+func (cp *CPUDriver) releaseRestoredClaim(claimUID types.UID) {
+	if cp.restored == nil {
+		return
+	}
+	cp.restored.mu.Lock()
+	defer cp.restored.mu.Unlock()
+	claim, ok := cp.restored.claims[claimUID]
+	if !ok {
+		return
+	}
+	if cpus, held := cp.cpuAllocationStore.GetResourceClaimAllocation(claimUID); held && cpus.Equals(claim.CPUs) {
+		cp.cpuAllocationStore.RemoveResourceClaimAllocation(claimUID)
+	}
+}
+
+// restoredPlacement returns the CPUs a restored claim held out of the available CPUs of a device, if
+// they are numCPUs, so it is placed like before the reboot.
+func (cp *CPUDriver) restoredPlacement(claimUID types.UID, availableCPUs cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, bool) {
+	if cp.restored == nil {
+		return cpuset.New(), false
+	}
+	cp.restored.mu.Lock()
+	defer cp.restored.mu.Unlock()
+	claim, ok := cp.restored.claims[claimUID]
+	if !ok {
+		return cpuset.New(), false
+	}
+	cpus := claim.CPUs.Intersection(availableCPUs)
+	result := "reused"
+	if cpus.Size() != numCPUs {
+		cpus, result = cpuset.New(), "moved"
+	}
+	if !cp.dryRun {
+		restoredClaimPlacements.WithLabelValues(result).Inc()
+	}
+	return cpus, !cpus.IsEmpty()
+}
+
+// forgetRestoredClaim drops a restored claim once prepared again, before its allocation is committed.
+func (cp *CPUDriver) forgetRestoredClaim(claimUID types.UID) {
+	if cp.restored == nil || cp.dryRun {
+		return
+	}
+	cp.restored.mu.Lock()
+	defer cp.restored.mu.Unlock()
+	delete(cp.restored.claims, claimUID)
+}
+
+// expireRestoredClaims releases the CPUs held for the restored claims the kubelet didn't prepare again
+// within restoredClaimTTL, e.g. because their pods were deleted while the node was down.
+func (cp *CPUDriver) expireRestoredClaims(ctx context.Context, now time.Time) {
+	logger := klog.FromContext(ctx)
+	// serialized with PrepareResourceClaims, so a claim being prepared again is forgotten before it is checked.
+	cp.settingsMu.Lock()
+	defer cp.settingsMu.Unlock()
+	cp.restored.mu.Lock()
+	if now.Sub(cp.restored.restored) < restoredClaimTTL || len(cp.restored.claims) == 0 {
+		cp.restored.mu.Unlock()
+		return
+	}
+	expired := cp.restored.claims
+	cp.restored.claims = map[types.UID]store.ClaimAllocation{}
+	cp.restored.mu.Unlock()
+
+	for uid, claim := range expired {
+		cpus, held := cp.cpuAllocationStore.GetResourceClaimAllocation(uid)
+		if !held || !cpus.Equals(claim.CPUs) {
+			continue
+		}
+		logger.Info("Releasing the CPUs of the claim not prepared again after the reboot", "claim", klog.KRef(claim.Namespace, claim.Name), "claimUID", uid, "cpus", cpus.String(), "ttl", restoredClaimTTL)
+		cp.cpuAllocationStore.RemoveResourceClaimAllocation(uid)
+		restoredClaimPlacements.WithLabelValues("expired").Inc()
+	}
+	if cp.nriPlugin == nil {
+		return
+	}
+	updates := cp.getSharedContainerUpdates("")
+	if len(updates) == 0 {
+		return
+	}
+	if _, err := cp.nriPlugin.UpdateContainers(updates); err != nil {
+		logger.Error(err, "Failed to update shared containers after releasing the restored claims")
+	}
+}
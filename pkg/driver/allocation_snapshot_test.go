@@ -0,0 +1,186 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+func TestAllocationSnapshot(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "allocations.snapshot")
+	oldBootIDPath := bootIDPath
+	bootIDPath = filepath.Join(dir, "boot_id")
+	t.Cleanup(func() { bootIDPath = oldBootIDPath })
+	setBootID := func(bootID string) {
+		require.NoError(t, os.WriteFile(bootIDPath, []byte(bootID+"\n"), 0o644))
+	}
+	newDriver := func(reservedCPUs cpuset.CPUSet) *CPUDriver {
+		return &CPUDriver{
+			driverName:             testDriverName,
+			cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+			cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+			cpuTopology:            topo,
+			deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0, "cpudevnuma001": 1},
+			cpuAllocationStore:     store.NewCPUAllocation(topo, reservedCPUs),
+			cdiMgr:                 newMockCdiMgr(),
+		}
+	}
+	// claim-1 holds the CPUs 1 and 5 of NUMA node 0, where a new placement would pick the core of CPUs 0 and 4.
+	writeSnapshot := func() {
+		setBootID("boot-1")
+		cp := newDriver(cpuset.New())
+		cp.cpuAllocationStore.AddResourceClaimAllocation("claim-1", cpuset.New(1, 5))
+		cp.cpuAllocationStore.SetResourceClaimInfo("claim-1", store.ClaimInfo{
+			Namespace:   "ns",
+			Name:        "claim-1",
+			Priority:    10,
+			ReservedFor: []resourceapi.ResourceClaimConsumerReference{{Resource: "pods", Name: "app", UID: "pod-1"}},
+		})
+		cp.cpuAllocationStore.AddResourceClaimAllocation("claim-2", cpuset.New(2, 6))
+		cp.cpuAllocationStore.SetResourceClaimInfo("claim-2", store.ClaimInfo{Namespace: "ns", Name: "claim-2"})
+		require.NoError(t, cp.writeAllocationSnapshot(klog.Background(), path))
+	}
+
+	t.Run("restored after a reboot", func(t *testing.T) {
+		writeSnapshot()
+		setBootID("boot-2")
+		cp := newDriver(cpuset.New())
+		require.NoError(t, cp.restoreAllocationSnapshot(klog.Background(), path))
+		require.NoFileExists(t, path)
+
+		// the CPUs are held until the claims are prepared again.
+		require.Equal(t, cpuset.New(0, 3, 4, 7).String(), cp.cpuAllocationStore.GetSharedCPUs().String())
+		info := cp.cpuAllocationStore.GetResourceClaimInfos()["claim-1"]
+		require.Equal(t, int32(10), info.Priority)
+		require.Equal(t, types.UID("pod-1"), info.ReservedFor[0].UID)
+
+		claim := testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma000": 2})
+		claim.Namespace = "ns"
+		results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+		require.NoError(t, err)
+		require.NoError(t, results[claim.UID].Err)
+		cpus, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+		require.Equal(t, cpuset.New(1, 5).String(), cpus.String())
+
+		// the claims not prepared again are released after the TTL.
+		cp.expireRestoredClaims(context.Background(), time.Now())
+		_, held := cp.cpuAllocationStore.GetResourceClaimAllocation("claim-2")
+		require.True(t, held)
+		cp.expireRestoredClaims(context.Background(), time.Now().Add(restoredClaimTTL))
+		_, held = cp.cpuAllocationStore.GetResourceClaimAllocation("claim-2")
+		require.False(t, held)
+		_, held = cp.cpuAllocationStore.GetResourceClaimAllocation("claim-1")
+		require.True(t, held)
+	})
+
+	t.Run("expiry waits for a prepare in flight", func(t *testing.T) {
+		writeSnapshot()
+		setBootID("boot-2")
+		cp := newDriver(cpuset.New())
+		require.NoError(t, cp.restoreAllocationSnapshot(klog.Background(), path))
+
+		// a prepare reusing the restored CPUs holds settingsMu until its allocation is committed.
+		cp.settingsMu.RLock()
+		expired := make(chan struct{})
+		go func() {
+			cp.expireRestoredClaims(context.Background(), time.Now().Add(restoredClaimTTL))
+			close(expired)
+		}()
+		cp.forgetRestoredClaim("claim-1")
+		cp.cpuAllocationStore.AddResourceClaimAllocation("claim-1", cpuset.New(1, 5))
+		cp.settingsMu.RUnlock()
+		<-expired
+
+		_, held := cp.cpuAllocationStore.GetResourceClaimAllocation("claim-1")
+		require.True(t, held)
+		_, held = cp.cpuAllocationStore.GetResourceClaimAllocation("claim-2")
+		require.False(t, held)
+	})
+
+	t.Run("more CPUs than held", func(t *testing.T) {
+		writeSnapshot()
+		setBootID("boot-2")
+		cp := newDriver(cpuset.New())
+		require.NoError(t, cp.restoreAllocationSnapshot(klog.Background(), path))
+
+		claim := testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma000": 3})
+		results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+		require.NoError(t, err)
+		require.NoError(t, results[claim.UID].Err)
+		cpus, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+		require.Equal(t, 3, cpus.Size())
+	})
+
+	t.Run("CPUs reserved since the reboot", func(t *testing.T) {
+		writeSnapshot()
+		setBootID("boot-2")
+		cp := newDriver(cpuset.New(5))
+		require.NoError(t, cp.restoreAllocationSnapshot(klog.Background(), path))
+		_, held := cp.cpuAllocationStore.GetResourceClaimAllocation("claim-1")
+		require.False(t, held)
+		_, held = cp.cpuAllocationStore.GetResourceClaimAllocation("claim-2")
+		require.True(t, held)
+	})
+
+	t.Run("dropped in the same boot", func(t *testing.T) {
+		writeSnapshot()
+		cp := newDriver(cpuset.New())
+		require.NoError(t, cp.restoreAllocationSnapshot(klog.Background(), path))
+		require.NoFileExists(t, path)
+		require.Nil(t, cp.restored)
+		require.Empty(t, cp.cpuAllocationStore.GetResourceClaimInfos())
+	})
+
+	t.Run("written on stop", func(t *testing.T) {
+		setBootID("boot-1")
+		cp := newDriver(cpuset.New())
+		cp.draPlugin = standalonePlugin{}
+		cp.allocationSnapshotPath = path
+		cp.cpuAllocationStore.AddResourceClaimAllocation("claim-1", cpuset.New(1, 5))
+		cp.cpuAllocationStore.SetResourceClaimInfo("claim-1", store.ClaimInfo{Namespace: "ns", Name: "claim-1"})
+		cp.Stop()
+		require.FileExists(t, path)
+
+		setBootID("boot-2")
+		cp = newDriver(cpuset.New())
+		require.NoError(t, cp.restoreAllocationSnapshot(klog.Background(), path))
+		cpus, held := cp.cpuAllocationStore.GetResourceClaimAllocation("claim-1")
+		require.True(t, held)
+		require.Equal(t, cpuset.New(1, 5).String(), cpus.String())
+	})
+
+	t.Run("no snapshot", func(t *testing.T) {
+		cp := newDriver(cpuset.New())
+		require.NoError(t, cp.restoreAllocationSnapshot(klog.Background(), path))
+		require.Nil(t, cp.restored)
+	})
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/purevalidate"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/resctrl"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+// cacheAllocation returns the share of the last level cache and memory bandwidth asked by the claim.
+func (config *ClaimConfig) cacheAllocation() resctrl.Allocation {
+	return resctrl.Allocation{
+		CacheWays:              int(config.CacheWays),
+		MemoryBandwidthPercent: int(config.MemoryBandwidthPercent),
+	}
+}
+
+// allocatesCache reports whether the claim asks for a share of the cache or memory bandwidth.
+func (config *ClaimConfig) allocatesCache() bool {
+	return config.CacheWays > 0 || config.MemoryBandwidthPercent > 0
+}
+
+// validateCacheAllocation fails if the node can't give the claim the cache and memory bandwidth it asks
+// for, before any CPU is assigned. The rdt* device attributes let the claims select the capable nodes.
+func (cp *CPUDriver) validateCacheAllocation(claim *resourceapi.ResourceClaim, config *ClaimConfig) error {
+	if !config.allocatesCache() {
+		return nil
+	}
+	if err := purevalidate.ValidateCacheAllocation(config.CacheWays, config.MemoryBandwidthPercent); err != nil {
+		return fmt.Errorf("claim %s/%s: %w", claim.Namespace, claim.Name, err)
+	}
+	if cp.resctrl == nil {
+		return fmt.Errorf("claim %s/%s: cache allocation is not enabled on node %s, use a CEL selector on dra.cpu/rdtL3CAT or dra.cpu/rdtMBA", claim.Namespace, claim.Name, cp.nodeName)
+	}
+	if err := cp.resctrl.Validate(config.cacheAllocation()); err != nil {
+		return fmt.Errorf("claim %s/%s: %w", claim.Namespace, claim.Name, err)
+	}
+	return nil
+}
+
+// applyCacheAllocation moves the CPUs of the claim to a resctrl group of their own, with the share of
+// the cache and memory bandwidth the claim asks for. The CPUs of the other claims get a monitoring group,
+// when the host supports it, for the claim monitoring metrics.
+func (cp *CPUDriver) applyCacheAllocation(ctx context.Context, claim *resourceapi.ResourceClaim, config *ClaimConfig, cpus cpuset.CPUSet) error {
+	if cp.resctrl == nil || cp.dryRun {
+		return nil
+	}
+	if !config.allocatesCache() {
+		if cp.resctrl.Capabilities().L3Monitoring {
+			// monitoring is best effort, the claim is not failed for lack of monitoring IDs.
+			if err := cp.resctrl.CreateMonitoringGroup(string(claim.UID), cpus); err != nil {
+				klog.FromContext(ctx).Error(err, "Failed to monitor the claim CPUs", "cpus", cpus.String())
+			}
+		}
+		return nil
+	}
+	if err := cp.resctrl.CreateGroup(string(claim.UID), cpus, config.cacheAllocation()); err != nil {
+		return fmt.Errorf("claim %s/%s: %w", claim.Namespace, claim.Name, err)
+	}
+	klog.FromContext(ctx).Info("Cache allocation applied", "cpus", cpus.String(), "cacheWays", config.CacheWays, "memoryBandwidthPercent", config.MemoryBandwidthPercent)
+	return nil
+}
+
+// releaseCacheAllocation removes the resctrl group of a claim, if any, giving its CPUs back to the
+// default group.
+func (cp *CPUDriver) releaseCacheAllocation(claimUID types.UID) error {
+	if cp.resctrl == nil {
+		return nil
+	}
+	return cp.resctrl.RemoveGroup(string(claimUID))
+}
+
+// moveCacheAllocation moves the resctrl group of a claim, if any, to the new CPUs of the claim.
+func (cp *CPUDriver) moveCacheAllocation(claimUID types.UID, cpus cpuset.CPUSet) error {
+	if cp.resctrl == nil || cp.dryRun {
+		return nil
+	}
+	return cp.resctrl.UpdateGroupCPUs(string(claimUID), cpus)
+}
+
+// addRDTAttributes adds the cache and memory bandwidth allocation capabilities of the node to a device,
+// when the cache allocation is enabled.
+func (cp *CPUDriver) addRDTAttributes(device *resourceapi.Device) {
+	if cp.resctrl == nil {
+		return
+	}
+	caps := cp.resctrl.Capabilities()
+	l3CAT, mba := caps.L3CAT, caps.MBA
+	device.Attributes["dra.cpu/rdtL3CAT"] = resourceapi.DeviceAttribute{BoolValue: &l3CAT}
+	device.Attributes["dra.cpu/rdtMBA"] = resourceapi.DeviceAttribute{BoolValue: &mba}
+	if l3CAT {
+		cacheWays := int64(caps.L3CacheWays)
+		device.Attributes["dra.cpu/rdtL3CacheWays"] = resourceapi.DeviceAttribute{IntValue: &cacheWays}
+	}
+}
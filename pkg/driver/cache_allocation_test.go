@@ -0,0 +1,137 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/resctrl"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin"
+	"k8s.io/utils/cpuset"
+)
+
+// newFakeResctrl returns a resctrl Manager of a resctrl filesystem in a temporary directory, with
+// cache allocation of 11 ways and no memory bandwidth allocation.
+func newFakeResctrl(t *testing.T) (*resctrl.Manager, string) {
+	path := t.TempDir()
+	for name, content := range map[string]string{
+		"schemata":            "L3:0=7ff\n",
+		"info/L3/cbm_mask":    "7ff\n",
+		"info/L3/num_closids": "16\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(path, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(path, name), []byte(content), 0644))
+	}
+	m, err := resctrl.New(path)
+	require.NoError(t, err)
+	return m, path
+}
+
+func TestPrepareResourceClaimsCacheAllocation(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_SNC2_8CPUs_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	resctrlMgr, resctrlPath := newFakeResctrl(t)
+
+	testCases := []struct {
+		name          string
+		resctrl       *resctrl.Manager
+		opaqueConfig  string
+		expectedError bool
+	}{
+		{
+			name:         "cache ways",
+			resctrl:      resctrlMgr,
+			opaqueConfig: `{"cacheWays": 4}`,
+		},
+		{
+			name:          "too many cache ways",
+			resctrl:       resctrlMgr,
+			opaqueConfig:  `{"cacheWays": 12}`,
+			expectedError: true,
+		},
+		{
+			name:          "memory bandwidth not supported",
+			resctrl:       resctrlMgr,
+			opaqueConfig:  `{"memoryBandwidthPercent": 50}`,
+			expectedError: true,
+		},
+		{
+			name:          "cache allocation disabled",
+			opaqueConfig:  `{"cacheWays": 4}`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cp := &CPUDriver{
+				driverName:             testDriverName,
+				cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+				cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+				cpuTopology:            topo,
+				deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0},
+				cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+				cdiMgr:                 newMockCdiMgr(),
+				resctrl:                tc.resctrl,
+			}
+			claim := withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma000": 2}), testDriverName, tc.opaqueConfig)
+			groupPath := filepath.Join(resctrlPath, "dracpu-claim-1")
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			if tc.expectedError {
+				require.Error(t, results[claim.UID].Err)
+				require.NoDirExists(t, groupPath)
+				_, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+				require.False(t, ok)
+				return
+			}
+			require.NoError(t, results[claim.UID].Err)
+
+			cpus, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+			groupCPUs, err := os.ReadFile(filepath.Join(groupPath, "cpus_list"))
+			require.NoError(t, err)
+			require.Equal(t, cpus.String()+"\n", string(groupCPUs))
+			schemata, err := os.ReadFile(filepath.Join(groupPath, "schemata"))
+			require.NoError(t, err)
+			require.Equal(t, "L3:0=f\n", string(schemata))
+
+			_, err = cp.UnprepareResourceClaims(context.Background(), []kubeletplugin.NamespacedObject{{UID: claim.UID}})
+			require.NoError(t, err)
+			require.NoDirExists(t, groupPath)
+		})
+	}
+}
+
+func TestAddRDTAttributes(t *testing.T) {
+	resctrlMgr, _ := newFakeResctrl(t)
+
+	device := resourceapi.Device{Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{}}
+	(&CPUDriver{}).addRDTAttributes(&device)
+	require.Empty(t, device.Attributes)
+
+	(&CPUDriver{resctrl: resctrlMgr}).addRDTAttributes(&device)
+	require.True(t, *device.Attributes["dra.cpu/rdtL3CAT"].BoolValue)
+	require.False(t, *device.Attributes["dra.cpu/rdtMBA"].BoolValue)
+	require.Equal(t, int64(11), *device.Attributes["dra.cpu/rdtL3CacheWays"].IntValue)
+}
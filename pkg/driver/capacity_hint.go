@@ -0,0 +1,124 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// annotationCapacityHint is the node annotation describing the devices the driver publishes on a fresh node
+// of the same shape, for cluster autoscalers building the node templates of a node group.
+const annotationCapacityHint = "dra.cpu/capacity-hint"
+
+// capacityHint describes the devices the driver publishes on a node with no claims allocated.
+type capacityHint struct {
+	Driver     string `json:"driver"`
+	DeviceMode string `json:"deviceMode"`
+	GroupBy    string `json:"groupBy,omitempty"`
+	// CPUs is the number of CPUs claims can be allocated, excluding the reserved CPUs and, in grouped
+	// mode, the housekeeping CPUs.
+	CPUs       int  `json:"cpus"`
+	Sockets    int  `json:"sockets"`
+	NUMANodes  int  `json:"numaNodes"`
+	SMTEnabled bool `json:"smtEnabled"`
+	// DeviceCPUs is the dra.cpu/cpu capacity of each grouped device, by device name.
+	DeviceCPUs map[string]int `json:"deviceCPUs,omitempty"`
+}
+
+// newCapacityHint returns the capacity hint of the node. It only depends on the topology, the reserved
+// CPUs and, in grouped mode, the housekeeping CPUs, not on the CPUs pinned by the kubelet or degraded,
+// which a new node wouldn't have.
+func (cp *CPUDriver) newCapacityHint() capacityHint {
+	topo := cp.cpuTopology
+	excludedCPUs := cp.reservedCPUs
+	if cp.cpuDeviceMode == CPU_DEVICE_MODE_GROUPED {
+		excludedCPUs = cp.capacityExcludedCPUs()
+	}
+	hint := capacityHint{
+		Driver:     cp.driverName,
+		DeviceMode: cp.cpuDeviceMode,
+		CPUs:       topo.CPUDetails.CPUs().Difference(excludedCPUs).Size(),
+		Sockets:    topo.NumSockets,
+		NUMANodes:  topo.NumNUMANodes,
+		SMTEnabled: topo.SMTEnabled,
+	}
+	if cp.cpuDeviceMode != CPU_DEVICE_MODE_GROUPED {
+		return hint
+	}
+	hint.GroupBy = cp.cpuDeviceGroupBy
+	hint.DeviceCPUs = map[string]int{}
+	if cp.cpuDeviceGroupBy == GROUP_BY_NODE {
+		if size := topo.CPUDetails.CPUs().Difference(excludedCPUs).Size(); size > 0 {
+			hint.DeviceCPUs[cpuDeviceNodeGroupedName] = size
+		}
+		return hint
+	}
+	if cp.cpuDeviceGroupBy == GROUP_BY_SOCKET {
+		for _, socketID := range topo.CPUDetails.Sockets().List() {
+			if size := topo.CPUDetails.CPUsInSockets(socketID).Difference(excludedCPUs).Size(); size > 0 {
+				hint.DeviceCPUs[fmt.Sprintf("%s%03d", cpuDeviceSocketGroupedPrefix, socketID)] = size
+			}
+		}
+		return hint
+	}
+	for _, numaNodeID := range topo.CPUDetails.NUMANodes().List() {
+		if size := topo.CPUDetails.CPUsInNUMANodes(numaNodeID).Difference(excludedCPUs).Size(); size > 0 {
+			hint.DeviceCPUs[fmt.Sprintf("%s%03d", cpuDeviceNUMAGroupedPrefix, numaNodeID)] = size
+		}
+	}
+	return hint
+}
+
+// syncCapacityHint sets the capacity hint annotation on the node, if it changed.
+func (cp *CPUDriver) syncCapacityHint(ctx context.Context) error {
+	hint, err := json.Marshal(cp.newCapacityHint())
+	if err != nil {
+		return err
+	}
+	node, err := cp.kubeClient.CoreV1().Nodes().Get(ctx, cp.nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get node: %w", err)
+	}
+	if node.Annotations[annotationCapacityHint] == string(hint) {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{annotationCapacityHint: string(hint)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := cp.kubeClient.CoreV1().Nodes().Patch(ctx, cp.nodeName, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to annotate node: %w", err)
+	}
+	return nil
+}
+
+// resyncCapacityHint is the periodic capacity hint sync, restoring the annotation if it was removed.
+func (cp *CPUDriver) resyncCapacityHint(ctx context.Context) {
+	if err := cp.syncCapacityHint(ctx); err != nil {
+		klog.FromContext(ctx).Error(err, "Failed to sync the capacity hint of the node")
+	}
+}
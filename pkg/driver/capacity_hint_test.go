@@ -0,0 +1,127 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/cpuset"
+)
+
+func TestNewCapacityHint(t *testing.T) {
+	// NUMA node 0 has CPUs 0-1,4-5, NUMA node 1 CPUs 2-3,6-7.
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_SNC2_8CPUs_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	testCases := []struct {
+		name             string
+		cpuDeviceMode    string
+		cpuDeviceGroupBy string
+		expectedHint     capacityHint
+	}{
+		{
+			name:             "grouped by NUMA node",
+			cpuDeviceMode:    CPU_DEVICE_MODE_GROUPED,
+			cpuDeviceGroupBy: GROUP_BY_NUMA_NODE,
+			expectedHint: capacityHint{
+				Driver: testDriverName, DeviceMode: CPU_DEVICE_MODE_GROUPED, GroupBy: GROUP_BY_NUMA_NODE,
+				CPUs: 7, Sockets: 1, NUMANodes: 2, SMTEnabled: topo.SMTEnabled,
+				DeviceCPUs: map[string]int{"cpudevnuma000": 3, "cpudevnuma001": 4},
+			},
+		},
+		{
+			name:             "grouped by socket",
+			cpuDeviceMode:    CPU_DEVICE_MODE_GROUPED,
+			cpuDeviceGroupBy: GROUP_BY_SOCKET,
+			expectedHint: capacityHint{
+				Driver: testDriverName, DeviceMode: CPU_DEVICE_MODE_GROUPED, GroupBy: GROUP_BY_SOCKET,
+				CPUs: 7, Sockets: 1, NUMANodes: 2, SMTEnabled: topo.SMTEnabled,
+				DeviceCPUs: map[string]int{"cpudevsocket000": 7},
+			},
+		},
+		{
+			name:             "grouped by node",
+			cpuDeviceMode:    CPU_DEVICE_MODE_GROUPED,
+			cpuDeviceGroupBy: GROUP_BY_NODE,
+			expectedHint: capacityHint{
+				Driver: testDriverName, DeviceMode: CPU_DEVICE_MODE_GROUPED, GroupBy: GROUP_BY_NODE,
+				CPUs: 7, Sockets: 1, NUMANodes: 2, SMTEnabled: topo.SMTEnabled,
+				DeviceCPUs: map[string]int{"cpudevnode": 7},
+			},
+		},
+		{
+			name:          "individual",
+			cpuDeviceMode: CPU_DEVICE_MODE_INDIVIDUAL,
+			expectedHint: capacityHint{
+				Driver: testDriverName, DeviceMode: CPU_DEVICE_MODE_INDIVIDUAL,
+				CPUs: 7, Sockets: 1, NUMANodes: 2, SMTEnabled: topo.SMTEnabled,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cp := &CPUDriver{
+				driverName:       testDriverName,
+				cpuDeviceMode:    tc.cpuDeviceMode,
+				cpuDeviceGroupBy: tc.cpuDeviceGroupBy,
+				cpuTopology:      topo,
+				reservedCPUs:     cpuset.New(0),
+			}
+			require.Equal(t, tc.expectedHint, cp.newCapacityHint())
+		})
+	}
+}
+
+func TestSyncCapacityHint(t *testing.T) {
+	kubeClient := fake.NewClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: testNodeName, Annotations: map[string]string{"other": "value"}},
+	})
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_4CPUS_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cp := &CPUDriver{
+		driverName:       testDriverName,
+		nodeName:         testNodeName,
+		kubeClient:       kubeClient,
+		cpuDeviceMode:    CPU_DEVICE_MODE_GROUPED,
+		cpuDeviceGroupBy: GROUP_BY_NUMA_NODE,
+		cpuTopology:      topo,
+		reservedCPUs:     cpuset.New(),
+	}
+	ctx := context.Background()
+
+	require.NoError(t, cp.syncCapacityHint(ctx))
+	node, err := kubeClient.CoreV1().Nodes().Get(ctx, testNodeName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "value", node.Annotations["other"])
+	var hint capacityHint
+	require.NoError(t, json.Unmarshal([]byte(node.Annotations[annotationCapacityHint]), &hint))
+	require.Equal(t, map[string]int{"cpudevnuma000": 4}, hint.DeviceCPUs)
+
+	// unchanged, the node is not patched again
+	kubeClient.ClearActions()
+	require.NoError(t, cp.syncCapacityHint(ctx))
+	for _, action := range kubeClient.Actions() {
+		require.NotEqual(t, "patch", action.GetVerb())
+	}
+}
@@ -31,6 +31,9 @@ const (
 	cdiVendor       = "dra.k8s.io"
 	cdiClass        = "cpu"
 	cdiEnvVarPrefix = "DRA_CPUSET"
+	// cdiPollingEnvVarPrefix names the variable of the polling CPUs of a claim, kept in the CDI device so
+	// they are known again after a restart of the driver.
+	cdiPollingEnvVarPrefix = "DRA_POLLING_CPUSET"
 )
 
 var (
@@ -43,10 +46,11 @@ type CdiManager struct {
 	mutex      sync.Mutex
 	cdiKind    string
 	driverName string
+	logger     klog.Logger
 }
 
 // NewCdiManager creates a manager for the driver's CDI spec file.
-func NewCdiManager(driverName string) (*CdiManager, error) {
+func NewCdiManager(logger klog.Logger, driverName string) (*CdiManager, error) {
 	path := filepath.Join(cdiSpecDir, fmt.Sprintf("%s.json", driverName))
 
 	if err := os.MkdirAll(cdiSpecDir, 0755); err != nil {
@@ -58,6 +62,7 @@ func NewCdiManager(driverName string) (*CdiManager, error) {
 		path:       path,
 		cdiKind:    cdiKind,
 		driverName: driverName,
+		logger:     logger,
 	}
 
 	if _, err := os.Stat(path); os.IsNotExist(err) {
@@ -73,12 +78,12 @@ func NewCdiManager(driverName string) (*CdiManager, error) {
 		return nil, fmt.Errorf("error accessing CDI spec file %q: %w", path, err)
 	}
 
-	klog.Infof("Initialized CDI file manager for %q", path)
+	logger.Info("Initialized the CDI file manager", "path", path)
 	return c, nil
 }
 
 // AddDevice adds a device to the CDI spec file.
-func (c *CdiManager) AddDevice(deviceName string, envVar string) error {
+func (c *CdiManager) AddDevice(deviceName string, envVars ...string) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
@@ -92,9 +97,7 @@ func (c *CdiManager) AddDevice(deviceName string, envVar string) error {
 	newDevice := cdiSpec.Device{
 		Name: deviceName,
 		ContainerEdits: cdiSpec.ContainerEdits{
-			Env: []string{
-				envVar,
-			},
+			Env: envVars,
 		},
 	}
 
@@ -158,7 +161,7 @@ func (c *CdiManager) readSpecFromFile() (*cdiSpec.Spec, error) {
 	if err := json.Unmarshal(data, spec); err != nil {
 		return nil, fmt.Errorf("error unmarshaling CDI spec from %q: %w", c.path, err)
 	}
-	klog.Infof("Read CDI spec from %q spec:%+v", c.path, spec)
+	c.logger.V(4).Info("Read the CDI spec", "path", c.path, "devices", len(spec.Devices))
 	return spec, nil
 }
 
@@ -198,6 +201,6 @@ func (c *CdiManager) writeSpecToFile(spec *cdiSpec.Spec) (err error) {
 		return fmt.Errorf("failed to rename temporary CDI spec: %w", err)
 	}
 
-	klog.Infof("Successfully updated and synced CDI spec file %q", c.path)
+	c.logger.V(2).Info("Updated and synced the CDI spec file", "path", c.path)
 	return nil
 }
@@ -23,6 +23,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
+	"k8s.io/klog/v2"
 	cdiSpec "tags.cncf.io/container-device-interface/specs-go"
 )
 
@@ -80,7 +81,7 @@ func TestAddDevice(t *testing.T) {
 			})
 			cdiSpecDir = t.TempDir()
 
-			mgr, err := NewCdiManager(testDriverName)
+			mgr, err := NewCdiManager(klog.Background(), testDriverName)
 			require.NoError(t, err)
 
 			_, err = os.Stat(filepath.Join(cdiSpecDir, testDriverName+".json"))
@@ -163,7 +164,7 @@ func TestRemoveDevice(t *testing.T) {
 			})
 			cdiSpecDir = t.TempDir()
 
-			mgr, err := NewCdiManager(testDriverName)
+			mgr, err := NewCdiManager(klog.Background(), testDriverName)
 			require.NoError(t, err)
 			for _, dev := range tcase.initial {
 				err = mgr.AddDevice(dev.name, dev.env)
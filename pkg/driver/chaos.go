@@ -0,0 +1,192 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/containerd/nri/pkg/stub"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+const (
+	// chaosMaxRPCDelay is the longest delay injected in the kubelet RPCs.
+	chaosMaxRPCDelay = 5 * time.Second
+	// invariantCheckPeriod is how often the allocation invariants are checked in chaos mode.
+	invariantCheckPeriod = 10 * time.Second
+)
+
+// errChaosInjected is the error of the failures injected in chaos mode.
+var errChaosInjected = errors.New("chaos: injected failure")
+
+// chaosInjector injects faults for soak testing: each fault point is hit with the given probability.
+// A nil chaosInjector never injects anything, so the fault points cost nothing outside of chaos mode.
+type chaosInjector struct {
+	probability float64
+}
+
+// newChaosInjector returns a chaosInjector hitting the fault points with the given probability,
+// nil if the probability is not positive.
+func newChaosInjector(probability float64) *chaosInjector {
+	if probability <= 0 {
+		return nil
+	}
+	return &chaosInjector{probability: probability}
+}
+
+func (c *chaosInjector) hit() bool {
+	return c != nil && rand.Float64() < c.probability
+}
+
+// failure returns an error for the operation if the fault point is hit.
+func (c *chaosInjector) failure(logger klog.Logger, operation string) error {
+	if !c.hit() {
+		return nil
+	}
+	logger.Info("Chaos: injecting a failure", "operation", operation)
+	return fmt.Errorf("%s: %w", operation, errChaosInjected)
+}
+
+// delay sleeps for a random time up to chaosMaxRPCDelay if the fault point is hit.
+func (c *chaosInjector) delay(ctx context.Context, operation string) {
+	if !c.hit() {
+		return
+	}
+	delay := rand.N(chaosMaxRPCDelay)
+	klog.FromContext(ctx).Info("Chaos: delaying the operation", "operation", operation, "delay", delay)
+	select {
+	case <-ctx.Done():
+	case <-time.After(delay):
+	}
+}
+
+// waitForRestart returns once a restart of a controller running every period is due, or ctx is done.
+func (c *chaosInjector) waitForRestart(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if c.hit() {
+				return
+			}
+		}
+	}
+}
+
+// chaosNRIStub fails the container updates sent to the runtime, like failing cgroup writes.
+type chaosNRIStub struct {
+	stub.Stub
+	chaos  *chaosInjector
+	logger klog.Logger
+}
+
+func (s *chaosNRIStub) UpdateContainers(updates []*api.ContainerUpdate) ([]*api.ContainerUpdate, error) {
+	if err := s.chaos.failure(s.logger, "container update"); err != nil {
+		return updates, err
+	}
+	return s.Stub.UpdateContainers(updates)
+}
+
+// startController runs f every period until ctx is done. In chaos mode, the controller is randomly
+// stopped and restarted, waiting for the running iteration to complete, like after a crash.
+func (cp *CPUDriver) startController(ctx context.Context, name string, f func(context.Context), period time.Duration) {
+	if cp.chaos == nil {
+		go wait.UntilWithContext(ctx, f, period)
+		return
+	}
+	go func() {
+		for ctx.Err() == nil {
+			controllerCtx, cancel := context.WithCancel(ctx)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				wait.UntilWithContext(controllerCtx, f, period)
+			}()
+			cp.chaos.waitForRestart(ctx, period)
+			if ctx.Err() == nil {
+				klog.FromContext(ctx).Info("Chaos: restarting the controller", "controller", name)
+			}
+			cancel()
+			<-done
+		}
+	}()
+}
+
+// checkInvariants returns the violations of the invariants of the CPU allocations: no CPU is allocated
+// to two claims or reserved, and the CDI spec injects the allocated CPUs of every claim in its containers.
+func (cp *CPUDriver) checkInvariants() []string {
+	var violations []string
+	allocated := map[int]string{}
+	allocations := cp.cpuAllocationStore.GetClaimAllocationsUsing(cp.cpuTopology.CPUDetails.CPUs())
+	for _, allocation := range allocations {
+		for _, cpu := range allocation.CPUs.List() {
+			if owner, ok := allocated[cpu]; ok {
+				violations = append(violations, fmt.Sprintf("CPU %d allocated to claims %s and %s", cpu, owner, allocation.ClaimUID))
+			}
+			allocated[cpu] = string(allocation.ClaimUID)
+		}
+		if reserved := allocation.CPUs.Intersection(cp.reservedCPUs); !reserved.IsEmpty() {
+			violations = append(violations, fmt.Sprintf("reserved CPUs %s allocated to claim %s", reserved.String(), allocation.ClaimUID))
+		}
+	}
+
+	spec, err := cp.cdiMgr.GetSpec()
+	if err != nil {
+		return append(violations, fmt.Sprintf("failed to read the CDI spec: %v", err))
+	}
+	envByDevice := map[string][]string{}
+	for _, device := range spec.Devices {
+		envByDevice[device.Name] = device.ContainerEdits.Env
+	}
+	for _, allocation := range allocations {
+		deviceName := getCDIDeviceName(allocation.ClaimUID)
+		env, ok := envByDevice[deviceName]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("claim %s has no CDI device %s", allocation.ClaimUID, deviceName))
+			continue
+		}
+		prefix := fmt.Sprintf("%s_%s=", cdiEnvVarPrefix, allocation.ClaimUID)
+		for _, envVar := range env {
+			if !strings.HasPrefix(envVar, prefix) {
+				continue
+			}
+			if cpus, err := cpuset.Parse(strings.TrimPrefix(envVar, prefix)); err != nil || !cpus.Equals(allocation.CPUs) {
+				violations = append(violations, fmt.Sprintf("CDI device %s injects CPUs %q, claim %s is allocated %s", deviceName, strings.TrimPrefix(envVar, prefix), allocation.ClaimUID, allocation.CPUs.String()))
+			}
+		}
+	}
+	return violations
+}
+
+// verifyInvariants logs and counts the invariant violations.
+func (cp *CPUDriver) verifyInvariants(ctx context.Context) {
+	for _, violation := range cp.checkInvariants() {
+		klog.FromContext(ctx).Error(nil, "Invariant violated", "violation", violation)
+		invariantViolations.Inc()
+	}
+}
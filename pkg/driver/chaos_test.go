@@ -0,0 +1,107 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+func TestChaosInjector(t *testing.T) {
+	var disabled *chaosInjector
+	require.Nil(t, newChaosInjector(0))
+	require.NoError(t, disabled.failure(klog.Background(), "cgroup write"))
+	disabled.delay(context.Background(), "PrepareResourceClaims")
+
+	always := newChaosInjector(1)
+	require.ErrorIs(t, always.failure(klog.Background(), "cgroup write"), errChaosInjected)
+
+	nriStub := &chaosNRIStub{Stub: &fakeNRIStub{}, chaos: always, logger: klog.Background()}
+	_, err := nriStub.UpdateContainers([]*api.ContainerUpdate{{ContainerId: "ctr-1"}})
+	require.ErrorIs(t, err, errChaosInjected)
+}
+
+func TestStartControllerChaosRestarts(t *testing.T) {
+	cp := &CPUDriver{chaos: newChaosInjector(1)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var running, runs atomic.Int32
+	var overlapped atomic.Bool
+	cp.startController(ctx, "test", func(context.Context) {
+		if running.Add(1) != 1 {
+			overlapped.Store(true)
+		}
+		runs.Add(1)
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+	}, 5*time.Millisecond)
+
+	// every tick restarts the controller, which runs again right away.
+	require.Eventually(t, func() bool { return runs.Load() >= 5 }, 5*time.Second, 5*time.Millisecond)
+	require.False(t, overlapped.Load(), "restarted controllers must not overlap")
+}
+
+func TestCheckInvariants(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_4CPUS_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	newDriver := func() *CPUDriver {
+		return &CPUDriver{
+			cpuTopology:        topo,
+			reservedCPUs:       cpuset.New(0),
+			cpuAllocationStore: store.NewCPUAllocation(topo, cpuset.New()),
+			cdiMgr:             newMockCdiMgr(),
+		}
+	}
+	addClaim := func(cp *CPUDriver, claimUID string, cpus cpuset.CPUSet) {
+		cp.cpuAllocationStore.AddResourceClaimAllocation(types.UID(claimUID), cpus)
+		require.NoError(t, cp.cdiMgr.AddDevice(getCDIDeviceName(types.UID(claimUID)), cdiEnvVarPrefix+"_"+claimUID+"="+cpus.String()))
+	}
+
+	cp := newDriver()
+	addClaim(cp, "claim-1", cpuset.New(1, 3))
+	addClaim(cp, "claim-2", cpuset.New(2))
+	require.Empty(t, cp.checkInvariants())
+
+	cp = newDriver()
+	addClaim(cp, "claim-1", cpuset.New(1, 3))
+	addClaim(cp, "claim-2", cpuset.New(3))
+	require.Equal(t, []string{"CPU 3 allocated to claims claim-1 and claim-2"}, cp.checkInvariants())
+
+	cp = newDriver()
+	addClaim(cp, "claim-1", cpuset.New(0, 1))
+	require.Equal(t, []string{"reserved CPUs 0 allocated to claim claim-1"}, cp.checkInvariants())
+
+	cp = newDriver()
+	addClaim(cp, "claim-1", cpuset.New(1))
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-1", cpuset.New(2))
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-2", cpuset.New(3))
+	require.Equal(t, []string{
+		`CDI device claim-claim-1 injects CPUs "1", claim claim-1 is allocated 2`,
+		"claim claim-2 has no CDI device claim-claim-2",
+	}, cp.checkInvariants())
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+// hasClaimAffinity reports whether the claim configuration places the claim relative to another
+// claim of this driver, which must then be prepared first.
+func (config *ClaimConfig) hasClaimAffinity() bool {
+	return config.NUMAAffinityWithClaim != "" || config.NUMAAntiAffinityWithClaim != ""
+}
+
+// dependsOnClaim returns 1 for the claims placed relative to other claims, 0 otherwise.
+func (cp *CPUDriver) dependsOnClaim(claim *resourceapi.ResourceClaim) int {
+	config, err := cp.getClaimConfig(claim)
+	if err != nil || !config.hasClaimAffinity() {
+		return 0
+	}
+	return 1
+}
+
+// affinityNUMANodes returns the NUMA nodes the CPUs of the claim can be taken from to honor the
+// NUMA affinity and anti-affinity with the other claims of its pods, and whether there is any
+// such constraint. The constraints only apply once the other claim is prepared: the claims of a
+// pod asking for affinity are prepared after the others, and of two claims asking for affinity
+// with each other, the second one prepared follows the first one.
+func (cp *CPUDriver) affinityNUMANodes(ctx context.Context, claim *resourceapi.ResourceClaim, config *ClaimConfig) (cpuset.CPUSet, bool, error) {
+	if !config.hasClaimAffinity() {
+		return cpuset.New(), false, nil
+	}
+	numaNodes := cp.cpuTopology.CPUDetails.NUMANodes()
+	constrained := false
+	if config.NUMAAffinityWithClaim != "" {
+		peerNUMANodes, found, err := cp.peerClaimNUMANodes(ctx, claim, config.NUMAAffinityWithClaim)
+		if err != nil {
+			return cpuset.New(), false, err
+		}
+		if found {
+			numaNodes = numaNodes.Intersection(peerNUMANodes)
+			constrained = true
+		}
+	}
+	if config.NUMAAntiAffinityWithClaim != "" {
+		peerNUMANodes, found, err := cp.peerClaimNUMANodes(ctx, claim, config.NUMAAntiAffinityWithClaim)
+		if err != nil {
+			return cpuset.New(), false, err
+		}
+		if found {
+			numaNodes = numaNodes.Difference(peerNUMANodes)
+			constrained = true
+		}
+	}
+	if constrained && numaNodes.Size() == 0 {
+		return cpuset.New(), false, fmt.Errorf("claim %s/%s: no NUMA node satisfies both numaAffinityWithClaim %q and numaAntiAffinityWithClaim %q", claim.Namespace, claim.Name, config.NUMAAffinityWithClaim, config.NUMAAntiAffinityWithClaim)
+	}
+	return numaNodes, constrained, nil
+}
+
+// peerClaimNUMANodes returns the NUMA nodes of the CPUs of the claim with the given name, in the pod
+// spec, of the pods the claim is reserved for. It reports false if that claim isn't prepared yet.
+// Naming a claim that the pods don't have is an error.
+func (cp *CPUDriver) peerClaimNUMANodes(ctx context.Context, claim *resourceapi.ResourceClaim, name string) (cpuset.CPUSet, bool, error) {
+	logger := klog.FromContext(ctx)
+	found := false
+	for _, consumer := range claim.Status.ReservedFor {
+		if consumer.Resource != "pods" || consumer.APIGroup != "" {
+			continue
+		}
+		pod, err := cp.kubeClient.CoreV1().Pods(claim.Namespace).Get(ctx, consumer.Name, metav1.GetOptions{})
+		if err != nil {
+			return cpuset.New(), false, fmt.Errorf("failed to get pod %s/%s: %w", claim.Namespace, consumer.Name, err)
+		}
+		for _, status := range pod.Status.ResourceClaimStatuses {
+			if status.Name != name || status.ResourceClaimName == nil || *status.ResourceClaimName == claim.Name {
+				continue
+			}
+			found = true
+			peer, err := cp.kubeClient.ResourceV1().ResourceClaims(claim.Namespace).Get(ctx, *status.ResourceClaimName, metav1.GetOptions{})
+			if err != nil {
+				return cpuset.New(), false, fmt.Errorf("failed to get claim %s/%s of pod %s: %w", claim.Namespace, *status.ResourceClaimName, pod.Name, err)
+			}
+			cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(peer.UID)
+			if !ok {
+				continue
+			}
+			numaNodes := cp.cpuTopology.CPUDetails.KeepOnly(cpus).NUMANodes()
+			logger.V(2).Info("Found the NUMA nodes of the peer claim", "peerClaim", klog.KObj(peer), "cpus", cpus.String(), "numaNodes", numaNodes.String())
+			return numaNodes, true, nil
+		}
+	}
+	if !found {
+		return cpuset.New(), false, fmt.Errorf("claim %s/%s: no claim %q in the pods it is reserved for", claim.Namespace, claim.Name, name)
+	}
+	logger.V(2).Info("Peer claim not prepared yet, ignoring its NUMA affinity", "peerClaim", name)
+	return cpuset.New(), false, nil
+}
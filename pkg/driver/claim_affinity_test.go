@@ -0,0 +1,128 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/cpuset"
+	"k8s.io/utils/ptr"
+)
+
+func TestPrepareClaimsWithNUMAAffinity(t *testing.T) {
+	testCases := []struct {
+		name             string
+		opaqueConfig     string
+		sameNUMANodes    bool
+		expectedError    bool
+		workersCPUsCount int64
+	}{
+		{
+			name:             "anti-affinity takes the other NUMA node",
+			opaqueConfig:     `{"numaAntiAffinityWithClaim": "rx"}`,
+			workersCPUsCount: 4,
+		},
+		{
+			name:             "affinity takes the same NUMA node",
+			opaqueConfig:     `{"numaAffinityWithClaim": "rx"}`,
+			sameNUMANodes:    true,
+			workersCPUsCount: 2,
+		},
+		{
+			name:             "affinity with a full NUMA node fails",
+			opaqueConfig:     `{"numaAffinityWithClaim": "rx"}`,
+			workersCPUsCount: 4,
+			expectedError:    true,
+		},
+		{
+			name:             "unknown peer claim",
+			opaqueConfig:     `{"numaAntiAffinityWithClaim": "tx"}`,
+			workersCPUsCount: 2,
+			expectedError:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			newClaim := func(name string, numCPUs int64, opaqueConfig ...string) *resourceapi.ResourceClaim {
+				claim := withOpaqueConfig(testClaim(types.UID("pod-1-"+name), testDriverName, testNodeName, map[string]int64{"cpudevsocket000": numCPUs}), testDriverName, opaqueConfig...)
+				claim.Namespace = "ns"
+				claim.Status.ReservedFor = []resourceapi.ResourceClaimConsumerReference{{Resource: "pods", Name: "pod-1"}}
+				return claim
+			}
+			rx := newClaim("rx", 2)
+			workers := newClaim("workers", tc.workersCPUsCount, tc.opaqueConfig)
+			kubeClient := fake.NewClientset(
+				rx.DeepCopy(),
+				workers.DeepCopy(),
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod-1"},
+					Status: corev1.PodStatus{
+						ResourceClaimStatuses: []corev1.PodResourceClaimStatus{
+							{Name: "rx", ResourceClaimName: ptr.To(rx.Name)},
+							{Name: "workers", ResourceClaimName: ptr.To(workers.Name)},
+						},
+					},
+				},
+			)
+			mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_SNC2_8CPUs_HT}
+			topo, _ := mockProvider.GetCPUTopology()
+			cp := &CPUDriver{
+				driverName:           testDriverName,
+				nodeName:             testNodeName,
+				kubeClient:           kubeClient,
+				cpuDeviceMode:        CPU_DEVICE_MODE_GROUPED,
+				cpuDeviceGroupBy:     GROUP_BY_SOCKET,
+				cpuTopology:          topo,
+				deviceNameToSocketID: map[string]int{"cpudevsocket000": 0},
+				cpuAllocationStore:   store.NewCPUAllocation(topo, cpuset.New()),
+				podConfigStore:       store.NewPodConfig(),
+				claimTracker:         store.NewClaimTracker(),
+				cdiMgr:               newMockCdiMgr(),
+			}
+
+			// the workers claim comes first, but is prepared after the rx claim it depends on.
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{workers, rx})
+			require.NoError(t, err)
+			require.NoError(t, results[rx.UID].Err)
+			if tc.expectedError {
+				require.Error(t, results[workers.UID].Err)
+				return
+			}
+			require.NoError(t, results[workers.UID].Err)
+			rxCPUs, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(rx.UID)
+			workersCPUs, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(workers.UID)
+			rxNUMANodes := topo.CPUDetails.KeepOnly(rxCPUs).NUMANodes()
+			workersNUMANodes := topo.CPUDetails.KeepOnly(workersCPUs).NUMANodes()
+			require.Equal(t, 1, rxNUMANodes.Size())
+			if tc.sameNUMANodes {
+				require.True(t, workersNUMANodes.Equals(rxNUMANodes), "rx on NUMA nodes %s, workers on %s", rxNUMANodes, workersNUMANodes)
+			} else {
+				require.True(t, workersNUMANodes.Intersection(rxNUMANodes).IsEmpty(), "rx on NUMA nodes %s, workers on %s", rxNUMANodes, workersNUMANodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/purevalidate"
+	resourceapi "k8s.io/api/resource/v1"
+)
+
+// ClaimConfig holds the parameters users can pass to the driver through the opaque
+// device configuration of a ResourceClaim or of a DeviceClass.
+type ClaimConfig struct {
+	// Priority of the claim. In grouped mode, when the CPUs left in a device are too
+	// fragmented to give full cores to a claim, CPUs assigned to lower-priority claims
+	// of the same device can be migrated to make room. Defaults to 0, which never preempts.
+	Priority int32 `json:"priority,omitempty"`
+	// Strategy is the placement strategy used to pick the CPUs of the claim in grouped mode,
+	// overriding the driver default. See cpumanager.Strategies for the supported values.
+	Strategy string `json:"strategy,omitempty"`
+	// AlignWithClaim is the name, in the pod spec, of another claim of the pod whose devices the CPUs
+	// are placed next to: in grouped mode, the CPUs are taken from the NUMA nodes of those devices.
+	AlignWithClaim string `json:"alignWithClaim,omitempty"`
+	// AlignWithDriver restricts the alignment to the devices of the given driver, e.g. gpu.nvidia.com.
+	// If set without AlignWithClaim, the devices of the driver in all the other claims of the pod are used.
+	AlignWithDriver string `json:"alignWithDriver,omitempty"`
+	// PollingCores is the number of full physical cores, out of the CPUs of the claim in grouped mode,
+	// dedicated to polling a NIC, e.g. for DPDK. They are taken on the NUMA node of the NIC, none of
+	// their SMT siblings is shared, and they are recorded in the claim device status.
+	PollingCores int32 `json:"pollingCores,omitempty"`
+	// PollingNIC is the PCI address of the NIC the polling cores are placed next to, e.g. 0000:3b:00.0.
+	// If not set, it is taken from the pollingNICAnnotation of the pod, or the NUMA nodes of the devices
+	// the claim is aligned with are used.
+	PollingNIC string `json:"pollingNIC,omitempty"`
+	// EmulatorThreadCPUs is the number of CPUs of the claim set aside for the emulator threads of a VM,
+	// e.g. for KubeVirt isolateEmulatorThread. The remaining CPUs are meant to be mapped 1:1 to the vCPUs.
+	EmulatorThreadCPUs int32 `json:"emulatorThreadCPUs,omitempty"`
+	// ReportAllocation records the CPUs of the claim in the claim device status. It is implied by
+	// PollingCores and EmulatorThreadCPUs.
+	ReportAllocation bool `json:"reportAllocation,omitempty"`
+	// PerformanceHint asks for the CPUs best suited to a kind of workload. performanceHintFastest
+	// picks the fastest cores, like the favored cores of Turbo Boost Max 3.0, when Strategy is not set.
+	PerformanceHint string `json:"performanceHint,omitempty"`
+	// Contiguous requires the CPUs of the claim to have consecutive IDs, for legacy workloads. In grouped
+	// mode the CPUs of each device are taken from a free contiguous range instead of the placement strategy.
+	Contiguous bool `json:"contiguous,omitempty"`
+	// NUMAAffinityWithClaim is the name, in the pod spec, of another claim of this driver in the pod whose
+	// CPUs the CPUs of the claim share their NUMA nodes with, e.g. to keep a worker pool next to its RX pool.
+	NUMAAffinityWithClaim string `json:"numaAffinityWithClaim,omitempty"`
+	// NUMAAntiAffinityWithClaim is the name, in the pod spec, of another claim of this driver in the pod
+	// whose NUMA nodes the CPUs of the claim are kept off, e.g. to separate an RX polling pool from the workers.
+	NUMAAntiAffinityWithClaim string `json:"numaAntiAffinityWithClaim,omitempty"`
+	// SocketAffinity is the socket the CPUs of the claim must be on, e.g. for per-socket licensed software.
+	SocketAffinity *int32 `json:"socketAffinity,omitempty"`
+	// MaxSockets is the maximum number of sockets the CPUs of the claim can span, e.g. 1 to avoid the
+	// cross-socket latency. Zero doesn't limit them.
+	MaxSockets int32 `json:"maxSockets,omitempty"`
+	// CacheWays is the number of last level cache ways the CPUs of the claim get their own resctrl group
+	// for, capping the cache the claim can fill. Needs the driver --resctrl-path and L3 cache allocation.
+	CacheWays int32 `json:"cacheWays,omitempty"`
+	// MemoryBandwidthPercent caps the memory bandwidth of the CPUs of the claim, in percents of the
+	// bandwidth of the memory controller. Needs the driver --resctrl-path and memory bandwidth allocation.
+	MemoryBandwidthPercent int32 `json:"memoryBandwidthPercent,omitempty"`
+	// OnPodFailure is what happens to the CPUs of the claim when its consumer pods are failed or crash
+	// looping for longer than the driver --pod-failure-threshold: keep (default) or release.
+	OnPodFailure string `json:"onPodFailure,omitempty"`
+	// UseHousekeepingCPUs lets the claim get the housekeeping CPUs of the driver --housekeeping-cpus in
+	// grouped mode, on top of the other free CPUs of its devices. In individual mode, the claims get the
+	// housekeeping CPU devices by tolerating their dra.cpu/housekeeping taint instead.
+	UseHousekeepingCPUs bool `json:"useHousekeepingCPUs,omitempty"`
+	// CPUBandwidth is what the CPU quota and weight of the containers of the claim follow: pod (default),
+	// their requests and limits, or claim, the number of CPUs of the claim.
+	CPUBandwidth string `json:"cpuBandwidth,omitempty"`
+}
+
+// performanceHintFastest is the PerformanceHint of the claims preferring the fastest cores.
+const performanceHintFastest = purevalidate.PerformanceHintFastest
+
+// getClaimConfig decodes the opaque configuration meant for this driver from the claim allocation.
+// The allocation lists the DeviceClass configurations before the claim ones, so the latter take precedence.
+func (cp *CPUDriver) getClaimConfig(claim *resourceapi.ResourceClaim) (*ClaimConfig, error) {
+	config := &ClaimConfig{}
+	if claim.Status.Allocation == nil {
+		return config, nil
+	}
+	for _, deviceConfig := range claim.Status.Allocation.Devices.Config {
+		if deviceConfig.Opaque == nil || deviceConfig.Opaque.Driver != cp.driverName {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(deviceConfig.Opaque.Parameters.Raw))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(config); err != nil {
+			return nil, fmt.Errorf("invalid opaque configuration for claim %s/%s: %w", claim.Namespace, claim.Name, err)
+		}
+	}
+	return config, nil
+}
+
+// placementStrategy returns the strategy picking the CPUs of a claim: the one in the claim
+// configuration if set, the one matching its performance hint, the driver default otherwise.
+func (cp *CPUDriver) placementStrategy(config *ClaimConfig) (cpumanager.Strategy, error) {
+	if err := purevalidate.ValidatePerformanceHint(config.PerformanceHint); err != nil {
+		return nil, err
+	}
+	name := cp.allocationStrategy
+	if config.PerformanceHint == performanceHintFastest {
+		name = cpumanager.StrategyFastestCores
+	}
+	if config.Strategy != "" {
+		name = config.Strategy
+	}
+	if name == "" {
+		name = cpumanager.StrategyPacked
+	}
+	return cpumanager.NewStrategy(name)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/purevalidate"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/cpuset"
+)
+
+func withOpaqueConfig(claim *resourceapi.ResourceClaim, driverName string, parameters ...string) *resourceapi.ResourceClaim {
+	for _, params := range parameters {
+		claim.Status.Allocation.Devices.Config = append(claim.Status.Allocation.Devices.Config, resourceapi.DeviceAllocationConfiguration{
+			Source: resourceapi.AllocationConfigSourceClaim,
+			DeviceConfiguration: resourceapi.DeviceConfiguration{
+				Opaque: &resourceapi.OpaqueDeviceConfiguration{
+					Driver:     driverName,
+					Parameters: runtime.RawExtension{Raw: []byte(params)},
+				},
+			},
+		})
+	}
+	return claim
+}
+
+func TestGetClaimConfig(t *testing.T) {
+	testCases := []struct {
+		name           string
+		claim          *resourceapi.ResourceClaim
+		expectedError  bool
+		expectedConfig *ClaimConfig
+	}{
+		{
+			name:           "no opaque configuration",
+			claim:          testClaim("claim-1", testDriverName, testNodeName, nil),
+			expectedConfig: &ClaimConfig{},
+		},
+		{
+			name:           "no allocation",
+			claim:          &resourceapi.ResourceClaim{},
+			expectedConfig: &ClaimConfig{},
+		},
+		{
+			name:           "priority",
+			claim:          withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, nil), testDriverName, `{"priority": 10}`),
+			expectedConfig: &ClaimConfig{Priority: 10},
+		},
+		{
+			name:           "strategy",
+			claim:          withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, nil), testDriverName, `{"strategy": "lowest-numbered"}`),
+			expectedConfig: &ClaimConfig{Strategy: cpumanager.StrategyLowestNumbered},
+		},
+		{
+			name:           "later configuration takes precedence",
+			claim:          withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, nil), testDriverName, `{"priority": 10}`, `{"priority": 20}`),
+			expectedConfig: &ClaimConfig{Priority: 20},
+		},
+		{
+			name:           "configuration for other drivers is ignored",
+			claim:          withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, nil), "gpu.example.com", `{"sharing": true}`),
+			expectedConfig: &ClaimConfig{},
+		},
+		{
+			name:          "unknown field",
+			claim:         withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, nil), testDriverName, `{"priorty": 10}`),
+			expectedError: true,
+		},
+		{
+			name:          "malformed",
+			claim:         withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, nil), testDriverName, `{"priority": "high"}`),
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cp := &CPUDriver{driverName: testDriverName}
+			config, err := cp.getClaimConfig(tc.claim)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedConfig, config)
+		})
+	}
+}
+
+func TestPrepareResourceClaimsPlacementStrategy(t *testing.T) {
+	// cores are (0,2) and (1,3).
+	testCases := []struct {
+		name           string
+		driverStrategy string
+		opaqueConfig   []string
+		expectedError  bool
+		expectedCPUs   cpuset.CPUSet
+	}{
+		{
+			name:         "default strategy packs full cores",
+			expectedCPUs: cpuset.New(0, 2),
+		},
+		{
+			name:           "driver strategy",
+			driverStrategy: cpumanager.StrategyLowestNumbered,
+			expectedCPUs:   cpuset.New(0, 1),
+		},
+		{
+			name:           "claim strategy overrides the driver strategy",
+			driverStrategy: cpumanager.StrategyLowestNumbered,
+			opaqueConfig:   []string{`{"strategy": "sibling-first"}`},
+			expectedCPUs:   cpuset.New(0, 2),
+		},
+		{
+			name:           "performance hint overrides the driver strategy",
+			driverStrategy: cpumanager.StrategyLowestNumbered,
+			opaqueConfig:   []string{`{"performanceHint": "fastest"}`},
+			expectedCPUs:   cpuset.New(0, 2),
+		},
+		{
+			name:          "unknown performance hint",
+			opaqueConfig:  []string{`{"performanceHint": "slowest"}`},
+			expectedError: true,
+		},
+		{
+			name:          "unknown claim strategy",
+			opaqueConfig:  []string{`{"strategy": "random"}`},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_4CPUS_HT}
+			topo, _ := mockProvider.GetCPUTopology()
+			cp := &CPUDriver{
+				driverName:             testDriverName,
+				cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+				cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+				allocationStrategy:     tc.driverStrategy,
+				cpuTopology:            topo,
+				deviceNameToNUMANodeID: map[string]int{"cpudevnuma0": 0},
+				cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+				podConfigStore:         store.NewPodConfig(),
+				claimTracker:           store.NewClaimTracker(),
+				cdiMgr:                 newMockCdiMgr(),
+			}
+
+			claim := withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma0": 2}), testDriverName, tc.opaqueConfig...)
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			if tc.expectedError {
+				require.Error(t, results[claim.UID].Err)
+				_, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+				require.False(t, ok)
+				return
+			}
+			require.NoError(t, results[claim.UID].Err)
+			cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+			require.True(t, ok)
+			require.True(t, tc.expectedCPUs.Equals(cpus), "expected %s got %s", tc.expectedCPUs.String(), cpus.String())
+		})
+	}
+}
+
+// TestClaimConfigMatchesPurevalidate keeps the parameters validated by the policy engines in sync with
+// the ones the driver decodes.
+func TestClaimConfigMatchesPurevalidate(t *testing.T) {
+	fields := func(typ reflect.Type) map[string]reflect.Type {
+		result := map[string]reflect.Type{}
+		for i := 0; i < typ.NumField(); i++ {
+			result[typ.Field(i).Tag.Get("json")] = typ.Field(i).Type
+		}
+		return result
+	}
+	require.Equal(t, fields(reflect.TypeFor[purevalidate.Parameters]()), fields(reflect.TypeFor[ClaimConfig]()))
+}
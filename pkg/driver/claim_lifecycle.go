@@ -0,0 +1,92 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"encoding/json"
+	"time"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// The phases of the lifecycle of a claim observed by claimPhaseDuration.
+const (
+	// claimPhaseAllocate is from the creation of the claim to its allocation by the scheduler.
+	claimPhaseAllocate = "allocate"
+	// claimPhasePrepare is from the allocation of the claim to the end of its prepare by the driver.
+	claimPhasePrepare = "prepare"
+	// claimPhaseStart is from the prepare of the claim to the creation of its first container.
+	claimPhaseStart = "start"
+)
+
+// observeClaimPrepared records the allocate and prepare phases of a claim the driver just prepared, and
+// the time it was prepared at, for its start phase.
+func (cp *CPUDriver) observeClaimPrepared(claim *resourceapi.ResourceClaim, now time.Time) {
+	if cp.dryRun {
+		return
+	}
+	if allocated, ok := claimAllocationTime(claim); ok {
+		claimPhaseDuration.WithLabelValues(claimPhaseAllocate).Observe(allocated.Sub(claim.CreationTimestamp.Time).Seconds())
+		claimPhaseDuration.WithLabelValues(claimPhasePrepare).Observe(now.Sub(allocated).Seconds())
+	}
+	cp.lifecycleMu.Lock()
+	defer cp.lifecycleMu.Unlock()
+	if cp.claimsPreparedAt == nil {
+		cp.claimsPreparedAt = map[types.UID]time.Time{}
+	}
+	cp.claimsPreparedAt[claim.UID] = now
+}
+
+// observeClaimsStarted records the start phase of the claims of a container, the first container
+// of a claim only.
+func (cp *CPUDriver) observeClaimsStarted(claimUIDs []types.UID, now time.Time) {
+	cp.lifecycleMu.Lock()
+	defer cp.lifecycleMu.Unlock()
+	for _, uid := range claimUIDs {
+		if prepared, ok := cp.claimsPreparedAt[uid]; ok {
+			claimPhaseDuration.WithLabelValues(claimPhaseStart).Observe(now.Sub(prepared).Seconds())
+			delete(cp.claimsPreparedAt, uid)
+		}
+	}
+}
+
+// forgetClaimLifecycle drops the prepare time of a claim unprepared before any of its containers started.
+func (cp *CPUDriver) forgetClaimLifecycle(claimUID types.UID) {
+	cp.lifecycleMu.Lock()
+	defer cp.lifecycleMu.Unlock()
+	delete(cp.claimsPreparedAt, claimUID)
+}
+
+// claimAllocationTime returns when the allocation of the claim was written: the ResourceClaim has no
+// allocation timestamp, but the managed fields record when the scheduler last updated the status
+// fields it owns, the allocation and the reservation of the claim.
+func claimAllocationTime(claim *resourceapi.ResourceClaim) (time.Time, bool) {
+	for _, entry := range claim.ManagedFields {
+		if entry.Subresource != "status" || entry.Time == nil || entry.FieldsV1 == nil {
+			continue
+		}
+		fields := map[string]map[string]any{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		if _, ok := fields["f:status"]["f:allocation"]; ok {
+			return entry.Time.Time, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/cpuset"
+)
+
+func claimPhaseCount(t *testing.T, phase string) uint64 {
+	m := &dto.Metric{}
+	require.NoError(t, claimPhaseDuration.WithLabelValues(phase).(prometheus.Metric).Write(m))
+	return m.Histogram.GetSampleCount()
+}
+
+func TestClaimAllocationTime(t *testing.T) {
+	allocated := metav1.NewTime(time.Date(2026, 1, 1, 10, 0, 5, 0, time.UTC))
+	claim := &resourceapi.ResourceClaim{ObjectMeta: metav1.ObjectMeta{ManagedFields: []metav1.ManagedFieldsEntry{
+		{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply, Time: &metav1.Time{}, FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{}}`)}},
+		{Manager: "dra.cpu", Operation: metav1.ManagedFieldsOperationApply, Subresource: "status", Time: &metav1.Time{}, FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:devices":{}}}`)}},
+		{Manager: "kube-scheduler", Operation: metav1.ManagedFieldsOperationUpdate, Subresource: "status", Time: &allocated, FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:allocation":{},"f:reservedFor":{}}}`)}},
+	}}}
+	got, ok := claimAllocationTime(claim)
+	require.True(t, ok)
+	require.Equal(t, allocated.Time, got)
+
+	claim.ManagedFields = claim.ManagedFields[:2]
+	_, ok = claimAllocationTime(claim)
+	require.False(t, ok)
+}
+
+func TestClaimLifecycleMetrics(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cp := &CPUDriver{
+		driverName:             testDriverName,
+		cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+		cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+		cpuTopology:            topo,
+		deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0, "cpudevnuma001": 1},
+		cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+		podConfigStore:         store.NewPodConfig(),
+		claimTracker:           store.NewClaimTracker(),
+		cdiMgr:                 newMockCdiMgr(),
+	}
+	claim := testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma000": 2})
+	claim.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Minute))
+	allocated := metav1.NewTime(time.Now().Add(-30 * time.Second))
+	claim.ManagedFields = []metav1.ManagedFieldsEntry{
+		{Manager: "kube-scheduler", Operation: metav1.ManagedFieldsOperationUpdate, Subresource: "status", Time: &allocated, FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:allocation":{}}}`)}},
+	}
+	allocate, prepare, start := claimPhaseCount(t, claimPhaseAllocate), claimPhaseCount(t, claimPhasePrepare), claimPhaseCount(t, claimPhaseStart)
+
+	results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err)
+	require.NoError(t, results[claim.UID].Err)
+	require.Equal(t, allocate+1, claimPhaseCount(t, claimPhaseAllocate))
+	require.Equal(t, prepare+1, claimPhaseCount(t, claimPhasePrepare))
+
+	// the prepare retries of the kubelet are not observed again.
+	_, err = cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err)
+	require.Equal(t, prepare+1, claimPhaseCount(t, claimPhasePrepare))
+
+	cpus, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+	pod := &api.PodSandbox{Id: "pod-id", Name: "pod", Namespace: "ns", Uid: "pod-uid"}
+	// the container restarts are not observed.
+	for _, id := range []string{"ctr-1", "ctr-2"} {
+		ctr := &api.Container{Id: id, PodSandboxId: pod.Id, Name: "app", Env: []string{fmt.Sprintf("%s_%s=%s", cdiEnvVarPrefix, claim.UID, cpus.String())}}
+		_, _, err = cp.CreateContainer(context.Background(), pod, ctr)
+		require.NoError(t, err)
+	}
+	require.Equal(t, start+1, claimPhaseCount(t, claimPhaseStart))
+}
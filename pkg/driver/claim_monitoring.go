@@ -0,0 +1,81 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+)
+
+var (
+	claimLabels           = []string{"namespace", "claim", "pods"}
+	claimLLCOccupancyDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "claim_llc_occupancy_bytes"),
+		"Last level cache used by the tasks running on the CPUs of the claim.", claimLabels, nil)
+	claimMemoryTrafficDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "claim_memory_traffic_bytes_total"),
+		"Memory traffic of the tasks running on the CPUs of the claim, whose rate is their memory bandwidth.", claimLabels, nil)
+	claimLocalMemoryTrafficDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "claim_local_memory_traffic_bytes_total"),
+		"Memory traffic of the tasks running on the CPUs of the claim to their local NUMA node.", claimLabels, nil)
+)
+
+// claimMonitoringCollector exports the resctrl monitoring counters of the prepared claims, read at every
+// scrape, labeled by the claim and the pods it is reserved for.
+type claimMonitoringCollector struct {
+	cp     *CPUDriver
+	logger klog.Logger
+}
+
+// Describe implements prometheus.Collector.
+func (c *claimMonitoringCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- claimLLCOccupancyDesc
+	ch <- claimMemoryTrafficDesc
+	ch <- claimLocalMemoryTrafficDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *claimMonitoringCollector) Collect(ch chan<- prometheus.Metric) {
+	features := c.cp.resctrl.Capabilities().MonitoringFeatures
+	for claimUID, info := range c.cp.cpuAllocationStore.GetResourceClaimInfos() {
+		mon, ok, err := c.cp.resctrl.ReadMonitoring(string(claimUID))
+		if err != nil {
+			c.logger.Error(err, "Failed to read the monitoring counters of the claim", "claim", klog.KRef(info.Namespace, info.Name), "claimUID", claimUID)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		pods := make([]string, 0, len(info.ReservedFor))
+		for _, consumer := range info.ReservedFor {
+			if consumer.Resource == "pods" {
+				pods = append(pods, consumer.Name)
+			}
+		}
+		slices.Sort(pods)
+		labels := []string{info.Namespace, info.Name, strings.Join(pods, ",")}
+		if slices.Contains(features, "llc_occupancy") {
+			ch <- prometheus.MustNewConstMetric(claimLLCOccupancyDesc, prometheus.GaugeValue, float64(mon.LLCOccupancyBytes), labels...)
+		}
+		if slices.Contains(features, "mbm_total_bytes") {
+			ch <- prometheus.MustNewConstMetric(claimMemoryTrafficDesc, prometheus.CounterValue, float64(mon.MBMTotalBytes), labels...)
+		}
+		if slices.Contains(features, "mbm_local_bytes") {
+			ch <- prometheus.MustNewConstMetric(claimLocalMemoryTrafficDesc, prometheus.CounterValue, float64(mon.MBMLocalBytes), labels...)
+		}
+	}
+}
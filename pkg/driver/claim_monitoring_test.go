@@ -0,0 +1,105 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/resctrl"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+func TestClaimMonitoringCollector(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_SNC2_8CPUs_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	_, resctrlPath := newFakeResctrl(t)
+	for name, content := range map[string]string{
+		"info/L3_MON/mon_features": "llc_occupancy\nmbm_total_bytes\n",
+		"mon_groups/.keep":         "",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(resctrlPath, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(resctrlPath, name), []byte(content), 0644))
+	}
+	resctrlMgr, err := resctrl.New(resctrlPath)
+	require.NoError(t, err)
+	cp := &CPUDriver{
+		driverName:             testDriverName,
+		cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+		cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+		cpuTopology:            topo,
+		deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0, "cpudevnuma001": 1},
+		cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+		cdiMgr:                 newMockCdiMgr(),
+		resctrl:                resctrlMgr,
+	}
+	monitored := testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma000": 2})
+	monitored.Status.ReservedFor = []resourceapi.ResourceClaimConsumerReference{{Resource: "pods", Name: "pod-1", UID: "pod-uid-1"}}
+	allocated := withOpaqueConfig(testClaim("claim-2", testDriverName, testNodeName, map[string]int64{"cpudevnuma001": 2}), testDriverName, `{"cacheWays": 2}`)
+	results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{monitored, allocated})
+	require.NoError(t, err)
+	require.NoError(t, results[monitored.UID].Err)
+	require.NoError(t, results[allocated.UID].Err)
+
+	// the claims without cache allocation get a monitoring group, the others are monitored by their group.
+	monitoredPath := filepath.Join(resctrlPath, "mon_groups", "dracpu-claim-1")
+	allocatedPath := filepath.Join(resctrlPath, "dracpu-claim-2")
+	require.DirExists(t, monitoredPath)
+	require.NoDirExists(t, filepath.Join(resctrlPath, "mon_groups", "dracpu-claim-2"))
+	for name, content := range map[string]string{
+		filepath.Join(monitoredPath, "mon_data/mon_L3_00/llc_occupancy"):   "1048576\n",
+		filepath.Join(monitoredPath, "mon_data/mon_L3_00/mbm_total_bytes"): "4096\n",
+		filepath.Join(allocatedPath, "mon_data/mon_L3_00/llc_occupancy"):   "2048\n",
+		filepath.Join(allocatedPath, "mon_data/mon_L3_00/mbm_total_bytes"): "8192\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0755))
+		require.NoError(t, os.WriteFile(name, []byte(content), 0644))
+	}
+
+	ch := make(chan prometheus.Metric, 10)
+	(&claimMonitoringCollector{cp: cp, logger: klog.Background()}).Collect(ch)
+	close(ch)
+	values := map[string]float64{}
+	for metric := range ch {
+		m := &dto.Metric{}
+		require.NoError(t, metric.Write(m))
+		labels := map[string]string{}
+		for _, label := range m.Label {
+			labels[label.GetName()] = label.GetValue()
+		}
+		if m.Gauge != nil {
+			values[labels["claim"]+"/"+labels["pods"]+"/llc"] = m.Gauge.GetValue()
+		} else {
+			values[labels["claim"]+"/"+labels["pods"]+"/mbm"] = m.Counter.GetValue()
+		}
+	}
+	require.Equal(t, map[string]float64{
+		"claim-1/pod-1/llc": 1048576,
+		"claim-1/pod-1/mbm": 4096,
+		"claim-2//llc":      2048,
+		"claim-2//mbm":      8192,
+	}, values)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	resourceapply "k8s.io/client-go/applyconfigurations/resource/v1"
+	"k8s.io/utils/cpuset"
+)
+
+// ClaimAllocationStatus is the data recorded in the claim device status, for the workloads that need
+// the exact host CPUs of the claim, e.g. KubeVirt mapping vCPUs 1:1 or DPDK pinning its polling threads.
+type ClaimAllocationStatus struct {
+	// CPUs are all the CPUs of the claim.
+	CPUs string `json:"cpus"`
+	// NUMANodes are the NUMA nodes of the CPUs of the claim.
+	NUMANodes string `json:"numaNodes"`
+	// PollingCPUs are the CPUs of the polling cores of the claim.
+	PollingCPUs string `json:"pollingCPUs,omitempty"`
+	// EmulatorThreadCPUs are the CPUs of the claim set aside for the emulator threads of a VM.
+	EmulatorThreadCPUs string `json:"emulatorThreadCPUs,omitempty"`
+	// NUMA breaks the CPUs of the claim down by NUMA node.
+	NUMA []NUMANodeAllocationStatus `json:"numa,omitempty"`
+}
+
+// NUMANodeAllocationStatus describes the CPUs of a claim on one NUMA node.
+type NUMANodeAllocationStatus struct {
+	// NUMANode is the ID of the NUMA node.
+	NUMANode int `json:"numaNode"`
+	// Socket is the ID of the socket of the NUMA node.
+	Socket int `json:"socket"`
+	// CPUs are the CPUs of the claim on the NUMA node.
+	CPUs string `json:"cpus"`
+	// Cores are the IDs, unique within the socket, of the physical cores of those CPUs.
+	Cores string `json:"cores"`
+}
+
+// reportsAllocation returns true if the CPUs of the claim are recorded in the claim device status.
+func (c *ClaimConfig) reportsAllocation() bool {
+	return c.ReportAllocation || c.PollingCores > 0 || c.EmulatorThreadCPUs > 0
+}
+
+// reportsAllocation returns true if the CPUs of a claim with the configuration are recorded in the claim device status.
+func (cp *CPUDriver) reportsAllocation(config *ClaimConfig) bool {
+	return cp.reportAllocations || config.reportsAllocation()
+}
+
+// newClaimAllocationStatus describes the CPUs of a claim, setting aside the emulator thread CPUs.
+func (cp *CPUDriver) newClaimAllocationStatus(config *ClaimConfig, cpus, pollingCPUs cpuset.CPUSet) (ClaimAllocationStatus, error) {
+	status := ClaimAllocationStatus{
+		CPUs:        cpus.String(),
+		NUMANodes:   cp.cpuTopology.CPUDetails.KeepOnly(cpus).NUMANodes().String(),
+		PollingCPUs: pollingCPUs.String(),
+	}
+	details := cp.cpuTopology.CPUDetails.KeepOnly(cpus)
+	for _, numaNodeID := range details.NUMANodes().List() {
+		numaDetails := details.KeepOnly(details.CPUsInNUMANodes(numaNodeID))
+		status.NUMA = append(status.NUMA, NUMANodeAllocationStatus{
+			NUMANode: numaNodeID,
+			// all CPUs in a NUMA node belong to the same socket.
+			Socket: numaDetails.Sockets().List()[0],
+			CPUs:   numaDetails.CPUs().String(),
+			Cores:  numaDetails.CoresInNUMANodes(numaNodeID).String(),
+		})
+	}
+	if config.EmulatorThreadCPUs > 0 {
+		emulatorCPUs, err := cp.emulatorThreadCPUs(cpus.Difference(pollingCPUs), int(config.EmulatorThreadCPUs))
+		if err != nil {
+			return ClaimAllocationStatus{}, err
+		}
+		status.EmulatorThreadCPUs = emulatorCPUs.String()
+	}
+	return status, nil
+}
+
+// emulatorThreadCPUs picks numCPUs out of the CPUs of a claim for the emulator threads of a VM, leaving
+// at least one CPU for the vCPUs. The CPUs whose SMT siblings are not in the claim are picked first, so the
+// vCPUs keep as many full cores as possible, then the highest-numbered ones.
+func (cp *CPUDriver) emulatorThreadCPUs(cpus cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, error) {
+	if numCPUs >= cpus.Size() {
+		return cpuset.New(), fmt.Errorf("%d emulator thread CPUs leave no vCPU out of the %d CPUs of the claim", numCPUs, cpus.Size())
+	}
+	topo := cp.cpuTopology
+	var partial, full []int
+	list := cpus.List()
+	for i := len(list) - 1; i >= 0; i-- {
+		info := topo.CPUDetails[list[i]]
+		// core IDs are only unique within a socket
+		coreCPUs := topo.CPUDetails.CPUsInCores(info.CoreID).Intersection(topo.CPUDetails.CPUsInSockets(info.SocketID))
+		if coreCPUs.IsSubsetOf(cpus) {
+			full = append(full, list[i])
+		} else {
+			partial = append(partial, list[i])
+		}
+	}
+	return cpuset.New(append(partial, full...)[:numCPUs]...), nil
+}
+
+// recordAllocationStatus records the CPUs of the claim in the status of its devices allocated by this driver.
+func (cp *CPUDriver) recordAllocationStatus(ctx context.Context, claim *resourceapi.ResourceClaim, allocationStatus ClaimAllocationStatus) error {
+	// there is no claim to record the status in without an API server.
+	if cp.dryRun || cp.standalone {
+		return nil
+	}
+	data, err := json.Marshal(allocationStatus)
+	if err != nil {
+		return err
+	}
+	status := resourceapply.ResourceClaimStatus()
+	for _, result := range claim.Status.Allocation.Devices.Results {
+		if result.Driver != cp.driverName {
+			continue
+		}
+		device := resourceapply.AllocatedDeviceStatus().
+			WithDriver(result.Driver).
+			WithPool(result.Pool).
+			WithDevice(result.Device).
+			WithData(runtime.RawExtension{Raw: data})
+		if result.ShareID != nil {
+			device = device.WithShareID(string(*result.ShareID))
+		}
+		status = status.WithDevices(device)
+	}
+	claimApply := resourceapply.ResourceClaim(claim.Name, claim.Namespace).WithStatus(status)
+	_, err = cp.kubeClient.ResourceV1().ResourceClaims(claim.Namespace).ApplyStatus(ctx, claimApply, metav1.ApplyOptions{FieldManager: cp.driverName, Force: true})
+	return err
+}
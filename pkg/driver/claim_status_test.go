@@ -0,0 +1,155 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/cpuset"
+)
+
+func TestPrepareResourceClaimsReportAllocation(t *testing.T) {
+	// cores are (0,2) and (1,3).
+	testCases := []struct {
+		name              string
+		cpuDeviceMode     string
+		consumedCapacity  map[string]int64
+		opaqueConfig      []string
+		reportAllocations bool
+		expectedError     bool
+		expectedStatus    *ClaimAllocationStatus
+	}{
+		{
+			name:             "not reported by default",
+			cpuDeviceMode:    CPU_DEVICE_MODE_GROUPED,
+			consumedCapacity: map[string]int64{"cpudevnuma000": 2},
+		},
+		{
+			name:             "grouped mode",
+			cpuDeviceMode:    CPU_DEVICE_MODE_GROUPED,
+			consumedCapacity: map[string]int64{"cpudevnuma000": 2},
+			opaqueConfig:     []string{`{"reportAllocation": true}`},
+			expectedStatus:   &ClaimAllocationStatus{CPUs: "0,2", NUMANodes: "0", NUMA: []NUMANodeAllocationStatus{{NUMANode: 0, Socket: 0, CPUs: "0,2", Cores: "0"}}},
+		},
+		{
+			name:              "reported for all the claims",
+			cpuDeviceMode:     CPU_DEVICE_MODE_GROUPED,
+			consumedCapacity:  map[string]int64{"cpudevnuma000": 2},
+			reportAllocations: true,
+			expectedStatus:    &ClaimAllocationStatus{CPUs: "0,2", NUMANodes: "0", NUMA: []NUMANodeAllocationStatus{{NUMANode: 0, Socket: 0, CPUs: "0,2", Cores: "0"}}},
+		},
+		{
+			name:             "emulator thread on the CPU without its sibling",
+			cpuDeviceMode:    CPU_DEVICE_MODE_GROUPED,
+			consumedCapacity: map[string]int64{"cpudevnuma000": 3},
+			opaqueConfig:     []string{`{"emulatorThreadCPUs": 1}`},
+			expectedStatus:   &ClaimAllocationStatus{CPUs: "0-2", NUMANodes: "0", EmulatorThreadCPUs: "1", NUMA: []NUMANodeAllocationStatus{{NUMANode: 0, Socket: 0, CPUs: "0-2", Cores: "0-1"}}},
+		},
+		{
+			name:             "emulator thread on the highest-numbered full core",
+			cpuDeviceMode:    CPU_DEVICE_MODE_GROUPED,
+			consumedCapacity: map[string]int64{"cpudevnuma000": 4},
+			opaqueConfig:     []string{`{"emulatorThreadCPUs": 2}`},
+			expectedStatus:   &ClaimAllocationStatus{CPUs: "0-3", NUMANodes: "0", EmulatorThreadCPUs: "2-3", NUMA: []NUMANodeAllocationStatus{{NUMANode: 0, Socket: 0, CPUs: "0-3", Cores: "0-1"}}},
+		},
+		{
+			name:             "emulator threads leave no vCPU",
+			cpuDeviceMode:    CPU_DEVICE_MODE_GROUPED,
+			consumedCapacity: map[string]int64{"cpudevnuma000": 2},
+			opaqueConfig:     []string{`{"emulatorThreadCPUs": 2}`},
+			expectedError:    true,
+		},
+		{
+			name:             "individual mode",
+			cpuDeviceMode:    CPU_DEVICE_MODE_INDIVIDUAL,
+			consumedCapacity: map[string]int64{"cpudev000": 1, "cpudev001": 1, "cpudev002": 1},
+			opaqueConfig:     []string{`{"emulatorThreadCPUs": 1}`},
+			expectedStatus:   &ClaimAllocationStatus{CPUs: "0-2", NUMANodes: "0", EmulatorThreadCPUs: "1", NUMA: []NUMANodeAllocationStatus{{NUMANode: 0, Socket: 0, CPUs: "0-2", Cores: "0-1"}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claim := withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, tc.consumedCapacity), testDriverName, tc.opaqueConfig...)
+			claim.Namespace = "ns"
+			kubeClient := fake.NewClientset(claim.DeepCopy())
+			mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_4CPUS_HT}
+			topo, _ := mockProvider.GetCPUTopology()
+			cp := &CPUDriver{
+				driverName:             testDriverName,
+				kubeClient:             kubeClient,
+				cpuDeviceMode:          tc.cpuDeviceMode,
+				cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+				cpuTopology:            topo,
+				deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0},
+				deviceNameToCPUID:      map[string]int{"cpudev000": 0, "cpudev001": 1, "cpudev002": 2, "cpudev003": 3},
+				cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+				podConfigStore:         store.NewPodConfig(),
+				claimTracker:           store.NewClaimTracker(),
+				cdiMgr:                 newMockCdiMgr(),
+				reportAllocations:      tc.reportAllocations,
+			}
+
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			if tc.expectedError {
+				require.Error(t, results[claim.UID].Err)
+				_, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+				require.False(t, ok)
+				return
+			}
+			require.NoError(t, results[claim.UID].Err)
+
+			updated, err := kubeClient.ResourceV1().ResourceClaims("ns").Get(context.Background(), "claim-1", metav1.GetOptions{})
+			require.NoError(t, err)
+			if tc.expectedStatus == nil {
+				require.Empty(t, updated.Status.Devices)
+				return
+			}
+			require.Len(t, updated.Status.Devices, len(tc.consumedCapacity))
+			for _, device := range updated.Status.Devices {
+				status := ClaimAllocationStatus{}
+				require.NoError(t, json.Unmarshal(device.Data.Raw, &status))
+				require.Equal(t, *tc.expectedStatus, status, "device %s", device.Device)
+			}
+		})
+	}
+}
+
+func TestNewClaimAllocationStatusNUMABreakdown(t *testing.T) {
+	// NUMA node 0 has CPUs 0,1,4,5 on cores 0-1 of socket 0, NUMA node 1 CPUs 2,3,6,7 on cores 2-3 of socket 1.
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cp := &CPUDriver{cpuTopology: topo}
+
+	status, err := cp.newClaimAllocationStatus(&ClaimConfig{}, cpuset.New(0, 4, 5, 2, 6), cpuset.New())
+	require.NoError(t, err)
+	require.Equal(t, "0,2,4-6", status.CPUs)
+	require.Equal(t, "0-1", status.NUMANodes)
+	require.Equal(t, []NUMANodeAllocationStatus{
+		{NUMANode: 0, Socket: 0, CPUs: "0,4-5", Cores: "0-1"},
+		{NUMANode: 1, Socket: 1, CPUs: "2,6", Cores: "2"},
+	}, status.NUMA)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/utils/cpuset"
+)
+
+// eventReasonContiguousCPUsUnavailable is recorded on contiguous claims which could not get a contiguous range of CPU IDs.
+const eventReasonContiguousCPUsUnavailable = "ContiguousCPUsUnavailable"
+
+// takeContiguousCPUs picks numCPUs CPUs with consecutive IDs out of the available ones. The smallest
+// free range fitting the request is used, the lowest numbered one on ties, to limit the fragmentation.
+func takeContiguousCPUs(availableCPUs cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, error) {
+	bestStart, bestSize := -1, 0
+	cpus := availableCPUs.List()
+	for start := 0; start < len(cpus); {
+		end := start + 1
+		for end < len(cpus) && cpus[end] == cpus[end-1]+1 {
+			end++
+		}
+		if size := end - start; size >= numCPUs && (bestStart < 0 || size < bestSize) {
+			bestStart, bestSize = cpus[start], size
+		}
+		start = end
+	}
+	if bestStart < 0 {
+		return cpuset.New(), fmt.Errorf("no range of %d contiguous CPU IDs available, free CPUs: %s", numCPUs, availableCPUs.String())
+	}
+	result := make([]int, 0, numCPUs)
+	for cpu := bestStart; cpu < bestStart+numCPUs; cpu++ {
+		result = append(result, cpu)
+	}
+	return cpuset.New(result...), nil
+}
+
+// isContiguous returns true if the CPU IDs form a single range.
+func isContiguous(cpus cpuset.CPUSet) bool {
+	list := cpus.List()
+	return len(list) == 0 || list[len(list)-1]-list[0] == len(list)-1
+}
+
+// recordContiguousFailure records the reason a contiguous claim could not be prepared on the claim.
+func (cp *CPUDriver) recordContiguousFailure(claim *resourceapi.ResourceClaim, err error) {
+	cp.eventRecorder.Eventf(claimReference(claim.UID, claim.Namespace, claim.Name), corev1.EventTypeWarning, eventReasonContiguousCPUsUnavailable,
+		"Cannot get contiguous CPUs on node %s: %v", cp.nodeName, err)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/cpuset"
+)
+
+func TestTakeContiguousCPUs(t *testing.T) {
+	testCases := []struct {
+		name          string
+		availableCPUs cpuset.CPUSet
+		numCPUs       int
+		expectedError bool
+		expectedCPUs  cpuset.CPUSet
+	}{
+		{
+			name:          "all CPUs free",
+			availableCPUs: cpuset.New(0, 1, 2, 3, 4, 5, 6, 7),
+			numCPUs:       3,
+			expectedCPUs:  cpuset.New(0, 1, 2),
+		},
+		{
+			name:          "smallest fitting range",
+			availableCPUs: cpuset.New(0, 1, 2, 3, 5, 6, 8, 9, 10),
+			numCPUs:       3,
+			expectedCPUs:  cpuset.New(8, 9, 10),
+		},
+		{
+			name:          "lowest numbered range on ties",
+			availableCPUs: cpuset.New(1, 2, 4, 5, 7, 8),
+			numCPUs:       2,
+			expectedCPUs:  cpuset.New(1, 2),
+		},
+		{
+			name:          "fragmented",
+			availableCPUs: cpuset.New(0, 2, 4, 6),
+			numCPUs:       2,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cpus, err := takeContiguousCPUs(tc.availableCPUs, tc.numCPUs)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, tc.expectedCPUs.Equals(cpus), "expected %s got %s", tc.expectedCPUs.String(), cpus.String())
+		})
+	}
+}
+
+func TestPrepareResourceClaimsContiguous(t *testing.T) {
+	// CPU 1 is allocated, leaving 0 and 2-3 free.
+	testCases := []struct {
+		name          string
+		cpuDeviceMode string
+		devices       map[string]int64
+		opaqueConfig  []string
+		expectedError bool
+		expectedEvent bool
+		expectedCPUs  cpuset.CPUSet
+	}{
+		{
+			name:          "grouped, contiguous range",
+			cpuDeviceMode: CPU_DEVICE_MODE_GROUPED,
+			devices:       map[string]int64{"cpudevnuma0": 2},
+			opaqueConfig:  []string{`{"contiguous": true}`},
+			expectedCPUs:  cpuset.New(2, 3),
+		},
+		{
+			name:          "grouped, without contiguous",
+			cpuDeviceMode: CPU_DEVICE_MODE_GROUPED,
+			devices:       map[string]int64{"cpudevnuma0": 2},
+			expectedCPUs:  cpuset.New(0, 2),
+		},
+		{
+			name:          "grouped, too fragmented",
+			cpuDeviceMode: CPU_DEVICE_MODE_GROUPED,
+			devices:       map[string]int64{"cpudevnuma0": 3},
+			opaqueConfig:  []string{`{"contiguous": true}`},
+			expectedError: true,
+			expectedEvent: true,
+		},
+		{
+			name:          "grouped, with polling cores",
+			cpuDeviceMode: CPU_DEVICE_MODE_GROUPED,
+			devices:       map[string]int64{"cpudevnuma0": 2},
+			opaqueConfig:  []string{`{"contiguous": true, "pollingCores": 1}`},
+			expectedError: true,
+		},
+		{
+			name:          "individual, contiguous devices",
+			cpuDeviceMode: CPU_DEVICE_MODE_INDIVIDUAL,
+			devices:       map[string]int64{"cpudev002": 1, "cpudev003": 1},
+			opaqueConfig:  []string{`{"contiguous": true}`},
+			expectedCPUs:  cpuset.New(2, 3),
+		},
+		{
+			name:          "individual, non contiguous devices",
+			cpuDeviceMode: CPU_DEVICE_MODE_INDIVIDUAL,
+			devices:       map[string]int64{"cpudev000": 1, "cpudev002": 1},
+			opaqueConfig:  []string{`{"contiguous": true}`},
+			expectedError: true,
+			expectedEvent: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_4CPUS_HT}
+			topo, _ := mockProvider.GetCPUTopology()
+			recorder := record.NewFakeRecorder(10)
+			cp := &CPUDriver{
+				driverName:             testDriverName,
+				nodeName:               testNodeName,
+				cpuDeviceMode:          tc.cpuDeviceMode,
+				cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+				cpuTopology:            topo,
+				deviceNameToNUMANodeID: map[string]int{"cpudevnuma0": 0},
+				deviceNameToCPUID:      map[string]int{"cpudev000": 0, "cpudev001": 1, "cpudev002": 2, "cpudev003": 3},
+				cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+				podConfigStore:         store.NewPodConfig(),
+				claimTracker:           store.NewClaimTracker(),
+				cdiMgr:                 newMockCdiMgr(),
+				eventRecorder:          recorder,
+			}
+			cp.cpuAllocationStore.AddResourceClaimAllocation("claim-0", cpuset.New(1))
+
+			claim := withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, tc.devices), testDriverName, tc.opaqueConfig...)
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			if tc.expectedError {
+				require.Error(t, results[claim.UID].Err)
+				_, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+				require.False(t, ok)
+				if tc.expectedEvent {
+					require.Len(t, recorder.Events, 1)
+				} else {
+					require.Empty(t, recorder.Events)
+				}
+				return
+			}
+			require.NoError(t, results[claim.UID].Err)
+			cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+			require.True(t, ok)
+			require.True(t, tc.expectedCPUs.Equals(cpus), "expected %s got %s", tc.expectedCPUs.String(), cpus.String())
+			require.Empty(t, recorder.Events)
+		})
+	}
+}
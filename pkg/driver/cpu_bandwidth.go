@@ -0,0 +1,70 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"github.com/containerd/nri/pkg/api"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/purevalidate"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+const (
+	// CPU_BANDWIDTH_POLICY_POD leaves the CPU quota and weight of the containers to the requests and limits of the pod.
+	CPU_BANDWIDTH_POLICY_POD = purevalidate.CPUBandwidthPod
+	// CPU_BANDWIDTH_POLICY_CLAIM sets the CPU quota and weight of the containers of the claim from the number of its CPUs.
+	CPU_BANDWIDTH_POLICY_CLAIM = purevalidate.CPUBandwidthClaim
+)
+
+const (
+	// cpuBandwidthPeriod is the CFS period, in microseconds, of the quota of the claims, the kernel default.
+	cpuBandwidthPeriod = 100000
+	// cpuSharesPerCPU are the CPU shares of a CPU, like the kubelet sets them from the requests; the
+	// runtime converts them to the cpu.weight of the cgroup v2.
+	cpuSharesPerCPU = 1024
+)
+
+// cpuBandwidthFromClaim returns true if the CPU quota and weight of the containers of the claim follow its CPUs.
+func (config *ClaimConfig) cpuBandwidthFromClaim() (bool, error) {
+	if err := purevalidate.ValidateCPUBandwidth(config.CPUBandwidth); err != nil {
+		return false, err
+	}
+	return config.CPUBandwidth == CPU_BANDWIDTH_POLICY_CLAIM, nil
+}
+
+// adjustCPUBandwidth sets the cpu.max and cpu.weight of a container from the number of its guaranteed CPUs
+// when one of its claims asks for it, so the container is neither throttled below the CPUs of its claims
+// nor weighted as if it had the CPUs of its pod requests only. The limits of the pod cgroup still apply.
+func (cp *CPUDriver) adjustCPUBandwidth(logger klog.Logger, adjust *api.ContainerAdjustment, claimAllocations map[types.UID]cpuset.CPUSet) {
+	infos := cp.cpuAllocationStore.GetResourceClaimInfos()
+	guaranteedCPUs := cpuset.New()
+	fromClaim := false
+	for uid, cpus := range claimAllocations {
+		guaranteedCPUs = guaranteedCPUs.Union(cpus)
+		fromClaim = fromClaim || infos[uid].CPUBandwidthFromClaim
+	}
+	if !fromClaim {
+		return
+	}
+	quota := int64(guaranteedCPUs.Size()) * cpuBandwidthPeriod
+	shares := uint64(guaranteedCPUs.Size()) * cpuSharesPerCPU
+	logger.Info("Setting the CPU bandwidth of the container from its claims", "quota", quota, "period", cpuBandwidthPeriod, "shares", shares)
+	adjust.SetLinuxCPUQuota(quota)
+	adjust.SetLinuxCPUPeriod(cpuBandwidthPeriod)
+	adjust.SetLinuxCPUShares(shares)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/utils/cpuset"
+)
+
+func TestPrepareResourceClaimsCPUBandwidth(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+
+	testCases := []struct {
+		name              string
+		opaqueConfig      []string
+		expectedFromClaim bool
+		expectedError     string
+	}{
+		{
+			name: "pod bandwidth by default",
+		},
+		{
+			name:              "claim bandwidth",
+			opaqueConfig:      []string{`{"cpuBandwidth": "claim"}`},
+			expectedFromClaim: true,
+		},
+		{
+			name:          "unknown policy",
+			opaqueConfig:  []string{`{"cpuBandwidth": "burst"}`},
+			expectedError: "unknown cpuBandwidth policy",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cp := &CPUDriver{
+				driverName:             testDriverName,
+				cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+				cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+				cpuTopology:            topo,
+				deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0, "cpudevnuma001": 1},
+				cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+				cdiMgr:                 newMockCdiMgr(),
+			}
+			claim := withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma000": 2}), testDriverName, tc.opaqueConfig...)
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, results[claim.UID].Err, tc.expectedError)
+				return
+			}
+			require.NoError(t, results[claim.UID].Err)
+			require.Equal(t, tc.expectedFromClaim, cp.cpuAllocationStore.GetResourceClaimInfos()[claim.UID].CPUBandwidthFromClaim)
+		})
+	}
+}
+
+func TestCreateContainerCPUBandwidth(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cp := &CPUDriver{
+		cpuAllocationStore: store.NewCPUAllocation(topo, cpuset.New()),
+		podConfigStore:     store.NewPodConfig(),
+		claimTracker:       store.NewClaimTracker(),
+	}
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-bandwidth", cpuset.New(0, 1))
+	cp.cpuAllocationStore.SetResourceClaimInfo("claim-bandwidth", store.ClaimInfo{Namespace: "ns", Name: "bandwidth", CPUBandwidthFromClaim: true})
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-plain", cpuset.New(2))
+	cp.cpuAllocationStore.SetResourceClaimInfo("claim-plain", store.ClaimInfo{Namespace: "ns", Name: "plain"})
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-other", cpuset.New(3))
+	cp.cpuAllocationStore.SetResourceClaimInfo("claim-other", store.ClaimInfo{Namespace: "ns", Name: "other"})
+
+	pod := &api.PodSandbox{Id: "pod-id", Name: "pod", Namespace: "ns", Uid: "pod-uid"}
+	ctr := &api.Container{Id: "ctr-id-1", PodSandboxId: pod.Id, Name: "both", Env: []string{
+		fmt.Sprintf("%s_%s=%s", cdiEnvVarPrefix, "claim-bandwidth", "0-1"),
+		fmt.Sprintf("%s_%s=%s", cdiEnvVarPrefix, "claim-plain", "2"),
+	}}
+	adjust, _, err := cp.CreateContainer(context.Background(), pod, ctr)
+	require.NoError(t, err)
+	cpu := adjust.Linux.Resources.Cpu
+	require.Equal(t, "0-2", cpu.Cpus)
+	require.Equal(t, int64(3*cpuBandwidthPeriod), cpu.Quota.GetValue())
+	require.Equal(t, uint64(cpuBandwidthPeriod), cpu.Period.GetValue())
+	require.Equal(t, uint64(3*cpuSharesPerCPU), cpu.Shares.GetValue())
+
+	ctr = &api.Container{Id: "ctr-id-2", PodSandboxId: pod.Id, Name: "other", Env: []string{fmt.Sprintf("%s_%s=%s", cdiEnvVarPrefix, "claim-other", "3")}}
+	adjust, _, err = cp.CreateContainer(context.Background(), pod, ctr)
+	require.NoError(t, err)
+	cpu = adjust.Linux.Resources.Cpu
+	require.Equal(t, "3", cpu.Cpus)
+	require.Nil(t, cpu.Quota)
+	require.Nil(t, cpu.Period)
+	require.Nil(t, cpu.Shares)
+}
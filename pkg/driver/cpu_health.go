@@ -0,0 +1,153 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+const (
+	// degradedReasonMachineCheck marks the CPUs which handled machine check exceptions. They stay degraded until the driver restarts.
+	degradedReasonMachineCheck = "MachineCheck"
+	// degradedReasonThermalThrottling marks the CPUs throttled since the previous check, until a check finds no new throttling.
+	degradedReasonThermalThrottling = "ThermalThrottling"
+	// degradedCPUTaintKey is the key of the taint added to the individual devices of degraded CPUs.
+	degradedCPUTaintKey = "dra.cpu/degraded"
+	// eventReasonCPUsReplaced is recorded on claims whose degraded CPUs were replaced by healthy ones.
+	eventReasonCPUsReplaced = "DegradedCPUsReplaced"
+)
+
+// checkCPUHealth compares the hardware event counters of the CPUs with the ones of the previous check,
+// and marks the CPUs with new machine check exceptions or thermal throttling events as degraded.
+// Degraded CPUs are removed from the shared pool and from the capacity of the grouped devices, and
+// their individual devices are tainted. The first check only records the counters as a baseline,
+// so events which happened before the driver started are ignored.
+func (cp *CPUDriver) checkCPUHealth(ctx context.Context) {
+	logger := klog.FromContext(ctx)
+	counters, err := cp.readCPUHealthCounters()
+	if err != nil {
+		logger.Error(err, "Failed to read the CPU health counters")
+		return
+	}
+	previous := cp.cpuHealthCounters
+	cp.cpuHealthCounters = counters
+	if previous == nil {
+		return
+	}
+
+	degraded := map[int]string{}
+	for cpuID, reason := range cp.cpuAllocationStore.GetDegradedCPUs() {
+		if reason == degradedReasonMachineCheck {
+			degraded[cpuID] = reason
+		}
+	}
+	for cpuID, c := range counters {
+		prev, ok := previous[cpuID]
+		if !ok {
+			continue
+		}
+		if c.MachineChecks > prev.MachineChecks {
+			degraded[cpuID] = degradedReasonMachineCheck
+		} else if _, ok := degraded[cpuID]; !ok && c.ThermalThrottles > prev.ThermalThrottles {
+			degraded[cpuID] = degradedReasonThermalThrottling
+		}
+	}
+	if !cp.cpuAllocationStore.SetDegradedCPUs(logger, degraded) {
+		return
+	}
+	degradedCPUs.Set(float64(len(degraded)))
+
+	if cp.replaceDegradedClaims {
+		cp.replaceClaimsOnDegradedCPUs(ctx, cpuset.New(slices.Collect(maps.Keys(degraded))...))
+	}
+	cp.requestPublish(ctx)
+	updates := cp.getSharedContainerUpdates("")
+	if len(updates) == 0 {
+		return
+	}
+	if _, err := cp.nriPlugin.UpdateContainers(updates); err != nil {
+		logger.Error(err, "Failed to update the shared containers after the degraded CPUs change")
+	}
+}
+
+// replaceClaimsOnDegradedCPUs moves the grouped mode claims off the degraded CPUs: every degraded CPU of
+// a claim is replaced by a free CPU of the same device, the other CPUs of the claim are kept.
+// Claims for which the device has not enough free CPUs are left untouched.
+func (cp *CPUDriver) replaceClaimsOnDegradedCPUs(ctx context.Context, degraded cpuset.CPUSet) {
+	if cp.cpuDeviceMode != CPU_DEVICE_MODE_GROUPED {
+		return
+	}
+	logger := klog.FromContext(ctx)
+	for _, allocation := range cp.cpuAllocationStore.GetClaimAllocationsUsing(degraded) {
+		newCPUs, err := cp.replaceDegradedCPUs(logger, allocation, degraded)
+		if err != nil {
+			logger.Error(err, "Cannot replace the degraded CPUs of the claim", "claim", klog.KRef(allocation.Namespace, allocation.Name), "claimUID", allocation.ClaimUID, "cpus", allocation.CPUs.Intersection(degraded).String())
+			continue
+		}
+		logger.Info("Replacing the degraded CPUs of the claim", "claim", klog.KRef(allocation.Namespace, allocation.Name), "claimUID", allocation.ClaimUID, "oldCPUs", allocation.CPUs.String(), "cpus", newCPUs.String())
+		cp.moveClaims(logger, []claimMigration{{ClaimAllocation: allocation, newCPUs: newCPUs}})
+		if allocation.Name != "" {
+			cp.eventRecorder.Eventf(claimReference(allocation.ClaimUID, allocation.Namespace, allocation.Name), corev1.EventTypeWarning, eventReasonCPUsReplaced,
+				"Degraded CPUs %s on node %s replaced, CPUs %s moved to %s", allocation.CPUs.Intersection(degraded).String(), cp.nodeName, allocation.CPUs.String(), newCPUs.String())
+		}
+	}
+}
+
+// replaceDegradedCPUs returns the CPUs of the claim with its degraded CPUs replaced by free CPUs of the same device,
+// so the CPUs the claim consumes from each device don't change.
+func (cp *CPUDriver) replaceDegradedCPUs(logger klog.Logger, allocation store.ClaimAllocation, degraded cpuset.CPUSet) (cpuset.CPUSet, error) {
+	topo := cp.cpuTopology
+	// count the degraded CPUs of the claim per device.
+	degradedPerDevice := map[int]int{}
+	for _, cpuID := range allocation.CPUs.Intersection(degraded).List() {
+		switch cp.cpuDeviceGroupBy {
+		case GROUP_BY_SOCKET:
+			degradedPerDevice[topo.CPUDetails[cpuID].SocketID]++
+		case GROUP_BY_NODE:
+			degradedPerDevice[0]++
+		default:
+			degradedPerDevice[topo.CPUDetails[cpuID].NUMANodeID]++
+		}
+	}
+
+	newCPUs := allocation.CPUs.Difference(degraded)
+	freeCPUs := cp.cpuAllocationStore.GetSharedCPUs()
+	for _, deviceID := range slices.Sorted(maps.Keys(degradedPerDevice)) {
+		deviceCPUs := topo.CPUDetails.CPUsInNUMANodes(deviceID)
+		switch cp.cpuDeviceGroupBy {
+		case GROUP_BY_SOCKET:
+			deviceCPUs = topo.CPUDetails.CPUsInSockets(deviceID)
+		case GROUP_BY_NODE:
+			deviceCPUs = topo.CPUDetails.CPUs()
+		}
+		cpus, err := cpumanager.TakeByTopologyNUMAPacked(logger, topo, freeCPUs.Intersection(deviceCPUs), degradedPerDevice[deviceID], cpumanager.CPUSortingStrategyPacked, true)
+		if err != nil {
+			return cpuset.New(), err
+		}
+		freeCPUs = freeCPUs.Difference(cpus)
+		newCPUs = newCPUs.Union(cpus)
+	}
+	return newCPUs, nil
+}
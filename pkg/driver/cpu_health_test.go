@@ -0,0 +1,171 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+// fakeCPUHealthCounters returns the counters of successive checks.
+type fakeCPUHealthCounters struct {
+	checks []map[int]cpuinfo.CPUHealthCounters
+}
+
+func (f *fakeCPUHealthCounters) read() (map[int]cpuinfo.CPUHealthCounters, error) {
+	counters := f.checks[0]
+	f.checks = f.checks[1:]
+	return counters, nil
+}
+
+func newHealthTestDriver(t *testing.T, deviceMode string, counters ...map[int]cpuinfo.CPUHealthCounters) (*CPUDriver, *mockKubeletPlugin) {
+	t.Helper()
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_4CPUS_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	mockPlugin := &mockKubeletPlugin{}
+	cp := &CPUDriver{
+		driverName:             testDriverName,
+		nodeName:               testNodeName,
+		draPlugin:              mockPlugin,
+		cpuDeviceMode:          deviceMode,
+		cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+		cpuTopology:            topo,
+		deviceNameToCPUID:      make(map[string]int),
+		deviceNameToNUMANodeID: make(map[string]int),
+		deviceNameToSocketID:   make(map[string]int),
+		cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+		podConfigStore:         store.NewPodConfig(),
+		claimTracker:           store.NewClaimTracker(),
+		cdiMgr:                 newMockCdiMgr(),
+		nriPlugin:              &fakeNRIStub{},
+		eventRecorder:          record.NewFakeRecorder(10),
+		readCPUHealthCounters:  (&fakeCPUHealthCounters{checks: counters}).read,
+	}
+	return cp, mockPlugin
+}
+
+func TestCheckCPUHealth(t *testing.T) {
+	cp, mockPlugin := newHealthTestDriver(t, CPU_DEVICE_MODE_INDIVIDUAL,
+		// baseline: events which happened before the driver started are ignored.
+		map[int]cpuinfo.CPUHealthCounters{0: {}, 1: {MachineChecks: 3}, 2: {ThermalThrottles: 7}, 3: {}},
+		map[int]cpuinfo.CPUHealthCounters{0: {}, 1: {MachineChecks: 4}, 2: {ThermalThrottles: 9}, 3: {}},
+		// no new event: the thermal throttling is over, machine checks are permanent.
+		map[int]cpuinfo.CPUHealthCounters{0: {}, 1: {MachineChecks: 4}, 2: {ThermalThrottles: 9}, 3: {}},
+	)
+	ctx := context.Background()
+
+	cp.checkCPUHealth(ctx)
+	require.Empty(t, cp.cpuAllocationStore.GetDegradedCPUs())
+	require.Nil(t, mockPlugin.publishedResources, "resources must not be republished when no CPU is degraded")
+
+	cp.checkCPUHealth(ctx)
+	require.Equal(t, map[int]string{1: degradedReasonMachineCheck, 2: degradedReasonThermalThrottling}, cp.cpuAllocationStore.GetDegradedCPUs())
+	require.True(t, cp.cpuAllocationStore.GetSharedCPUs().Equals(cpuset.New(0, 3)))
+	require.NotNil(t, mockPlugin.publishedResources)
+	taints := map[int][]resourceapi.DeviceTaint{}
+	for _, device := range mockPlugin.publishedResources.Pools[testNodeName].Slices[0].Devices {
+		taints[cp.deviceNameToCPUID[device.Name]] = device.Taints
+	}
+	require.Equal(t, map[int][]resourceapi.DeviceTaint{
+		0: nil,
+		1: {{Key: degradedCPUTaintKey, Value: degradedReasonMachineCheck, Effect: resourceapi.DeviceTaintEffectNoSchedule}},
+		2: {{Key: degradedCPUTaintKey, Value: degradedReasonThermalThrottling, Effect: resourceapi.DeviceTaintEffectNoSchedule}},
+		3: nil,
+	}, taints)
+
+	cp.checkCPUHealth(ctx)
+	require.Equal(t, map[int]string{1: degradedReasonMachineCheck}, cp.cpuAllocationStore.GetDegradedCPUs())
+	require.True(t, cp.cpuAllocationStore.GetSharedCPUs().Equals(cpuset.New(0, 2, 3)))
+}
+
+func TestReplaceClaimsOnDegradedCPUs(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		replaceDegradedClaims bool
+		degradedCPUs          map[int]cpuinfo.CPUHealthCounters
+		expectedCPUs          cpuset.CPUSet
+		expectedCapacity      int64
+	}{
+		{
+			name:             "claims are not replaced by default",
+			degradedCPUs:     map[int]cpuinfo.CPUHealthCounters{0: {MachineChecks: 1}, 1: {}, 2: {}, 3: {}},
+			expectedCPUs:     cpuset.New(0, 2),
+			expectedCapacity: 3,
+		},
+		{
+			name:                  "degraded CPU replaced by a free CPU of the device",
+			replaceDegradedClaims: true,
+			degradedCPUs:          map[int]cpuinfo.CPUHealthCounters{0: {MachineChecks: 1}, 1: {}, 2: {}, 3: {}},
+			expectedCPUs:          cpuset.New(1, 2),
+			expectedCapacity:      3,
+		},
+		{
+			name:                  "not enough healthy free CPUs",
+			replaceDegradedClaims: true,
+			degradedCPUs:          map[int]cpuinfo.CPUHealthCounters{0: {MachineChecks: 1}, 1: {MachineChecks: 1}, 2: {}, 3: {MachineChecks: 1}},
+			expectedCPUs:          cpuset.New(0, 2),
+			expectedCapacity:      1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cp, mockPlugin := newHealthTestDriver(t, CPU_DEVICE_MODE_GROUPED,
+				map[int]cpuinfo.CPUHealthCounters{0: {}, 1: {}, 2: {}, 3: {}},
+				tc.degradedCPUs,
+			)
+			cp.replaceDegradedClaims = tc.replaceDegradedClaims
+			claimUID := types.UID("claim-1")
+			cp.cpuAllocationStore.AddResourceClaimAllocation(claimUID, cpuset.New(0, 2))
+			cp.cpuAllocationStore.SetResourceClaimInfo(claimUID, store.ClaimInfo{Namespace: "ns", Name: "claim-1"})
+			require.NoError(t, cp.claimTracker.SetOwner(klog.Background(), claimUID, "pod-1", "ctr"))
+			cp.podConfigStore.SetContainerState("pod-1", store.NewContainerState("ctr", "ctr-1", claimUID))
+
+			ctx := context.Background()
+			cp.checkCPUHealth(ctx)
+			cp.checkCPUHealth(ctx)
+
+			cpus, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(claimUID)
+			require.True(t, tc.expectedCPUs.Equals(cpus), "expected %s got %s", tc.expectedCPUs.String(), cpus.String())
+			devices := mockPlugin.publishedResources.Pools[testNodeName].Slices[0].Devices
+			require.Len(t, devices, 1)
+			capacity := devices[0].Capacity[cpuResourceQualifiedName].Value
+			require.Equal(t, tc.expectedCapacity, capacity.Value())
+
+			nriStub := cp.nriPlugin.(*fakeNRIStub)
+			if tc.expectedCPUs.Equals(cpuset.New(0, 2)) {
+				require.Empty(t, nriStub.updates)
+				return
+			}
+			expectedUpdate := &api.ContainerUpdate{ContainerId: "ctr-1"}
+			expectedUpdate.SetLinuxCPUSetCPUs(tc.expectedCPUs.String())
+			require.Equal(t, []*api.ContainerUpdate{expectedUpdate}, nriStub.updates)
+			require.Equal(t, []string{"DRA_CPUSET_claim-1=" + tc.expectedCPUs.String()}, cp.cdiMgr.(*mockCdiMgr).devices[getCDIDeviceName(claimUID)])
+			require.Len(t, cp.eventRecorder.(*record.FakeRecorder).Events, 1)
+		})
+	}
+}
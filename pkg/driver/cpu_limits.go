@@ -0,0 +1,84 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// checkConsumerCPULimits fails, when the cpu limits of the pinned containers must equal their requests,
+// if a container of the pods the claim is reserved for consumes it with a cpu limit other than its cpu
+// request: the CFS quota of a lower limit throttles the container on its exclusive CPUs, and the pod
+// loses its Guaranteed QoS class. It reports every such container.
+func (cp *CPUDriver) checkConsumerCPULimits(ctx context.Context, claim *resourceapi.ResourceClaim) error {
+	if !cp.requireCPULimits {
+		return nil
+	}
+	var errs []error
+	for _, consumer := range claim.Status.ReservedFor {
+		if consumer.Resource != "pods" || consumer.APIGroup != "" {
+			continue
+		}
+		pod, err := cp.kubeClient.CoreV1().Pods(claim.Namespace).Get(ctx, consumer.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get pod %s/%s: %w", claim.Namespace, consumer.Name, err)
+		}
+		podClaimNames := podClaimNames(pod, claim.Name)
+		for _, container := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+			if !slices.ContainsFunc(container.Resources.Claims, func(c corev1.ResourceClaim) bool { return slices.Contains(podClaimNames, c.Name) }) {
+				continue
+			}
+			limit, hasLimit := container.Resources.Limits[corev1.ResourceCPU]
+			if !hasLimit {
+				errs = append(errs, fmt.Errorf("container %s of pod %s/%s has no cpu limit, it must equal its cpu request", container.Name, pod.Namespace, pod.Name))
+				continue
+			}
+			// the apiserver defaults a missing request to the limit.
+			request, hasRequest := container.Resources.Requests[corev1.ResourceCPU]
+			if hasRequest && request.Cmp(limit) != 0 {
+				errs = append(errs, fmt.Errorf("container %s of pod %s/%s has a cpu limit of %s, it must equal its cpu request of %s", container.Name, pod.Namespace, pod.Name, limit.String(), request.String()))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("claim %s/%s pins containers whose cpu limits differ from their requests: %w", claim.Namespace, claim.Name, errors.Join(errs...))
+	}
+	return nil
+}
+
+// podClaimNames returns the names, in the pod spec, of the references of the pod to the claim.
+func podClaimNames(pod *corev1.Pod, claimName string) []string {
+	var names []string
+	for _, podClaim := range pod.Spec.ResourceClaims {
+		if podClaim.ResourceClaimName != nil && *podClaim.ResourceClaimName == claimName {
+			names = append(names, podClaim.Name)
+		}
+	}
+	for _, status := range pod.Status.ResourceClaimStatuses {
+		if status.ResourceClaimName != nil && *status.ResourceClaimName == claimName {
+			names = append(names, status.Name)
+		}
+	}
+	return names
+}
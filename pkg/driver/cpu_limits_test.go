@@ -0,0 +1,99 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestCheckConsumerCPULimits(t *testing.T) {
+	cpus := func(quantity string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(quantity)}
+	}
+	container := func(name string, requests, limits corev1.ResourceList) corev1.Container {
+		return corev1.Container{Name: name, Resources: corev1.ResourceRequirements{
+			Requests: requests,
+			Limits:   limits,
+			Claims:   []corev1.ResourceClaim{{Name: "cpus"}},
+		}}
+	}
+	pod := func(name string, containers ...corev1.Container) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+			Spec: corev1.PodSpec{
+				ResourceClaims: []corev1.PodResourceClaim{{Name: "cpus", ResourceClaimTemplateName: ptr.To("cpus")}},
+				Containers:     append(containers, corev1.Container{Name: "sidecar", Resources: corev1.ResourceRequirements{Requests: cpus("100m")}}),
+			},
+			Status: corev1.PodStatus{ResourceClaimStatuses: []corev1.PodResourceClaimStatus{{Name: "cpus", ResourceClaimName: ptr.To("claim-1")}}},
+		}
+	}
+	kubeClient := fake.NewClientset(
+		pod("guaranteed", container("app", cpus("2"), cpus("2"))),
+		pod("limits-only", container("app", nil, cpus("2"))),
+		pod("burstable", container("app", cpus("2"), cpus("4")), container("worker", cpus("2"), nil)),
+	)
+	claimFor := func(podName string) *resourceapi.ResourceClaim {
+		claim := testClaim("claim-1", testDriverName, testNodeName, nil)
+		claim.Namespace = "ns"
+		claim.Status.ReservedFor = []resourceapi.ResourceClaimConsumerReference{{Resource: "pods", Name: podName}}
+		return claim
+	}
+
+	testCases := []struct {
+		name          string
+		require       bool
+		pod           string
+		expectedError []string
+	}{
+		{name: "limits equal to requests", require: true, pod: "guaranteed"},
+		{name: "requests defaulted to the limits", require: true, pod: "limits-only"},
+		{
+			name:    "limits other than requests",
+			require: true,
+			pod:     "burstable",
+			expectedError: []string{
+				"container app of pod ns/burstable has a cpu limit of 4, it must equal its cpu request of 2",
+				"container worker of pod ns/burstable has no cpu limit",
+			},
+		},
+		{name: "not required", pod: "burstable"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cp := &CPUDriver{kubeClient: kubeClient, requireCPULimits: tc.require}
+			err := cp.checkConsumerCPULimits(context.Background(), claimFor(tc.pod))
+			if len(tc.expectedError) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			for _, expected := range tc.expectedError {
+				require.ErrorContains(t, err, expected)
+			}
+			// the containers not consuming the claim, like the sidecar, are not checked.
+			require.NotContains(t, err.Error(), "sidecar")
+		})
+	}
+}
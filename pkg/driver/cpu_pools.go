@@ -0,0 +1,207 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/nodeconfig"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/cpuset"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultCPUPoolName is the pool of the CPUs outside of all the configured pools, for the claims of
+// the tenants without a pool.
+const defaultCPUPoolName = "default"
+
+// CPUPool is a static partition of the CPUs of the node, dedicated to the claims of some tenants.
+type CPUPool struct {
+	Name string
+	CPUs cpuset.CPUSet
+	// Namespaces are the namespaces whose claims get their CPUs from the pool.
+	Namespaces []string
+	// PriorityClassNames are the priority classes of the pods whose claims get their CPUs from the pool.
+	PriorityClassNames []string
+	// DeviceClassNames are the DeviceClasses the claims request the CPUs of the pool through, so one
+	// driver serves several classes, e.g. cpu-exclusive and cpu-batch, each with its own CPUs.
+	DeviceClassNames []string
+}
+
+// cpuPoolsConfig is the format of the CPU pools configuration file.
+type cpuPoolsConfig struct {
+	Pools []nodeconfig.CPUPoolSpec `json:"pools"`
+}
+
+// LoadCPUPools reads the CPU pools of a YAML or JSON configuration file, like:
+//
+//	pools:
+//	- name: telecom
+//	  cpus: 0-31
+//	  namespaces: [ran]
+//	- name: batch
+//	  cpus: 32-63
+//	  priorityClassNames: [batch-low]
+//	  deviceClassNames: [cpu-batch]
+func LoadCPUPools(path string) ([]CPUPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CPU pools configuration: %w", err)
+	}
+	var config cpuPoolsConfig
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse the CPU pools configuration %s: %w", path, err)
+	}
+	return NewCPUPools(config.Pools)
+}
+
+// NewCPUPools validates the CPU pools of a configuration. The pools must not share CPUs, and a
+// namespace, priority class or device class can only be mapped to one pool.
+func NewCPUPools(specs []nodeconfig.CPUPoolSpec) ([]CPUPool, error) {
+	pools := []CPUPool{}
+	poolCPUs := cpuset.New()
+	tenants := map[string]string{}
+	for _, p := range specs {
+		if p.Name == "" || p.Name == defaultCPUPoolName {
+			return nil, fmt.Errorf("CPU pool with CPUs %q has the invalid name %q", p.CPUs, p.Name)
+		}
+		if slices.ContainsFunc(pools, func(pool CPUPool) bool { return pool.Name == p.Name }) {
+			return nil, fmt.Errorf("CPU pool %s is defined twice", p.Name)
+		}
+		cpus, err := cpuset.Parse(p.CPUs)
+		if err != nil {
+			return nil, fmt.Errorf("CPU pool %s: invalid cpus %q: %w", p.Name, p.CPUs, err)
+		}
+		if cpus.IsEmpty() {
+			return nil, fmt.Errorf("CPU pool %s has no CPUs", p.Name)
+		}
+		if shared := poolCPUs.Intersection(cpus); !shared.IsEmpty() {
+			return nil, fmt.Errorf("CPU pool %s shares the CPUs %s with another pool", p.Name, shared.String())
+		}
+		poolCPUs = poolCPUs.Union(cpus)
+		for _, namespace := range p.Namespaces {
+			if other, ok := tenants["namespace "+namespace]; ok {
+				return nil, fmt.Errorf("namespace %s is mapped to the CPU pools %s and %s", namespace, other, p.Name)
+			}
+			tenants["namespace "+namespace] = p.Name
+		}
+		for _, priorityClass := range p.PriorityClassNames {
+			if other, ok := tenants["priority class "+priorityClass]; ok {
+				return nil, fmt.Errorf("priority class %s is mapped to the CPU pools %s and %s", priorityClass, other, p.Name)
+			}
+			tenants["priority class "+priorityClass] = p.Name
+		}
+		for _, deviceClass := range p.DeviceClassNames {
+			if other, ok := tenants["device class "+deviceClass]; ok {
+				return nil, fmt.Errorf("device class %s is mapped to the CPU pools %s and %s", deviceClass, other, p.Name)
+			}
+			tenants["device class "+deviceClass] = p.Name
+		}
+		pools = append(pools, CPUPool{Name: p.Name, CPUs: cpus, Namespaces: p.Namespaces, PriorityClassNames: p.PriorityClassNames, DeviceClassNames: p.DeviceClassNames})
+	}
+	return pools, nil
+}
+
+// claimPoolCPUs returns the name and the CPUs of the pool the claim gets its CPUs from: the pool of
+// the device classes it requests the CPUs through, else the pool of its namespace, else the pool of
+// the priority class of its consumer pods. The claims of the tenants without a pool get the CPUs
+// outside of all the pools, in the default pool.
+func (cp *CPUDriver) claimPoolCPUs(ctx context.Context, claim *resourceapi.ResourceClaim) (string, cpuset.CPUSet, error) {
+	allCPUs := cp.cpuTopology.CPUDetails.CPUs()
+	if len(cp.cpuPools) == 0 {
+		return defaultCPUPoolName, allCPUs, nil
+	}
+	var classPool *CPUPool
+	for _, deviceClass := range claimDeviceClasses(claim, cp.driverName) {
+		for i := range cp.cpuPools {
+			pool := &cp.cpuPools[i]
+			if !slices.Contains(pool.DeviceClassNames, deviceClass) {
+				continue
+			}
+			if classPool != nil && classPool.Name != pool.Name {
+				return "", cpuset.New(), fmt.Errorf("claim %s/%s requests CPUs through the device classes of the CPU pools %s and %s", claim.Namespace, claim.Name, classPool.Name, pool.Name)
+			}
+			classPool = pool
+		}
+	}
+	if classPool != nil {
+		return classPool.Name, classPool.CPUs, nil
+	}
+	for _, pool := range cp.cpuPools {
+		if slices.Contains(pool.Namespaces, claim.Namespace) {
+			return pool.Name, pool.CPUs, nil
+		}
+	}
+	for _, consumer := range claim.Status.ReservedFor {
+		if consumer.Resource != "pods" || consumer.APIGroup != "" {
+			continue
+		}
+		pod, err := cp.kubeClient.CoreV1().Pods(claim.Namespace).Get(ctx, consumer.Name, metav1.GetOptions{})
+		if err != nil {
+			return "", cpuset.New(), fmt.Errorf("failed to get pod %s/%s: %w", claim.Namespace, consumer.Name, err)
+		}
+		for _, pool := range cp.cpuPools {
+			if pod.Spec.PriorityClassName != "" && slices.Contains(pool.PriorityClassNames, pod.Spec.PriorityClassName) {
+				return pool.Name, pool.CPUs, nil
+			}
+		}
+	}
+	defaultCPUs := allCPUs
+	for _, pool := range cp.cpuPools {
+		defaultCPUs = defaultCPUs.Difference(pool.CPUs)
+	}
+	return defaultCPUPoolName, defaultCPUs, nil
+}
+
+// claimDeviceClasses returns the DeviceClasses of the requests of the claim allocated devices of the driver,
+// the ones of the chosen subrequests for the requests with alternatives.
+func claimDeviceClasses(claim *resourceapi.ResourceClaim, driverName string) []string {
+	var deviceClasses []string
+	for _, result := range claim.Status.Allocation.Devices.Results {
+		if result.Driver != driverName {
+			continue
+		}
+		requestName, subRequestName, _ := strings.Cut(result.Request, "/")
+		for _, request := range claim.Spec.Devices.Requests {
+			if request.Name != requestName {
+				continue
+			}
+			if request.Exactly != nil {
+				deviceClasses = append(deviceClasses, request.Exactly.DeviceClassName)
+			}
+			for _, subRequest := range request.FirstAvailable {
+				if subRequest.Name == subRequestName {
+					deviceClasses = append(deviceClasses, subRequest.DeviceClassName)
+				}
+			}
+		}
+	}
+	return deviceClasses
+}
+
+// checkClaimPool fails if the CPUs allocated to the claim by the scheduler are not all in its pool.
+func checkClaimPool(claim *resourceapi.ResourceClaim, poolName string, poolCPUs, cpus cpuset.CPUSet) error {
+	if outside := cpus.Difference(poolCPUs); !outside.IsEmpty() {
+		return fmt.Errorf("claim %s/%s: allocated CPUs %s are outside of its CPU pool %s with CPUs %s; use a CEL selector on dra.cpu/cpuID", claim.Namespace, claim.Name, outside.String(), poolName, poolCPUs.String())
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/cpuset"
+)
+
+func TestLoadCPUPools(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        string
+		expected      []CPUPool
+		expectedError string
+	}{
+		{
+			name: "pools",
+			config: `pools:
+- name: telecom
+  cpus: 0-3
+  namespaces: [ran, core]
+- name: batch
+  cpus: 4-5,7
+  priorityClassNames: [batch-low]
+  deviceClassNames: [cpu-batch]
+`,
+			expected: []CPUPool{
+				{Name: "telecom", CPUs: cpuset.New(0, 1, 2, 3), Namespaces: []string{"ran", "core"}},
+				{Name: "batch", CPUs: cpuset.New(4, 5, 7), PriorityClassNames: []string{"batch-low"}, DeviceClassNames: []string{"cpu-batch"}},
+			},
+		},
+		{
+			name:     "no pools",
+			config:   `pools: []`,
+			expected: []CPUPool{},
+		},
+		{
+			name:          "overlapping pools",
+			config:        `{"pools": [{"name": "a", "cpus": "0-3"}, {"name": "b", "cpus": "3-7"}]}`,
+			expectedError: "shares the CPUs 3",
+		},
+		{
+			name:          "namespace in two pools",
+			config:        `{"pools": [{"name": "a", "cpus": "0-3", "namespaces": ["ran"]}, {"name": "b", "cpus": "4-7", "namespaces": ["ran"]}]}`,
+			expectedError: "namespace ran is mapped to the CPU pools a and b",
+		},
+		{
+			name:          "device class in two pools",
+			config:        `{"pools": [{"name": "a", "cpus": "0-3", "deviceClassNames": ["cpu-batch"]}, {"name": "b", "cpus": "4-7", "deviceClassNames": ["cpu-batch"]}]}`,
+			expectedError: "device class cpu-batch is mapped to the CPU pools a and b",
+		},
+		{
+			name:          "pool defined twice",
+			config:        `{"pools": [{"name": "a", "cpus": "0-3"}, {"name": "a", "cpus": "4-7"}]}`,
+			expectedError: "defined twice",
+		},
+		{
+			name:          "default pool",
+			config:        `{"pools": [{"name": "default", "cpus": "0-3"}]}`,
+			expectedError: "invalid name",
+		},
+		{
+			name:          "invalid cpus",
+			config:        `{"pools": [{"name": "a", "cpus": "0-x"}]}`,
+			expectedError: "invalid cpus",
+		},
+		{
+			name:          "unknown field",
+			config:        `{"pools": [{"name": "a", "cpus": "0-3", "namespace": "ran"}]}`,
+			expectedError: "unknown field",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pools.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.config), 0644))
+			pools, err := LoadCPUPools(path)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, pools)
+		})
+	}
+}
+
+func TestPrepareResourceClaimsCPUPools(t *testing.T) {
+	// NUMA node 0 has the CPUs 0,1,4,5: the telecom pool gets the core 0 (CPUs 0,4), the batch pool
+	// the CPU 1, and the default pool the CPU 5.
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	pools := []CPUPool{
+		{Name: "telecom", CPUs: cpuset.New(0, 2, 4, 6), Namespaces: []string{"ran"}},
+		{Name: "batch", CPUs: cpuset.New(1, 3), PriorityClassNames: []string{"batch-low"}, DeviceClassNames: []string{"cpu-batch"}},
+	}
+	kubeClient := fake.NewClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "jobs", Name: "batch-pod"}, Spec: corev1.PodSpec{PriorityClassName: "batch-low"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "jobs", Name: "web-pod"}},
+	)
+
+	testCases := []struct {
+		name          string
+		deviceMode    string
+		namespace     string
+		pod           string
+		deviceClass   string
+		devices       map[string]int64
+		expectedCPUs  cpuset.CPUSet
+		expectedError string
+	}{
+		{
+			name:         "namespace pool",
+			deviceMode:   CPU_DEVICE_MODE_GROUPED,
+			namespace:    "ran",
+			devices:      map[string]int64{"cpudevnuma000": 2},
+			expectedCPUs: cpuset.New(0, 4),
+		},
+		{
+			name:         "priority class pool",
+			deviceMode:   CPU_DEVICE_MODE_GROUPED,
+			namespace:    "jobs",
+			pod:          "batch-pod",
+			devices:      map[string]int64{"cpudevnuma000": 1},
+			expectedCPUs: cpuset.New(1),
+		},
+		{
+			name:         "device class pool",
+			deviceMode:   CPU_DEVICE_MODE_GROUPED,
+			namespace:    "ran",
+			deviceClass:  "cpu-batch",
+			devices:      map[string]int64{"cpudevnuma000": 1},
+			expectedCPUs: cpuset.New(1),
+		},
+		{
+			name:         "default pool",
+			deviceMode:   CPU_DEVICE_MODE_GROUPED,
+			namespace:    "jobs",
+			pod:          "web-pod",
+			devices:      map[string]int64{"cpudevnuma000": 1},
+			expectedCPUs: cpuset.New(5),
+		},
+		{
+			name:          "pool without capacity",
+			deviceMode:    CPU_DEVICE_MODE_GROUPED,
+			namespace:     "jobs",
+			pod:           "web-pod",
+			devices:       map[string]int64{"cpudevnuma000": 2},
+			expectedError: "not enough",
+		},
+		{
+			name:         "individual claim in its pool",
+			deviceMode:   CPU_DEVICE_MODE_INDIVIDUAL,
+			namespace:    "ran",
+			devices:      map[string]int64{"cpudev004": 1},
+			expectedCPUs: cpuset.New(4),
+		},
+		{
+			name:          "individual claim outside of its pool",
+			deviceMode:    CPU_DEVICE_MODE_INDIVIDUAL,
+			namespace:     "ran",
+			devices:       map[string]int64{"cpudev001": 1},
+			expectedError: "outside of its CPU pool telecom",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cp := &CPUDriver{
+				driverName:             testDriverName,
+				kubeClient:             kubeClient,
+				cpuDeviceMode:          tc.deviceMode,
+				cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+				cpuTopology:            topo,
+				deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0, "cpudevnuma001": 1},
+				deviceNameToCPUID:      map[string]int{"cpudev001": 1, "cpudev004": 4},
+				cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+				cdiMgr:                 newMockCdiMgr(),
+				cpuPools:               pools,
+			}
+			claim := testClaim("claim-1", testDriverName, testNodeName, tc.devices)
+			claim.Namespace = tc.namespace
+			if tc.pod != "" {
+				claim.Status.ReservedFor = []resourceapi.ResourceClaimConsumerReference{{Resource: "pods", Name: tc.pod}}
+			}
+			if tc.deviceClass != "" {
+				claim.Spec.Devices.Requests = []resourceapi.DeviceRequest{{Name: "cpus", Exactly: &resourceapi.ExactDeviceRequest{DeviceClassName: tc.deviceClass}}}
+				for i := range claim.Status.Allocation.Devices.Results {
+					claim.Status.Allocation.Devices.Results[i].Request = "cpus"
+				}
+			}
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, results[claim.UID].Err, tc.expectedError)
+				return
+			}
+			require.NoError(t, results[claim.UID].Err)
+			cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+			require.True(t, ok)
+			require.Equal(t, tc.expectedCPUs.String(), cpus.String())
+		})
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DebugState is a snapshot of the allocator served by the /debug/state endpoint, to troubleshoot stuck allocations.
+type DebugState struct {
+	DeviceMode           string         `json:"deviceMode"`
+	ReservedCPUs         string         `json:"reservedCPUs"`
+	SharedCPUs           string         `json:"sharedCPUs"`
+	AllocatableCPUs      string         `json:"allocatableCPUs"`
+	KubeletExclusiveCPUs string         `json:"kubeletExclusiveCPUs"`
+	DegradedCPUs         map[int]string `json:"degradedCPUs,omitempty"`
+	Claims               []DebugClaim   `json:"claims"`
+	// Containers is the number of containers tracked by the NRI plugin.
+	Containers int              `json:"containers"`
+	Checkpoint DebugCheckpoint  `json:"checkpoint"`
+	Publish    DebugPublication `json:"publish"`
+	// Devices is the number of devices in the device name caches of the last publication.
+	Devices int `json:"devices"`
+}
+
+// DebugClaim is a prepared claim of the DebugState.
+type DebugClaim struct {
+	UID         types.UID `json:"uid"`
+	Namespace   string    `json:"namespace"`
+	Name        string    `json:"name"`
+	CPUs        string    `json:"cpus"`
+	Priority    int32     `json:"priority,omitempty"`
+	PollingCPUs string    `json:"pollingCPUs,omitempty"`
+	// Released is set when the CPUs of the claim were given back to the shared pool while its pods are failing.
+	Released bool `json:"released,omitempty"`
+}
+
+// DebugCheckpoint is the state of the kubelet CPU Manager checkpoint sync of the DebugState.
+type DebugCheckpoint struct {
+	Path  string `json:"path,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// DebugPublication is the state of the ResourceSlice publication of the DebugState.
+type DebugPublication struct {
+	LastPublishTime time.Time `json:"lastPublishTime,omitzero"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// DebugState returns a snapshot of the allocator, its prepared claims and the state of its controllers.
+func (cp *CPUDriver) DebugState() DebugState {
+	state := DebugState{
+		DeviceMode:           cp.cpuDeviceMode,
+		ReservedCPUs:         cp.reservedCPUs.String(),
+		SharedCPUs:           cp.cpuAllocationStore.GetSharedCPUs().String(),
+		AllocatableCPUs:      cp.cpuAllocationStore.GetAllocatableCPUs().String(),
+		KubeletExclusiveCPUs: cp.cpuAllocationStore.GetKubeletExclusiveCPUs().String(),
+		DegradedCPUs:         cp.cpuAllocationStore.GetDegradedCPUs(),
+		Claims:               []DebugClaim{},
+		Containers:           cp.podConfigStore.Len(),
+		Checkpoint:           DebugCheckpoint{Path: cp.kubeletCheckpointPath},
+	}
+
+	for claimUID, info := range cp.cpuAllocationStore.GetResourceClaimInfos() {
+		cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claimUID)
+		if !ok {
+			continue
+		}
+		state.Claims = append(state.Claims, DebugClaim{
+			UID:         claimUID,
+			Namespace:   info.Namespace,
+			Name:        info.Name,
+			CPUs:        cpus.String(),
+			Priority:    info.Priority,
+			PollingCPUs: info.PollingCPUs.String(),
+		})
+	}
+	cp.releasedClaimsMu.Lock()
+	for claimUID, released := range cp.releasedClaims {
+		state.Claims = append(state.Claims, DebugClaim{
+			UID:         claimUID,
+			Namespace:   released.Namespace,
+			Name:        released.Name,
+			CPUs:        released.CPUs.String(),
+			Priority:    released.Priority,
+			PollingCPUs: released.PollingCPUs.String(),
+			Released:    true,
+		})
+	}
+	cp.releasedClaimsMu.Unlock()
+	slices.SortFunc(state.Claims, func(a, b DebugClaim) int {
+		return strings.Compare(string(a.UID), string(b.UID))
+	})
+
+	cp.statusMu.Lock()
+	state.Publish.LastPublishTime = cp.status.lastPublishTime
+	if cp.status.publishErr != nil {
+		state.Publish.Error = cp.status.publishErr.Error()
+	}
+	if cp.status.checkpointErr != nil {
+		state.Checkpoint.Error = cp.status.checkpointErr.Error()
+	}
+	cp.statusMu.Unlock()
+
+	cp.devicesMu.RLock()
+	state.Devices = len(cp.deviceNameToCPUID) + len(cp.deviceNameToSocketID) + len(cp.deviceNameToNUMANodeID)
+	if cp.nodeDevicePublished {
+		state.Devices++
+	}
+	cp.devicesMu.RUnlock()
+	return state
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+func TestDebugState(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	publishTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	cp := &CPUDriver{
+		cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+		reservedCPUs:           cpuset.New(0),
+		cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New(0)),
+		podConfigStore:         store.NewPodConfig(),
+		kubeletCheckpointPath:  "/var/lib/kubelet/cpu_manager_state",
+		deviceNameToSocketID:   map[string]int{"cpudevsocket000": 0, "cpudevsocket001": 1},
+		deviceNameToCPUID:      map[string]int{},
+		deviceNameToNUMANodeID: map[string]int{},
+		releasedClaims: map[types.UID]store.ClaimAllocation{
+			"claim-b": {ClaimUID: "claim-b", ClaimInfo: store.ClaimInfo{Namespace: "ns", Name: "b"}, CPUs: cpuset.New(3)},
+		},
+		status: driverStatus{lastPublishTime: publishTime, checkpointErr: errors.New("checkpoint is corrupted")},
+	}
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-a", cpuset.New(1, 2))
+	cp.cpuAllocationStore.SetResourceClaimInfo("claim-a", store.ClaimInfo{Namespace: "ns", Name: "a", Priority: 10, PollingCPUs: cpuset.New(2)})
+	cp.cpuAllocationStore.SetKubeletExclusiveCPUs(klog.Background(), cpuset.New(7))
+	cp.cpuAllocationStore.SetDegradedCPUs(klog.Background(), map[int]string{6: degradedReasonThermalThrottling})
+	cp.podConfigStore.SetContainerState("pod", store.NewContainerState("ctr", "ctr-id"))
+
+	expected := DebugState{
+		DeviceMode:           CPU_DEVICE_MODE_GROUPED,
+		ReservedCPUs:         "0",
+		SharedCPUs:           "3-5",
+		AllocatableCPUs:      "1-5",
+		KubeletExclusiveCPUs: "7",
+		DegradedCPUs:         map[int]string{6: degradedReasonThermalThrottling},
+		Claims: []DebugClaim{
+			{UID: "claim-a", Namespace: "ns", Name: "a", CPUs: "1-2", Priority: 10, PollingCPUs: "2"},
+			{UID: "claim-b", Namespace: "ns", Name: "b", CPUs: "3", Released: true},
+		},
+		Containers: 1,
+		Checkpoint: DebugCheckpoint{Path: "/var/lib/kubelet/cpu_manager_state", Error: "checkpoint is corrupted"},
+		Publish:    DebugPublication{LastPublishTime: publishTime},
+		Devices:    2,
+	}
+	state := cp.DebugState()
+	require.Equal(t, expected, state)
+
+	// the state is served as JSON, which must round trip.
+	data, err := json.Marshal(state)
+	require.NoError(t, err)
+	var decoded DebugState
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, expected, decoded)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+// numaNodeAttributes are the device attribute names, without domain, drivers commonly use to
+// publish the NUMA node of a device, e.g. dra.net/numaNode or dra.cpu/numaNodeID.
+var numaNodeAttributes = []string{"numaNode", "numaNodeID", "numa"}
+
+// alignedNUMANodes returns the NUMA nodes of the peer devices the claim configuration asks to align
+// with, resolved from the other claims of the pods the claim is reserved for. It returns an empty set
+// if the claim doesn't ask for alignment. Asking for alignment with devices that can't be found, or
+// that don't publish their NUMA node, is an error: silently spreading the CPUs would defeat its purpose.
+func (cp *CPUDriver) alignedNUMANodes(ctx context.Context, claim *resourceapi.ResourceClaim, config *ClaimConfig) (cpuset.CPUSet, error) {
+	if config.AlignWithClaim == "" && config.AlignWithDriver == "" {
+		return cpuset.New(), nil
+	}
+	numaNodes := cpuset.New()
+	found := false
+	for _, consumer := range claim.Status.ReservedFor {
+		if consumer.Resource != "pods" || consumer.APIGroup != "" {
+			continue
+		}
+		pod, err := cp.kubeClient.CoreV1().Pods(claim.Namespace).Get(ctx, consumer.Name, metav1.GetOptions{})
+		if err != nil {
+			return cpuset.New(), fmt.Errorf("failed to get pod %s/%s: %w", claim.Namespace, consumer.Name, err)
+		}
+		for _, status := range pod.Status.ResourceClaimStatuses {
+			if status.ResourceClaimName == nil || *status.ResourceClaimName == claim.Name {
+				continue
+			}
+			if config.AlignWithClaim != "" && status.Name != config.AlignWithClaim {
+				continue
+			}
+			peer, err := cp.kubeClient.ResourceV1().ResourceClaims(claim.Namespace).Get(ctx, *status.ResourceClaimName, metav1.GetOptions{})
+			if err != nil {
+				return cpuset.New(), fmt.Errorf("failed to get claim %s/%s of pod %s: %w", claim.Namespace, *status.ResourceClaimName, pod.Name, err)
+			}
+			if peer.Status.Allocation == nil {
+				continue
+			}
+			for _, result := range peer.Status.Allocation.Devices.Results {
+				if result.Driver == cp.driverName {
+					continue
+				}
+				if config.AlignWithDriver != "" && result.Driver != config.AlignWithDriver {
+					continue
+				}
+				numaNode, err := cp.deviceNUMANode(ctx, result)
+				if err != nil {
+					return cpuset.New(), err
+				}
+				klog.FromContext(ctx).Info("Claim aligned with a device of another claim", "peerClaim", klog.KObj(peer), "driver", result.Driver, "pool", result.Pool, "device", result.Device, "numaNode", numaNode)
+				numaNodes = numaNodes.Union(cpuset.New(numaNode))
+				found = true
+			}
+		}
+	}
+	if !found {
+		return cpuset.New(), fmt.Errorf("claim %s/%s asks to align with claim %q of driver %q, but no such device is allocated to its pods", claim.Namespace, claim.Name, config.AlignWithClaim, config.AlignWithDriver)
+	}
+	return numaNodes, nil
+}
+
+// deviceNUMANode returns the NUMA node the driver of an allocated device published for it on this node.
+func (cp *CPUDriver) deviceNUMANode(ctx context.Context, result resourceapi.DeviceRequestAllocationResult) (int, error) {
+	selector := fields.Set{
+		"spec.nodeName": cp.nodeName,
+		"spec.driver":   result.Driver,
+	}.AsSelector().String()
+	slices, err := cp.kubeClient.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list ResourceSlices of driver %s: %w", result.Driver, err)
+	}
+	for _, slice := range slices.Items {
+		if slice.Spec.Driver != result.Driver || slice.Spec.Pool.Name != result.Pool {
+			continue
+		}
+		for _, device := range slice.Spec.Devices {
+			if device.Name != result.Device {
+				continue
+			}
+			for _, numaNodeAttribute := range numaNodeAttributes {
+				for name, attribute := range device.Attributes {
+					id := string(name)
+					if i := strings.LastIndex(id, "/"); i >= 0 {
+						id = id[i+1:]
+					}
+					if id == numaNodeAttribute && attribute.IntValue != nil {
+						return int(*attribute.IntValue), nil
+					}
+				}
+			}
+			return 0, fmt.Errorf("device %s/%s/%s has no NUMA node attribute", result.Driver, result.Pool, result.Device)
+		}
+	}
+	return 0, fmt.Errorf("device %s/%s/%s not found in the ResourceSlices of node %s", result.Driver, result.Pool, result.Device, cp.nodeName)
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/cpuset"
+	"k8s.io/utils/ptr"
+)
+
+func TestPrepareResourceClaimsAlignWithDevice(t *testing.T) {
+	peerClaim := func(name, driver, device string) *resourceapi.ResourceClaim {
+		return &resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+			Status: resourceapi.ResourceClaimStatus{
+				Allocation: &resourceapi.AllocationResult{
+					Devices: resourceapi.DeviceAllocationResult{
+						Results: []resourceapi.DeviceRequestAllocationResult{{Driver: driver, Pool: testNodeName, Device: device}},
+					},
+				},
+			},
+		}
+	}
+	peerSlice := func(driver string, devices ...resourceapi.Device) *resourceapi.ResourceSlice {
+		return &resourceapi.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: testNodeName + "-" + driver},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   driver,
+				NodeName: ptr.To(testNodeName),
+				Pool:     resourceapi.ResourcePool{Name: testNodeName},
+				Devices:  devices,
+			},
+		}
+	}
+	numaAttribute := func(name resourceapi.QualifiedName, numaNode int64) map[resourceapi.QualifiedName]resourceapi.DeviceAttribute {
+		return map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{name: {IntValue: &numaNode}}
+	}
+
+	// NUMA node 0 has the cores (0,4) and (1,5), NUMA node 1 has the cores (2,6) and (3,7).
+	testCases := []struct {
+		name          string
+		opaqueConfig  []string
+		expectedError bool
+		expectedCPUs  cpuset.CPUSet
+	}{
+		{
+			name:         "no alignment",
+			expectedCPUs: cpuset.New(0, 4),
+		},
+		{
+			name:         "align with driver",
+			opaqueConfig: []string{`{"alignWithDriver": "gpu.nvidia.com"}`},
+			expectedCPUs: cpuset.New(2, 6),
+		},
+		{
+			name:         "align with claim",
+			opaqueConfig: []string{`{"alignWithClaim": "nic"}`},
+			expectedCPUs: cpuset.New(0, 4),
+		},
+		{
+			name:         "align with claim and driver",
+			opaqueConfig: []string{`{"alignWithClaim": "gpu", "alignWithDriver": "gpu.nvidia.com"}`},
+			expectedCPUs: cpuset.New(2, 6),
+		},
+		{
+			name:          "no device of the driver",
+			opaqueConfig:  []string{`{"alignWithDriver": "fpga.example.com"}`},
+			expectedError: true,
+		},
+		{
+			name:          "no such claim in the pod",
+			opaqueConfig:  []string{`{"alignWithClaim": "storage"}`},
+			expectedError: true,
+		},
+		{
+			name:          "device without NUMA node attribute",
+			opaqueConfig:  []string{`{"alignWithClaim": "accel"}`},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeClient := fake.NewClientset(
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod-1", UID: "pod-uid-1"},
+					Status: corev1.PodStatus{
+						ResourceClaimStatuses: []corev1.PodResourceClaimStatus{
+							{Name: "cpu", ResourceClaimName: ptr.To("claim-1")},
+							{Name: "gpu", ResourceClaimName: ptr.To("pod-1-gpu")},
+							{Name: "nic", ResourceClaimName: ptr.To("pod-1-nic")},
+							{Name: "accel", ResourceClaimName: ptr.To("pod-1-accel")},
+						},
+					},
+				},
+				peerClaim("pod-1-gpu", "gpu.nvidia.com", "gpu-1"),
+				peerClaim("pod-1-nic", "dra.net", "eth0"),
+				peerClaim("pod-1-accel", "accel.example.com", "accel-0"),
+				peerSlice("gpu.nvidia.com",
+					resourceapi.Device{Name: "gpu-0", Attributes: numaAttribute("numa", 0)},
+					resourceapi.Device{Name: "gpu-1", Attributes: numaAttribute("numa", 1)},
+				),
+				peerSlice("dra.net", resourceapi.Device{Name: "eth0", Attributes: numaAttribute("dra.net/numaNode", 0)}),
+				peerSlice("accel.example.com", resourceapi.Device{Name: "accel-0"}),
+			)
+			mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_SNC2_8CPUs_HT}
+			topo, _ := mockProvider.GetCPUTopology()
+			cp := &CPUDriver{
+				driverName:           testDriverName,
+				nodeName:             testNodeName,
+				kubeClient:           kubeClient,
+				cpuDeviceMode:        CPU_DEVICE_MODE_GROUPED,
+				cpuDeviceGroupBy:     GROUP_BY_SOCKET,
+				cpuTopology:          topo,
+				deviceNameToSocketID: map[string]int{"cpudevsocket000": 0},
+				cpuAllocationStore:   store.NewCPUAllocation(topo, cpuset.New()),
+				podConfigStore:       store.NewPodConfig(),
+				claimTracker:         store.NewClaimTracker(),
+				cdiMgr:               newMockCdiMgr(),
+			}
+
+			claim := withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevsocket000": 2}), testDriverName, tc.opaqueConfig...)
+			claim.Namespace = "ns"
+			claim.Status.ReservedFor = []resourceapi.ResourceClaimConsumerReference{{Resource: "pods", Name: "pod-1", UID: "pod-uid-1"}}
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			if tc.expectedError {
+				require.Error(t, results[claim.UID].Err)
+				_, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+				require.False(t, ok)
+				return
+			}
+			require.NoError(t, results[claim.UID].Err)
+			cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+			require.True(t, ok)
+			require.True(t, tc.expectedCPUs.Equals(cpus), "expected %s got %s", tc.expectedCPUs.String(), cpus.String())
+		})
+	}
+}
+
+func TestPrepareResourceClaimsAlignWithDeviceNUMANodeGrouped(t *testing.T) {
+	numaNode := int64(1)
+	kubeClient := fake.NewClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod-1"},
+			Status: corev1.PodStatus{
+				ResourceClaimStatuses: []corev1.PodResourceClaimStatus{{Name: "gpu", ResourceClaimName: ptr.To("pod-1-gpu")}},
+			},
+		},
+		&resourceapi.ResourceClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod-1-gpu"},
+			Status: resourceapi.ResourceClaimStatus{
+				Allocation: &resourceapi.AllocationResult{
+					Devices: resourceapi.DeviceAllocationResult{
+						Results: []resourceapi.DeviceRequestAllocationResult{{Driver: "gpu.nvidia.com", Pool: testNodeName, Device: "gpu-0"}},
+					},
+				},
+			},
+		},
+		&resourceapi.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu-slice"},
+			Spec: resourceapi.ResourceSliceSpec{
+				Driver:   "gpu.nvidia.com",
+				NodeName: ptr.To(testNodeName),
+				Pool:     resourceapi.ResourcePool{Name: testNodeName},
+				Devices: []resourceapi.Device{{
+					Name:       "gpu-0",
+					Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"numaNode": {IntValue: &numaNode}},
+				}},
+			},
+		},
+	)
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_SNC2_8CPUs_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cp := &CPUDriver{
+		driverName:             testDriverName,
+		nodeName:               testNodeName,
+		kubeClient:             kubeClient,
+		cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+		cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+		cpuTopology:            topo,
+		deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0, "cpudevnuma001": 1},
+		cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+		podConfigStore:         store.NewPodConfig(),
+		claimTracker:           store.NewClaimTracker(),
+		cdiMgr:                 newMockCdiMgr(),
+	}
+	newClaim := func(uid, device string) *resourceapi.ResourceClaim {
+		claim := withOpaqueConfig(testClaim(types.UID(uid), testDriverName, testNodeName, map[string]int64{device: 2}), testDriverName, `{"alignWithDriver": "gpu.nvidia.com"}`)
+		claim.Namespace = "ns"
+		claim.Status.ReservedFor = []resourceapi.ResourceClaimConsumerReference{{Resource: "pods", Name: "pod-1"}}
+		return claim
+	}
+
+	// the scheduler picked the NUMA node of the GPU
+	aligned := newClaim("claim-aligned", "cpudevnuma001")
+	// the scheduler picked another NUMA node, e.g. without a matchAttribute constraint
+	misaligned := newClaim("claim-misaligned", "cpudevnuma000")
+	results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{aligned, misaligned})
+	require.NoError(t, err)
+	require.NoError(t, results[aligned.UID].Err)
+	require.Error(t, results[misaligned.UID].Err)
+}
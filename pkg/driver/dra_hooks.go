@@ -17,13 +17,17 @@ limitations under the License.
 package driver
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"sort"
+	"time"
 
 	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
-	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
 	resourceapi "k8s.io/api/resource/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
@@ -48,15 +52,20 @@ const (
 
 	cpuDeviceSocketGroupedPrefix = "cpudevsocket"
 	cpuDeviceNUMAGroupedPrefix   = "cpudevnuma"
+	// cpuDeviceNodeGroupedName is the name of the single device of the node when grouping by node.
+	cpuDeviceNodeGroupedName = "cpudevnode"
 )
 
 // createGroupedCPUDeviceSlices creates Device objects based on the CPU topology, grouped by a specific criteria.
-func (cp *CPUDriver) createGroupedCPUDeviceSlices() [][]resourceapi.Device {
-	klog.Info("Creating grouped CPU devices", "groupBy", cp.cpuDeviceGroupBy)
+func (cp *CPUDriver) createGroupedCPUDeviceSlices(logger klog.Logger) [][]resourceapi.Device {
+	logger.V(2).Info("Creating grouped CPU devices", "groupBy", cp.cpuDeviceGroupBy)
 	var devices []resourceapi.Device
 
 	topo := cp.cpuTopology
 	smtEnabled := topo.SMTEnabled
+	numaNodesPerSocket := int64(topo.NUMANodesPerSocket())
+	kubeletExclusiveCPUs := cp.cpuAllocationStore.GetKubeletExclusiveCPUs()
+	degradedCPUs := cpuset.New(slices.Collect(maps.Keys(cp.cpuAllocationStore.GetDegradedCPUs()))...)
 
 	switch cp.cpuDeviceGroupBy {
 	case GROUP_BY_SOCKET:
@@ -65,7 +74,7 @@ func (cp *CPUDriver) createGroupedCPUDeviceSlices() [][]resourceapi.Device {
 			socketID := int64(socketIDInt)
 			deviceName := fmt.Sprintf("%s%03d", cpuDeviceSocketGroupedPrefix, socketIDInt)
 			socketCPUSet := topo.CPUDetails.CPUsInSockets(socketIDInt)
-			allocatableCPUs := socketCPUSet.Difference(cp.reservedCPUs)
+			allocatableCPUs := socketCPUSet.Difference(cp.capacityExcludedCPUs()).Difference(kubeletExclusiveCPUs).Difference(degradedCPUs)
 			availableCPUsInSocket := int64(allocatableCPUs.Size())
 
 			if allocatableCPUs.Size() == 0 {
@@ -81,13 +90,16 @@ func (cp *CPUDriver) createGroupedCPUDeviceSlices() [][]resourceapi.Device {
 			devices = append(devices, resourceapi.Device{
 				Name: deviceName,
 				Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
-					"dra.cpu/socketID":   {IntValue: &socketID},
-					"dra.cpu/numCPUs":    {IntValue: &availableCPUsInSocket},
-					"dra.cpu/smtEnabled": {BoolValue: &smtEnabled},
+					"dra.cpu/socketID":           {IntValue: &socketID},
+					"dra.cpu/numCPUs":            {IntValue: &availableCPUsInSocket},
+					"dra.cpu/smtEnabled":         {BoolValue: &smtEnabled},
+					"dra.cpu/numaNodesPerSocket": {IntValue: &numaNodesPerSocket},
 				},
 				Capacity:                 deviceCapacity,
 				AllowMultipleAllocations: ptr.To(true),
 			})
+			cp.addRDTAttributes(&devices[len(devices)-1])
+			cp.addNFDAttributes(logger, &devices[len(devices)-1])
 		}
 	case GROUP_BY_NUMA_NODE:
 		numaNodeIDs := topo.CPUDetails.NUMANodes().List()
@@ -95,7 +107,7 @@ func (cp *CPUDriver) createGroupedCPUDeviceSlices() [][]resourceapi.Device {
 			numaID := int64(numaIDInt)
 			deviceName := fmt.Sprintf("%s%03d", cpuDeviceNUMAGroupedPrefix, numaIDInt)
 			numaNodeCPUSet := topo.CPUDetails.CPUsInNUMANodes(numaIDInt)
-			allocatableCPUs := numaNodeCPUSet.Difference(cp.reservedCPUs)
+			allocatableCPUs := numaNodeCPUSet.Difference(cp.capacityExcludedCPUs()).Difference(kubeletExclusiveCPUs).Difference(degradedCPUs)
 			availableCPUsInNUMANode := int64(allocatableCPUs.Size())
 
 			if allocatableCPUs.Size() == 0 {
@@ -115,17 +127,46 @@ func (cp *CPUDriver) createGroupedCPUDeviceSlices() [][]resourceapi.Device {
 			devices = append(devices, resourceapi.Device{
 				Name: deviceName,
 				Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
-					"dra.cpu/numaNodeID": {IntValue: &numaID},
-					"dra.cpu/socketID":   {IntValue: &socketID},
-					"dra.cpu/numCPUs":    {IntValue: &availableCPUsInNUMANode},
-					"dra.cpu/smtEnabled": {BoolValue: &smtEnabled},
+					"dra.cpu/numaNodeID":         {IntValue: &numaID},
+					"dra.cpu/socketID":           {IntValue: &socketID},
+					"dra.cpu/numCPUs":            {IntValue: &availableCPUsInNUMANode},
+					"dra.cpu/smtEnabled":         {BoolValue: &smtEnabled},
+					"dra.cpu/numaNodesPerSocket": {IntValue: &numaNodesPerSocket},
 					// TODO(pravk03): Remove. Hack to align with NIC (DRANet). We need some standard attribute to align other resources with CPU.
 					"dra.net/numaNode": {IntValue: &numaID},
 				},
 				Capacity:                 deviceCapacity,
 				AllowMultipleAllocations: ptr.To(true),
 			})
+			cp.addRDTAttributes(&devices[len(devices)-1])
+			cp.addNFDAttributes(logger, &devices[len(devices)-1])
 		}
+	case GROUP_BY_NODE:
+		allocatableCPUs := topo.CPUDetails.CPUs().Difference(cp.capacityExcludedCPUs()).Difference(kubeletExclusiveCPUs).Difference(degradedCPUs)
+		availableCPUsInNode := int64(allocatableCPUs.Size())
+		cp.nodeDevicePublished = allocatableCPUs.Size() > 0
+		if !cp.nodeDevicePublished {
+			break
+		}
+		numSockets := int64(topo.NumSockets)
+		numNUMANodes := int64(topo.NumNUMANodes)
+
+		devices = append(devices, resourceapi.Device{
+			Name: cpuDeviceNodeGroupedName,
+			Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+				"dra.cpu/numCPUs":            {IntValue: &availableCPUsInNode},
+				"dra.cpu/numSockets":         {IntValue: &numSockets},
+				"dra.cpu/numNUMANodes":       {IntValue: &numNUMANodes},
+				"dra.cpu/smtEnabled":         {BoolValue: &smtEnabled},
+				"dra.cpu/numaNodesPerSocket": {IntValue: &numaNodesPerSocket},
+			},
+			Capacity: map[resourceapi.QualifiedName]resourceapi.DeviceCapacity{
+				cpuResourceQualifiedName: {Value: *resource.NewQuantity(availableCPUsInNode, resource.DecimalSI)},
+			},
+			AllowMultipleAllocations: ptr.To(true),
+		})
+		cp.addRDTAttributes(&devices[len(devices)-1])
+		cp.addNFDAttributes(logger, &devices[len(devices)-1])
 	}
 
 	if len(devices) == 0 {
@@ -138,7 +179,7 @@ func (cp *CPUDriver) createGroupedCPUDeviceSlices() [][]resourceapi.Device {
 // It groups CPUs by physical core to assign consecutive device IDs to hyperthreads.
 // This allows the DRA scheduler, which requests resources in contiguous blocks,
 // to co-locate workloads on hyperthreads of the same core.
-func (cp *CPUDriver) createCPUDeviceSlices() [][]resourceapi.Device {
+func (cp *CPUDriver) createCPUDeviceSlices(logger klog.Logger) [][]resourceapi.Device {
 	reservedCPUs := make(map[int]bool)
 	for _, cpuID := range cp.reservedCPUs.List() {
 		reservedCPUs[cpuID] = true
@@ -182,12 +223,23 @@ func (cp *CPUDriver) createCPUDeviceSlices() [][]resourceapi.Device {
 		return coreGroups[i][0].CpuID < coreGroups[j][0].CpuID
 	})
 
+	numaNodesPerSocket := int64(topo.NUMANodesPerSocket())
+	kubeletExclusiveCPUs := cp.cpuAllocationStore.GetKubeletExclusiveCPUs()
+	kubeletSharedCPUs := cp.cpuAllocationStore.GetKubeletSharedCPUs()
+	degradedCPUs := cp.cpuAllocationStore.GetDegradedCPUs()
 	devId := 0
 	var allDevices []resourceapi.Device
 	for _, group := range coreGroups {
 		for _, cpu := range group {
+			if kubeletExclusiveCPUs.Contains(cpu.CpuID) {
+				// Skip the device, but keep its name reserved so device names stay stable
+				// while the kubelet CPU Manager pins and releases CPUs.
+				devId++
+				continue
+			}
 			numaNode := int64(cpu.NUMANodeID)
 			cacheL3ID := int64(cpu.UncoreCacheID)
+			clusterID := int64(cpu.ClusterID)
 			socketID := int64(cpu.SocketID)
 			coreID := int64(cpu.CoreID)
 			cpuID := int64(cpu.CpuID)
@@ -198,17 +250,49 @@ func (cp *CPUDriver) createCPUDeviceSlices() [][]resourceapi.Device {
 			cpuDevice := resourceapi.Device{
 				Name: deviceName,
 				Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
-					"dra.cpu/numaNodeID": {IntValue: &numaNode},
-					"dra.cpu/cacheL3ID":  {IntValue: &cacheL3ID},
-					"dra.cpu/coreType":   {StringValue: &coreType},
-					"dra.cpu/socketID":   {IntValue: &socketID},
-					"dra.cpu/coreID":     {IntValue: &coreID},
-					"dra.cpu/cpuID":      {IntValue: &cpuID},
+					"dra.cpu/numaNodeID":         {IntValue: &numaNode},
+					"dra.cpu/cacheL3ID":          {IntValue: &cacheL3ID},
+					"dra.cpu/clusterID":          {IntValue: &clusterID},
+					"dra.cpu/coreType":           {StringValue: &coreType},
+					"dra.cpu/socketID":           {IntValue: &socketID},
+					"dra.cpu/coreID":             {IntValue: &coreID},
+					"dra.cpu/cpuID":              {IntValue: &cpuID},
+					"dra.cpu/numaNodesPerSocket": {IntValue: &numaNodesPerSocket},
 					// TODO(pravk03): Remove. Hack to align with NIC (DRANet). We need some standard attribute to align other resources with CPU.
 					"dra.net/numaNode": {IntValue: &numaNode},
 				},
 				Capacity: make(map[resourceapi.QualifiedName]resourceapi.DeviceCapacity),
 			}
+			if cp.kubeletCheckpointPath != "" {
+				// tells the CPUs the kubelet also runs its unpinned containers on from the ones carved out for the claims.
+				kubeletShared := kubeletSharedCPUs.Contains(cpu.CpuID)
+				cpuDevice.Attributes["dra.cpu/kubeletShared"] = resourceapi.DeviceAttribute{BoolValue: &kubeletShared}
+			}
+			if cpu.Capacity > 0 {
+				capacity := int64(cpu.Capacity)
+				cpuDevice.Attributes["dra.cpu/capacity"] = resourceapi.DeviceAttribute{IntValue: &capacity}
+			}
+			if cpu.BaseFrequencyKHz > 0 {
+				baseFrequency := int64(cpu.BaseFrequencyKHz / 1000)
+				cpuDevice.Attributes["dra.cpu/baseFrequencyMHz"] = resourceapi.DeviceAttribute{IntValue: &baseFrequency}
+			}
+			if cpu.MaxFrequencyKHz > 0 {
+				maxFrequency := int64(cpu.MaxFrequencyKHz / 1000)
+				cpuDevice.Attributes["dra.cpu/maxFrequencyMHz"] = resourceapi.DeviceAttribute{IntValue: &maxFrequency}
+			}
+			if cpu.PerformanceRank > 0 {
+				performanceRank := int64(cpu.PerformanceRank)
+				cpuDevice.Attributes["dra.cpu/performanceRank"] = resourceapi.DeviceAttribute{IntValue: &performanceRank}
+			}
+			cp.addRDTAttributes(&cpuDevice)
+			cp.addNFDAttributes(logger, &cpuDevice)
+			if reason, ok := degradedCPUs[cpu.CpuID]; ok {
+				cpuDevice.Taints = []resourceapi.DeviceTaint{{Key: degradedCPUTaintKey, Value: reason, Effect: resourceapi.DeviceTaintEffectNoSchedule}}
+			}
+			if cp.housekeepingCPUs.Contains(cpu.CpuID) {
+				// only the claims tolerating the taint get the housekeeping CPUs.
+				cpuDevice.Taints = append(cpuDevice.Taints, resourceapi.DeviceTaint{Key: housekeepingCPUTaintKey, Effect: resourceapi.DeviceTaintEffectNoSchedule})
+			}
 			allDevices = append(allDevices, cpuDevice)
 		}
 	}
@@ -223,17 +307,20 @@ func (cp *CPUDriver) createCPUDeviceSlices() [][]resourceapi.Device {
 
 // PublishResources publishes ResourceSlice for CPU resources.
 func (cp *CPUDriver) PublishResources(ctx context.Context) {
-	klog.Infof("Publishing resources")
+	logger := klog.FromContext(ctx)
+	logger.V(2).Info("Publishing resources")
 
 	var deviceChunks [][]resourceapi.Device
+	cp.devicesMu.Lock()
 	if cp.cpuDeviceMode == CPU_DEVICE_MODE_GROUPED {
-		deviceChunks = cp.createGroupedCPUDeviceSlices()
+		deviceChunks = cp.createGroupedCPUDeviceSlices(logger)
 	} else {
-		deviceChunks = cp.createCPUDeviceSlices()
+		deviceChunks = cp.createCPUDeviceSlices(logger)
 	}
+	cp.devicesMu.Unlock()
 
 	if deviceChunks == nil {
-		klog.Infof("No devices to publish or error occurred.")
+		logger.Info("No devices to publish")
 		return
 	}
 
@@ -249,29 +336,56 @@ func (cp *CPUDriver) PublishResources(ctx context.Context) {
 		},
 	}
 
-	err := cp.draPlugin.PublishResources(ctx, resources)
-	if err != nil {
-		klog.Errorf("error publishing resources: %v", err)
+	// the slices are written in the background, their writes record the publish status, see
+	// resourceSliceWriteObserver. Only a plugin not set up to publish fails here.
+	if err := cp.draPlugin.PublishResources(ctx, resources); err != nil {
+		cp.recordPublish(time.Now(), err)
+		logger.Error(err, "Failed to publish resources")
 	}
 }
 
 // PrepareResourceClaims is called by the kubelet to prepare a resource claim.
 func (cp *CPUDriver) PrepareResourceClaims(ctx context.Context, claims []*resourceapi.ResourceClaim) (map[types.UID]kubeletplugin.PrepareResult, error) {
-	klog.Infof("PrepareResourceClaims is called: number of claims: %d", len(claims))
+	klog.FromContext(ctx).V(2).Info("PrepareResourceClaims called", "claims", len(claims))
+	cp.chaos.delay(ctx, "PrepareResourceClaims")
 
 	result := make(map[types.UID]kubeletplugin.PrepareResult)
 
 	if len(claims) == 0 {
 		return result, nil
 	}
+	cp.settingsMu.RLock()
+	defer cp.settingsMu.RUnlock()
 
+	// the claims placed relative to other claims of the pods go last, so those are prepared already.
+	claims = slices.Clone(claims)
+	slices.SortStableFunc(claims, func(a, b *resourceapi.ResourceClaim) int {
+		return cmp.Compare(cp.dependsOnClaim(a), cp.dependsOnClaim(b))
+	})
 	for _, claim := range claims {
+		claimCtx := klog.NewContext(ctx, klog.LoggerWithValues(klog.FromContext(ctx), "claim", klog.KObj(claim), "claimUID", claim.UID))
+		if err := cp.checkSandboxedConsumers(claimCtx, claim); err != nil {
+			result[claim.UID] = kubeletplugin.PrepareResult{Err: err}
+			continue
+		}
+		if err := cp.checkConsumerCPULimits(claimCtx, claim); err != nil {
+			result[claim.UID] = kubeletplugin.PrepareResult{Err: err}
+			continue
+		}
+		if claim.Status.Allocation != nil {
+			if cpus, ok := cp.preparedClaimCPUs(claimCtx, claim); ok {
+				result[claim.UID] = cp.preparedResult(claimCtx, claim, cpus)
+				continue
+			}
+		}
+		cp.releaseRestoredClaim(claim.UID)
 		if cp.cpuDeviceMode == CPU_DEVICE_MODE_GROUPED {
-			klog.Infof("Claim %s/%s is for a grouped resource", claim.Namespace, claim.Name)
-			result[claim.UID] = cp.prepareGroupedResourceClaim(ctx, claim)
+			result[claim.UID] = cp.prepareGroupedResourceClaim(claimCtx, claim)
 		} else {
-			klog.Infof("Claim %s/%s is for an individual resource", claim.Namespace, claim.Name)
-			result[claim.UID] = cp.prepareResourceClaim(ctx, claim)
+			result[claim.UID] = cp.prepareResourceClaim(claimCtx, claim)
+		}
+		if result[claim.UID].Err == nil && len(result[claim.UID].Devices) > 0 {
+			cp.observeClaimPrepared(claim, time.Now())
 		}
 	}
 	return result, nil
@@ -281,8 +395,62 @@ func getCDIDeviceName(uid types.UID) string {
 	return fmt.Sprintf("claim-%s", uid)
 }
 
+// preparedDevices returns the devices of the driver allocated to a claim, all injecting the CDI device of
+// the claim. The kubelet exposes them through the PodResources API, for each container of the requests
+// they are associated with, so monitoring agents and topology aware schedulers see the CPU devices.
+func (cp *CPUDriver) preparedDevices(claim *resourceapi.ResourceClaim, cdiDeviceID string) []kubeletplugin.Device {
+	preparedDevices := []kubeletplugin.Device{}
+	for _, allocResult := range claim.Status.Allocation.Devices.Results {
+		if allocResult.Driver != cp.driverName {
+			continue
+		}
+		preparedDevices = append(preparedDevices, kubeletplugin.Device{
+			PoolName:     allocResult.Pool,
+			DeviceName:   allocResult.Device,
+			CDIDeviceIDs: []string{cdiDeviceID},
+			Requests:     []string{allocResult.Request},
+			ShareID:      allocResult.ShareID,
+		})
+	}
+	return preparedDevices
+}
+
+// newClaimInfo returns the info the allocation store keeps for a claim prepared with the config and the
+// polling CPUs, failing if its pod failure or CPU bandwidth policy is invalid.
+func newClaimInfo(claim *resourceapi.ResourceClaim, config *ClaimConfig, pollingCPUs cpuset.CPUSet, bindMemoryNodes bool) (store.ClaimInfo, error) {
+	releaseOnPodFailure, err := config.releasesOnPodFailure()
+	if err != nil {
+		return store.ClaimInfo{}, fmt.Errorf("claim %s/%s: %w", claim.Namespace, claim.Name, err)
+	}
+	cpuBandwidthFromClaim, err := config.cpuBandwidthFromClaim()
+	if err != nil {
+		return store.ClaimInfo{}, fmt.Errorf("claim %s/%s: %w", claim.Namespace, claim.Name, err)
+	}
+	return store.ClaimInfo{
+		Namespace:             claim.Namespace,
+		Name:                  claim.Name,
+		Priority:              config.Priority,
+		ReservedFor:           claim.Status.ReservedFor,
+		PollingCPUs:           pollingCPUs,
+		ReleaseOnPodFailure:   releaseOnPodFailure,
+		CPUBandwidthFromClaim: cpuBandwidthFromClaim,
+		BindMemoryNodes:       bindMemoryNodes,
+	}, nil
+}
+
+// claimCDIEnv returns the environment variables of the CDI device of a claim: its CPUs and, if it has
+// polling cores, its polling CPUs.
+func claimCDIEnv(claimUID types.UID, cpus, pollingCPUs cpuset.CPUSet) []string {
+	env := []string{fmt.Sprintf("%s_%s=%s", cdiEnvVarPrefix, claimUID, cpus.String())}
+	if !pollingCPUs.IsEmpty() {
+		env = append(env, fmt.Sprintf("%s_%s=%s", cdiPollingEnvVarPrefix, claimUID, pollingCPUs.String()))
+	}
+	return env
+}
+
 func (cp *CPUDriver) prepareGroupedResourceClaim(ctx context.Context, claim *resourceapi.ResourceClaim) kubeletplugin.PrepareResult {
-	klog.Infof("prepareResourceClaim claim:%s/%s", claim.Namespace, claim.Name)
+	logger := klog.FromContext(ctx)
+	logger.V(2).Info("Preparing grouped claim")
 
 	if claim.Status.Allocation == nil {
 		return kubeletplugin.PrepareResult{
@@ -290,6 +458,57 @@ func (cp *CPUDriver) prepareGroupedResourceClaim(ctx context.Context, claim *res
 		}
 	}
 
+	claimConfig, err := cp.getClaimConfig(claim)
+	if err != nil {
+		return kubeletplugin.PrepareResult{Err: err}
+	}
+	strategy, err := cp.placementStrategy(claimConfig)
+	if err != nil {
+		return kubeletplugin.PrepareResult{Err: fmt.Errorf("claim %s/%s: %w", claim.Namespace, claim.Name, err)}
+	}
+	if claimConfig.Contiguous && claimConfig.PollingCores > 0 {
+		return kubeletplugin.PrepareResult{Err: fmt.Errorf("claim %s/%s: contiguous CPUs can't be combined with polling cores", claim.Namespace, claim.Name)}
+	}
+	// the sockets of the node device are only known once its CPUs are picked, see below.
+	if claimConfig.confinesSockets() && cp.cpuDeviceGroupBy != GROUP_BY_NODE {
+		if err := checkSocketConfinement(claim, claimConfig, cp.groupedClaimSockets(claim)); err != nil {
+			return kubeletplugin.PrepareResult{Err: err}
+		}
+	}
+	if err := cp.validateCacheAllocation(claim, claimConfig); err != nil {
+		return kubeletplugin.PrepareResult{Err: err}
+	}
+	// the polling CPUs of the info are only known once the CPUs are assigned, see below.
+	claimInfo, err := newClaimInfo(claim, claimConfig, cpuset.New(), cp.bindsMemoryNodes(strategy))
+	if err != nil {
+		return kubeletplugin.PrepareResult{Err: err}
+	}
+	alignedNUMANodes, err := cp.alignedNUMANodes(ctx, claim, claimConfig)
+	if err != nil {
+		return kubeletplugin.PrepareResult{Err: err}
+	}
+	affinityNUMANodes, hasAffinity, err := cp.affinityNUMANodes(ctx, claim, claimConfig)
+	if err != nil {
+		return kubeletplugin.PrepareResult{Err: err}
+	}
+	pollingNUMANodes := cpuset.New()
+	if claimConfig.PollingCores > 0 {
+		pollingNUMANodes, err = cp.pollingNUMANodes(ctx, claim, claimConfig, alignedNUMANodes)
+		if err != nil {
+			return kubeletplugin.PrepareResult{Err: err}
+		}
+	}
+	pollingCPUs := cpuset.New()
+	isolatedCPUs := cp.smtIsolatedCPUs(claim)
+	poolName, poolCPUs, err := cp.claimPoolCPUs(ctx, claim)
+	if err != nil {
+		return kubeletplugin.PrepareResult{Err: err}
+	}
+	if !claimConfig.UseHousekeepingCPUs {
+		poolCPUs = poolCPUs.Difference(cp.housekeepingCPUs)
+	}
+	logger.V(2).Info("CPU pool of the claim", "pool", poolName, "cpus", poolCPUs.String())
+
 	var cpuAssignment cpuset.CPUSet
 	for _, alloc := range claim.Status.Allocation.Devices.Results {
 		claimCPUCount := int64(0)
@@ -299,73 +518,208 @@ func (cp *CPUDriver) prepareGroupedResourceClaim(ctx context.Context, claim *res
 		if quantity, ok := alloc.ConsumedCapacity[cpuResourceQualifiedName]; ok {
 			count := quantity.Value()
 			claimCPUCount = count
-			klog.Infof("Found request for %d CPUs in device %s for claim %s", count, alloc.Device, claim.Name)
+			logger.V(2).Info("Found CPU request", "device", alloc.Device, "cpus", count)
 		}
 
 		topo := cp.cpuTopology
 
-		var availableCPUsForDevice cpuset.CPUSet
-		if cp.cpuDeviceGroupBy == GROUP_BY_SOCKET {
-			socketID, ok := cp.deviceNameToSocketID[alloc.Device]
-			if !ok {
-				return kubeletplugin.PrepareResult{Err: fmt.Errorf("no valid socket ID found for device %s", alloc.Device)}
+		var deviceCPUs, availableCPUsForDevice, deviceIsolatedCPUs cpuset.CPUSet
+		// confineErr is set when no single NUMA node has enough free CPUs for a claim with a priority,
+		// which can still get them by preempting lower-priority claims.
+		var confineErr error
+		if cp.cpuDeviceGroupBy != GROUP_BY_NUMA_NODE { // socket or node
+			var groupCPUs cpuset.CPUSet
+			if cp.cpuDeviceGroupBy == GROUP_BY_NODE {
+				cp.devicesMu.RLock()
+				ok := cp.nodeDevicePublished && alloc.Device == cpuDeviceNodeGroupedName
+				cp.devicesMu.RUnlock()
+				if !ok {
+					return kubeletplugin.PrepareResult{Err: fmt.Errorf("device %s is not the node device %s", alloc.Device, cpuDeviceNodeGroupedName)}
+				}
+				groupCPUs = topo.CPUDetails.CPUs()
+				if claimConfig.SocketAffinity != nil {
+					// the scheduler can't pick the socket of the node device, so the CPUs are taken from it.
+					groupCPUs = topo.CPUDetails.CPUsInSockets(int(*claimConfig.SocketAffinity))
+				}
+			} else {
+				cp.devicesMu.RLock()
+				socketID, ok := cp.deviceNameToSocketID[alloc.Device]
+				cp.devicesMu.RUnlock()
+				if !ok {
+					return kubeletplugin.PrepareResult{Err: fmt.Errorf("no valid socket ID found for device %s", alloc.Device)}
+				}
+				groupCPUs = topo.CPUDetails.CPUsInSockets(socketID)
+			}
+			deviceIsolatedCPUs = groupCPUs.Intersection(isolatedCPUs)
+			deviceCPUs = groupCPUs.Intersection(poolCPUs).Difference(isolatedCPUs)
+			availableCPUsForDevice = cp.cpuAllocationStore.GetSharedCPUs().Intersection(deviceCPUs)
+			logger.V(2).Info("Device CPUs", "device", alloc.Device, "cpus", groupCPUs.String(), "available", availableCPUsForDevice.String())
+			if alignedNUMANodes.Size() > 0 {
+				alignedCPUs := topo.CPUDetails.CPUsInNUMANodes(alignedNUMANodes.List()...)
+				deviceCPUs = deviceCPUs.Intersection(alignedCPUs)
+				availableCPUsForDevice = availableCPUsForDevice.Intersection(alignedCPUs)
+				logger.V(2).Info("Device CPUs aligned with NUMA nodes", "device", alloc.Device, "numaNodes", alignedNUMANodes.String(), "available", availableCPUsForDevice.String())
+			}
+			if hasAffinity {
+				affinityCPUs := topo.CPUDetails.CPUsInNUMANodes(affinityNUMANodes.List()...)
+				deviceCPUs = deviceCPUs.Intersection(affinityCPUs)
+				availableCPUsForDevice = availableCPUsForDevice.Intersection(affinityCPUs)
+				logger.V(2).Info("Device CPUs restricted by the claim NUMA affinity", "device", alloc.Device, "numaNodes", affinityNUMANodes.String(), "available", availableCPUsForDevice.String())
+			}
+			if cp.confineToNUMANode {
+				confinedCPUs, err := confineToSingleNUMANode(topo, availableCPUsForDevice, int(claimCPUCount))
+				switch {
+				case err != nil && (claimConfig.Priority == 0 || claimConfig.Contiguous):
+					return kubeletplugin.PrepareResult{Err: fmt.Errorf("device %s: %w", alloc.Device, err)}
+				case err != nil:
+					confineErr = fmt.Errorf("device %s: %w", alloc.Device, err)
+					logger.V(2).Info("No single NUMA node has enough free CPUs for the device, preempting lower-priority claims", "device", alloc.Device, "available", availableCPUsForDevice.String())
+				default:
+					availableCPUsForDevice = confinedCPUs
+					logger.V(2).Info("Device CPUs confined to a single NUMA node", "device", alloc.Device, "available", availableCPUsForDevice.String())
+				}
 			}
-			socketCPUs := topo.CPUDetails.CPUsInSockets(socketID)
-			availableCPUsForDevice = cp.cpuAllocationStore.GetSharedCPUs().Intersection(socketCPUs)
-			klog.Infof("Socket %d CPUs:%s available CPUs: %s", socketID, socketCPUs.String(), availableCPUsForDevice.String())
 		} else { // numanode
+			cp.devicesMu.RLock()
 			numaNodeID, ok := cp.deviceNameToNUMANodeID[alloc.Device]
+			cp.devicesMu.RUnlock()
 			if !ok {
 				return kubeletplugin.PrepareResult{Err: fmt.Errorf("no valid NUMA node ID found for device %s", alloc.Device)}
 			}
+			if alignedNUMANodes.Size() > 0 && !alignedNUMANodes.Contains(numaNodeID) {
+				return kubeletplugin.PrepareResult{Err: fmt.Errorf("device %s on NUMA node %d is not aligned with the NUMA nodes %s of the claim %s/%s peer devices", alloc.Device, numaNodeID, alignedNUMANodes.String(), claim.Namespace, claim.Name)}
+			}
+			if hasAffinity && !affinityNUMANodes.Contains(numaNodeID) {
+				return kubeletplugin.PrepareResult{Err: fmt.Errorf("device %s on NUMA node %d breaks the NUMA affinity of claim %s/%s, which allows NUMA nodes %s; use a CEL selector on dra.cpu/numaNodeID", alloc.Device, numaNodeID, claim.Namespace, claim.Name, affinityNUMANodes.String())}
+			}
 			numaCPUs := topo.CPUDetails.CPUsInNUMANodes(numaNodeID)
-			availableCPUsForDevice = cp.cpuAllocationStore.GetSharedCPUs().Intersection(numaCPUs)
-			klog.Infof("NUMA node %d CPUs:%s available CPUs: %s", numaNodeID, numaCPUs.String(), availableCPUsForDevice.String())
+			deviceIsolatedCPUs = numaCPUs.Intersection(isolatedCPUs)
+			deviceCPUs = numaCPUs.Intersection(poolCPUs).Difference(isolatedCPUs)
+			availableCPUsForDevice = cp.cpuAllocationStore.GetSharedCPUs().Intersection(deviceCPUs)
+			logger.V(2).Info("NUMA node CPUs", "numaNode", numaNodeID, "cpus", numaCPUs.String(), "available", availableCPUsForDevice.String())
 		}
 
-		logger := klog.FromContext(ctx)
-		cur, err := cpumanager.TakeByTopologyNUMAPacked(logger, topo, availableCPUsForDevice, int(claimCPUCount), cpumanager.CPUSortingStrategyPacked, true)
-		if err != nil {
-			return kubeletplugin.PrepareResult{Err: err}
+		devicePollingCPUs := cpuset.New()
+		if claimConfig.PollingCores > 0 && pollingCPUs.Size() == 0 {
+			devicePollingCPUs, err = cp.takePollingCores(availableCPUsForDevice, pollingNUMANodes, int(claimConfig.PollingCores))
+			if err != nil {
+				return kubeletplugin.PrepareResult{Err: fmt.Errorf("device %s: %w", alloc.Device, err)}
+			}
+			if devicePollingCPUs.Size() > int(claimCPUCount) {
+				return kubeletplugin.PrepareResult{Err: fmt.Errorf("claim %s/%s: %d polling cores need %d CPUs, more than the %d CPUs requested from device %s", claim.Namespace, claim.Name, claimConfig.PollingCores, devicePollingCPUs.Size(), claimCPUCount, alloc.Device)}
+			}
+			pollingCPUs = devicePollingCPUs
+			deviceCPUs = deviceCPUs.Difference(pollingCPUs)
+			availableCPUsForDevice = availableCPUsForDevice.Difference(pollingCPUs)
+			claimCPUCount -= int64(pollingCPUs.Size())
+			logger.Info("Polling CPUs assigned", "device", alloc.Device, "pollingCPUs", pollingCPUs.String())
+		}
+
+		cur := cpuset.New()
+		if claimConfig.Contiguous && claimCPUCount > 0 {
+			cur, err = takeContiguousCPUs(availableCPUsForDevice, int(claimCPUCount))
+			if err != nil {
+				err = cp.smtIsolationError(claim, err, availableCPUsForDevice, deviceIsolatedCPUs, int(claimCPUCount))
+				cp.recordContiguousFailure(claim, err)
+				return kubeletplugin.PrepareResult{Err: fmt.Errorf("claim %s/%s device %s: %w", claim.Namespace, claim.Name, alloc.Device, err)}
+			}
+		} else if claimCPUCount > 0 {
+			if confineErr != nil {
+				// the preemption confines the CPUs it takes to a single NUMA node, see takeCPUs.
+				cur, err = cpuset.New(), confineErr
+			} else {
+				cur, err = cp.takePlacement(logger, claim.UID, strategy, availableCPUsForDevice, int(claimCPUCount))
+			}
+			if claimConfig.Priority > 0 && (err != nil || !cp.hasFullCores(cur, int(claimCPUCount))) {
+				if preemptedCPUs, ok := cp.preemptLowerPriorityClaims(ctx, claim, claimConfig.Priority, strategy, deviceCPUs, int(claimCPUCount)); ok {
+					cur, err = preemptedCPUs, nil
+				}
+			}
+			if err != nil {
+				return kubeletplugin.PrepareResult{Err: cp.smtIsolationError(claim, err, availableCPUsForDevice, deviceIsolatedCPUs, int(claimCPUCount))}
+			}
 		}
+		cur = cur.Union(devicePollingCPUs)
 		cpuAssignment = cpuAssignment.Union(cur)
-		klog.Infof("CPU assignment for device %s: %s. All cpus assigned:%s", alloc.Device, cur.String(), cpuAssignment.String())
+		logger.V(2).Info("CPUs assigned for device", "device", alloc.Device, "strategy", strategy.Name(), "cpus", cur.String(), "claimCPUs", cpuAssignment.String())
 	}
 
 	if cpuAssignment.Size() == 0 {
-		klog.V(5).Infof("prepareResourceClaim claim:%s/%s has no CPU allocations for this driver", claim.Namespace, claim.Name)
+		logger.V(5).Info("Claim has no CPU allocations for this driver")
 		return kubeletplugin.PrepareResult{}
 	}
+	if cp.cpuDeviceGroupBy == GROUP_BY_NODE && claimConfig.confinesSockets() {
+		if err := checkSocketConfinement(claim, claimConfig, cp.cpuTopology.CPUDetails.KeepOnly(cpuAssignment).Sockets()); err != nil {
+			return kubeletplugin.PrepareResult{Err: err}
+		}
+	}
+	var allocationStatus ClaimAllocationStatus
+	if cp.reportsAllocation(claimConfig) {
+		allocationStatus, err = cp.newClaimAllocationStatus(claimConfig, cpuAssignment, pollingCPUs)
+		if err != nil {
+			return kubeletplugin.PrepareResult{Err: fmt.Errorf("claim %s/%s: %w", claim.Namespace, claim.Name, err)}
+		}
+	}
 
+	if err := cp.applyCacheAllocation(ctx, claim, claimConfig, cpuAssignment); err != nil {
+		return kubeletplugin.PrepareResult{Err: err}
+	}
+	claimInfo.PollingCPUs = pollingCPUs
+	cp.journalPrepare(logger, claim, cpuAssignment, cp.cpuAllocationStore.GetSharedCPUs(), strategy.Name(), poolName)
+	cp.forgetRestoredClaim(claim.UID)
 	cp.cpuAllocationStore.AddResourceClaimAllocation(claim.UID, cpuAssignment)
+	cp.cpuAllocationStore.SetResourceClaimInfo(claim.UID, claimInfo)
+	if cp.placements != nil && !cp.dryRun {
+		cp.placements.forget(claim.UID)
+	}
 
 	deviceName := getCDIDeviceName(claim.UID)
-	envVar := fmt.Sprintf("%s_%s=%s", cdiEnvVarPrefix, claim.UID, cpuAssignment.String())
-	if err := cp.cdiMgr.AddDevice(deviceName, envVar); err != nil {
+	envVar := claimCDIEnv(claim.UID, cpuAssignment, pollingCPUs)
+	if err := cp.cdiMgr.AddDevice(deviceName, envVar...); err != nil {
 		return kubeletplugin.PrepareResult{Err: err}
 	}
 
-	qualifiedName := cdiparser.QualifiedName(cdiVendor, cdiClass, deviceName)
-	klog.Infof("prepareResourceClaim CDIDeviceName:%s envVar:%s qualifiedName:%v", deviceName, envVar, qualifiedName)
-	preparedDevices := []kubeletplugin.Device{}
-	for _, allocResult := range claim.Status.Allocation.Devices.Results {
-		preparedDevice := kubeletplugin.Device{
-			PoolName:     allocResult.Pool,
-			DeviceName:   allocResult.Device,
-			CDIDeviceIDs: []string{qualifiedName},
-			Requests:     []string{allocResult.Request},
+	if cp.reportsAllocation(claimConfig) {
+		if err := cp.recordAllocationStatus(ctx, claim, allocationStatus); err != nil {
+			logger.Error(err, "Failed to record the CPUs in the claim status", "cpus", cpuAssignment.String())
 		}
-		preparedDevices = append(preparedDevices, preparedDevice)
 	}
 
-	klog.Infof("prepareResourceClaim preparedDevices:%+v", preparedDevices)
+	qualifiedName := cdiparser.QualifiedName(cdiVendor, cdiClass, deviceName)
+	logger.Info("Prepared claim", "cdiDevice", qualifiedName, "env", envVar)
+	preparedDevices := cp.preparedDevices(claim, qualifiedName)
+
+	logger.V(5).Info("Prepared devices", "devices", preparedDevices)
 	return kubeletplugin.PrepareResult{
 		Devices: preparedDevices,
 	}
 }
 
-func (cp *CPUDriver) prepareResourceClaim(_ context.Context, claim *resourceapi.ResourceClaim) kubeletplugin.PrepareResult {
-	klog.Infof("prepareResourceClaim claim:%s/%s", claim.Namespace, claim.Name)
+// confineToSingleNUMANode narrows down the available CPUs to the NUMA node which has the
+// fewest free CPUs still able to satisfy the request (best fit). On machines with sub-NUMA
+// clustering this keeps a claim within a single SNC/NPS domain.
+func confineToSingleNUMANode(topo *cpuinfo.CPUTopology, availableCPUs cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, error) {
+	var bestFit cpuset.CPUSet
+	found := false
+	for _, numaID := range topo.CPUDetails.KeepOnly(availableCPUs).NUMANodes().List() {
+		numaCPUs := availableCPUs.Intersection(topo.CPUDetails.CPUsInNUMANodes(numaID))
+		if numaCPUs.Size() < numCPUs {
+			continue
+		}
+		if !found || numaCPUs.Size() < bestFit.Size() {
+			bestFit = numaCPUs
+			found = true
+		}
+	}
+	if !found {
+		return cpuset.New(), fmt.Errorf("no single NUMA node has %d free CPUs", numCPUs)
+	}
+	return bestFit, nil
+}
+
+func (cp *CPUDriver) prepareResourceClaim(ctx context.Context, claim *resourceapi.ResourceClaim) kubeletplugin.PrepareResult {
+	logger := klog.FromContext(ctx)
+	logger.V(2).Info("Preparing individual claim")
 
 	if claim.Status.Allocation == nil {
 		return kubeletplugin.PrepareResult{
@@ -373,12 +727,19 @@ func (cp *CPUDriver) prepareResourceClaim(_ context.Context, claim *resourceapi.
 		}
 	}
 
+	claimConfig, err := cp.getClaimConfig(claim)
+	if err != nil {
+		return kubeletplugin.PrepareResult{Err: err}
+	}
+
 	claimCPUIDs := []int{}
 	for _, alloc := range claim.Status.Allocation.Devices.Results {
 		if alloc.Driver != cp.driverName {
 			continue
 		}
+		cp.devicesMu.RLock()
 		cpuID, ok := cp.deviceNameToCPUID[alloc.Device]
+		cp.devicesMu.RUnlock()
 		if !ok {
 			return kubeletplugin.PrepareResult{
 				Err: fmt.Errorf("device %q not found in device to CPU ID map", alloc.Device),
@@ -388,30 +749,80 @@ func (cp *CPUDriver) prepareResourceClaim(_ context.Context, claim *resourceapi.
 	}
 
 	if len(claimCPUIDs) == 0 {
-		klog.V(5).Infof("prepareResourceClaim claim:%s/%s has no CPU allocations for this driver", claim.Namespace, claim.Name)
+		logger.V(5).Info("Claim has no CPU allocations for this driver")
 		return kubeletplugin.PrepareResult{}
 	}
 
 	claimCPUSet := cpuset.New(claimCPUIDs...)
+	if claimConfig.Contiguous && !isContiguous(claimCPUSet) {
+		err := fmt.Errorf("allocated CPUs %s are not contiguous, use a CEL selector on dra.cpu/cpuID to get a contiguous range", claimCPUSet.String())
+		cp.recordContiguousFailure(claim, err)
+		return kubeletplugin.PrepareResult{Err: fmt.Errorf("claim %s/%s: %w", claim.Namespace, claim.Name, err)}
+	}
+	if claimConfig.confinesSockets() {
+		if err := checkSocketConfinement(claim, claimConfig, cp.cpuTopology.CPUDetails.KeepOnly(claimCPUSet).Sockets()); err != nil {
+			return kubeletplugin.PrepareResult{Err: err}
+		}
+	}
+	if err := cp.checkSMTIsolation(claim, claimCPUSet); err != nil {
+		return kubeletplugin.PrepareResult{Err: err}
+	}
+	var poolName string
+	if len(cp.cpuPools) > 0 {
+		var poolCPUs cpuset.CPUSet
+		poolName, poolCPUs, err = cp.claimPoolCPUs(ctx, claim)
+		if err != nil {
+			return kubeletplugin.PrepareResult{Err: err}
+		}
+		if err := checkClaimPool(claim, poolName, poolCPUs, claimCPUSet); err != nil {
+			return kubeletplugin.PrepareResult{Err: err}
+		}
+	}
+	if err := cp.validateCacheAllocation(claim, claimConfig); err != nil {
+		return kubeletplugin.PrepareResult{Err: err}
+	}
+	claimInfo, err := newClaimInfo(claim, claimConfig, cpuset.New(), false)
+	if err != nil {
+		return kubeletplugin.PrepareResult{Err: err}
+	}
+	affinityNUMANodes, hasAffinity, err := cp.affinityNUMANodes(ctx, claim, claimConfig)
+	if err != nil {
+		return kubeletplugin.PrepareResult{Err: err}
+	}
+	if hasAffinity {
+		if claimNUMANodes := cp.cpuTopology.CPUDetails.KeepOnly(claimCPUSet).NUMANodes(); !claimNUMANodes.IsSubsetOf(affinityNUMANodes) {
+			return kubeletplugin.PrepareResult{Err: fmt.Errorf("claim %s/%s: allocated CPUs %s on NUMA nodes %s break its NUMA affinity, which allows NUMA nodes %s; use a CEL selector on dra.cpu/numaNodeID", claim.Namespace, claim.Name, claimCPUSet.String(), claimNUMANodes.String(), affinityNUMANodes.String())}
+		}
+	}
+	var allocationStatus ClaimAllocationStatus
+	if cp.reportsAllocation(claimConfig) {
+		allocationStatus, err = cp.newClaimAllocationStatus(claimConfig, claimCPUSet, cpuset.New())
+		if err != nil {
+			return kubeletplugin.PrepareResult{Err: fmt.Errorf("claim %s/%s: %w", claim.Namespace, claim.Name, err)}
+		}
+	}
+	if err := cp.applyCacheAllocation(ctx, claim, claimConfig, claimCPUSet); err != nil {
+		return kubeletplugin.PrepareResult{Err: err}
+	}
+	cp.journalPrepare(logger, claim, claimCPUSet, cp.cpuAllocationStore.GetSharedCPUs(), "", poolName)
+	cp.forgetRestoredClaim(claim.UID)
 	cp.cpuAllocationStore.AddResourceClaimAllocation(claim.UID, claimCPUSet)
+	cp.cpuAllocationStore.SetResourceClaimInfo(claim.UID, claimInfo)
 	deviceName := getCDIDeviceName(claim.UID)
 	envVar := fmt.Sprintf("%s_%s=%s", cdiEnvVarPrefix, claim.UID, claimCPUSet.String())
 	if err := cp.cdiMgr.AddDevice(deviceName, envVar); err != nil {
 		return kubeletplugin.PrepareResult{Err: err}
 	}
-
-	qualifiedName := cdiparser.QualifiedName(cdiVendor, cdiClass, deviceName)
-	klog.Infof("prepareResourceClaim CDIDeviceName:%s envVar:%s qualifiedName:%v", deviceName, envVar, qualifiedName)
-	preparedDevices := []kubeletplugin.Device{}
-	for _, allocResult := range claim.Status.Allocation.Devices.Results {
-		preparedDevice := kubeletplugin.Device{
-			PoolName:     allocResult.Pool,
-			DeviceName:   allocResult.Device,
-			CDIDeviceIDs: []string{qualifiedName},
+	if cp.reportsAllocation(claimConfig) {
+		if err := cp.recordAllocationStatus(ctx, claim, allocationStatus); err != nil {
+			logger.Error(err, "Failed to record the CPUs in the claim status", "cpus", claimCPUSet.String())
 		}
-		preparedDevices = append(preparedDevices, preparedDevice)
 	}
 
+	qualifiedName := cdiparser.QualifiedName(cdiVendor, cdiClass, deviceName)
+	logger.Info("Prepared claim", "cdiDevice", qualifiedName, "env", envVar)
+	preparedDevices := cp.preparedDevices(claim, qualifiedName)
+
 	return kubeletplugin.PrepareResult{
 		Devices: preparedDevices,
 	}
@@ -419,7 +830,9 @@ func (cp *CPUDriver) prepareResourceClaim(_ context.Context, claim *resourceapi.
 
 // UnprepareResourceClaims is called by the kubelet to unprepare the resources for a claim.
 func (cp *CPUDriver) UnprepareResourceClaims(ctx context.Context, claims []kubeletplugin.NamespacedObject) (map[types.UID]error, error) {
-	klog.Infof("UnprepareResourceClaims is called: number of claims: %d", len(claims))
+	logger := klog.FromContext(ctx)
+	logger.V(2).Info("UnprepareResourceClaims called", "claims", len(claims))
+	cp.chaos.delay(ctx, "UnprepareResourceClaims")
 
 	result := make(map[types.UID]error)
 
@@ -427,24 +840,42 @@ func (cp *CPUDriver) UnprepareResourceClaims(ctx context.Context, claims []kubel
 		return result, nil
 	}
 
+	cp.settingsMu.RLock()
+	defer cp.settingsMu.RUnlock()
+
 	for _, claim := range claims {
-		klog.Infof("UnprepareResourceClaims claim:%+v", claim)
+		claimLogger := klog.LoggerWithValues(logger, "claim", klog.KRef(claim.Namespace, claim.Name), "claimUID", claim.UID)
 		err := cp.unprepareResourceClaim(ctx, claim)
 		result[claim.UID] = err
 		if err != nil {
-			klog.Infof("error unpreparing resources for claim %s/%s : %v", claim.Namespace, claim.Name, err)
+			claimLogger.Error(err, "Failed to unprepare claim")
+			continue
 		}
+		claimLogger.Info("Unprepared claim")
 	}
 	return result, nil
 }
 
-func (cp *CPUDriver) unprepareResourceClaim(_ context.Context, claim kubeletplugin.NamespacedObject) error {
+func (cp *CPUDriver) unprepareResourceClaim(ctx context.Context, claim kubeletplugin.NamespacedObject) error {
+	if err := cp.releaseCacheAllocation(claim.UID); err != nil {
+		return err
+	}
+	cp.forgetReleasedClaim(claim.UID)
+	cp.forgetRestoredClaim(claim.UID)
+	cp.forgetClaimLifecycle(claim.UID)
+	if cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID); ok {
+		cp.journalRelease(klog.FromContext(ctx), JOURNAL_EVENT_UNPREPARE, claim.UID, claim.Namespace, claim.Name, cpus)
+	}
 	cp.cpuAllocationStore.RemoveResourceClaimAllocation(claim.UID)
 	// Remove the device from the CDI spec file using the manager.
 	return cp.cdiMgr.RemoveDevice(getCDIDeviceName(claim.UID))
 }
 
-func (cp *CPUDriver) HandleError(_ context.Context, err error, msg string) {
-	// TODO: Implement this function
-	klog.Error("HandleError error:", err, "msg:", msg)
+func (cp *CPUDriver) HandleError(ctx context.Context, err error, msg string) {
+	// the errors of the resourceslice controller are the only recoverable ones, e.g. the fields of the
+	// slices the API server dropped.
+	if errors.Is(err, kubeletplugin.ErrRecoverable) {
+		cp.recordPublish(time.Now(), err)
+	}
+	klog.FromContext(ctx).Error(err, msg)
 }
@@ -33,6 +33,7 @@ import (
 	"k8s.io/dynamic-resource-allocation/resourceslice"
 	"k8s.io/utils/cpuset"
 	cdiparser "tags.cncf.io/container-device-interface/pkg/parser"
+	cdiSpec "tags.cncf.io/container-device-interface/specs-go"
 )
 
 const (
@@ -56,22 +57,22 @@ func (m *mockKubeletPlugin) PublishResources(ctx context.Context, resources reso
 func (m *mockKubeletPlugin) Stop() {}
 
 type mockCdiMgr struct {
-	devices     map[string]string
+	devices     map[string][]string
 	addError    error
 	removeError error
 }
 
 func newMockCdiMgr() *mockCdiMgr {
 	return &mockCdiMgr{
-		devices: make(map[string]string),
+		devices: make(map[string][]string),
 	}
 }
 
-func (m *mockCdiMgr) AddDevice(deviceName, envVar string) error {
+func (m *mockCdiMgr) AddDevice(deviceName string, envVars ...string) error {
 	if m.addError != nil {
 		return m.addError
 	}
-	m.devices[deviceName] = envVar
+	m.devices[deviceName] = envVars
 	return nil
 }
 
@@ -83,6 +84,14 @@ func (m *mockCdiMgr) RemoveDevice(deviceName string) error {
 	return nil
 }
 
+func (m *mockCdiMgr) GetSpec() (*cdiSpec.Spec, error) {
+	spec := &cdiSpec.Spec{}
+	for deviceName, envVars := range m.devices {
+		spec.Devices = append(spec.Devices, cdiSpec.Device{Name: deviceName, ContainerEdits: cdiSpec.ContainerEdits{Env: envVars}})
+	}
+	return spec, nil
+}
+
 var (
 	// Sibling CPUs are non-consecutive: (0,2), (1,3)
 	mockCPUInfos_SingleSocket_4CPUS_HT = []cpuinfo.CPUInfo{
@@ -115,6 +124,28 @@ var (
 		{CpuID: 6, CoreID: 2, SocketID: 1, NUMANodeID: 1, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 2},
 		{CpuID: 7, CoreID: 3, SocketID: 1, NUMANodeID: 1, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 3},
 	}
+	// Same as mockCPUInfos_DualSocket_4CPUsPerSocket_HT, but the core IDs are per socket like on most servers.
+	mockCPUInfos_DualSocket_PerSocketCoreIDs_HT = []cpuinfo.CPUInfo{
+		{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 4},
+		{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 5},
+		{CpuID: 2, CoreID: 0, SocketID: 1, NUMANodeID: 1, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 6},
+		{CpuID: 3, CoreID: 1, SocketID: 1, NUMANodeID: 1, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 7},
+		{CpuID: 4, CoreID: 0, SocketID: 0, NUMANodeID: 0, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 0},
+		{CpuID: 5, CoreID: 1, SocketID: 0, NUMANodeID: 0, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 1},
+		{CpuID: 6, CoreID: 0, SocketID: 1, NUMANodeID: 1, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 2},
+		{CpuID: 7, CoreID: 1, SocketID: 1, NUMANodeID: 1, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 3},
+	}
+	// 1 socket split in 2 sub-NUMA clusters, 2 cores/cluster, HT on. Total 8 logical CPUs.
+	mockCPUInfos_SingleSocket_SNC2_8CPUs_HT = []cpuinfo.CPUInfo{
+		{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 4},
+		{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 5},
+		{CpuID: 2, CoreID: 2, SocketID: 0, NUMANodeID: 1, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 6},
+		{CpuID: 3, CoreID: 3, SocketID: 0, NUMANodeID: 1, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 7},
+		{CpuID: 4, CoreID: 0, SocketID: 0, NUMANodeID: 0, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 0},
+		{CpuID: 5, CoreID: 1, SocketID: 0, NUMANodeID: 0, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 1},
+		{CpuID: 6, CoreID: 2, SocketID: 0, NUMANodeID: 1, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 2},
+		{CpuID: 7, CoreID: 3, SocketID: 0, NUMANodeID: 1, CoreType: cpuinfo.CoreTypePerformance, SiblingCpuID: 3},
+	}
 	mockCPUInfos_DualSocket_EqualsResourceSliceLimit = func() []cpuinfo.CPUInfo {
 		var infos []cpuinfo.CPUInfo
 		cpusPerNumaNode := maxDevicesPerResourceSlice / 2
@@ -221,6 +252,15 @@ func TestPublishResources(t *testing.T) {
 			expectedDevices:            len(mockCPUInfos_DualSocket_4CPUsPerSocket_HT),
 			expectedDevicesPerNUMANode: map[int]int{0: 4, 1: 4},
 		},
+		{
+			name:                       "single socket, sub-NUMA clustering, HT on",
+			cpuInfos:                   mockCPUInfos_SingleSocket_SNC2_8CPUs_HT,
+			reservedCPUs:               cpuset.New(),
+			expectPublish:              true,
+			expectedNumSlices:          1,
+			expectedDevices:            len(mockCPUInfos_SingleSocket_SNC2_8CPUs_HT),
+			expectedDevicesPerNUMANode: map[int]int{0: 4, 1: 4},
+		},
 		{
 			name:                       "dual socket, 120 CPUs per socker, HT on",
 			cpuInfos:                   mockCPUInfos_DualSocket_120CPUsPerSocket_HT,
@@ -278,6 +318,19 @@ func TestPublishResources(t *testing.T) {
 			expectedDevices:            len(mockCPUInfos_SingleSocket_4CPUS_HT) - 2,
 			expectedDevicesPerNUMANode: map[int]int{0: 2},
 		},
+		{
+			name: "arm big.LITTLE with clusters",
+			cpuInfos: []cpuinfo.CPUInfo{
+				{CpuID: 0, CoreID: 0, SocketID: 0, NUMANodeID: 0, SiblingCpuID: -1, CoreType: cpuinfo.CoreTypePerformance, UncoreCacheID: 0, ClusterID: 0, Capacity: 1024},
+				{CpuID: 1, CoreID: 1, SocketID: 0, NUMANodeID: 0, SiblingCpuID: -1, CoreType: cpuinfo.CoreTypePerformance, UncoreCacheID: 0, ClusterID: 0, Capacity: 1024},
+				{CpuID: 2, CoreID: 2, SocketID: 0, NUMANodeID: 0, SiblingCpuID: -1, CoreType: cpuinfo.CoreTypeEfficiency, UncoreCacheID: 0, ClusterID: 1, Capacity: 446},
+				{CpuID: 3, CoreID: 3, SocketID: 0, NUMANodeID: 0, SiblingCpuID: -1, CoreType: cpuinfo.CoreTypeEfficiency, UncoreCacheID: 0, ClusterID: 1, Capacity: 446},
+			},
+			expectPublish:              true,
+			expectedNumSlices:          1,
+			expectedDevices:            4,
+			expectedDevicesPerNUMANode: map[int]int{0: 4},
+		},
 		{
 			name:                       "all cpus reserved",
 			cpuInfos:                   mockCPUInfos_SingleSocket_4CPUS_HT,
@@ -295,11 +348,12 @@ func TestPublishResources(t *testing.T) {
 			mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: tc.cpuInfos, Err: tc.cpuInfoErr}
 			topo, _ := mockProvider.GetCPUTopology()
 			cp := &CPUDriver{
-				nodeName:          testNodeName,
-				draPlugin:         mockPlugin,
-				deviceNameToCPUID: make(map[string]int),
-				cpuTopology:       topo,
-				reservedCPUs:      tc.reservedCPUs,
+				nodeName:           testNodeName,
+				draPlugin:          mockPlugin,
+				deviceNameToCPUID:  make(map[string]int),
+				cpuTopology:        topo,
+				reservedCPUs:       tc.reservedCPUs,
+				cpuAllocationStore: store.NewCPUAllocation(topo, tc.reservedCPUs),
 			}
 
 			cp.PublishResources(context.Background())
@@ -367,6 +421,13 @@ func TestPublishResources(t *testing.T) {
 					require.Equal(t, CacheL3ID, *device.Attributes["dra.cpu/cacheL3ID"].IntValue)
 					require.Equal(t, coreType, *device.Attributes["dra.cpu/coreType"].StringValue)
 					require.Equal(t, socketID, *device.Attributes["dra.cpu/socketID"].IntValue)
+					require.Equal(t, int64(cpuInfo.ClusterID), *device.Attributes["dra.cpu/clusterID"].IntValue)
+					if cpuInfo.Capacity > 0 {
+						require.Equal(t, int64(cpuInfo.Capacity), *device.Attributes["dra.cpu/capacity"].IntValue)
+					} else {
+						require.NotContains(t, device.Attributes, resourceapi.QualifiedName("dra.cpu/capacity"))
+					}
+					require.Equal(t, int64(topo.NUMANodesPerSocket()), *device.Attributes["dra.cpu/numaNodesPerSocket"].IntValue)
 					devicesPerNumaInSlices[cpuInfo.NUMANodeID]++
 				}
 			}
@@ -446,8 +507,9 @@ func TestPrepareResourceClaims(t *testing.T) {
 						Allocation: &resourceapi.AllocationResult{
 							Devices: resourceapi.DeviceAllocationResult{
 								Results: []resourceapi.DeviceRequestAllocationResult{
-									{Driver: testDriverName, Pool: testNodeName, Device: "cpudev0"},
-									{Driver: testDriverName, Pool: testNodeName, Device: "cpudev1"},
+									{Driver: testDriverName, Pool: testNodeName, Device: "cpudev0", Request: "cpus"},
+									{Driver: testDriverName, Pool: testNodeName, Device: "cpudev1", Request: "cpus"},
+									{Driver: "gpu.example.com", Pool: testNodeName, Device: "gpu0", Request: "gpu"},
 								},
 							},
 						},
@@ -459,8 +521,8 @@ func TestPrepareResourceClaims(t *testing.T) {
 			expectedCdiDevice:       cdiDeviceName,
 			expectedCdiEnvVar:       fmt.Sprintf("%s_%s=%s", cdiEnvVarPrefix, claimUID, "0-1"),
 			expectedPreparedDevices: []kubeletplugin.Device{
-				{PoolName: testNodeName, DeviceName: "cpudev0", CDIDeviceIDs: []string{cdiQualifiedName}},
-				{PoolName: testNodeName, DeviceName: "cpudev1", CDIDeviceIDs: []string{cdiQualifiedName}},
+				{PoolName: testNodeName, DeviceName: "cpudev0", CDIDeviceIDs: []string{cdiQualifiedName}, Requests: []string{"cpus"}},
+				{PoolName: testNodeName, DeviceName: "cpudev1", CDIDeviceIDs: []string{cdiQualifiedName}, Requests: []string{"cpus"}},
 			},
 		},
 		{
@@ -566,9 +628,9 @@ func TestPrepareResourceClaims(t *testing.T) {
 
 			require.Len(t, mockCdiMgr.devices, tc.expectedCdiDevicesCount)
 			if tc.expectedCdiDevice != "" {
-				envVar, ok := mockCdiMgr.devices[tc.expectedCdiDevice]
+				envVars, ok := mockCdiMgr.devices[tc.expectedCdiDevice]
 				require.True(t, ok, "expected CDI device not found")
-				require.Equal(t, tc.expectedCdiEnvVar, envVar)
+				require.Equal(t, []string{tc.expectedCdiEnvVar}, envVars)
 			}
 		})
 	}
@@ -601,6 +663,8 @@ func TestPrepareResourceClaimsGroupedMode(t *testing.T) {
 			for i := 0; i < topo.NumNUMANodes; i++ {
 				driver.deviceNameToNUMANodeID[fmt.Sprintf("%snuma%d", cpuDevicePrefix, i)] = i
 			}
+		case GROUP_BY_NODE:
+			driver.nodeDevicePublished = true
 		}
 		return driver
 	}
@@ -615,6 +679,7 @@ func TestPrepareResourceClaimsGroupedMode(t *testing.T) {
 		groupBy                 string
 		reservedCPUs            cpuset.CPUSet
 		initialAllocations      map[types.UID]cpuset.CPUSet
+		confineToNUMANode       bool
 		claims                  []*resourceapi.ResourceClaim
 		mockCdiAddError         error
 		expectedError           bool
@@ -685,6 +750,42 @@ func TestPrepareResourceClaimsGroupedMode(t *testing.T) {
 			claims:        []*resourceapi.ResourceClaim{testClaim(claimUID, testDriverName, testNodeName, map[string]int64{"cpudevnuma99": 2})},
 			expectedError: true,
 		},
+		{
+			name:           "NodeGrouped_DualSocketHT_Alloc6CPU",
+			cpuInfos:       mockCPUInfos_DualSocket_4CPUsPerSocket_HT,
+			groupBy:        GROUP_BY_NODE,
+			claims:         []*resourceapi.ResourceClaim{testClaim(claimUID, testDriverName, testNodeName, map[string]int64{"cpudevnode": 6})},
+			expectedCPUSet: cpuset.New(0, 1, 4, 5, 2, 6),
+		},
+		{
+			name:           "NodeGrouped_DualSocketHT_Alloc2CPU_WithReserved",
+			cpuInfos:       mockCPUInfos_DualSocket_4CPUsPerSocket_HT,
+			groupBy:        GROUP_BY_NODE,
+			reservedCPUs:   cpuset.New(0, 4),
+			claims:         []*resourceapi.ResourceClaim{testClaim(claimUID, testDriverName, testNodeName, map[string]int64{"cpudevnode": 2})},
+			expectedCPUSet: cpuset.New(1, 5),
+		},
+		{
+			name:           "NodeGrouped_DualSocketHT_SocketAffinity",
+			cpuInfos:       mockCPUInfos_DualSocket_4CPUsPerSocket_HT,
+			groupBy:        GROUP_BY_NODE,
+			claims:         []*resourceapi.ResourceClaim{withOpaqueConfig(testClaim(claimUID, testDriverName, testNodeName, map[string]int64{"cpudevnode": 2}), testDriverName, `{"socketAffinity": 1}`)},
+			expectedCPUSet: cpuset.New(2, 6),
+		},
+		{
+			name:          "NodeGrouped_DualSocketHT_MaxSocketsExceeded",
+			cpuInfos:      mockCPUInfos_DualSocket_4CPUsPerSocket_HT,
+			groupBy:       GROUP_BY_NODE,
+			claims:        []*resourceapi.ResourceClaim{withOpaqueConfig(testClaim(claimUID, testDriverName, testNodeName, map[string]int64{"cpudevnode": 6}), testDriverName, `{"maxSockets": 1}`)},
+			expectedError: true,
+		},
+		{
+			name:          "NodeGrouped_DualSocketHT_DeviceNotFound",
+			cpuInfos:      mockCPUInfos_DualSocket_4CPUsPerSocket_HT,
+			groupBy:       GROUP_BY_NODE,
+			claims:        []*resourceapi.ResourceClaim{testClaim(claimUID, testDriverName, testNodeName, map[string]int64{"cpudevsocket0": 2})},
+			expectedError: true,
+		},
 		{
 			name:           "SocketGrouped_DualSocketHT_MultiSocketRequest",
 			cpuInfos:       mockCPUInfos_DualSocket_4CPUsPerSocket_HT,
@@ -764,11 +865,48 @@ func TestPrepareResourceClaimsGroupedMode(t *testing.T) {
 			claims:        []*resourceapi.ResourceClaim{testClaim(claimUID, testDriverName, testNodeName, map[string]int64{"cpudevsocket0": 5})},
 			expectedError: true,
 		},
+		{
+			name:               "SocketGrouped_SNC2_ConfineToNUMANode_BestFit",
+			cpuInfos:           mockCPUInfos_SingleSocket_SNC2_8CPUs_HT,
+			groupBy:            GROUP_BY_SOCKET,
+			confineToNUMANode:  true,
+			initialAllocations: map[types.UID]cpuset.CPUSet{"other-claim": cpuset.New(0, 4)},
+			claims:             []*resourceapi.ResourceClaim{testClaim(claimUID, testDriverName, testNodeName, map[string]int64{"cpudevsocket0": 2})},
+			// NUMA node 0 has exactly 2 free CPUs left, so it is the best fit
+			expectedCPUSet: cpuset.New(1, 5),
+		},
+		{
+			name:               "SocketGrouped_SNC2_ConfineToNUMANode_SkipsTooSmallNode",
+			cpuInfos:           mockCPUInfos_SingleSocket_SNC2_8CPUs_HT,
+			groupBy:            GROUP_BY_SOCKET,
+			confineToNUMANode:  true,
+			initialAllocations: map[types.UID]cpuset.CPUSet{"other-claim": cpuset.New(0, 4)},
+			claims:             []*resourceapi.ResourceClaim{testClaim(claimUID, testDriverName, testNodeName, map[string]int64{"cpudevsocket0": 4})},
+			expectedCPUSet:     cpuset.New(2, 3, 6, 7),
+		},
+		{
+			name:               "SocketGrouped_SNC2_ConfineToNUMANode_NoSingleNodeFits",
+			cpuInfos:           mockCPUInfos_SingleSocket_SNC2_8CPUs_HT,
+			groupBy:            GROUP_BY_SOCKET,
+			confineToNUMANode:  true,
+			initialAllocations: map[types.UID]cpuset.CPUSet{"other-claim": cpuset.New(0, 4)},
+			claims:             []*resourceapi.ResourceClaim{testClaim(claimUID, testDriverName, testNodeName, map[string]int64{"cpudevsocket0": 5})},
+			expectedError:      true,
+		},
+		{
+			name:               "SocketGrouped_SNC2_NotConfined_SpansNUMANodes",
+			cpuInfos:           mockCPUInfos_SingleSocket_SNC2_8CPUs_HT,
+			groupBy:            GROUP_BY_SOCKET,
+			initialAllocations: map[types.UID]cpuset.CPUSet{"other-claim": cpuset.New(0, 4)},
+			claims:             []*resourceapi.ResourceClaim{testClaim(claimUID, testDriverName, testNodeName, map[string]int64{"cpudevsocket0": 5})},
+			expectedCPUSet:     cpuset.New(1, 2, 3, 6, 7),
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			driver := baseCPUDriver(tc.groupBy, tc.cpuInfos, tc.initialAllocations, tc.reservedCPUs)
+			driver.confineToNUMANode = tc.confineToNUMANode
 			mockCdiMgr := newMockCdiMgr()
 			mockCdiMgr.addError = tc.mockCdiAddError
 			driver.cdiMgr = mockCdiMgr
@@ -799,7 +937,7 @@ func TestPrepareResourceClaimsGroupedMode(t *testing.T) {
 					}
 					require.ElementsMatch(t, expectedPreparedDevices, result.Devices)
 
-					envVar := mockCdiMgr.devices[cdiDeviceName]
+					envVar := mockCdiMgr.devices[cdiDeviceName][0]
 					parts := strings.SplitN(envVar, "=", 2)
 					// if expectedCPUSet is empty, parts[1] can be empty
 					if tc.expectedCPUSet.Size() > 0 {
@@ -905,3 +1043,76 @@ func testClaim(claimUID types.UID, driverName, poolName string, consumedCapacity
 		},
 	}
 }
+
+// newFakeCPUInfos returns the CPUs of a symmetric machine with 2 threads per core, enumerated like Linux does.
+func newFakeCPUInfos(sockets, numaNodesPerSocket, coresPerNUMANode int) []cpuinfo.CPUInfo {
+	numCores := sockets * numaNodesPerSocket * coresPerNUMANode
+	cpuInfos := []cpuinfo.CPUInfo{}
+	for cpuID := 0; cpuID < 2*numCores; cpuID++ {
+		coreID := cpuID % numCores
+		numaID := coreID / coresPerNUMANode
+		cpuInfos = append(cpuInfos, cpuinfo.CPUInfo{
+			CpuID:         cpuID,
+			CoreID:        coreID,
+			SocketID:      numaID / numaNodesPerSocket,
+			NUMANodeID:    numaID,
+			SiblingCpuID:  (cpuID + numCores) % (2 * numCores),
+			CoreType:      cpuinfo.CoreTypePerformance,
+			UncoreCacheID: numaID,
+		})
+	}
+	return cpuInfos
+}
+
+func BenchmarkPrepareResourceClaimsGroupedMode(b *testing.B) {
+	for _, tt := range []struct {
+		sockets, numaNodesPerSocket, coresPerNUMANode int
+		claimCPUs                                     int64
+	}{
+		{1, 1, 2, 2},
+		{2, 1, 16, 8},
+		{2, 2, 24, 16},
+		{2, 4, 60, 32},
+	} {
+		cpuInfos := newFakeCPUInfos(tt.sockets, tt.numaNodesPerSocket, tt.coresPerNUMANode)
+		b.Run(fmt.Sprintf("%dthreads/claim%d", len(cpuInfos), tt.claimCPUs), func(b *testing.B) {
+			mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: cpuInfos}
+			topo, _ := mockProvider.GetCPUTopology()
+			cp := &CPUDriver{
+				driverName:             testDriverName,
+				cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+				cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+				cpuTopology:            topo,
+				deviceNameToNUMANodeID: map[string]int{"cpudevnuma0": 0},
+				cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+				cdiMgr:                 newMockCdiMgr(),
+			}
+			claims := []*resourceapi.ResourceClaim{testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma0": tt.claimCPUs})}
+			for b.Loop() {
+				results, err := cp.PrepareResourceClaims(context.Background(), claims)
+				if err != nil || results["claim-1"].Err != nil {
+					b.Fatalf("prepare failed: %v %v", err, results["claim-1"].Err)
+				}
+				cp.cpuAllocationStore.RemoveResourceClaimAllocation("claim-1")
+			}
+		})
+	}
+}
+
+func TestPreparedDevices(t *testing.T) {
+	shareID := types.UID("share-1")
+	claim := testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma000": 2})
+	claim.Status.Allocation.Devices.Results[0].Request = "cpus"
+	claim.Status.Allocation.Devices.Results[0].ShareID = &shareID
+	claim.Status.Allocation.Devices.Results = append(claim.Status.Allocation.Devices.Results,
+		resourceapi.DeviceRequestAllocationResult{Driver: "dra.net", Pool: testNodeName, Device: "eth1", Request: "nic"})
+
+	cp := &CPUDriver{driverName: testDriverName}
+	require.Equal(t, []kubeletplugin.Device{{
+		PoolName:     testNodeName,
+		DeviceName:   "cpudevnuma000",
+		CDIDeviceIDs: []string{"dra.k8s.io/cpu=claim-claim-1"},
+		Requests:     []string{"cpus"},
+		ShareID:      &shareID,
+	}}, cp.preparedDevices(claim, "dra.k8s.io/cpu=claim-claim-1"))
+}
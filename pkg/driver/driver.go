@@ -17,21 +17,40 @@ limitations under the License.
 package driver
 
 import (
+	"cmp"
 	"context"
+	"crypto/sha256"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sync"
 	"time"
 
+	"github.com/containerd/nri/pkg/api"
 	"github.com/containerd/nri/pkg/stub"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cgroupfs"
 	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/hostfs"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/resctrl"
 	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/dynamic-resource-allocation/kubeletplugin"
 	"k8s.io/dynamic-resource-allocation/resourceslice"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/cpuset"
+	cdiSpec "tags.cncf.io/container-device-interface/specs-go"
 )
 
 const (
@@ -46,12 +65,16 @@ const (
 	GROUP_BY_SOCKET = "socket"
 	// GROUP_BY_NUMA_NODE groups CPUs by NUMA node.
 	GROUP_BY_NUMA_NODE = "numanode"
+	// GROUP_BY_NODE exposes all the CPUs of the node as a single device.
+	GROUP_BY_NODE = "node"
 )
 
 const (
 	kubeletPluginPath = "/var/lib/kubelet/plugins"
 	// maxAttempts indicates the number of times the driver will try to recover itself before failing
 	maxAttempts = 5
+	// kubeletCheckpointSyncPeriod is how often the kubelet CPU Manager checkpoint is re-read
+	kubeletCheckpointSyncPeriod = 10 * time.Second
 )
 
 // KubeletPlugin is an interface that describes the methods used from kubeletplugin.Helper.
@@ -61,8 +84,9 @@ type KubeletPlugin interface {
 }
 
 type cdiManager interface {
-	AddDevice(deviceName string, envVar string) error
+	AddDevice(deviceName string, envVars ...string) error
 	RemoveDevice(deviceName string) error
+	GetSpec() (*cdiSpec.Spec, error)
 }
 
 // CPUInfoProvider is an interface for getting CPU information.
@@ -86,56 +110,240 @@ type CPUDriver struct {
 	deviceNameToCPUID      map[string]int
 	deviceNameToSocketID   map[string]int
 	deviceNameToNUMANodeID map[string]int
+	nodeDevicePublished    bool
 	reservedCPUs           cpuset.CPUSet
+	housekeepingCPUs       cpuset.CPUSet
 	cpuDeviceMode          string
 	cpuDeviceGroupBy       string
+	confineToNUMANode      bool
+	allocationStrategy     string
+	kubeletCheckpointPath  string
 	claimTracker           *store.ClaimTracker
+	eventRecorder          record.EventRecorder
+	orphanedClaimTTL       time.Duration
+	// orphanedClaims tracks since when prepared claims have been orphaned, only used by the cleanup loop.
+	orphanedClaims map[types.UID]time.Time
+
+	cpuHealthCheckPeriod  time.Duration
+	replaceDegradedClaims bool
+	readCPUHealthCounters func() (map[int]cpuinfo.CPUHealthCounters, error)
+	// cpuHealthCounters are the counters of the previous health check, only used by the health check loop.
+	cpuHealthCounters map[int]cpuinfo.CPUHealthCounters
+
+	driverVersion string
+	// statusMu protects status, which is updated by the publish and checkpoint sync paths and reported by the node status loop.
+	statusMu sync.Mutex
+	status   driverStatus
+
+	// nfdLabels are the names of the node-feature-discovery labels republished as device attributes.
+	nfdLabels []string
+	// nfdAttributes are the device attributes of the nfdLabels found on the node, protected by devicesMu.
+	nfdAttributes map[resourceapi.QualifiedName]resourceapi.DeviceAttribute
+	// extendedResourceName is the node extended resource mirroring the free CPUs, empty if disabled.
+	extendedResourceName string
+	// chaos injects faults in chaos mode, nil otherwise.
+	chaos *chaosInjector
+	// sandboxedRuntimeHandlers are the runtime handlers of the pods whose CPUs are enforced by the sandboxedRuntimePolicy.
+	sandboxedRuntimeHandlers []string
+	sandboxedRuntimePolicy   string
+	// requireCPULimits fails to prepare the claims of the containers whose cpu limits differ from their requests.
+	requireCPULimits bool
+	// settingsMu protects the settings the DRACPUConfig of the node changes while running, held by PrepareResourceClaims.
+	// The expiry of the restored claims holds it as well, so it doesn't release the CPUs of a claim being prepared,
+	// and so do the release of the orphaned claims and the release and reacquisition of the claims of failing pods.
+	// UnprepareResourceClaims reads it.
+	settingsMu sync.RWMutex
+	// smtIsolation keeps the claims, or the claims of different namespaces, off the hyperthread siblings of each other.
+	smtIsolation string
+	// cpuPools are the static partitions of the CPUs dedicated to the claims of some tenants, empty if disabled.
+	cpuPools []CPUPool
+	// nodeConfigBase is the configuration from the flags the DRACPUConfig of the node overrides, only used by the node config loop.
+	nodeConfigBase Config
+	// restartCh is notified when the DRACPUConfig of the node changes settings requiring a restart.
+	restartCh chan string
+	// dynamicClient reads the DRACPUConfig and writes the DRACPUNodeStatus of the node, nil if both are disabled.
+	dynamicClient dynamic.Interface
+	// publishNodeStatus enables the DRACPUNodeStatus of the node, updated by the node status loop.
+	publishNodeStatus bool
+	// reportAllocations records the CPUs of every claim in its device status, as if they set reportAllocation.
+	reportAllocations bool
+	// enforcementBackend is how the containers are pinned to their CPUs, auto resolved at start.
+	enforcementBackend string
+	// cgroupRoot and cgroupDriver locate the cgroups of the containers for the cgroupfs and none backends.
+	cgroupRoot   string
+	cgroupDriver cgroupfs.CgroupDriver
+	// placements are the placements of the claims pre-computed before their prepare, nil if disabled.
+	placements *placementCache
+	// journal records the allocation decisions of the claims on the node, nil if disabled.
+	journal *allocationJournal
+	// allocationSnapshotPath is the file the claim allocations are written to on shutdown, empty if disabled.
+	allocationSnapshotPath string
+	// restored are the claims of the allocation snapshot of the previous boot, nil if none.
+	restored *restoredClaims
+
+	// lifecycleMu protects claimsPreparedAt, the prepare times of the claims none of whose containers started yet.
+	lifecycleMu      sync.Mutex
+	claimsPreparedAt map[types.UID]time.Time
+	// pinningMismatches are the IDs of the containers not running on the CPUs of their claims with the
+	// none enforcement backend, only used by the pinning verification loop.
+	pinningMismatches map[string]bool
+	// publishRequests holds the pending request of the publisher, nil if the resources are published right away.
+	publishRequests chan struct{}
+	// resctrl programs the cache and memory bandwidth allocation of the claims, nil if disabled.
+	resctrl *resctrl.Manager
+	// podFailureThreshold is how long the pods of a claim with the release policy fail before its CPUs are released.
+	podFailureThreshold time.Duration
+	// failingClaims tracks since when the pods of claims are failing, only used by the failed pods loop.
+	failingClaims map[types.UID]time.Time
+	// releasedClaimsMu protects releasedClaims, the prepared claims whose CPUs were released while their pods were failing.
+	releasedClaimsMu sync.Mutex
+	releasedClaims   map[types.UID]store.ClaimAllocation
+
+	// standalone is set when the driver runs without an API server, see StartStandalone.
+	standalone bool
+	// standaloneClaims are the digests of the files of the prepared claims of the standalone mode, only used by its sync loop.
+	standaloneClaims map[types.UID][sha256.Size]byte
+	// standaloneFiles are the claims prepared from the files of the claims directory, by file name.
+	standaloneFiles map[string]types.UID
+
+	// dryRun is set on the copies of the driver simulating a prepare, which skip the changes to the node
+	// and the API objects their stubs don't discard, see simulationDriver.
+	dryRun bool
+
+	// devicesMu protects the deviceNameTo* maps and nodeDevicePublished, which are rebuilt every time resources are published.
+	devicesMu sync.RWMutex
 }
 
 // Config is the configuration for the CPUDriver.
 type Config struct {
-	DriverName       string
-	NodeName         string
-	ReservedCPUs     cpuset.CPUSet
-	CpuDeviceMode    string
-	CPUDeviceGroupBy string
+	DriverName        string
+	NodeName          string
+	ReservedCPUs      cpuset.CPUSet
+	CpuDeviceMode     string
+	CPUDeviceGroupBy  string
+	ConfineToNUMANode bool
+	// AllocationStrategy is the default placement strategy for grouped mode claims.
+	AllocationStrategy string
+	// KubeletCheckpointPath, if set, enables coexistence with the kubelet CPU Manager static policy.
+	KubeletCheckpointPath string
+	// OrphanedClaimTTL is how long a prepared claim without consumer pods is kept before releasing its CPUs. Zero disables the cleanup.
+	OrphanedClaimTTL time.Duration
+	// CPUHealthCheckPeriod is how often the CPUs are checked for machine check exceptions and thermal throttling. Zero disables the checks.
+	CPUHealthCheckPeriod time.Duration
+	// ReplaceDegradedClaims moves the grouped mode claims off the CPUs found degraded.
+	ReplaceDegradedClaims bool
+	// DriverVersion is reported on the ResourceSlices published by the driver.
+	DriverVersion string
+	// NFDLabels are the names, without domain, of the node-feature-discovery labels of the node
+	// republished as device attributes, e.g. cpu-cpuid.AVX512F.
+	NFDLabels []string
+	// ExtendedResourceName is the node extended resource the CPUs claims can get are mirrored into,
+	// e.g. dra.cpu/exclusive-cpus. Empty disables it.
+	ExtendedResourceName string
+	// ChaosProbability enables the chaos mode for soak tests when positive: each fault point, like
+	// a kubelet RPC, a container update or a controller iteration, is hit with this probability.
+	ChaosProbability float64
+	// SandboxedRuntimeHandlers are the runtime handlers, e.g. kata or runsc, whose pods are handled
+	// according to the SandboxedRuntimePolicy.
+	SandboxedRuntimeHandlers []string
+	// SandboxedRuntimePolicy is how the CPUs of the sandboxed pods are enforced: pin, annotate or reject.
+	SandboxedRuntimePolicy string
+	// ResctrlPath is where the resctrl filesystem is mounted, e.g. /sys/fs/resctrl, to give the claims
+	// their share of the last level cache and memory bandwidth. Empty disables it.
+	ResctrlPath string
+	// PodFailureThreshold is how long the consumer pods of a claim with the release onPodFailure policy
+	// must be failed or crash looping before the CPUs of the claim are released. Zero disables it.
+	PodFailureThreshold time.Duration
+	// SMTIsolation keeps the hyperthread siblings of a physical core from being shared by two claims,
+	// with the claim policy, or by the claims of two namespaces, with the namespace policy.
+	SMTIsolation string
+	// CPUPools are the static partitions of the CPUs the claims of some namespaces or priority classes
+	// are restricted to. The other claims get the CPUs outside of all the pools.
+	CPUPools []CPUPool
+	// NodeConfigName is the name of the DRACPUConfig overriding the settings of the configuration,
+	// read with the DynamicClient. Empty disables it.
+	NodeConfigName string
+	DynamicClient  dynamic.Interface
+	// PublishNodeStatus writes the CPU allocation summary of the node in its DRACPUNodeStatus, with the DynamicClient.
+	PublishNodeStatus bool
+	// PublishQPS and PublishBurst rate limit the ResourceSlice publications, coalescing the requests
+	// made in the meantime. Zero uses DefaultPublishQPS and DefaultPublishBurst.
+	PublishQPS   float32
+	PublishBurst int
+	// PublishResyncPeriod is how often, with jitter, the resources are republished. Zero disables it.
+	PublishResyncPeriod time.Duration
+	// PublishClientConfig is the client config the ResourceSlices are written with, so their writes are
+	// observed for the publish metrics and status. Nil writes them with the clientset of Start, unobserved.
+	PublishClientConfig *rest.Config
+	// ReportAllocations records the CPUs, cores and NUMA nodes of every prepared claim in the status
+	// of its devices, as if all the claims set the reportAllocation parameter.
+	ReportAllocations bool
+	// HousekeepingCPUs are the CPUs the claims only get with the useHousekeepingCPUs parameter: a cpuset,
+	// or first-core-per-numa for the first physical core of each NUMA node. Empty is none.
+	HousekeepingCPUs string
+	// EnforcementBackend is how the containers are pinned to their CPUs: auto, nri, cgroupfs or none.
+	// Empty is nri.
+	EnforcementBackend string
+	// CgroupRoot is the mount point of the cgroup v2 hierarchy of the cgroupfs and none backends, empty is /sys/fs/cgroup.
+	CgroupRoot string
+	// CgroupDriver names the cgroups of the containers of the cgroupfs and none backends, see cgroupfs.CgroupDrivers.
+	// Empty is auto.
+	CgroupDriver string
+	// PrewarmClaims watches the pods bound to the node to pre-compute the placements of their grouped mode
+	// claims before the kubelet prepares them.
+	PrewarmClaims bool
+	// AllocationJournalPath is the file of the node the allocation decisions are appended to, rotated at
+	// AllocationJournalMaxSize bytes, zero is DefaultAllocationJournalMaxSize. Empty disables it.
+	AllocationJournalPath    string
+	AllocationJournalMaxSize int64
+	// RequireCPULimits fails to prepare the claims consumed by containers whose cpu limits differ from
+	// their cpu requests, so the pinned containers aren't throttled and keep the Guaranteed QoS class.
+	RequireCPULimits bool
+	// AllocationSnapshotPath is the file the claim allocations are written to on shutdown. After a reboot,
+	// their CPUs are held for the claims the kubelet prepares again. Empty disables it.
+	AllocationSnapshotPath string
 }
 
 // Start creates and starts a new CPUDriver.
 func Start(ctx context.Context, clientset kubernetes.Interface, config *Config) (*CPUDriver, error) {
-	plugin := &CPUDriver{
-		driverName:             config.DriverName,
-		nodeName:               config.NodeName,
-		kubeClient:             clientset,
-		deviceNameToCPUID:      make(map[string]int),
-		deviceNameToSocketID:   make(map[string]int),
-		deviceNameToNUMANodeID: make(map[string]int),
-		reservedCPUs:           config.ReservedCPUs,
-		cpuDeviceMode:          config.CpuDeviceMode,
-		cpuDeviceGroupBy:       config.CPUDeviceGroupBy,
-		claimTracker:           store.NewClaimTracker(),
+	// all the loggers derived from ctx, including the ones of the kubelet RPCs, carry the node name.
+	ctx = klog.NewContext(ctx, klog.LoggerWithValues(klog.FromContext(ctx), "node", config.NodeName))
+	nodeConfigBase := *config
+	if config.NodeConfigName != "" {
+		nodeConfig, err := loadNodeConfig(ctx, clientset, nodeConfigBase)
+		if err != nil {
+			return nil, err
+		}
+		config = &nodeConfig
 	}
-	cpuInfoProvider := cpuinfo.NewSystemCPUInfo()
-	topo, err := cpuInfoProvider.GetCPUTopology()
+	plugin, err := newCPUDriver(ctx, clientset, config, nodeConfigBase)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get CPU topology: %w", err)
-	}
-	if topo == nil {
-		return nil, fmt.Errorf("failed to get CPU topology: topology is nil")
+		return nil, err
 	}
-	plugin.cpuTopology = topo
-	plugin.cpuAllocationStore = store.NewCPUAllocation(plugin.cpuTopology, config.ReservedCPUs)
-	plugin.podConfigStore = store.NewPodConfig()
+
+	eventBroadcaster := record.NewBroadcaster(record.WithContext(ctx))
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientset.CoreV1().Events("")})
+	plugin.eventRecorder = eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: config.DriverName, Host: config.NodeName})
 
 	driverPluginPath := filepath.Join(kubeletPluginPath, config.DriverName)
 	if err := os.MkdirAll(driverPluginPath, 0750); err != nil {
 		return nil, fmt.Errorf("failed to create plugin path %s: %w", driverPluginPath, err)
 	}
 
+	publishClient := clientset
+	if config.PublishClientConfig != nil {
+		publishConfig := rest.CopyConfig(config.PublishClientConfig)
+		publishConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &resourceSliceWriteObserver{cp: plugin, next: rt}
+		})
+		if publishClient, err = kubernetes.NewForConfig(publishConfig); err != nil {
+			return nil, fmt.Errorf("failed to create the ResourceSlice client: %w", err)
+		}
+	}
 	kubeletOpts := []kubeletplugin.Option{
 		kubeletplugin.DriverName(config.DriverName),
 		kubeletplugin.NodeName(config.NodeName),
-		kubeletplugin.KubeClient(clientset),
+		kubeletplugin.KubeClient(publishClient),
 	}
 	d, err := kubeletplugin.Start(ctx, plugin, kubeletOpts...)
 	if err != nil {
@@ -153,57 +361,267 @@ func Start(ctx context.Context, clientset kubernetes.Interface, config *Config)
 		return nil, err
 	}
 
-	cdiMgr, err := NewCdiManager(config.DriverName)
+	cdiMgr, err := NewCdiManager(klog.FromContext(ctx), config.DriverName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create CDI manager: %w", err)
 	}
 	plugin.cdiMgr = cdiMgr
 
-	// register the NRI plugin
+	logger := klog.FromContext(ctx)
+	if publishOnly {
+		logger.Info("NRI is not available, running in publish-only mode: claims are accounted for but containers are not pinned to their CPUs", "os", runtime.GOOS)
+		plugin.enforcementBackend = ENFORCEMENT_BACKEND_NONE
+	} else if err := plugin.startEnforcementBackend(ctx); err != nil {
+		return nil, err
+	}
+
+	if plugin.kubeletCheckpointPath != "" {
+		// the first sync must complete before publishing, so we never advertise CPUs pinned by the kubelet.
+		if _, _, err := plugin.syncKubeletCheckpoint(logger); err != nil {
+			return nil, fmt.Errorf("failed to sync kubelet CPU Manager checkpoint: %w", err)
+		}
+		plugin.startController(ctx, "kubelet-checkpoint", plugin.resyncKubeletCheckpoint, kubeletCheckpointSyncPeriod)
+	}
+
+	if len(plugin.nfdLabels) > 0 {
+		// NFD may not have labeled the node yet, the labels are picked up by the periodic sync.
+		if _, err := plugin.syncNFDAttributes(ctx); err != nil {
+			logger.Error(err, "Failed to sync the node-feature-discovery labels of the node")
+		}
+		plugin.startController(ctx, "nfd", plugin.resyncNFDAttributes, nfdSyncPeriod)
+	}
+
+	if plugin.extendedResourceName != "" {
+		plugin.startController(ctx, "extended-resource", plugin.resyncExtendedResource, extendedResourceSyncPeriod)
+	}
+
+	if plugin.orphanedClaimTTL > 0 {
+		plugin.startController(ctx, "orphaned-claims", func(ctx context.Context) {
+			plugin.cleanupOrphanedClaims(ctx, time.Now())
+		}, orphanedClaimsCheckPeriod)
+	}
+
+	if plugin.restored != nil {
+		plugin.startController(ctx, "restored-claims", func(ctx context.Context) {
+			plugin.expireRestoredClaims(ctx, time.Now())
+		}, restoredClaimsCheckPeriod)
+	}
+
+	if plugin.podFailureThreshold > 0 {
+		plugin.startController(ctx, "failed-pods", func(ctx context.Context) {
+			plugin.releaseFailedPodClaims(ctx, time.Now())
+		}, failedPodsCheckPeriod)
+	}
+
+	if config.NodeConfigName != "" {
+		plugin.startController(ctx, "node-config", plugin.resyncNodeConfig, nodeConfigSyncPeriod)
+	}
+
+	if config.PrewarmClaims && plugin.cpuDeviceMode == CPU_DEVICE_MODE_GROUPED {
+		plugin.placements = newPlacementCache()
+		plugin.startClaimPrewarmer(ctx)
+	}
+
+	if plugin.cpuHealthCheckPeriod > 0 {
+		plugin.startController(ctx, "cpu-health", plugin.checkCPUHealth, plugin.cpuHealthCheckPeriod)
+	}
+
+	// publish available resources
+	plugin.startPublisher(ctx, cmp.Or(config.PublishQPS, DefaultPublishQPS), cmp.Or(config.PublishBurst, DefaultPublishBurst), config.PublishResyncPeriod)
+	plugin.requestPublish(ctx)
+
+	plugin.startController(ctx, "node-status", func(ctx context.Context) {
+		plugin.updateNodeStatus(ctx, time.Now())
+	}, nodeStatusUpdatePeriod)
+	plugin.startController(ctx, "capacity-hint", plugin.resyncCapacityHint, nodeStatusUpdatePeriod)
+
+	if plugin.chaos != nil {
+		logger.Info("Chaos mode enabled, do not use in production", "probability", config.ChaosProbability)
+		go wait.UntilWithContext(ctx, plugin.verifyInvariants, invariantCheckPeriod)
+	}
+
+	return plugin, nil
+}
+
+// newCPUDriver creates the driver of the configuration with the topology of the node, without starting it.
+func newCPUDriver(ctx context.Context, clientset kubernetes.Interface, config *Config, nodeConfigBase Config) (*CPUDriver, error) {
+	plugin := &CPUDriver{
+		driverName:               config.DriverName,
+		nodeName:                 config.NodeName,
+		kubeClient:               clientset,
+		deviceNameToCPUID:        make(map[string]int),
+		deviceNameToSocketID:     make(map[string]int),
+		deviceNameToNUMANodeID:   make(map[string]int),
+		reservedCPUs:             config.ReservedCPUs,
+		cpuDeviceMode:            config.CpuDeviceMode,
+		cpuDeviceGroupBy:         config.CPUDeviceGroupBy,
+		confineToNUMANode:        config.ConfineToNUMANode,
+		allocationStrategy:       config.AllocationStrategy,
+		kubeletCheckpointPath:    config.KubeletCheckpointPath,
+		claimTracker:             store.NewClaimTracker(),
+		orphanedClaimTTL:         config.OrphanedClaimTTL,
+		orphanedClaims:           make(map[types.UID]time.Time),
+		cpuHealthCheckPeriod:     config.CPUHealthCheckPeriod,
+		replaceDegradedClaims:    config.ReplaceDegradedClaims,
+		readCPUHealthCounters:    cpuinfo.ReadCPUHealthCounters,
+		driverVersion:            config.DriverVersion,
+		nfdLabels:                config.NFDLabels,
+		extendedResourceName:     config.ExtendedResourceName,
+		chaos:                    newChaosInjector(config.ChaosProbability),
+		sandboxedRuntimeHandlers: config.SandboxedRuntimeHandlers,
+		sandboxedRuntimePolicy:   config.SandboxedRuntimePolicy,
+		requireCPULimits:         config.RequireCPULimits,
+		allocationSnapshotPath:   config.AllocationSnapshotPath,
+		smtIsolation:             config.SMTIsolation,
+		cpuPools:                 config.CPUPools,
+		podFailureThreshold:      config.PodFailureThreshold,
+		failingClaims:            make(map[types.UID]time.Time),
+		releasedClaims:           make(map[types.UID]store.ClaimAllocation),
+		nodeConfigBase:           nodeConfigBase,
+		restartCh:                make(chan string, 1),
+		dynamicClient:            config.DynamicClient,
+		publishNodeStatus:        config.PublishNodeStatus,
+		reportAllocations:        config.ReportAllocations,
+		enforcementBackend:       cmp.Or(config.EnforcementBackend, ENFORCEMENT_BACKEND_NRI),
+		cgroupRoot:               config.CgroupRoot,
+	}
+	cgroupDriver, err := cgroupfs.NewCgroupDriver(config.CgroupDriver)
+	if err != nil {
+		return nil, err
+	}
+	plugin.cgroupDriver = cgroupDriver
+	if config.ResctrlPath != "" {
+		resctrlMgr, err := resctrl.New(config.ResctrlPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up the cache allocation: %w", err)
+		}
+		plugin.resctrl = resctrlMgr
+		if resctrlMgr.Capabilities().L3Monitoring {
+			prometheus.MustRegister(&claimMonitoringCollector{cp: plugin, logger: klog.FromContext(ctx)})
+		}
+	}
+	if config.AllocationJournalPath != "" {
+		plugin.journal, err = openAllocationJournal(config.AllocationJournalPath, cmp.Or(config.AllocationJournalMaxSize, DefaultAllocationJournalMaxSize))
+		if err != nil {
+			return nil, err
+		}
+	}
+	cpuInfoProvider := cpuinfo.NewSystemCPUInfo()
+	topo, err := cpuInfoProvider.GetCPUTopology()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CPU topology: %w", err)
+	}
+	if topo == nil {
+		return nil, fmt.Errorf("failed to get CPU topology: topology is nil")
+	}
+	plugin.cpuTopology = topo
+	if config.HousekeepingCPUs != "" {
+		plugin.housekeepingCPUs, err = resolveHousekeepingCPUs(topo, config.HousekeepingCPUs)
+		if err != nil {
+			return nil, err
+		}
+		klog.FromContext(ctx).Info("Housekeeping CPUs", "cpus", plugin.housekeepingCPUs.String())
+	}
+	plugin.cpuAllocationStore = store.NewCPUAllocation(plugin.cpuTopology, config.ReservedCPUs)
+	plugin.podConfigStore = store.NewPodConfig()
+	if plugin.allocationSnapshotPath != "" {
+		// a snapshot that can't be restored only costs the placements of the claims.
+		if err := plugin.restoreAllocationSnapshot(klog.FromContext(ctx), plugin.allocationSnapshotPath); err != nil {
+			klog.FromContext(ctx).Error(err, "Failed to restore the allocation snapshot")
+		}
+	}
+	return plugin, nil
+}
+
+// startEnforcementBackend starts pinning the containers to their CPUs with the enforcement backend.
+func (cp *CPUDriver) startEnforcementBackend(ctx context.Context) error {
+	logger := klog.FromContext(ctx)
+	cgroupfsRuntime := cgroupfs.New(cp.cgroupRoot, cp.cgroupDriver)
+	if cp.enforcementBackend == ENFORCEMENT_BACKEND_AUTO {
+		var reason string
+		cp.enforcementBackend, reason = detectEnforcementBackend(hostfs.OS{}, api.DefaultSocketPath, cgroupfsRuntime)
+		logger.Info("Detected the enforcement backend", "backend", cp.enforcementBackend, "reason", reason)
+	}
+	switch cp.enforcementBackend {
+	case ENFORCEMENT_BACKEND_NONE:
+		logger.Info("Enforcement backend none: claims are accounted for but containers are not pinned to their CPUs")
+		if err := cgroupfsRuntime.Available(); err != nil {
+			logger.Error(err, "Can't verify the CPUs of the containers")
+			return nil
+		}
+		cp.startController(ctx, "pinning-verification", func(ctx context.Context) {
+			cp.verifyPinning(ctx, cgroupfsRuntime)
+		}, pinningVerificationPeriod)
+		return nil
+	case ENFORCEMENT_BACKEND_CGROUPFS:
+		if err := cgroupfsRuntime.Available(); err != nil {
+			return fmt.Errorf("cgroupfs enforcement backend: %w", err)
+		}
+		return cp.startCgroupfsBackend(ctx, cgroupfsRuntime)
+	default:
+		return cp.startNRIPlugin(ctx, cp.driverName)
+	}
+}
+
+// startNRIPlugin registers the NRI plugin pinning the containers and keeps it running.
+func (cp *CPUDriver) startNRIPlugin(ctx context.Context, driverName string) error {
+	logger := klog.FromContext(ctx)
 	nriOpts := []stub.Option{
-		stub.WithPluginName(config.DriverName),
+		stub.WithPluginName(driverName),
 		stub.WithPluginIdx("00"),
 		// https://github.com/containerd/nri/pull/173
 		// Otherwise it silently exits the program
 		stub.WithOnClose(func() {
-			klog.Infof("%s NRI plugin closed", config.DriverName)
+			logger.Info("NRI plugin closed", "plugin", driverName)
 		}),
 	}
-	stub, err := stub.New(plugin, nriOpts...)
+	stub, err := stub.New(cp, nriOpts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create plugin stub: %w", err)
+		return fmt.Errorf("failed to create plugin stub: %w", err)
+	}
+	cp.nriPlugin = stub
+	if cp.chaos != nil {
+		cp.nriPlugin = &chaosNRIStub{Stub: stub, chaos: cp.chaos, logger: logger}
 	}
-	plugin.nriPlugin = stub
 
 	go func() {
 		for i := 0; i < maxAttempts; i++ {
-			err = plugin.nriPlugin.Run(ctx)
-			if err != nil {
-				klog.Infof("NRI plugin failed with error %v", err)
+			if err := cp.nriPlugin.Run(ctx); err != nil {
+				logger.Error(err, "NRI plugin failed")
 			}
 			select {
 			case <-ctx.Done():
 				return
 			default:
-				klog.Infof("Restarting NRI plugin %d out of %d", i, maxAttempts)
+				logger.Info("Restarting the NRI plugin", "attempt", i, "maxAttempts", maxAttempts)
 			}
 		}
-		klog.Fatalf("NRI plugin failed for %d times to be restarted", maxAttempts)
+		logger.Error(nil, "NRI plugin failed to be restarted", "attempts", maxAttempts)
+		klog.FlushAndExit(klog.ExitFlushTimeout, 1)
 	}()
-
-	// publish available resources
-	go plugin.PublishResources(ctx)
-
-	return plugin, nil
+	return nil
 }
 
 // Stop stops the CPUDriver.
 func (cp *CPUDriver) Stop() {
-	cp.nriPlugin.Stop()
+	logger := klog.LoggerWithValues(klog.Background(), "node", cp.nodeName)
+	if cp.nriPlugin != nil {
+		cp.nriPlugin.Stop()
+	}
 	cp.draPlugin.Stop()
+	if cp.allocationSnapshotPath != "" {
+		if err := cp.writeAllocationSnapshot(logger, cp.allocationSnapshotPath); err != nil {
+			logger.Error(err, "Failed to write the allocation snapshot")
+		}
+	}
+	if cp.journal != nil {
+		if err := cp.journal.close(); err != nil {
+			logger.Error(err, "Failed to close the allocation journal")
+		}
+	}
 }
 
 // Shutdown is called when the runtime is shutting down.
-func (cp *CPUDriver) Shutdown(_ context.Context) {
-	klog.Info("Runtime shutting down...")
+func (cp *CPUDriver) Shutdown(ctx context.Context) {
+	klog.FromContext(ctx).Info("Runtime shutting down")
 }
@@ -0,0 +1,280 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/containerd/nri/pkg/api"
+	nrilog "github.com/containerd/nri/pkg/log"
+	"github.com/containerd/nri/pkg/stub"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cgroupfs"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/hostfs"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+const (
+	// ENFORCEMENT_BACKEND_AUTO picks nri if the runtime serves the NRI socket, cgroupfs if the cpuset
+	// controller and the container bundles are available, none otherwise.
+	ENFORCEMENT_BACKEND_AUTO = "auto"
+	// ENFORCEMENT_BACKEND_NRI pins the containers through the NRI plugin of the container runtime.
+	ENFORCEMENT_BACKEND_NRI = "nri"
+	// ENFORCEMENT_BACKEND_CGROUPFS finds the containers in the OCI bundles of the runtime and writes
+	// the cpuset of their cgroups directly.
+	ENFORCEMENT_BACKEND_CGROUPFS = "cgroupfs"
+	// ENFORCEMENT_BACKEND_NONE accounts for the claims without pinning the containers, and verifies
+	// whether something else pinned them to the CPUs of their claims.
+	ENFORCEMENT_BACKEND_NONE = "none"
+)
+
+// cgroupfsPollPeriod is how often the cgroupfs backend looks for the started and stopped containers.
+// The containers run on all the CPUs of their cgroup until they are found.
+const cgroupfsPollPeriod = time.Second
+
+// detectEnforcementBackend resolves the auto enforcement backend from the capabilities of the node.
+func detectEnforcementBackend(fsys hostfs.FS, nriSocketPath string, runtime containerRuntime) (string, string) {
+	info, err := fsys.Stat(nriSocketPath)
+	if err == nil && info.Mode()&os.ModeSocket != 0 {
+		return ENFORCEMENT_BACKEND_NRI, fmt.Sprintf("%s available", nriSocketPath)
+	}
+	if err := runtime.Available(); err != nil {
+		return ENFORCEMENT_BACKEND_NONE, fmt.Sprintf("%s not available and can't use the cgroupfs: %v", nriSocketPath, err)
+	}
+	return ENFORCEMENT_BACKEND_CGROUPFS, fmt.Sprintf("%s not available", nriSocketPath)
+}
+
+// containerRuntime lists the containers of the node and sets their CPUs, see cgroupfs.Runtime.
+type containerRuntime interface {
+	Available() error
+	ListContainers() ([]cgroupfs.Container, error)
+	ReadCPUs(ctr cgroupfs.Container) (cpuset.CPUSet, error)
+	SetCPUs(ctr cgroupfs.Container, cpus string) error
+}
+
+// cgroupfsStub stands in for the NRI plugin of the cgroupfs backend: it polls the runtime for the
+// started and stopped containers, passes them to the NRI handlers of the driver, and applies the
+// adjustments and updates they return to the cgroups of the containers.
+type cgroupfsStub struct {
+	cp      *CPUDriver
+	runtime containerRuntime
+
+	// mu protects containers, the running containers known to the driver by ID.
+	mu           sync.Mutex
+	containers   map[string]cgroupfs.Container
+	synchronized bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var _ stub.Stub = &cgroupfsStub{}
+
+func newCgroupfsStub(cp *CPUDriver, runtime containerRuntime) *cgroupfsStub {
+	return &cgroupfsStub{cp: cp, runtime: runtime, containers: map[string]cgroupfs.Container{}}
+}
+
+// startCgroupfsBackend starts pinning the containers through the cgroupfs.
+func (cp *CPUDriver) startCgroupfsBackend(ctx context.Context, runtime containerRuntime) error {
+	s := newCgroupfsStub(cp, runtime)
+	cp.nriPlugin = s
+	if cp.chaos != nil {
+		cp.nriPlugin = &chaosNRIStub{Stub: s, chaos: cp.chaos, logger: klog.FromContext(ctx)}
+	}
+	return s.Start(ctx)
+}
+
+// Run starts polling the containers and waits until Stop is called or ctx is done.
+func (s *cgroupfsStub) Run(ctx context.Context) error {
+	if err := s.Start(ctx); err != nil {
+		return err
+	}
+	s.Wait()
+	return nil
+}
+
+// Start starts polling the containers.
+func (s *cgroupfsStub) Start(ctx context.Context) error {
+	if s.done != nil {
+		return errors.New("cgroupfs backend already started")
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		wait.UntilWithContext(ctx, s.sync, cgroupfsPollPeriod)
+	}()
+	return nil
+}
+
+// Stop stops polling the containers.
+func (s *cgroupfsStub) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// Wait waits for the polling to stop.
+func (s *cgroupfsStub) Wait() {
+	if s.done != nil {
+		<-s.done
+	}
+}
+
+func (s *cgroupfsStub) RegistrationTimeout() time.Duration {
+	return stub.DefaultRegistrationTimeout
+}
+
+func (s *cgroupfsStub) RequestTimeout() time.Duration {
+	return stub.DefaultRequestTimeout
+}
+
+func (s *cgroupfsStub) Logger() nrilog.Logger {
+	return nrilog.Get()
+}
+
+// UpdateContainers writes the CPUs of the updates, it returns the updates that failed.
+func (s *cgroupfsStub) UpdateContainers(updates []*api.ContainerUpdate) ([]*api.ContainerUpdate, error) {
+	var failed []*api.ContainerUpdate
+	var errs []error
+	for _, update := range updates {
+		cpus := update.GetLinux().GetResources().GetCpu().GetCpus()
+		if cpus == "" {
+			continue
+		}
+		s.mu.Lock()
+		ctr, ok := s.containers[update.GetContainerId()]
+		s.mu.Unlock()
+		if !ok {
+			// The container stopped since the update was computed.
+			continue
+		}
+		if err := s.runtime.SetCPUs(ctr, cpus); err != nil {
+			failed = append(failed, update)
+			errs = append(errs, err)
+		}
+	}
+	return failed, errors.Join(errs...)
+}
+
+// sync passes the containers started and stopped since the previous poll to the NRI handlers, the
+// stopped ones first so their CPUs go back to the shared pool before the new ones are pinned.
+func (s *cgroupfsStub) sync(ctx context.Context) {
+	logger := klog.FromContext(ctx)
+	containers, err := s.runtime.ListContainers()
+	if err != nil {
+		logger.Error(err, "Failed to list the containers")
+		return
+	}
+	running := make(map[string]cgroupfs.Container, len(containers))
+	for _, ctr := range containers {
+		running[ctr.ID] = ctr
+	}
+
+	if !s.synchronized {
+		pods, ctrs := nriPodsAndContainers(containers)
+		if _, err := s.cp.Synchronize(ctx, pods, ctrs); err != nil {
+			logger.Error(err, "Failed to synchronize the containers")
+			return
+		}
+		s.mu.Lock()
+		s.containers, s.synchronized = running, true
+		s.mu.Unlock()
+		return
+	}
+
+	s.mu.Lock()
+	var stopped []cgroupfs.Container
+	for id, ctr := range s.containers {
+		if _, ok := running[id]; !ok {
+			stopped = append(stopped, ctr)
+		}
+	}
+	s.mu.Unlock()
+	for _, ctr := range stopped {
+		updates, err := s.cp.StopContainer(ctx, nriPod(ctr), nriContainer(ctr))
+		s.mu.Lock()
+		delete(s.containers, ctr.ID)
+		s.mu.Unlock()
+		if err != nil {
+			logger.Error(err, "Failed to stop the container", "pod", klog.KRef(ctr.PodNamespace, ctr.PodName), "podUID", ctr.PodUID, "container", ctr.Name, "containerID", ctr.ID)
+			continue
+		}
+		s.apply(logger, updates)
+	}
+
+	for _, ctr := range containers {
+		s.mu.Lock()
+		_, known := s.containers[ctr.ID]
+		s.mu.Unlock()
+		if known {
+			continue
+		}
+		adjust, updates, err := s.cp.CreateContainer(ctx, nriPod(ctr), nriContainer(ctr))
+		s.mu.Lock()
+		// Even if it fails, the container is already running and isn't retried.
+		s.containers[ctr.ID] = ctr
+		s.mu.Unlock()
+		if err != nil {
+			logger.Error(err, "Failed to pin the container", "pod", klog.KRef(ctr.PodNamespace, ctr.PodName), "podUID", ctr.PodUID, "container", ctr.Name, "containerID", ctr.ID)
+			continue
+		}
+		if cpus := adjust.GetLinux().GetResources().GetCpu().GetCpus(); cpus != "" {
+			if err := s.runtime.SetCPUs(ctr, cpus); err != nil {
+				logger.Error(err, "Failed to pin the container", "pod", klog.KRef(ctr.PodNamespace, ctr.PodName), "podUID", ctr.PodUID, "container", ctr.Name, "containerID", ctr.ID, "cpus", cpus)
+			}
+		}
+		s.apply(logger, updates)
+	}
+}
+
+func (s *cgroupfsStub) apply(logger klog.Logger, updates []*api.ContainerUpdate) {
+	if len(updates) == 0 {
+		return
+	}
+	if _, err := s.UpdateContainers(updates); err != nil {
+		logger.Error(err, "Failed to update the containers")
+	}
+}
+
+// nriPodsAndContainers converts the containers to the pods and containers passed to the NRI handlers.
+func nriPodsAndContainers(containers []cgroupfs.Container) ([]*api.PodSandbox, []*api.Container) {
+	var pods []*api.PodSandbox
+	var ctrs []*api.Container
+	for _, ctr := range containers {
+		if !slices.ContainsFunc(pods, func(pod *api.PodSandbox) bool { return pod.Id == ctr.SandboxID }) {
+			pods = append(pods, nriPod(ctr))
+		}
+		ctrs = append(ctrs, nriContainer(ctr))
+	}
+	return pods, ctrs
+}
+
+func nriPod(ctr cgroupfs.Container) *api.PodSandbox {
+	return &api.PodSandbox{Id: ctr.SandboxID, Uid: ctr.PodUID, Name: ctr.PodName, Namespace: ctr.PodNamespace}
+}
+
+func nriContainer(ctr cgroupfs.Container) *api.Container {
+	return &api.Container{Id: ctr.ID, PodSandboxId: ctr.SandboxID, Name: ctr.Name, Env: ctr.Env}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cgroupfs"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/hostfs"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/cpuset"
+)
+
+// fakeContainerRuntime records the CPUs written for each container, read back as their effective CPUs.
+type fakeContainerRuntime struct {
+	available  error
+	containers []cgroupfs.Container
+	cpus       map[string]string
+}
+
+func (f *fakeContainerRuntime) Available() error {
+	return f.available
+}
+
+func (f *fakeContainerRuntime) ListContainers() ([]cgroupfs.Container, error) {
+	return f.containers, nil
+}
+
+func (f *fakeContainerRuntime) ReadCPUs(ctr cgroupfs.Container) (cpuset.CPUSet, error) {
+	cpus, ok := f.cpus[ctr.ID]
+	if !ok {
+		return cpuset.New(), fs.ErrNotExist
+	}
+	return cpuset.Parse(cpus)
+}
+
+func (f *fakeContainerRuntime) SetCPUs(ctr cgroupfs.Container, cpus string) error {
+	f.cpus[ctr.ID] = cpus
+	return nil
+}
+
+func TestDetectEnforcementBackend(t *testing.T) {
+	fake := hostfs.NewFake(nil)
+	runtime := &fakeContainerRuntime{}
+
+	backend, _ := detectEnforcementBackend(fake, "/var/run/nri/nri.sock", runtime)
+	require.Equal(t, ENFORCEMENT_BACKEND_CGROUPFS, backend)
+
+	runtime.available = errors.New("cpuset controller not available")
+	backend, reason := detectEnforcementBackend(fake, "/var/run/nri/nri.sock", runtime)
+	require.Equal(t, ENFORCEMENT_BACKEND_NONE, backend)
+	require.Contains(t, reason, "cpuset controller not available")
+
+	fake.Add("/var/run/nri/nri.sock", &fstest.MapFile{Mode: fs.ModeSocket})
+	backend, _ = detectEnforcementBackend(fake, "/var/run/nri/nri.sock", runtime)
+	require.Equal(t, ENFORCEMENT_BACKEND_NRI, backend)
+}
+
+func TestCgroupfsStubSync(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, err := mockProvider.GetCPUTopology()
+	require.NoError(t, err)
+	driver := &CPUDriver{
+		podConfigStore:     store.NewPodConfig(),
+		cpuAllocationStore: store.NewCPUAllocation(topo, cpuset.New()),
+		claimTracker:       store.NewClaimTracker(),
+		cpuTopology:        topo,
+	}
+	shared := cgroupfs.Container{ID: "shared", Name: "app", SandboxID: "sandbox-1", PodUID: "pod-uid-1", PodName: "pod-1", PodNamespace: "ns"}
+	guaranteed := cgroupfs.Container{
+		ID: "guaranteed", Name: "app", SandboxID: "sandbox-2", PodUID: "pod-uid-2", PodName: "pod-2", PodNamespace: "ns",
+		Env: []string{cdiEnvVarPrefix + "_claim-1=0-1"},
+	}
+	runtime := &fakeContainerRuntime{containers: []cgroupfs.Container{shared}, cpus: map[string]string{}}
+	s := newCgroupfsStub(driver, runtime)
+
+	// The first poll synchronizes the state of the driver with the running containers.
+	s.sync(context.Background())
+	require.Empty(t, runtime.cpus)
+	require.Equal(t, []types.UID{"shared"}, driver.podConfigStore.GetContainersWithSharedCPUs())
+
+	// The claim is prepared, then its container is started.
+	driver.cpuAllocationStore.AddResourceClaimAllocation("claim-1", cpuset.New(0, 1))
+	runtime.containers = append(runtime.containers, guaranteed)
+	s.sync(context.Background())
+	require.Equal(t, map[string]string{"guaranteed": "0-1", "shared": "2-7"}, runtime.cpus)
+
+	// Known containers are not pinned again.
+	runtime.cpus = map[string]string{}
+	s.sync(context.Background())
+	require.Empty(t, runtime.cpus)
+
+	// The container stops, then its claim is unprepared.
+	runtime.containers = []cgroupfs.Container{shared}
+	s.sync(context.Background())
+	require.Equal(t, map[string]string{"shared": "2-7"}, runtime.cpus)
+	require.Nil(t, driver.podConfigStore.GetContainerState("pod-uid-2", "app"))
+
+	driver.cpuAllocationStore.RemoveResourceClaimAllocation("claim-1")
+	failed, err := s.UpdateContainers(driver.getSharedContainerUpdates(""))
+	require.NoError(t, err)
+	require.Empty(t, failed)
+	require.Equal(t, map[string]string{"shared": "0-7"}, runtime.cpus)
+}
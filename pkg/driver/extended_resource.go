@@ -0,0 +1,73 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// extendedResourceSyncPeriod is how often the free CPUs are mirrored into the node extended resource.
+const extendedResourceSyncPeriod = 10 * time.Second
+
+// syncExtendedResource mirrors the CPUs claims can get into the node extended resource, for schedulers,
+// autoscalers and quota systems not aware of DRA: the capacity is the number of CPUs the driver publishes,
+// the allocatable the number of those CPUs not allocated to claims. The node is only patched on changes,
+// comparing with the node status so the values are restored if the kubelet resets them.
+func (cp *CPUDriver) syncExtendedResource(ctx context.Context) error {
+	name := corev1.ResourceName(cp.extendedResourceName)
+	capacity := *resource.NewQuantity(int64(cp.cpuAllocationStore.GetAllocatableCPUs().Size()), resource.DecimalSI)
+	allocatable := *resource.NewQuantity(int64(cp.cpuAllocationStore.GetSharedCPUs().Size()), resource.DecimalSI)
+
+	node, err := cp.kubeClient.CoreV1().Nodes().Get(ctx, cp.nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get node: %w", err)
+	}
+	if current, ok := node.Status.Capacity[name]; ok && current.Equal(capacity) {
+		if current, ok := node.Status.Allocatable[name]; ok && current.Equal(allocatable) {
+			return nil
+		}
+	}
+	patch, err := json.Marshal(map[string]any{
+		"status": map[string]any{
+			"capacity":    corev1.ResourceList{name: capacity},
+			"allocatable": corev1.ResourceList{name: allocatable},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := cp.kubeClient.CoreV1().Nodes().PatchStatus(ctx, cp.nodeName, patch); err != nil {
+		return fmt.Errorf("failed to patch node status: %w", err)
+	}
+	klog.FromContext(ctx).V(4).Info("Extended resource of the node set", "resource", name, "capacity", capacity.String(), "allocatable", allocatable.String())
+	return nil
+}
+
+// resyncExtendedResource is the periodic extended resource sync, logging the failures.
+func (cp *CPUDriver) resyncExtendedResource(ctx context.Context) {
+	if err := cp.syncExtendedResource(ctx); err != nil {
+		klog.FromContext(ctx).Error(err, "Failed to sync the extended resource of the node", "resource", cp.extendedResourceName)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/cpuset"
+)
+
+func TestSyncExtendedResource(t *testing.T) {
+	kubeClient := fake.NewClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: testNodeName},
+		Status: corev1.NodeStatus{
+			Capacity:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+			Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+		},
+	})
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_4CPUS_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cp := &CPUDriver{
+		driverName:           testDriverName,
+		nodeName:             testNodeName,
+		kubeClient:           kubeClient,
+		extendedResourceName: "dra.cpu/exclusive-cpus",
+		cpuAllocationStore:   store.NewCPUAllocation(topo, cpuset.New(0)),
+	}
+	ctx := context.Background()
+	getResources := func() (corev1.ResourceList, corev1.ResourceList) {
+		t.Helper()
+		node, err := kubeClient.CoreV1().Nodes().Get(ctx, testNodeName, metav1.GetOptions{})
+		require.NoError(t, err)
+		return node.Status.Capacity, node.Status.Allocatable
+	}
+
+	require.NoError(t, cp.syncExtendedResource(ctx))
+	capacity, allocatable := getResources()
+	require.Equal(t, corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4"), "dra.cpu/exclusive-cpus": resource.MustParse("3")}, capacity)
+	require.Equal(t, corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4"), "dra.cpu/exclusive-cpus": resource.MustParse("3")}, allocatable)
+
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-1", cpuset.New(1, 3))
+	require.NoError(t, cp.syncExtendedResource(ctx))
+	capacity, allocatable = getResources()
+	require.Equal(t, resource.MustParse("3"), capacity["dra.cpu/exclusive-cpus"])
+	require.Equal(t, resource.MustParse("1"), allocatable["dra.cpu/exclusive-cpus"])
+
+	// unchanged, the node is not patched again
+	kubeClient.ClearActions()
+	require.NoError(t, cp.syncExtendedResource(ctx))
+	for _, action := range kubeClient.Actions() {
+		require.NotEqual(t, "patch", action.GetVerb())
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+// eventReasonClaimForceReleased is recorded on claims released by an administrator.
+const eventReasonClaimForceReleased = "ClaimForceReleased"
+
+// ErrClaimNotPrepared is returned when force releasing a claim the driver has not prepared.
+var ErrClaimNotPrepared = errors.New("claim not prepared on this node")
+
+// ForceReleaseClaim releases a prepared claim the kubelet can't unprepare, e.g. when it is wedged in the
+// middle of an unprepare: the CPUs of the claim go back to the shared pool, the running container using
+// the claim is moved to the CPUs of its other claims, or to the shared CPUs, and the CDI device of the
+// claim, from which the driver restores its claims on restart, is removed. It returns the released CPUs.
+func (cp *CPUDriver) ForceReleaseClaim(ctx context.Context, claimUID types.UID) (cpuset.CPUSet, error) {
+	logger := klog.FromContext(ctx).WithValues("claimUID", claimUID)
+
+	released := false
+	cpus, prepared := cp.cpuAllocationStore.GetResourceClaimAllocation(claimUID)
+	info := cp.cpuAllocationStore.GetResourceClaimInfos()[claimUID]
+	cp.releasedClaimsMu.Lock()
+	if allocation, ok := cp.releasedClaims[claimUID]; ok {
+		cpus, info, released = allocation.CPUs, allocation.ClaimInfo, true
+		delete(cp.releasedClaims, claimUID)
+	}
+	cp.releasedClaimsMu.Unlock()
+	if !prepared && !released && !cp.hasCDIDevice(claimUID) {
+		return cpuset.New(), ErrClaimNotPrepared
+	}
+
+	logger.Info("Force releasing claim", "claim", klog.KRef(info.Namespace, info.Name), "cpus", cpus.String())
+	var errs []error
+	if err := cp.releaseCacheAllocation(claimUID); err != nil {
+		errs = append(errs, fmt.Errorf("failed to release the cache allocation: %w", err))
+	}
+	cp.journalRelease(logger, JOURNAL_EVENT_FORCE_RELEASE, claimUID, info.Namespace, info.Name, cpus)
+	cp.cpuAllocationStore.RemoveResourceClaimAllocation(claimUID)
+	if err := cp.cdiMgr.RemoveDevice(getCDIDeviceName(claimUID)); err != nil {
+		errs = append(errs, fmt.Errorf("failed to remove the CDI device: %w", err))
+	}
+
+	updates := []*api.ContainerUpdate{}
+	if owner, ok := cp.claimTracker.FindOwner(logger, claimUID); ok {
+		if state := cp.podConfigStore.GetContainerState(owner.PodUID, owner.ContainerName); state != nil {
+			otherClaimUIDs := []types.UID{}
+			containerCPUs := cpuset.New()
+			for _, uid := range state.ResourceClaimUIDs() {
+				if uid == claimUID {
+					continue
+				}
+				otherClaimUIDs = append(otherClaimUIDs, uid)
+				otherCPUs, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(uid)
+				containerCPUs = containerCPUs.Union(otherCPUs)
+			}
+			// a container left without claims is a shared CPU container, updated with the shared CPUs below.
+			cp.podConfigStore.SetContainerState(owner.PodUID, store.NewContainerState(owner.ContainerName, state.ContainerUID(), otherClaimUIDs...))
+			if len(otherClaimUIDs) > 0 {
+				update := &api.ContainerUpdate{ContainerId: string(state.ContainerUID())}
+				update.SetLinuxCPUSetCPUs(containerCPUs.String())
+				updates = append(updates, update)
+			}
+		}
+	}
+	cp.claimTracker.Cleanup(logger, claimUID)
+	updates = append(updates, cp.getSharedContainerUpdates("")...)
+	if len(updates) > 0 {
+		if _, err := cp.nriPlugin.UpdateContainers(updates); err != nil {
+			errs = append(errs, fmt.Errorf("failed to update the containers: %w", err))
+		}
+	}
+
+	claimsForceReleased.Inc()
+	if info.Name != "" {
+		cp.eventRecorder.Eventf(claimReference(claimUID, info.Namespace, info.Name), corev1.EventTypeWarning, eventReasonClaimForceReleased,
+			"CPUs %s force released on node %s by an administrator", cpus.String(), cp.nodeName)
+	}
+	return cpus, errors.Join(errs...)
+}
+
+// hasCDIDevice returns true if the CDI spec has the device of the claim.
+func (cp *CPUDriver) hasCDIDevice(claimUID types.UID) bool {
+	spec, err := cp.cdiMgr.GetSpec()
+	if err != nil {
+		return false
+	}
+	deviceName := getCDIDeviceName(claimUID)
+	for _, device := range spec.Devices {
+		if device.Name == deviceName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+func TestForceReleaseClaim(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	nriStub := &fakeNRIStub{}
+	cdiMgr := newMockCdiMgr()
+	recorder := record.NewFakeRecorder(10)
+	cp := &CPUDriver{
+		nodeName:           testNodeName,
+		cpuAllocationStore: store.NewCPUAllocation(topo, cpuset.New()),
+		podConfigStore:     store.NewPodConfig(),
+		claimTracker:       store.NewClaimTracker(),
+		cdiMgr:             cdiMgr,
+		nriPlugin:          nriStub,
+		eventRecorder:      recorder,
+		releasedClaims:     make(map[types.UID]store.ClaimAllocation),
+	}
+	logger := klog.Background()
+	// the container of the pod uses claim-1 and claim-2, claim-3 is already released on pod failure.
+	for claimUID, cpus := range map[types.UID]cpuset.CPUSet{"claim-1": cpuset.New(0, 4), "claim-2": cpuset.New(1, 5)} {
+		cp.cpuAllocationStore.AddResourceClaimAllocation(claimUID, cpus)
+		cp.cpuAllocationStore.SetResourceClaimInfo(claimUID, store.ClaimInfo{Namespace: "ns", Name: string(claimUID)})
+		_ = cdiMgr.AddDevice(getCDIDeviceName(claimUID), "")
+		require.NoError(t, cp.claimTracker.SetOwner(logger, claimUID, "pod-uid", "ctr"))
+	}
+	cp.podConfigStore.SetContainerState("pod-uid", store.NewContainerState("ctr", "ctr-id", "claim-1", "claim-2"))
+	cp.podConfigStore.SetContainerState("shared-pod", store.NewContainerState("shared-ctr", "shared-ctr-id"))
+	cp.releasedClaims["claim-3"] = store.ClaimAllocation{ClaimUID: "claim-3", ClaimInfo: store.ClaimInfo{Namespace: "ns", Name: "claim-3"}, CPUs: cpuset.New(2)}
+	_ = cdiMgr.AddDevice(getCDIDeviceName("claim-3"), "")
+
+	cpus, err := cp.ForceReleaseClaim(context.Background(), "claim-1")
+	require.NoError(t, err)
+	require.True(t, cpus.Equals(cpuset.New(0, 4)), "released CPUs %s", cpus.String())
+	_, allocated := cp.cpuAllocationStore.GetResourceClaimAllocation("claim-1")
+	require.False(t, allocated)
+	require.NotContains(t, cdiMgr.devices, getCDIDeviceName("claim-1"))
+	_, owned := cp.claimTracker.FindOwner(logger, "claim-1")
+	require.False(t, owned)
+	require.Equal(t, []types.UID{"claim-2"}, cp.podConfigStore.GetContainerState("pod-uid", "ctr").ResourceClaimUIDs())
+	require.Len(t, nriStub.updates, 2)
+	require.Equal(t, "ctr-id", nriStub.updates[0].ContainerId)
+	require.Equal(t, "1,5", nriStub.updates[0].Linux.Resources.Cpu.Cpus)
+	require.Equal(t, "shared-ctr-id", nriStub.updates[1].ContainerId)
+	require.Equal(t, "0,2-4,6-7", nriStub.updates[1].Linux.Resources.Cpu.Cpus)
+	require.Len(t, recorder.Events, 1)
+
+	// the last claim of the container turns it into a shared CPU container.
+	nriStub.updates = nil
+	_, err = cp.ForceReleaseClaim(context.Background(), "claim-2")
+	require.NoError(t, err)
+	require.Empty(t, cp.podConfigStore.GetContainerState("pod-uid", "ctr").ResourceClaimUIDs())
+	require.ElementsMatch(t, []string{"ctr-id", "shared-ctr-id"}, []string{nriStub.updates[0].ContainerId, nriStub.updates[1].ContainerId})
+
+	cpus, err = cp.ForceReleaseClaim(context.Background(), "claim-3")
+	require.NoError(t, err)
+	require.True(t, cpus.Equals(cpuset.New(2)))
+	require.Empty(t, cp.releasedClaims)
+	require.Empty(t, cdiMgr.devices)
+
+	_, err = cp.ForceReleaseClaim(context.Background(), "claim-unknown")
+	require.ErrorIs(t, err, ErrClaimNotPrepared)
+	require.Len(t, recorder.Events, 3)
+}
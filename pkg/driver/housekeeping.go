@@ -0,0 +1,61 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"fmt"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"k8s.io/utils/cpuset"
+)
+
+// HOUSEKEEPING_CPUS_FIRST_CORE_PER_NUMA makes the CPUs of the first physical core of each NUMA node,
+// the one with the lowest CPU ID, the housekeeping CPUs.
+const HOUSEKEEPING_CPUS_FIRST_CORE_PER_NUMA = "first-core-per-numa"
+
+// housekeepingCPUTaintKey is the key of the taint added to the individual devices of the housekeeping CPUs.
+const housekeepingCPUTaintKey = "dra.cpu/housekeeping"
+
+// resolveHousekeepingCPUs returns the housekeeping CPUs of the topology: the CPUs of the first core of
+// each NUMA node for HOUSEKEEPING_CPUS_FIRST_CORE_PER_NUMA, else the CPUs of the cpuset. Empty is none.
+func resolveHousekeepingCPUs(topo *cpuinfo.CPUTopology, housekeepingCPUs string) (cpuset.CPUSet, error) {
+	if housekeepingCPUs != HOUSEKEEPING_CPUS_FIRST_CORE_PER_NUMA {
+		cpus, err := cpuset.Parse(housekeepingCPUs)
+		if err != nil {
+			return cpuset.New(), fmt.Errorf("invalid housekeeping CPUs %q, must be a cpuset or %s: %w", housekeepingCPUs, HOUSEKEEPING_CPUS_FIRST_CORE_PER_NUMA, err)
+		}
+		if unknown := cpus.Difference(topo.CPUDetails.CPUs()); !unknown.IsEmpty() {
+			return cpuset.New(), fmt.Errorf("housekeeping CPUs %s are not CPUs of the node", unknown.String())
+		}
+		return cpus, nil
+	}
+	cpus := cpuset.New()
+	for _, numaNodeID := range topo.CPUDetails.NUMANodes().List() {
+		first := topo.CPUDetails.CPUsInNUMANodes(numaNodeID).List()[0]
+		cpus = cpus.Union(cpuset.New(first))
+		if sibling := topo.CPUDetails[first].SiblingCpuID; sibling >= 0 {
+			cpus = cpus.Union(cpuset.New(sibling))
+		}
+	}
+	return cpus, nil
+}
+
+// capacityExcludedCPUs are the CPUs left out of the capacity of the grouped devices: the reserved
+// CPUs, and the housekeeping CPUs only the claims with the useHousekeepingCPUs parameter get.
+func (cp *CPUDriver) capacityExcludedCPUs() cpuset.CPUSet {
+	return cp.reservedCPUs.Union(cp.housekeepingCPUs)
+}
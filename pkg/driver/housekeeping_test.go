@@ -0,0 +1,138 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+func TestResolveHousekeepingCPUs(t *testing.T) {
+	// NUMA node 0 has the cores 0 (CPUs 0,4) and 1 (CPUs 1,5), NUMA node 1 the cores 2 (CPUs 2,6) and 3 (CPUs 3,7).
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+
+	cpus, err := resolveHousekeepingCPUs(topo, HOUSEKEEPING_CPUS_FIRST_CORE_PER_NUMA)
+	require.NoError(t, err)
+	require.Equal(t, "0,2,4,6", cpus.String())
+
+	cpus, err = resolveHousekeepingCPUs(topo, "0,4")
+	require.NoError(t, err)
+	require.Equal(t, "0,4", cpus.String())
+
+	_, err = resolveHousekeepingCPUs(topo, "0,8")
+	require.ErrorContains(t, err, "not CPUs of the node")
+	_, err = resolveHousekeepingCPUs(topo, "core-0")
+	require.Error(t, err)
+}
+
+func TestHousekeepingCPUsGroupedMode(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+
+	testCases := []struct {
+		name          string
+		opaqueConfig  []string
+		devices       map[string]int64
+		expectedCPUs  cpuset.CPUSet
+		expectedError bool
+	}{
+		{
+			name:         "housekeeping core left out",
+			devices:      map[string]int64{"cpudevnuma000": 2},
+			expectedCPUs: cpuset.New(1, 5),
+		},
+		{
+			name:          "not enough CPUs without the housekeeping core",
+			devices:       map[string]int64{"cpudevnuma000": 3},
+			expectedError: true,
+		},
+		{
+			name:         "claim using the housekeeping CPUs",
+			opaqueConfig: []string{`{"useHousekeepingCPUs": true}`},
+			devices:      map[string]int64{"cpudevnuma000": 4},
+			expectedCPUs: cpuset.New(0, 1, 4, 5),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cp := &CPUDriver{
+				driverName:             testDriverName,
+				cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+				cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+				cpuTopology:            topo,
+				deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0, "cpudevnuma001": 1},
+				cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+				cdiMgr:                 newMockCdiMgr(),
+				housekeepingCPUs:       cpuset.New(0, 4),
+			}
+
+			devices := cp.createGroupedCPUDeviceSlices(klog.Background())[0]
+			require.Len(t, devices, 2)
+			capacity := devices[0].Capacity[cpuResourceQualifiedName].Value
+			require.Equal(t, int64(2), capacity.Value())
+			require.Equal(t, 6, cp.newCapacityHint().CPUs)
+
+			claim := withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, tc.devices), testDriverName, tc.opaqueConfig...)
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			if tc.expectedError {
+				require.Error(t, results[claim.UID].Err)
+				return
+			}
+			require.NoError(t, results[claim.UID].Err)
+			cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+			require.True(t, ok)
+			require.Equal(t, tc.expectedCPUs.String(), cpus.String())
+		})
+	}
+}
+
+func TestHousekeepingCPUsIndividualMode(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cp := &CPUDriver{
+		driverName:         testDriverName,
+		cpuDeviceMode:      CPU_DEVICE_MODE_INDIVIDUAL,
+		cpuTopology:        topo,
+		deviceNameToCPUID:  make(map[string]int),
+		cpuAllocationStore: store.NewCPUAllocation(topo, cpuset.New()),
+		housekeepingCPUs:   cpuset.New(0, 4),
+	}
+
+	taints := map[int][]resourceapi.DeviceTaint{}
+	for _, device := range cp.createCPUDeviceSlices(klog.Background())[0] {
+		taints[cp.deviceNameToCPUID[device.Name]] = device.Taints
+	}
+	require.Len(t, taints, 8)
+	for cpuID, deviceTaints := range taints {
+		if cpuID == 0 || cpuID == 4 {
+			require.Equal(t, []resourceapi.DeviceTaint{{Key: housekeepingCPUTaintKey, Effect: resourceapi.DeviceTaintEffectNoSchedule}}, deviceTaints)
+			continue
+		}
+		require.Empty(t, deviceTaints, "CPU %d", cpuID)
+	}
+	require.Equal(t, 8, cp.newCapacityHint().CPUs, "individual housekeeping CPUs are still published")
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
+	"k8s.io/klog/v2"
+)
+
+// syncKubeletCheckpoint reads the kubelet CPU Manager checkpoint and records the CPUs it exclusively
+// assigned to Guaranteed pods not using claims, so they are excluded from the driver allocatable pool.
+// A missing checkpoint is treated as empty: the kubelet may not have written it yet.
+// It returns the checkpoint and whether the set of kubelet exclusive CPUs changed.
+// The outcome is recorded in the driver status reported on the node.
+func (cp *CPUDriver) syncKubeletCheckpoint(logger klog.Logger) (*cpumanager.KubeletCheckpoint, bool, error) {
+	checkpoint, changed, err := cp.readKubeletCheckpoint(logger)
+	cp.recordCheckpointSync(err)
+	return checkpoint, changed, err
+}
+
+// readKubeletCheckpoint does the work of syncKubeletCheckpoint without recording the outcome. It also
+// records the shared pool of the kubelet, published in the kubeletShared attribute of the CPU devices.
+func (cp *CPUDriver) readKubeletCheckpoint(logger klog.Logger) (*cpumanager.KubeletCheckpoint, bool, error) {
+	checkpoint, err := cpumanager.ReadKubeletCheckpoint(cp.kubeletCheckpointPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, false, err
+		}
+		logger.V(4).Info("Kubelet CPU Manager checkpoint not found", "path", cp.kubeletCheckpointPath)
+		checkpoint = &cpumanager.KubeletCheckpoint{}
+	}
+	exclusiveCPUs, err := checkpoint.ExclusiveCPUs()
+	if err != nil {
+		return nil, false, err
+	}
+	sharedCPUs, ok, err := checkpoint.SharedCPUs()
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		// without a shared pool, the containers of the kubelet can run on any CPU.
+		sharedCPUs = cp.cpuTopology.CPUDetails.CPUs()
+	}
+	exclusiveChanged := cp.cpuAllocationStore.SetKubeletExclusiveCPUs(logger, exclusiveCPUs)
+	sharedChanged := cp.cpuAllocationStore.SetKubeletSharedCPUs(logger, sharedCPUs)
+	return checkpoint, exclusiveChanged || sharedChanged, nil
+}
+
+// resyncKubeletCheckpoint periodically picks up the CPUs the kubelet CPU Manager assigned or released
+// and propagates the change to the published ResourceSlices and to the shared pool containers.
+func (cp *CPUDriver) resyncKubeletCheckpoint(ctx context.Context) {
+	logger := klog.FromContext(ctx)
+	_, changed, err := cp.syncKubeletCheckpoint(logger)
+	if err != nil {
+		logger.Error(err, "Failed to sync kubelet CPU Manager checkpoint")
+		return
+	}
+	if !changed {
+		return
+	}
+	cp.requestPublish(ctx)
+	updates := cp.getSharedContainerUpdates("")
+	if len(updates) == 0 {
+		return
+	}
+	if _, err := cp.nriPlugin.UpdateContainers(updates); err != nil {
+		logger.Error(err, "Failed to update the shared containers after the kubelet CPU Manager checkpoint change")
+	}
+}
+
+// isKubeletPinned returns true if the kubelet CPU Manager exclusively assigned CPUs to the container.
+// Such containers are left untouched by the NRI plugin.
+func isKubeletPinned(logger klog.Logger, checkpoint *cpumanager.KubeletCheckpoint, podUID, containerName string) bool {
+	if checkpoint == nil {
+		return false
+	}
+	cpus, ok, err := checkpoint.ContainerCPUs(podUID, containerName)
+	if err != nil {
+		logger.Error(err, "Invalid kubelet CPU Manager checkpoint entry")
+		return false
+	}
+	if ok {
+		logger.Info("Container is pinned by the kubelet CPU Manager", "podUID", podUID, "container", containerName, "cpus", cpus.String())
+	}
+	return ok
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+func writeKubeletCheckpoint(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "cpu_manager_state")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestSyncKubeletCheckpoint(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+
+	testCases := []struct {
+		name               string
+		path               string
+		expectedError      bool
+		expectedChanged    bool
+		expectedCPUs       cpuset.CPUSet
+		expectedSharedCPUs cpuset.CPUSet
+	}{
+		{
+			name:               "exclusive assignments",
+			path:               writeKubeletCheckpoint(t, `{"policyName":"static","defaultCpuSet":"0-1,4-7","entries":{"pod-uid-1":{"ctr-1":"2-3"}},"checksum":1}`),
+			expectedChanged:    true,
+			expectedCPUs:       cpuset.New(2, 3),
+			expectedSharedCPUs: cpuset.New(0, 1, 4, 5, 6, 7),
+		},
+		{
+			name:               "CPUs carved out of the kubelet shared pool",
+			path:               writeKubeletCheckpoint(t, `{"policyName":"static","defaultCpuSet":"0-3","checksum":1}`),
+			expectedChanged:    true,
+			expectedCPUs:       cpuset.New(),
+			expectedSharedCPUs: cpuset.New(0, 1, 2, 3),
+		},
+		{
+			name:               "none policy",
+			path:               writeKubeletCheckpoint(t, `{"policyName":"none","defaultCpuSet":"","checksum":1}`),
+			expectedCPUs:       cpuset.New(),
+			expectedSharedCPUs: topo.CPUDetails.CPUs(),
+		},
+		{
+			name:               "missing checkpoint",
+			path:               filepath.Join(t.TempDir(), "cpu_manager_state"),
+			expectedCPUs:       cpuset.New(),
+			expectedSharedCPUs: topo.CPUDetails.CPUs(),
+		},
+		{
+			name:               "malformed checkpoint",
+			path:               writeKubeletCheckpoint(t, `{"policyName"`),
+			expectedError:      true,
+			expectedCPUs:       cpuset.New(),
+			expectedSharedCPUs: topo.CPUDetails.CPUs(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cp := &CPUDriver{
+				kubeletCheckpointPath: tc.path,
+				cpuTopology:           topo,
+				cpuAllocationStore:    store.NewCPUAllocation(topo, cpuset.New()),
+			}
+			_, changed, err := cp.syncKubeletCheckpoint(klog.Background())
+			if tc.expectedError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expectedChanged, changed)
+			require.True(t, tc.expectedCPUs.Equals(cp.cpuAllocationStore.GetKubeletExclusiveCPUs()))
+			require.True(t, tc.expectedSharedCPUs.Equals(cp.cpuAllocationStore.GetKubeletSharedCPUs()))
+		})
+	}
+}
+
+func TestCreateContainerKubeletPinned(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	pod := &api.PodSandbox{Id: "pod-id-1", Name: "my-pod", Namespace: "my-ns", Uid: "pod-uid-1"}
+
+	podConfigStore := store.NewPodConfig()
+	podConfigStore.SetContainerState("shared-pod-1", store.NewContainerState("shared-ctr-1", "shared-uid-1"))
+	cp := &CPUDriver{
+		kubeletCheckpointPath: writeKubeletCheckpoint(t, `{"policyName":"static","defaultCpuSet":"0-5","entries":{"pod-uid-1":{"pinned-ctr":"6-7"}},"checksum":1}`),
+		podConfigStore:        podConfigStore,
+		cpuAllocationStore:    store.NewCPUAllocation(topo, cpuset.New()),
+		claimTracker:          store.NewClaimTracker(),
+	}
+
+	// the container pinned by the kubelet is left alone, and the shared pool shrinks
+	pinned := &api.Container{Id: "pinned-id", PodSandboxId: pod.Id, Name: "pinned-ctr"}
+	adjust, updates, err := cp.CreateContainer(context.Background(), pod, pinned)
+	require.NoError(t, err)
+	require.Equal(t, &api.ContainerAdjustment{}, adjust)
+	require.Equal(t, []*api.ContainerUpdate{
+		{
+			ContainerId: "shared-uid-1",
+			Linux:       &api.LinuxContainerUpdate{Resources: &api.LinuxResources{Cpu: &api.LinuxCPU{Cpus: "0-5"}}},
+		},
+	}, updates)
+	require.Nil(t, cp.podConfigStore.GetContainerState("pod-uid-1", "pinned-ctr"))
+
+	// other containers of the same pod are still confined to the shared pool
+	shared := &api.Container{Id: "shared-id", PodSandboxId: pod.Id, Name: "shared-ctr"}
+	adjust, _, err = cp.CreateContainer(context.Background(), pod, shared)
+	require.NoError(t, err)
+	require.Equal(t, &api.ContainerAdjustment{
+		Linux: &api.LinuxContainerAdjustment{Resources: &api.LinuxResources{Cpu: &api.LinuxCPU{Cpus: "0-5"}}},
+	}, adjust)
+	require.NotNil(t, cp.podConfigStore.GetContainerState("pod-uid-1", "shared-ctr"))
+}
+
+func TestPublishResourcesKubeletExclusiveCPUs(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	kubeletCPUs := cpuset.New(2, 6)
+
+	newDriver := func(mode string) (*CPUDriver, *mockKubeletPlugin) {
+		mockPlugin := &mockKubeletPlugin{}
+		cp := &CPUDriver{
+			nodeName:               testNodeName,
+			draPlugin:              mockPlugin,
+			cpuTopology:            topo,
+			cpuDeviceMode:          mode,
+			cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+			deviceNameToCPUID:      make(map[string]int),
+			deviceNameToSocketID:   make(map[string]int),
+			deviceNameToNUMANodeID: make(map[string]int),
+			cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+		}
+		return cp, mockPlugin
+	}
+
+	publishedDevices := func(plugin *mockKubeletPlugin) []resourceapi.Device {
+		var devices []resourceapi.Device
+		for _, slice := range plugin.publishedResources.Pools[testNodeName].Slices {
+			devices = append(devices, slice.Devices...)
+		}
+		return devices
+	}
+
+	t.Run("individual mode keeps device names stable", func(t *testing.T) {
+		cp, plugin := newDriver(CPU_DEVICE_MODE_INDIVIDUAL)
+		cp.PublishResources(context.Background())
+		before := make(map[string]int)
+		for name, cpuID := range cp.deviceNameToCPUID {
+			before[name] = cpuID
+		}
+		require.Len(t, publishedDevices(plugin), 8)
+
+		cp.cpuAllocationStore.SetKubeletExclusiveCPUs(klog.Background(), kubeletCPUs)
+		cp.deviceNameToCPUID = make(map[string]int)
+		cp.PublishResources(context.Background())
+		devices := publishedDevices(plugin)
+		require.Len(t, devices, 6)
+		for _, device := range devices {
+			cpuID := cp.deviceNameToCPUID[device.Name]
+			require.False(t, kubeletCPUs.Contains(cpuID), "CPU %d pinned by the kubelet was published", cpuID)
+			require.Equal(t, before[device.Name], cpuID, "device %s changed CPU", device.Name)
+		}
+	})
+
+	t.Run("individual mode marks the kubelet shared CPUs", func(t *testing.T) {
+		cp, plugin := newDriver(CPU_DEVICE_MODE_INDIVIDUAL)
+		cp.PublishResources(context.Background())
+		for _, device := range publishedDevices(plugin) {
+			require.NotContains(t, device.Attributes, resourceapi.QualifiedName("dra.cpu/kubeletShared"))
+		}
+
+		cp.kubeletCheckpointPath = "/var/lib/kubelet/cpu_manager_state"
+		cp.cpuAllocationStore.SetKubeletSharedCPUs(klog.Background(), cpuset.New(0, 1, 4, 5))
+		cp.PublishResources(context.Background())
+		for _, device := range publishedDevices(plugin) {
+			cpuID := cp.deviceNameToCPUID[device.Name]
+			require.Equal(t, cpuID < 2 || cpuID == 4 || cpuID == 5, *device.Attributes["dra.cpu/kubeletShared"].BoolValue, "CPU %d", cpuID)
+		}
+	})
+
+	t.Run("grouped mode reduces the capacity", func(t *testing.T) {
+		cp, plugin := newDriver(CPU_DEVICE_MODE_GROUPED)
+		cp.cpuAllocationStore.SetKubeletExclusiveCPUs(klog.Background(), kubeletCPUs)
+		cp.PublishResources(context.Background())
+		capacities := make(map[string]int64)
+		for _, device := range publishedDevices(plugin) {
+			quantity := device.Capacity[cpuResourceQualifiedName].Value
+			capacities[device.Name] = quantity.Value()
+		}
+		require.Equal(t, map[string]int64{"cpudevnuma000": 4, "cpudevnuma001": 2}, capacities)
+	})
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"github.com/containerd/nri/pkg/api"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+// bindsMemoryNodes returns true if the containers of a grouped mode claim placed with the strategy get
+// their memory from the NUMA nodes of their CPUs only, so the bandwidth of every memory controller the
+// CPUs are spread on is used, instead of the memory being allocated wherever the kernel finds room.
+func (cp *CPUDriver) bindsMemoryNodes(strategy cpumanager.Strategy) bool {
+	return cp.cpuDeviceMode == CPU_DEVICE_MODE_GROUPED && strategy.Name() == cpumanager.StrategySpreadNUMA
+}
+
+// adjustMemoryNodes sets the cpuset.mems of a container to the NUMA nodes of its guaranteed CPUs
+// when one of its claims binds them.
+func (cp *CPUDriver) adjustMemoryNodes(logger klog.Logger, adjust *api.ContainerAdjustment, claimAllocations map[types.UID]cpuset.CPUSet) {
+	infos := cp.cpuAllocationStore.GetResourceClaimInfos()
+	guaranteedCPUs := cpuset.New()
+	bind := false
+	for uid, cpus := range claimAllocations {
+		guaranteedCPUs = guaranteedCPUs.Union(cpus)
+		bind = bind || infos[uid].BindMemoryNodes
+	}
+	if !bind {
+		return
+	}
+	memoryNodes := cp.cpuTopology.CPUDetails.KeepOnly(guaranteedCPUs).NUMANodes()
+	logger.Info("Binding the memory of the container to the NUMA nodes of its CPUs", "memoryNodes", memoryNodes.String())
+	adjust.SetLinuxCPUSetMems(memoryNodes.String())
+}
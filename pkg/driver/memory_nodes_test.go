@@ -0,0 +1,109 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/utils/cpuset"
+)
+
+func TestPrepareResourceClaimsSpreadNUMA(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+
+	testCases := []struct {
+		name               string
+		allocationStrategy string
+		opaqueConfig       []string
+		expectedCPUs       cpuset.CPUSet
+		expectedBind       bool
+	}{
+		{
+			name:         "packed by default",
+			expectedCPUs: cpuset.New(0, 1, 4, 5),
+		},
+		{
+			name:         "spread-numa strategy of the claim",
+			opaqueConfig: []string{fmt.Sprintf(`{"strategy": %q}`, cpumanager.StrategySpreadNUMA)},
+			expectedCPUs: cpuset.New(0, 2, 4, 6),
+			expectedBind: true,
+		},
+		{
+			name:               "spread-numa default strategy",
+			allocationStrategy: cpumanager.StrategySpreadNUMA,
+			expectedCPUs:       cpuset.New(0, 2, 4, 6),
+			expectedBind:       true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cp := &CPUDriver{
+				driverName:          testDriverName,
+				cpuDeviceMode:       CPU_DEVICE_MODE_GROUPED,
+				cpuDeviceGroupBy:    GROUP_BY_NODE,
+				nodeDevicePublished: true,
+				allocationStrategy:  tc.allocationStrategy,
+				cpuTopology:         topo,
+				cpuAllocationStore:  store.NewCPUAllocation(topo, cpuset.New()),
+				cdiMgr:              newMockCdiMgr(),
+			}
+			claim := withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, map[string]int64{cpuDeviceNodeGroupedName: 4}), testDriverName, tc.opaqueConfig...)
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			require.NoError(t, results[claim.UID].Err)
+			cpus, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+			require.True(t, tc.expectedCPUs.Equals(cpus), "expected %s, got %s", tc.expectedCPUs, cpus)
+			require.Equal(t, tc.expectedBind, cp.cpuAllocationStore.GetResourceClaimInfos()[claim.UID].BindMemoryNodes)
+		})
+	}
+}
+
+func TestCreateContainerMemoryNodes(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cp := &CPUDriver{
+		cpuTopology:        topo,
+		cpuAllocationStore: store.NewCPUAllocation(topo, cpuset.New()),
+		podConfigStore:     store.NewPodConfig(),
+		claimTracker:       store.NewClaimTracker(),
+	}
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-spread", cpuset.New(0, 2))
+	cp.cpuAllocationStore.SetResourceClaimInfo("claim-spread", store.ClaimInfo{Namespace: "ns", Name: "spread", BindMemoryNodes: true})
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-plain", cpuset.New(1))
+	cp.cpuAllocationStore.SetResourceClaimInfo("claim-plain", store.ClaimInfo{Namespace: "ns", Name: "plain"})
+
+	pod := &api.PodSandbox{Id: "pod-id", Name: "pod", Namespace: "ns", Uid: "pod-uid"}
+	ctr := &api.Container{Id: "ctr-id-1", PodSandboxId: pod.Id, Name: "spread", Env: []string{fmt.Sprintf("%s_%s=%s", cdiEnvVarPrefix, "claim-spread", "0,2")}}
+	adjust, _, err := cp.CreateContainer(context.Background(), pod, ctr)
+	require.NoError(t, err)
+	require.Equal(t, "0-1", adjust.Linux.Resources.Cpu.Mems)
+
+	ctr = &api.Container{Id: "ctr-id-2", PodSandboxId: pod.Id, Name: "plain", Env: []string{fmt.Sprintf("%s_%s=%s", cdiEnvVarPrefix, "claim-plain", "1")}}
+	adjust, _, err = cp.CreateContainer(context.Background(), pod, ctr)
+	require.NoError(t, err)
+	require.Empty(t, adjust.Linux.Resources.Cpu.Mems)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "dra_cpu"
+
+var (
+	orphanedClaims = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "orphaned_claims",
+		Help:      "Number of prepared claims whose consumer pods no longer exist and are waiting for the TTL to expire.",
+	})
+	orphanedClaimsReleased = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "orphaned_claims_released_total",
+		Help:      "Number of orphaned prepared claims whose CPUs were released by the driver.",
+	})
+	claimsForceReleased = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "claims_force_released_total",
+		Help:      "Number of prepared claims released by an administrator through the admin endpoint.",
+	})
+	degradedCPUs = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "degraded_cpus",
+		Help:      "Number of CPUs marked degraded because of machine check exceptions or thermal throttling.",
+	})
+	invariantViolations = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "invariant_violations_total",
+		Help:      "Number of violations of the CPU allocation invariants found in chaos mode.",
+	})
+	smtIsolationViolations = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "smt_isolation_violations_total",
+		Help:      "Number of claims which failed to prepare because they could only get CPUs sharing physical cores with other claims under the SMT isolation.",
+	})
+	pinningVerifiedContainers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "pinning_verified_containers",
+		Help:      "Number of containers of claims running on the CPUs of their claims (result=match) or not (result=mismatch) at the last verification of the none enforcement backend.",
+	}, []string{"result"})
+	publishDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "publish_duration_seconds",
+		Help:      "Time of the API server to write a ResourceSlice of the node.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 8),
+	})
+	publishConflicts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "publish_conflicts_total",
+		Help:      "Number of ResourceSlice writes of the node which hit a conflict and were retried.",
+	})
+	publishCoalesced = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "publish_coalesced_total",
+		Help:      "Number of ResourceSlice publication requests coalesced with a pending one.",
+	})
+	claimPhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "claim_phase_duration_seconds",
+		Help:      "Time the claims prepared by the driver spent in each phase: from their creation to their allocation by the scheduler (phase=allocate), from their allocation to the end of their prepare (phase=prepare), and from their prepare to the creation of their first container (phase=start).",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 14),
+	}, []string{"phase"})
+	prewarmedPlacements = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "prewarmed_placements_total",
+		Help:      "Number of device placements pre-computed before the prepare of their claim which were used (result=hit) or recomputed because the free CPUs changed since (result=stale).",
+	}, []string{"result"})
+	restoredClaimPlacements = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "restored_claim_placements_total",
+		Help:      "Number of device placements of the claims restored from the allocation snapshot after a reboot which got the CPUs of the claim before the reboot back (result=reused) or new CPUs (result=moved), and of such claims whose CPUs were released because the kubelet didn't prepare them again in time (result=expired).",
+	}, []string{"result"})
+)
+
+func init() {
+	prometheus.MustRegister(orphanedClaims, orphanedClaimsReleased, claimsForceReleased, degradedCPUs, invariantViolations, smtIsolationViolations, pinningVerifiedContainers, publishDuration, publishConflicts, publishCoalesced, claimPhaseDuration, prewarmedPlacements, restoredClaimPlacements)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// nfdLabelDomain is the domain of the node labels published by node-feature-discovery, also used
+	// as the domain of the device attributes they are republished as.
+	nfdLabelDomain = "feature.node.kubernetes.io"
+	// nfdSyncPeriod is how often the node labels published by node-feature-discovery are re-read.
+	nfdSyncPeriod = 1 * time.Minute
+)
+
+// attributeIDRegexp matches the valid device attribute IDs, a C identifier of at most 32 characters.
+var attributeIDRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,31}$`)
+
+// nfdAttributeID converts the name of a node-feature-discovery label to a device attribute ID,
+// e.g. cpu-cpuid.AVX512F to cpu_cpuid_AVX512F. It returns false if the result is not a valid ID.
+func nfdAttributeID(labelName string) (resourceapi.QualifiedName, bool) {
+	id := strings.NewReplacer(".", "_", "-", "_").Replace(labelName)
+	if !attributeIDRegexp.MatchString(id) {
+		return "", false
+	}
+	return resourceapi.QualifiedName(nfdLabelDomain + "/" + id), true
+}
+
+// nfdAttributes converts the node-feature-discovery labels of the node with the given names, e.g.
+// cpu-model.vendor_id, to device attributes. Boolean and integer values keep their type.
+// Labels missing on the node, which is how node-feature-discovery reports absent CPU features, are skipped.
+func nfdAttributes(logger klog.Logger, labels map[string]string, labelNames []string) map[resourceapi.QualifiedName]resourceapi.DeviceAttribute {
+	attributes := map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{}
+	for _, labelName := range labelNames {
+		value, ok := labels[nfdLabelDomain+"/"+labelName]
+		if !ok {
+			continue
+		}
+		id, ok := nfdAttributeID(labelName)
+		if !ok {
+			logger.Info("Node-feature-discovery label can't be converted to a device attribute, skipped", "label", labelName)
+			continue
+		}
+		if value == "true" || value == "false" {
+			boolValue := value == "true"
+			attributes[id] = resourceapi.DeviceAttribute{BoolValue: &boolValue}
+		} else if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+			attributes[id] = resourceapi.DeviceAttribute{IntValue: &intValue}
+		} else {
+			attributes[id] = resourceapi.DeviceAttribute{StringValue: &value}
+		}
+	}
+	return attributes
+}
+
+// syncNFDAttributes reads the node-feature-discovery labels of the node and records the device attributes
+// they are republished as. It returns whether the attributes changed.
+func (cp *CPUDriver) syncNFDAttributes(ctx context.Context) (bool, error) {
+	node, err := cp.kubeClient.CoreV1().Nodes().Get(ctx, cp.nodeName, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	attributes := nfdAttributes(klog.FromContext(ctx), node.Labels, cp.nfdLabels)
+	cp.devicesMu.Lock()
+	defer cp.devicesMu.Unlock()
+	if cp.nfdAttributes != nil && equality.Semantic.DeepEqual(attributes, cp.nfdAttributes) {
+		return false, nil
+	}
+	cp.nfdAttributes = attributes
+	return true, nil
+}
+
+// resyncNFDAttributes periodically picks up the changes of the node-feature-discovery labels,
+// which are usually published after the driver started, and republishes the devices.
+func (cp *CPUDriver) resyncNFDAttributes(ctx context.Context) {
+	changed, err := cp.syncNFDAttributes(ctx)
+	if err != nil {
+		klog.FromContext(ctx).Error(err, "Failed to sync the node-feature-discovery labels of the node")
+		return
+	}
+	if changed {
+		cp.requestPublish(ctx)
+	}
+}
+
+// addNFDAttributes adds the node-feature-discovery attributes to a device, within the limit of attributes
+// and capacities of a device. It must be called with devicesMu held.
+func (cp *CPUDriver) addNFDAttributes(logger klog.Logger, device *resourceapi.Device) {
+	for _, id := range slices.Sorted(maps.Keys(cp.nfdAttributes)) {
+		if len(device.Attributes)+len(device.Capacity) >= resourceapi.ResourceSliceMaxAttributesAndCapacitiesPerDevice {
+			logger.V(4).Info("Device has too many attributes, node-feature-discovery attribute skipped", "device", device.Name, "attribute", id)
+			continue
+		}
+		device.Attributes[id] = cp.nfdAttributes[id]
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+	"k8s.io/utils/ptr"
+)
+
+func TestNFDAttributes(t *testing.T) {
+	labels := map[string]string{
+		"feature.node.kubernetes.io/cpu-cpuid.AVX512F":                 "true",
+		"feature.node.kubernetes.io/cpu-model.family":                  "6",
+		"feature.node.kubernetes.io/cpu-model.vendor_id":               "Intel",
+		"feature.node.kubernetes.io/cpu-security.sgx.enabled":          "false",
+		"feature.node.kubernetes.io/cpu-hardware_multithreading.extra": "true",
+		"kubernetes.io/hostname":                                       "node-1",
+	}
+	attributes := nfdAttributes(klog.Background(), labels, []string{
+		"cpu-cpuid.AVX512F",
+		"cpu-model.family",
+		"cpu-model.vendor_id",
+		"cpu-security.sgx.enabled",
+		// absent feature
+		"cpu-cpuid.AMXTILE",
+		// longer than 32 characters once converted
+		"cpu-hardware_multithreading.extra",
+	})
+	require.Equal(t, map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{
+		"feature.node.kubernetes.io/cpu_cpuid_AVX512F":        {BoolValue: ptr.To(true)},
+		"feature.node.kubernetes.io/cpu_model_family":         {IntValue: ptr.To[int64](6)},
+		"feature.node.kubernetes.io/cpu_model_vendor_id":      {StringValue: ptr.To("Intel")},
+		"feature.node.kubernetes.io/cpu_security_sgx_enabled": {BoolValue: ptr.To(false)},
+	}, attributes)
+}
+
+func TestPublishResourcesNFDAttributes(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_4CPUS_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: testNodeName}}
+
+	for _, mode := range []string{CPU_DEVICE_MODE_GROUPED, CPU_DEVICE_MODE_INDIVIDUAL} {
+		t.Run(mode, func(t *testing.T) {
+			kubeClient := fake.NewClientset(node.DeepCopy())
+			mockPlugin := &mockKubeletPlugin{}
+			cp := &CPUDriver{
+				nodeName:               testNodeName,
+				kubeClient:             kubeClient,
+				draPlugin:              mockPlugin,
+				cpuTopology:            topo,
+				cpuDeviceMode:          mode,
+				cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+				deviceNameToCPUID:      make(map[string]int),
+				deviceNameToSocketID:   make(map[string]int),
+				deviceNameToNUMANodeID: make(map[string]int),
+				cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+				nfdLabels:              []string{"cpu-cpuid.AMXTILE"},
+			}
+			ctx := context.Background()
+			hasAMX := func() bool {
+				for _, slice := range mockPlugin.publishedResources.Pools[testNodeName].Slices {
+					for _, device := range slice.Devices {
+						attribute, ok := device.Attributes["feature.node.kubernetes.io/cpu_cpuid_AMXTILE"]
+						if !ok {
+							return false
+						}
+						require.True(t, *attribute.BoolValue)
+					}
+				}
+				return true
+			}
+
+			// not labeled by NFD yet
+			changed, err := cp.syncNFDAttributes(ctx)
+			require.NoError(t, err)
+			require.True(t, changed)
+			cp.PublishResources(ctx)
+			require.False(t, hasAMX())
+
+			changed, err = cp.syncNFDAttributes(ctx)
+			require.NoError(t, err)
+			require.False(t, changed)
+
+			labeled := node.DeepCopy()
+			labeled.Labels = map[string]string{"feature.node.kubernetes.io/cpu-cpuid.AMXTILE": "true"}
+			_, err = kubeClient.CoreV1().Nodes().Update(ctx, labeled, metav1.UpdateOptions{})
+			require.NoError(t, err)
+			cp.resyncNFDAttributes(ctx)
+			require.True(t, hasAMX())
+		})
+	}
+}
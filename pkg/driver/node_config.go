@@ -0,0 +1,141 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/nodeconfig"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+// nodeConfigSyncPeriod is how often the DRACPUConfig of the node is read to apply its changes.
+const nodeConfigSyncPeriod = 30 * time.Second
+
+// ApplySettings overrides the configuration with the settings of a DRACPUConfig.
+func (c *Config) ApplySettings(settings nodeconfig.Settings) error {
+	if settings.ReservedCPUs != nil {
+		reservedCPUs, err := cpuset.Parse(*settings.ReservedCPUs)
+		if err != nil {
+			return fmt.Errorf("invalid reservedCPUs %q: %w", *settings.ReservedCPUs, err)
+		}
+		c.ReservedCPUs = reservedCPUs
+	}
+	if settings.CPUDeviceMode != nil {
+		if *settings.CPUDeviceMode != CPU_DEVICE_MODE_GROUPED && *settings.CPUDeviceMode != CPU_DEVICE_MODE_INDIVIDUAL {
+			return fmt.Errorf("invalid cpuDeviceMode %q, must be %s or %s", *settings.CPUDeviceMode, CPU_DEVICE_MODE_GROUPED, CPU_DEVICE_MODE_INDIVIDUAL)
+		}
+		c.CpuDeviceMode = *settings.CPUDeviceMode
+	}
+	if settings.GroupBy != nil {
+		if *settings.GroupBy != GROUP_BY_SOCKET && *settings.GroupBy != GROUP_BY_NUMA_NODE && *settings.GroupBy != GROUP_BY_NODE {
+			return fmt.Errorf("invalid groupBy %q, must be %s, %s or %s", *settings.GroupBy, GROUP_BY_SOCKET, GROUP_BY_NUMA_NODE, GROUP_BY_NODE)
+		}
+		c.CPUDeviceGroupBy = *settings.GroupBy
+	}
+	if settings.SMTIsolation != nil {
+		if *settings.SMTIsolation != SMT_ISOLATION_NONE && *settings.SMTIsolation != SMT_ISOLATION_CLAIM && *settings.SMTIsolation != SMT_ISOLATION_NAMESPACE {
+			return fmt.Errorf("invalid smtIsolation %q, must be %s, %s or %s", *settings.SMTIsolation, SMT_ISOLATION_NONE, SMT_ISOLATION_CLAIM, SMT_ISOLATION_NAMESPACE)
+		}
+		c.SMTIsolation = *settings.SMTIsolation
+	}
+	if settings.CPUPools != nil {
+		pools, err := NewCPUPools(settings.CPUPools)
+		if err != nil {
+			return fmt.Errorf("invalid cpuPools: %w", err)
+		}
+		c.CPUPools = pools
+	}
+	return nil
+}
+
+// loadNodeConfig returns the configuration of the node: the base configuration, from the flags,
+// overridden by the settings of the DRACPUConfig matching the labels of the node, if it exists.
+func loadNodeConfig(ctx context.Context, kubeClient kubernetes.Interface, base Config) (Config, error) {
+	config, err := nodeconfig.Get(ctx, base.DynamicClient, base.NodeConfigName)
+	if apierrors.IsNotFound(err) {
+		klog.FromContext(ctx).V(2).Info("DRACPUConfig not found, using the flags", "config", base.NodeConfigName)
+		return base, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to get DRACPUConfig %s: %w", base.NodeConfigName, err)
+	}
+	node, err := kubeClient.CoreV1().Nodes().Get(ctx, base.NodeName, metav1.GetOptions{})
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to get node %s: %w", base.NodeName, err)
+	}
+	settings, err := config.Resolve(node.Labels)
+	if err != nil {
+		return Config{}, err
+	}
+	if err := base.ApplySettings(settings); err != nil {
+		return Config{}, fmt.Errorf("DRACPUConfig %s: %w", base.NodeConfigName, err)
+	}
+	return base, nil
+}
+
+// RestartRequired is notified when the DRACPUConfig changes settings which can't be changed while
+// running, like the reserved CPUs or the device mode, so the driver is restarted to apply them.
+func (cp *CPUDriver) RestartRequired() <-chan string {
+	return cp.restartCh
+}
+
+// resyncNodeConfig applies the changes of the DRACPUConfig of the node: the SMT isolation and the CPU
+// pools apply to the claims prepared from then on, the other settings require a restart.
+func (cp *CPUDriver) resyncNodeConfig(ctx context.Context) {
+	logger := klog.FromContext(ctx)
+	config, err := loadNodeConfig(ctx, cp.kubeClient, cp.nodeConfigBase)
+	if err != nil {
+		logger.Error(err, "Failed to sync the node configuration")
+		return
+	}
+	var changed []string
+	if !config.ReservedCPUs.Equals(cp.reservedCPUs) {
+		changed = append(changed, fmt.Sprintf("reservedCPUs %q", config.ReservedCPUs.String()))
+	}
+	if config.CpuDeviceMode != cp.cpuDeviceMode {
+		changed = append(changed, fmt.Sprintf("cpuDeviceMode %q", config.CpuDeviceMode))
+	}
+	if config.CPUDeviceGroupBy != cp.cpuDeviceGroupBy {
+		changed = append(changed, fmt.Sprintf("groupBy %q", config.CPUDeviceGroupBy))
+	}
+	if len(changed) > 0 {
+		select {
+		case cp.restartCh <- fmt.Sprintf("DRACPUConfig %s changed %v", cp.nodeConfigBase.NodeConfigName, changed):
+		default:
+		}
+		return
+	}
+
+	cp.settingsMu.Lock()
+	defer cp.settingsMu.Unlock()
+	if config.SMTIsolation != cp.smtIsolation {
+		logger.Info("Applying the SMT isolation of the DRACPUConfig", "config", cp.nodeConfigBase.NodeConfigName, "smtIsolation", config.SMTIsolation)
+		cp.smtIsolation = config.SMTIsolation
+	}
+	if !reflect.DeepEqual(config.CPUPools, cp.cpuPools) {
+		logger.Info("Applying the CPU pools of the DRACPUConfig", "config", cp.nodeConfigBase.NodeConfigName, "pools", len(config.CPUPools))
+		cp.cpuPools = config.CPUPools
+	}
+}
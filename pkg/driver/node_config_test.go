@@ -0,0 +1,158 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/nodeconfig"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/cpuset"
+	"k8s.io/utils/ptr"
+)
+
+func TestApplySettings(t *testing.T) {
+	testCases := []struct {
+		name          string
+		settings      nodeconfig.Settings
+		expected      Config
+		expectedError string
+	}{
+		{
+			name:     "no settings",
+			expected: Config{CpuDeviceMode: CPU_DEVICE_MODE_GROUPED, SMTIsolation: SMT_ISOLATION_NONE},
+		},
+		{
+			name: "all settings",
+			settings: nodeconfig.Settings{
+				ReservedCPUs:  ptr.To("0-1"),
+				CPUDeviceMode: ptr.To(CPU_DEVICE_MODE_INDIVIDUAL),
+				GroupBy:       ptr.To(GROUP_BY_SOCKET),
+				SMTIsolation:  ptr.To(SMT_ISOLATION_CLAIM),
+				CPUPools:      []nodeconfig.CPUPoolSpec{{Name: "ran", CPUs: "2-3", Namespaces: []string{"ran"}}},
+			},
+			expected: Config{
+				ReservedCPUs:     cpuset.New(0, 1),
+				CpuDeviceMode:    CPU_DEVICE_MODE_INDIVIDUAL,
+				CPUDeviceGroupBy: GROUP_BY_SOCKET,
+				SMTIsolation:     SMT_ISOLATION_CLAIM,
+				CPUPools:         []CPUPool{{Name: "ran", CPUs: cpuset.New(2, 3), Namespaces: []string{"ran"}}},
+			},
+		},
+		{
+			name:          "invalid device mode",
+			settings:      nodeconfig.Settings{CPUDeviceMode: ptr.To("shared")},
+			expectedError: "invalid cpuDeviceMode",
+		},
+		{
+			name:          "invalid reserved CPUs",
+			settings:      nodeconfig.Settings{ReservedCPUs: ptr.To("a-b")},
+			expectedError: "invalid reservedCPUs",
+		},
+		{
+			name:          "overlapping pools",
+			settings:      nodeconfig.Settings{CPUPools: []nodeconfig.CPUPoolSpec{{Name: "a", CPUs: "0-3"}, {Name: "b", CPUs: "3"}}},
+			expectedError: "invalid cpuPools",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := Config{CpuDeviceMode: CPU_DEVICE_MODE_GROUPED, SMTIsolation: SMT_ISOLATION_NONE}
+			err := config.ApplySettings(tc.settings)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, config)
+		})
+	}
+}
+
+func TestResyncNodeConfig(t *testing.T) {
+	newConfig := func(spec map[string]any) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "dra.cpu/v1alpha1",
+			"kind":       "DRACPUConfig",
+			"metadata":   map[string]any{"name": "dracpu"},
+			"spec":       spec,
+		}}
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{nodeconfig.GroupVersionResource: "DRACPUConfigList"})
+	configs := dynamicClient.Resource(nodeconfig.GroupVersionResource)
+	kubeClient := fake.NewClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: testNodeName, Labels: map[string]string{"tenant": "telecom"}}})
+	base := Config{
+		NodeName:         testNodeName,
+		ReservedCPUs:     cpuset.New(0),
+		CpuDeviceMode:    CPU_DEVICE_MODE_GROUPED,
+		CPUDeviceGroupBy: GROUP_BY_NUMA_NODE,
+		SMTIsolation:     SMT_ISOLATION_NONE,
+		NodeConfigName:   "dracpu",
+		DynamicClient:    dynamicClient,
+	}
+	cp := &CPUDriver{
+		kubeClient:       kubeClient,
+		reservedCPUs:     base.ReservedCPUs,
+		cpuDeviceMode:    base.CpuDeviceMode,
+		cpuDeviceGroupBy: base.CPUDeviceGroupBy,
+		smtIsolation:     base.SMTIsolation,
+		nodeConfigBase:   base,
+		restartCh:        make(chan string, 1),
+	}
+	ctx := context.Background()
+
+	// without a DRACPUConfig the flags apply.
+	cp.resyncNodeConfig(ctx)
+	require.Equal(t, SMT_ISOLATION_NONE, cp.smtIsolation)
+	require.Empty(t, cp.cpuPools)
+
+	// the override of the node changes the live settings.
+	_, err := configs.Create(ctx, newConfig(map[string]any{
+		"smtIsolation": "claim",
+		"overrides": []any{map[string]any{
+			"nodeSelector": map[string]any{"matchLabels": map[string]any{"tenant": "telecom"}},
+			"cpuPools":     []any{map[string]any{"name": "ran", "cpus": "2-3", "namespaces": []any{"ran"}}},
+		}},
+	}), metav1.CreateOptions{})
+	require.NoError(t, err)
+	cp.resyncNodeConfig(ctx)
+	require.Equal(t, SMT_ISOLATION_CLAIM, cp.smtIsolation)
+	require.Equal(t, []CPUPool{{Name: "ran", CPUs: cpuset.New(2, 3), Namespaces: []string{"ran"}}}, cp.cpuPools)
+	require.Empty(t, cp.restartCh)
+
+	// an invalid configuration is ignored.
+	_, err = configs.Update(ctx, newConfig(map[string]any{"smtIsolation": "core"}), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	cp.resyncNodeConfig(ctx)
+	require.Equal(t, SMT_ISOLATION_CLAIM, cp.smtIsolation)
+
+	// changing the reserved CPUs requires a restart.
+	_, err = configs.Update(ctx, newConfig(map[string]any{"reservedCPUs": "0-1"}), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	cp.resyncNodeConfig(ctx)
+	require.Len(t, cp.RestartRequired(), 1)
+	require.Contains(t, <-cp.RestartRequired(), `reservedCPUs "0-1"`)
+	require.Equal(t, SMT_ISOLATION_CLAIM, cp.smtIsolation)
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// nodeStatusUpdatePeriod is how often the node condition and the ResourceSlice annotations are refreshed.
+	nodeStatusUpdatePeriod = 1 * time.Minute
+	// nodeConditionType is the node condition reporting whether the driver is publishing its resources.
+	nodeConditionType corev1.NodeConditionType = "DRACPUNodeReady"
+
+	// annotationDriverVersion is the version of the driver that published the ResourceSlice.
+	annotationDriverVersion = "dra.cpu/driver-version"
+	// annotationLastPublishTime is when the driver last published its resources, in RFC 3339 format.
+	annotationLastPublishTime = "dra.cpu/last-publish-time"
+	// annotationCheckpointHealth is the state of the kubelet CPU Manager checkpoint sync.
+	annotationCheckpointHealth = "dra.cpu/checkpoint-health"
+
+	checkpointHealthy   = "Healthy"
+	checkpointUnhealthy = "Unhealthy"
+	checkpointDisabled  = "Disabled"
+)
+
+// driverStatus is what the driver reports about itself on the node and on its ResourceSlices.
+type driverStatus struct {
+	lastPublishTime time.Time
+	publishErr      error
+	checkpointErr   error
+	// conditionStatus and conditionTransitionTime are the last reported condition, to keep the transition time stable.
+	conditionStatus         corev1.ConditionStatus
+	conditionTransitionTime metav1.Time
+}
+
+// recordPublish records the outcome of a ResourceSlice write, or of a PublishResources call failing.
+func (cp *CPUDriver) recordPublish(now time.Time, err error) {
+	cp.statusMu.Lock()
+	defer cp.statusMu.Unlock()
+	cp.status.publishErr = err
+	if err == nil {
+		cp.status.lastPublishTime = now
+	}
+}
+
+// recordCheckpointSync records the outcome of a kubelet CPU Manager checkpoint sync.
+func (cp *CPUDriver) recordCheckpointSync(err error) {
+	cp.statusMu.Lock()
+	defer cp.statusMu.Unlock()
+	cp.status.checkpointErr = err
+}
+
+// checkpointHealth returns the state of the kubelet CPU Manager checkpoint sync.
+// It must be called with statusMu held.
+func (cp *CPUDriver) checkpointHealth() string {
+	if cp.kubeletCheckpointPath == "" {
+		return checkpointDisabled
+	}
+	if cp.status.checkpointErr != nil {
+		return checkpointUnhealthy
+	}
+	return checkpointHealthy
+}
+
+// nodeCondition returns the DRACPUNodeReady condition for the current driver status.
+// It must be called with statusMu held.
+func (cp *CPUDriver) nodeCondition(now time.Time) corev1.NodeCondition {
+	condition := corev1.NodeCondition{
+		Type:              nodeConditionType,
+		Status:            corev1.ConditionTrue,
+		LastHeartbeatTime: metav1.NewTime(now),
+		Reason:            "ResourcesPublished",
+		Message:           fmt.Sprintf("%s last published its resources at %s", cp.driverName, cp.status.lastPublishTime.UTC().Format(time.RFC3339)),
+	}
+	switch {
+	case cp.status.publishErr != nil:
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "PublishFailed"
+		condition.Message = fmt.Sprintf("%s failed to publish its resources: %v", cp.driverName, cp.status.publishErr)
+	case cp.status.lastPublishTime.IsZero():
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "ResourcesNotPublished"
+		condition.Message = fmt.Sprintf("%s has not published its resources yet", cp.driverName)
+	case cp.status.checkpointErr != nil:
+		condition.Status = corev1.ConditionFalse
+		condition.Reason = "CheckpointUnhealthy"
+		condition.Message = fmt.Sprintf("%s failed to sync the kubelet CPU Manager checkpoint: %v", cp.driverName, cp.status.checkpointErr)
+	}
+	if condition.Status != cp.status.conditionStatus {
+		cp.status.conditionStatus = condition.Status
+		cp.status.conditionTransitionTime = metav1.NewTime(now)
+	}
+	condition.LastTransitionTime = cp.status.conditionTransitionTime
+	return condition
+}
+
+// sliceAnnotations returns the annotations describing the driver status on its ResourceSlices.
+// It must be called with statusMu held.
+func (cp *CPUDriver) sliceAnnotations() map[string]string {
+	annotations := map[string]string{
+		annotationDriverVersion:    cp.driverVersion,
+		annotationCheckpointHealth: cp.checkpointHealth(),
+	}
+	if !cp.status.lastPublishTime.IsZero() {
+		annotations[annotationLastPublishTime] = cp.status.lastPublishTime.UTC().Format(time.RFC3339)
+	}
+	return annotations
+}
+
+// updateNodeStatus reports the driver status on the node condition and on the annotations of the
+// ResourceSlices published for the node, so operators can alert on stale or unhealthy driver instances.
+func (cp *CPUDriver) updateNodeStatus(ctx context.Context, now time.Time) {
+	cp.statusMu.Lock()
+	condition := cp.nodeCondition(now)
+	annotations := cp.sliceAnnotations()
+	cp.statusMu.Unlock()
+
+	logger := klog.FromContext(ctx)
+	if err := cp.patchNodeCondition(ctx, condition); err != nil {
+		logger.Error(err, "Failed to update the condition of the node", "condition", nodeConditionType)
+	}
+	if err := cp.annotateResourceSlices(ctx, annotations); err != nil {
+		logger.Error(err, "Failed to annotate the ResourceSlices of the node")
+	}
+	if cp.publishNodeStatus {
+		cp.updateNodeStatusResource(ctx, now)
+	}
+}
+
+// patchNodeCondition sets the condition on the node status, leaving the other conditions untouched.
+func (cp *CPUDriver) patchNodeCondition(ctx context.Context, condition corev1.NodeCondition) error {
+	patch, err := json.Marshal(map[string]any{
+		"status": map[string]any{
+			"conditions": []corev1.NodeCondition{condition},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = cp.kubeClient.CoreV1().Nodes().PatchStatus(ctx, cp.nodeName, patch)
+	return err
+}
+
+// annotateResourceSlices sets the annotations on the ResourceSlices of the driver for the node.
+// Slices already carrying the annotations are not patched.
+func (cp *CPUDriver) annotateResourceSlices(ctx context.Context, annotations map[string]string) error {
+	selector := fields.Set{
+		"spec.nodeName": cp.nodeName,
+		"spec.driver":   cp.driverName,
+	}.AsSelector().String()
+	slices, err := cp.kubeClient.ResourceV1().ResourceSlices().List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list ResourceSlices: %w", err)
+	}
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for _, slice := range slices.Items {
+		current := make(map[string]string, len(annotations))
+		for key := range annotations {
+			if value, ok := slice.Annotations[key]; ok {
+				current[key] = value
+			}
+		}
+		if maps.Equal(current, annotations) {
+			continue
+		}
+		if _, err := cp.kubeClient.ResourceV1().ResourceSlices().Patch(ctx, slice.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("failed to annotate ResourceSlice %s: %w", slice.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/nodestatus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+	"k8s.io/utils/ptr"
+)
+
+// nodeStatusResource returns the DRACPUNodeStatus summarizing the CPU allocation of the node.
+func (cp *CPUDriver) nodeStatusResource(now time.Time) *nodestatus.DRACPUNodeStatus {
+	status := &nodestatus.DRACPUNodeStatus{
+		ObjectMeta: metav1.ObjectMeta{Name: cp.nodeName},
+		Status: nodestatus.NodeStatus{
+			DriverVersion:  cp.driverVersion,
+			DeviceMode:     cp.cpuDeviceMode,
+			LastUpdateTime: metav1.NewTime(now),
+			NUMANodes:      []nodestatus.NUMANodeStatus{},
+			Claims:         []nodestatus.ClaimStatus{},
+		},
+	}
+	cp.statusMu.Lock()
+	if !cp.status.lastPublishTime.IsZero() {
+		status.Status.LastPublishTime = ptr.To(metav1.NewTime(cp.status.lastPublishTime))
+	}
+	if err := errors.Join(cp.status.publishErr, cp.status.checkpointErr); err != nil {
+		status.Status.LastError = err.Error()
+	}
+	cp.statusMu.Unlock()
+
+	allocatedCPUs := cpuset.New()
+	for claimUID, info := range cp.cpuAllocationStore.GetResourceClaimInfos() {
+		cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claimUID)
+		if !ok {
+			continue
+		}
+		allocatedCPUs = allocatedCPUs.Union(cpus)
+		status.Status.Claims = append(status.Status.Claims, nodestatus.ClaimStatus{Namespace: info.Namespace, Name: info.Name, UID: claimUID, CPUs: cpus.String()})
+	}
+	slices.SortFunc(status.Status.Claims, func(a, b nodestatus.ClaimStatus) int {
+		return strings.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+	freeCPUs := cp.cpuAllocationStore.GetSharedCPUs()
+	status.Status.AllocatableCPUs = cp.cpuAllocationStore.GetAllocatableCPUs().Size()
+	status.Status.AllocatedCPUs = allocatedCPUs.Size()
+	status.Status.FreeCPUs = freeCPUs.Size()
+
+	topo := cp.cpuTopology
+	status.Status.CPUsPerCore = topo.CPUsPerCore()
+	for _, numaNodeID := range topo.CPUDetails.NUMANodes().List() {
+		numaCPUs := topo.CPUDetails.CPUsInNUMANodes(numaNodeID)
+		numaStatus := nodestatus.NUMANodeStatus{
+			ID:            numaNodeID,
+			SocketID:      topo.CPUDetails[numaCPUs.List()[0]].SocketID,
+			AllocatedCPUs: numaCPUs.Intersection(allocatedCPUs).Size(),
+			FreeCPUs:      numaCPUs.Intersection(freeCPUs).Size(),
+		}
+		for _, coreID := range topo.CPUDetails.CoresInNUMANodes(numaNodeID).List() {
+			// core IDs are only unique within a socket
+			coreCPUs := topo.CPUDetails.CPUsInCores(coreID).Intersection(numaCPUs)
+			if coreCPUs.Intersection(allocatedCPUs).Size() > 0 {
+				numaStatus.AllocatedCores++
+			} else if coreCPUs.IsSubsetOf(freeCPUs) {
+				numaStatus.FreeCores++
+			}
+		}
+		status.Status.NUMANodes = append(status.Status.NUMANodes, numaStatus)
+	}
+	return status
+}
+
+// updateNodeStatusResource writes the DRACPUNodeStatus of the node, owned by the node so it is deleted with it.
+func (cp *CPUDriver) updateNodeStatusResource(ctx context.Context, now time.Time) {
+	logger := klog.FromContext(ctx)
+	status := cp.nodeStatusResource(now)
+	node, err := cp.kubeClient.CoreV1().Nodes().Get(ctx, cp.nodeName, metav1.GetOptions{})
+	if err != nil {
+		logger.Error(err, "Failed to get the node")
+		return
+	}
+	status.OwnerReferences = []metav1.OwnerReference{{APIVersion: "v1", Kind: "Node", Name: node.Name, UID: node.UID}}
+	if err := nodestatus.Update(ctx, cp.dynamicClient, status); err != nil {
+		logger.Error(err, "Failed to update the DRACPUNodeStatus of the node")
+	}
+}
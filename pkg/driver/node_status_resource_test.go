@@ -0,0 +1,117 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/nodestatus"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/cpuset"
+)
+
+func TestUpdateNodeStatusResource(t *testing.T) {
+	// NUMA node 0 has the cores 0 (CPUs 0,4) and 1 (CPUs 1,5), NUMA node 1 the cores 2 (CPUs 2,6) and 3 (CPUs 3,7).
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{nodestatus.GroupVersionResource: "DRACPUNodeStatusList"})
+	publishTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	cp := &CPUDriver{
+		nodeName:           testNodeName,
+		driverVersion:      "v1.0.0",
+		cpuDeviceMode:      CPU_DEVICE_MODE_GROUPED,
+		cpuTopology:        topo,
+		cpuAllocationStore: store.NewCPUAllocation(topo, cpuset.New(0)),
+		kubeClient:         fake.NewClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: testNodeName, UID: "node-uid"}}),
+		dynamicClient:      dynamicClient,
+		status:             driverStatus{lastPublishTime: publishTime, checkpointErr: errors.New("checkpoint is corrupted")},
+	}
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-b", cpuset.New(2, 6, 3))
+	cp.cpuAllocationStore.SetResourceClaimInfo("claim-b", store.ClaimInfo{Namespace: "ns", Name: "b"})
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-a", cpuset.New(1))
+	cp.cpuAllocationStore.SetResourceClaimInfo("claim-a", store.ClaimInfo{Namespace: "ns", Name: "a"})
+
+	now := publishTime.Add(time.Minute)
+	expected := nodestatus.NodeStatus{
+		DriverVersion:   "v1.0.0",
+		DeviceMode:      CPU_DEVICE_MODE_GROUPED,
+		LastUpdateTime:  metav1.NewTime(now),
+		LastPublishTime: &metav1.Time{Time: publishTime},
+		LastError:       "checkpoint is corrupted",
+		AllocatableCPUs: 7,
+		AllocatedCPUs:   4,
+		FreeCPUs:        3,
+		CPUsPerCore:     2,
+		NUMANodes: []nodestatus.NUMANodeStatus{
+			{ID: 0, SocketID: 0, AllocatedCPUs: 1, FreeCPUs: 2, AllocatedCores: 1, FreeCores: 0},
+			{ID: 1, SocketID: 1, AllocatedCPUs: 3, FreeCPUs: 1, AllocatedCores: 2, FreeCores: 0},
+		},
+		Claims: []nodestatus.ClaimStatus{
+			{Namespace: "ns", Name: "a", UID: "claim-a", CPUs: "1"},
+			{Namespace: "ns", Name: "b", UID: "claim-b", CPUs: "2-3,6"},
+		},
+	}
+	require.Equal(t, expected, cp.nodeStatusResource(now).Status)
+
+	// the status is created, then updated.
+	ctx := context.Background()
+	cp.updateNodeStatusResource(ctx, now)
+	cp.cpuAllocationStore.RemoveResourceClaimAllocation("claim-b")
+	cp.updateNodeStatusResource(ctx, now.Add(time.Minute))
+
+	obj, err := dynamicClient.Resource(nodestatus.GroupVersionResource).Get(ctx, testNodeName, metav1.GetOptions{})
+	require.NoError(t, err)
+	var status nodestatus.DRACPUNodeStatus
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &status))
+	require.Equal(t, "DRACPUNodeStatus", status.Kind)
+	require.Equal(t, []metav1.OwnerReference{{APIVersion: "v1", Kind: "Node", Name: testNodeName, UID: "node-uid"}}, status.OwnerReferences)
+	require.Equal(t, 1, status.Status.AllocatedCPUs)
+	require.Equal(t, []nodestatus.NUMANodeStatus{
+		{ID: 0, SocketID: 0, AllocatedCPUs: 1, FreeCPUs: 2, AllocatedCores: 1, FreeCores: 0},
+		{ID: 1, SocketID: 1, AllocatedCPUs: 0, FreeCPUs: 4, AllocatedCores: 0, FreeCores: 2},
+	}, status.Status.NUMANodes)
+	require.Len(t, status.Status.Claims, 1)
+}
+
+func TestNodeStatusResourcePerSocketCoreIDs(t *testing.T) {
+	// both sockets have the cores 0 and 1: (0,4) and (1,5) on NUMA node 0, (2,6) and (3,7) on NUMA node 1.
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_PerSocketCoreIDs_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cp := &CPUDriver{
+		cpuTopology:        topo,
+		cpuAllocationStore: store.NewCPUAllocation(topo, cpuset.New()),
+	}
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-a", cpuset.New(2))
+	cp.cpuAllocationStore.SetResourceClaimInfo("claim-a", store.ClaimInfo{Namespace: "ns", Name: "a"})
+
+	status := cp.nodeStatusResource(time.Now()).Status
+	require.Equal(t, []nodestatus.NUMANodeStatus{
+		{ID: 0, SocketID: 0, AllocatedCPUs: 0, FreeCPUs: 4, AllocatedCores: 0, FreeCores: 2},
+		{ID: 1, SocketID: 1, AllocatedCPUs: 1, FreeCPUs: 3, AllocatedCores: 1, FreeCores: 1},
+	}, status.NUMANodes)
+}
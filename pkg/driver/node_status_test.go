@@ -0,0 +1,163 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin"
+	"k8s.io/utils/ptr"
+)
+
+func TestUpdateNodeStatus(t *testing.T) {
+	kubeClient := fake.NewClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		},
+		&resourceapi.ResourceSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1-dra.cpu-abcde", Annotations: map[string]string{"other": "value"}},
+			Spec:       resourceapi.ResourceSliceSpec{Driver: "dra.cpu", NodeName: ptr.To("node-1")},
+		},
+	)
+	cp := &CPUDriver{
+		driverName:            "dra.cpu",
+		nodeName:              "node-1",
+		kubeClient:            kubeClient,
+		driverVersion:         "v0.1.0",
+		kubeletCheckpointPath: "/var/lib/kubelet/cpu_manager_state",
+	}
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	getCondition := func() corev1.NodeCondition {
+		t.Helper()
+		node, err := kubeClient.CoreV1().Nodes().Get(ctx, "node-1", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Len(t, node.Status.Conditions, 2, "the other node conditions must be preserved")
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == nodeConditionType {
+				return condition
+			}
+		}
+		t.Fatalf("node condition %s not found", nodeConditionType)
+		return corev1.NodeCondition{}
+	}
+	getAnnotations := func() map[string]string {
+		t.Helper()
+		slice, err := kubeClient.ResourceV1().ResourceSlices().Get(ctx, "node-1-dra.cpu-abcde", metav1.GetOptions{})
+		require.NoError(t, err)
+		return slice.Annotations
+	}
+
+	// not published yet
+	cp.updateNodeStatus(ctx, start)
+	condition := getCondition()
+	require.Equal(t, corev1.ConditionFalse, condition.Status)
+	require.Equal(t, "ResourcesNotPublished", condition.Reason)
+	require.Equal(t, map[string]string{
+		"other":                    "value",
+		annotationDriverVersion:    "v0.1.0",
+		annotationCheckpointHealth: checkpointHealthy,
+	}, getAnnotations())
+
+	// published
+	published := start.Add(time.Minute)
+	cp.recordPublish(published, nil)
+	cp.updateNodeStatus(ctx, published)
+	condition = getCondition()
+	require.Equal(t, corev1.ConditionTrue, condition.Status)
+	require.Equal(t, "ResourcesPublished", condition.Reason)
+	require.True(t, condition.LastTransitionTime.Time.Equal(published))
+	require.Equal(t, published.Format(time.RFC3339), getAnnotations()[annotationLastPublishTime])
+
+	// still healthy, only the heartbeat moves
+	cp.updateNodeStatus(ctx, start.Add(2*time.Minute))
+	condition = getCondition()
+	require.Equal(t, corev1.ConditionTrue, condition.Status)
+	require.True(t, condition.LastTransitionTime.Time.Equal(published))
+	require.True(t, condition.LastHeartbeatTime.Time.Equal(start.Add(2*time.Minute)))
+
+	// checkpoint sync failing
+	cp.recordCheckpointSync(errors.New("invalid checkpoint"))
+	cp.updateNodeStatus(ctx, start.Add(3*time.Minute))
+	condition = getCondition()
+	require.Equal(t, corev1.ConditionFalse, condition.Status)
+	require.Equal(t, "CheckpointUnhealthy", condition.Reason)
+	require.Equal(t, checkpointUnhealthy, getAnnotations()[annotationCheckpointHealth])
+
+	// publish failing takes precedence
+	cp.recordCheckpointSync(nil)
+	cp.recordPublish(start.Add(4*time.Minute), errors.New("apiserver unavailable"))
+	cp.updateNodeStatus(ctx, start.Add(4*time.Minute))
+	condition = getCondition()
+	require.Equal(t, corev1.ConditionFalse, condition.Status)
+	require.Equal(t, "PublishFailed", condition.Reason)
+	require.Equal(t, published.Format(time.RFC3339), getAnnotations()[annotationLastPublishTime], "last publish time is the last successful one")
+}
+
+func TestPublishStatusFromResourceSliceWrites(t *testing.T) {
+	cp := newPublisherTestDriver(t, &mockKubeletPlugin{})
+	statusCode := http.StatusCreated
+	observer := &resourceSliceWriteObserver{cp: cp, next: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: statusCode, Status: http.StatusText(statusCode), Body: http.NoBody, Request: req}, nil
+	})}
+	write := func() {
+		req, err := http.NewRequest(http.MethodPost, "https://apiserver/apis/resource.k8s.io/v1/resourceslices", nil)
+		require.NoError(t, err)
+		_, err = observer.RoundTrip(req)
+		require.NoError(t, err)
+	}
+	condition := func() corev1.NodeCondition {
+		cp.statusMu.Lock()
+		defer cp.statusMu.Unlock()
+		return cp.nodeCondition(time.Now())
+	}
+
+	// handing the slices to the kubelet plugin is not a publication.
+	cp.PublishResources(context.Background())
+	require.Equal(t, "ResourcesNotPublished", condition().Reason)
+
+	// every write rejected by the API server.
+	statusCode = http.StatusUnprocessableEntity
+	write()
+	require.Equal(t, "PublishFailed", condition().Reason)
+	require.True(t, cp.status.lastPublishTime.IsZero())
+
+	statusCode = http.StatusCreated
+	write()
+	require.Equal(t, "ResourcesPublished", condition().Reason)
+	require.False(t, cp.status.lastPublishTime.IsZero())
+
+	// the errors of the resourceslice controller, e.g. dropped fields, fail the publication as well.
+	cp.HandleError(context.Background(), fmt.Errorf("%w: dropped fields", kubeletplugin.ErrRecoverable), "publishing slices")
+	require.Equal(t, "PublishFailed", condition().Reason)
+	cp.HandleError(context.Background(), errors.New("gRPC server failed"), "DRA gRPC server failed")
+	require.Equal(t, "PublishFailed", condition().Reason)
+}
@@ -20,32 +20,51 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/containerd/nri/pkg/api"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
 	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/cpuset"
 )
 
+// containerLogger returns the logger of ctx with the keys identifying the container.
+func containerLogger(ctx context.Context, pod *api.PodSandbox, ctr *api.Container) klog.Logger {
+	return klog.LoggerWithValues(klog.FromContext(ctx), "pod", klog.KRef(pod.Namespace, pod.Name), "podUID", pod.Uid, "container", ctr.Name, "containerID", ctr.Id)
+}
+
 // Synchronize is called by the NRI to synchronize the state of the driver during bootstrap.
 func (cp *CPUDriver) Synchronize(ctx context.Context, pods []*api.PodSandbox, containers []*api.Container) ([]*api.ContainerUpdate, error) {
-	klog.Infof("Synchronized state with the runtime (%d pods, %d containers)...",
-		len(pods), len(containers))
+	logger := klog.FromContext(ctx)
+	logger.Info("Synchronizing state with the runtime", "pods", len(pods), "containers", len(containers))
+
+	var checkpoint *cpumanager.KubeletCheckpoint
+	if cp.kubeletCheckpointPath != "" {
+		var err error
+		checkpoint, _, err = cp.syncKubeletCheckpoint(logger)
+		if err != nil {
+			logger.Error(err, "Failed to sync kubelet CPU Manager checkpoint")
+		}
+	}
 
 	cpuAllocationStore := store.NewCPUAllocation(cp.cpuTopology, cp.reservedCPUs)
+	cpuAllocationStore.SetKubeletExclusiveCPUs(logger, cp.cpuAllocationStore.GetKubeletExclusiveCPUs())
 	podConfigStore := store.NewPodConfig()
 
-	logger := klog.FromContext(ctx)
 	for _, pod := range pods {
-		klog.Infof("Synchronize pod %s/%s UID %s", pod.Namespace, pod.Name, pod.Uid)
+		logger.V(2).Info("Synchronizing pod", "pod", klog.KRef(pod.Namespace, pod.Name), "podUID", pod.Uid)
 		for _, container := range containers {
 			if container.PodSandboxId != pod.Id {
 				continue
 			}
+			if isKubeletPinned(logger, checkpoint, pod.Uid, container.Name) {
+				continue
+			}
 			claimAllocations, err := parseDRAEnvToClaimAllocations(container.Env)
 			if err != nil {
-				klog.Errorf("Error parsing DRA env for container %s in pod %s/%s: %v", container.Name, pod.Namespace, pod.Name, err)
+				containerLogger(ctx, pod, container).Error(err, "Failed to parse DRA env")
 				continue
 			}
 			containerUID := types.UID(container.GetId())
@@ -65,13 +84,15 @@ func (cp *CPUDriver) Synchronize(ctx context.Context, pods []*api.PodSandbox, co
 					claimUIDs = append(claimUIDs, uid)
 					cpuAllocationStore.AddResourceClaimAllocation(uid, cpus)
 				}
-				klog.Infof("Synchronize: Found guaranteed CPUs for pod %s/%s container %s with cpus: %v", pod.Namespace, pod.Name, container.Name, allGuaranteedCPUs.String())
+				containerLogger(ctx, pod, container).Info("Found guaranteed CPUs", "cpus", allGuaranteedCPUs.String())
 				state = store.NewContainerState(container.GetName(), containerUID, claimUIDs...)
 			}
 			podConfigStore.SetContainerState(types.UID(pod.GetUid()), state)
 		}
 	}
 
+	// the claims restored after a reboot have no containers yet.
+	cp.holdRestoredClaims(logger, cpuAllocationStore)
 	cp.podConfigStore = podConfigStore
 	cp.cpuAllocationStore = cpuAllocationStore
 	return nil, nil
@@ -83,7 +104,7 @@ func parseDRAEnvToClaimAllocations(envs []string) (map[types.UID]cpuset.CPUSet,
 		if !strings.HasPrefix(env, cdiEnvVarPrefix) {
 			continue
 		}
-		klog.Infof("Parsing DRA env entry: %q", env)
+		klog.V(5).InfoS("Parsing DRA env entry", "env", env)
 		parts := strings.SplitN(env, "=", 2)
 		if len(parts) != 2 {
 			return nil, fmt.Errorf("malformed DRA env entry %q", env)
@@ -111,7 +132,7 @@ func (cp *CPUDriver) getSharedContainerUpdates(excludeID types.UID) []*api.Conta
 	updates := []*api.ContainerUpdate{}
 	sharedCPUs := cp.cpuAllocationStore.GetSharedCPUs()
 	sharedCPUContainers := cp.podConfigStore.GetContainersWithSharedCPUs()
-	klog.Infof("Updating CPU allocation to: %v for containers without guaranteed CPUs", sharedCPUs.String())
+	klog.V(2).InfoS("Updating the CPUs of the containers without guaranteed CPUs", "sharedCPUs", sharedCPUs.String())
 	for _, containerUID := range sharedCPUContainers {
 		if containerUID == excludeID {
 			// Skip the container being created as it is already covered in the container adjustment.
@@ -129,31 +150,62 @@ func (cp *CPUDriver) getSharedContainerUpdates(excludeID types.UID) []*api.Conta
 
 // CreateContainer handles container creation requests from the NRI.
 func (cp *CPUDriver) CreateContainer(ctx context.Context, pod *api.PodSandbox, ctr *api.Container) (*api.ContainerAdjustment, []*api.ContainerUpdate, error) {
-	klog.Infof("CreateContainer Pod:%s/%s PodUID:%s Container:%s ContainerID:%s", pod.Namespace, pod.Name, pod.Uid, ctr.Name, ctr.Id)
+	logger := containerLogger(ctx, pod, ctr)
+	logger.V(2).Info("CreateContainer called")
+	if err := cp.chaos.failure(logger, "cgroup write"); err != nil {
+		return nil, nil, err
+	}
 	adjust := &api.ContainerAdjustment{}
 	var updates []*api.ContainerUpdate
 
 	claimAllocations, err := parseDRAEnvToClaimAllocations(ctr.Env)
 	if err != nil {
-		klog.Errorf("Error parsing DRA env for container %s in pod %s/%s: %v", ctr.Name, pod.Namespace, pod.Name, err)
+		logger.Error(err, "Failed to parse DRA env")
 	}
 
 	containerId := types.UID(ctr.GetId())
 	podUID := types.UID(pod.GetUid())
 
+	if cp.kubeletCheckpointPath != "" {
+		// The kubelet updates its checkpoint when admitting the pod, before the container is created.
+		checkpoint, _, err := cp.syncKubeletCheckpoint(logger)
+		if err != nil {
+			logger.Error(err, "Failed to sync kubelet CPU Manager checkpoint")
+		}
+		if isKubeletPinned(logger, checkpoint, pod.Uid, ctr.Name) {
+			// Keep the cpuset set by the kubelet, and shrink the shared pool accordingly.
+			return adjust, cp.getSharedContainerUpdates(containerId), nil
+		}
+	}
+
 	if len(claimAllocations) == 0 {
 		// This is a shared container.
+		sharedCPUs := cp.cpuAllocationStore.GetSharedCPUs()
+		if cp.isSandboxedPod(pod) {
+			// The sandbox isn't tracked: it can't follow the updates of the shared CPUs.
+			if cp.sandboxedRuntimePolicy == SANDBOXED_RUNTIME_POLICY_ANNOTATE {
+				logger.Info("Passing the shared CPUs to the sandboxed runtime", "cpus", sharedCPUs.String(), "runtimeHandler", pod.GetRuntimeHandler())
+				adjust.AddAnnotation(sandboxedCPUsAnnotation, sharedCPUs.String())
+			}
+			return adjust, nil, nil
+		}
 		state := store.NewContainerState(ctr.GetName(), containerId)
 		cp.podConfigStore.SetContainerState(podUID, state)
 
-		sharedCPUs := cp.cpuAllocationStore.GetSharedCPUs()
-		klog.Infof("No guaranteed CPUs found in DRA env for pod %s/%s container %s. Using shared CPUs %s", pod.Namespace, pod.Name, ctr.Name, sharedCPUs.String())
+		logger.Info("Pinning container to the shared CPUs", "cpus", sharedCPUs.String())
 		adjust.SetLinuxCPUSetCPUs(sharedCPUs.String())
 	} else {
+		sandboxed := cp.isSandboxedPod(pod)
+		if sandboxed && cp.sandboxedRuntimePolicy == SANDBOXED_RUNTIME_POLICY_REJECT {
+			return nil, nil, fmt.Errorf("container %s of pod %s/%s uses the sandboxed runtime handler %s, whose CPUs can't be pinned", ctr.GetName(), pod.GetNamespace(), pod.GetName(), pod.GetRuntimeHandler())
+		}
+		if err := cp.reacquireReleasedClaims(logger, claimAllocations); err != nil {
+			return nil, nil, fmt.Errorf("container %s of pod %s/%s: %w", ctr.GetName(), pod.GetNamespace(), pod.GetName(), err)
+		}
 		guaranteedCPUs := cpuset.New()
 		claimUIDs := []types.UID{}
 		for uid, cpus := range claimAllocations {
-			err := cp.claimTracker.SetOwner(klog.FromContext(ctx), uid, types.UID(pod.Uid), ctr.Name)
+			err := cp.claimTracker.SetOwner(logger, uid, types.UID(pod.Uid), ctr.Name)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -161,9 +213,17 @@ func (cp *CPUDriver) CreateContainer(ctx context.Context, pod *api.PodSandbox, c
 			guaranteedCPUs = guaranteedCPUs.Union(cpus)
 			claimUIDs = append(claimUIDs, uid)
 		}
-		klog.Infof("Guaranteed CPUs found for pod:%s container:%s with cpus:%v", pod.Name, ctr.Name, guaranteedCPUs.String())
+		cp.observeClaimsStarted(claimUIDs, time.Now())
+		if sandboxed {
+			logger.Info("Passing the guaranteed CPUs to the sandboxed runtime", "cpus", guaranteedCPUs.String(), "runtimeHandler", pod.GetRuntimeHandler())
+			adjust.AddAnnotation(sandboxedCPUsAnnotation, guaranteedCPUs.String())
+		} else {
+			logger.Info("Pinning container to its guaranteed CPUs", "cpus", guaranteedCPUs.String())
+			adjust.SetLinuxCPUSetCPUs(guaranteedCPUs.String())
+			cp.adjustCPUBandwidth(logger, adjust, claimAllocations)
+			cp.adjustMemoryNodes(logger, adjust, claimAllocations)
+		}
 		state := store.NewContainerState(ctr.GetName(), containerId, claimUIDs...)
-		adjust.SetLinuxCPUSetCPUs(guaranteedCPUs.String())
 		cp.podConfigStore.SetContainerState(podUID, state)
 		// Remove the guaranteed CPUs from the containers with shared CPUs.
 		updates = cp.getSharedContainerUpdates(containerId)
@@ -173,27 +233,29 @@ func (cp *CPUDriver) CreateContainer(ctx context.Context, pod *api.PodSandbox, c
 }
 
 func (cp *CPUDriver) StopContainer(ctx context.Context, pod *api.PodSandbox, ctr *api.Container) ([]*api.ContainerUpdate, error) {
-	klog.Infof("StopContainer Pod:%s/%s PodUID:%s Container:%s ContainerID:%s", pod.Namespace, pod.Name, pod.Uid, ctr.Name, ctr.Id)
+	logger := containerLogger(ctx, pod, ctr)
+	logger.V(2).Info("StopContainer called")
 	updates := []*api.ContainerUpdate{}
 	claimUIDs := cp.podConfigStore.RemoveContainerState(types.UID(pod.GetUid()), ctr.GetName())
 	entries := "none"
 	if len(claimUIDs) > 0 {
 		// Remove the guaranteed CPUs from the containers with shared CPUs.
 		updates = cp.getSharedContainerUpdates(types.UID(ctr.GetId()))
-		cp.claimTracker.Cleanup(klog.FromContext(ctx), claimUIDs...)
+		cp.claimTracker.Cleanup(logger, claimUIDs...)
 		entries = fmt.Sprintf("%d entries", len(updates))
 	}
-	klog.Infof("StopContainer updates needed: %s", entries)
+	logger.V(2).Info("StopContainer updates needed", "updates", entries)
 	return updates, nil
 }
 
 // RemoveContainer handles container removal requests from the NRI.
-func (cp *CPUDriver) RemoveContainer(_ context.Context, pod *api.PodSandbox, ctr *api.Container) error {
-	klog.Infof("RemoveContainer Pod:%s/%s PodUID:%s Container:%s ContainerID:%s", pod.Namespace, pod.Name, pod.Uid, ctr.Name, ctr.Id)
+func (cp *CPUDriver) RemoveContainer(ctx context.Context, pod *api.PodSandbox, ctr *api.Container) error {
+	logger := containerLogger(ctx, pod, ctr)
+	logger.V(2).Info("RemoveContainer called")
 	claimUIDs := cp.podConfigStore.RemoveContainerState(types.UID(pod.GetUid()), ctr.GetName())
 	if len(claimUIDs) > 0 {
 		// this serves only for debugging purposes. We should never get here
-		klog.Errorf("RemoveContainer spurious updates needed (unexpected, please file a bug): %v", cp.getSharedContainerUpdates(types.UID(ctr.GetId())))
+		logger.Error(nil, "RemoveContainer spurious updates needed (unexpected, please file a bug)", "updates", cp.getSharedContainerUpdates(types.UID(ctr.GetId())))
 	}
 	return nil
 }
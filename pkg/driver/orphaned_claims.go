@@ -0,0 +1,129 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"time"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// orphanedClaimsCheckPeriod is how often prepared claims are checked for vanished consumer pods.
+	orphanedClaimsCheckPeriod = 1 * time.Minute
+	// eventReasonOrphanedClaimReleased is recorded on claims released by the orphan cleanup.
+	eventReasonOrphanedClaimReleased = "OrphanedClaimReleased"
+)
+
+// cleanupOrphanedClaims releases the CPUs of prepared claims whose consumer pods disappeared without the
+// claim being unprepared, e.g. after a kubelet crash or a forced pod deletion. A claim is released only
+// after being orphaned for longer than the TTL, to tolerate transient inconsistencies.
+// Claims recovered on restart have no recorded consumers and are never considered orphaned.
+func (cp *CPUDriver) cleanupOrphanedClaims(ctx context.Context, now time.Time) {
+	logger := klog.FromContext(ctx)
+	infos := cp.cpuAllocationStore.GetResourceClaimInfos()
+	for claimUID := range cp.orphanedClaims {
+		if _, ok := infos[claimUID]; !ok {
+			// unprepared in the meantime
+			delete(cp.orphanedClaims, claimUID)
+		}
+	}
+
+	for claimUID, info := range infos {
+		if !cp.isClaimOrphaned(ctx, info) {
+			delete(cp.orphanedClaims, claimUID)
+			continue
+		}
+		since, ok := cp.orphanedClaims[claimUID]
+		if !ok {
+			logger.Info("Claim is orphaned: none of its consumer pods exist anymore", "claim", klog.KRef(info.Namespace, info.Name), "claimUID", claimUID)
+			cp.orphanedClaims[claimUID] = now
+			continue
+		}
+		if now.Sub(since) < cp.orphanedClaimTTL {
+			continue
+		}
+		cp.releaseOrphanedClaim(klog.NewContext(ctx, klog.LoggerWithValues(logger, "claim", klog.KRef(info.Namespace, info.Name), "claimUID", claimUID)), claimUID, info)
+		delete(cp.orphanedClaims, claimUID)
+	}
+	orphanedClaims.Set(float64(len(cp.orphanedClaims)))
+}
+
+// isClaimOrphaned returns true if none of the pods the claim was reserved for exist anymore.
+// API errors other than NotFound are treated as the pod still existing.
+func (cp *CPUDriver) isClaimOrphaned(ctx context.Context, info store.ClaimInfo) bool {
+	if len(info.ReservedFor) == 0 {
+		return false
+	}
+	for _, consumer := range info.ReservedFor {
+		if consumer.Resource != "pods" || consumer.APIGroup != "" {
+			return false
+		}
+		pod, err := cp.kubeClient.CoreV1().Pods(info.Namespace).Get(ctx, consumer.Name, metav1.GetOptions{})
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				klog.FromContext(ctx).V(4).Info("Failed to get the consumer pod of the claim", "claim", klog.KRef(info.Namespace, info.Name), "pod", klog.KRef(info.Namespace, consumer.Name), "err", err)
+				return false
+			}
+			continue
+		}
+		if pod.UID == consumer.UID {
+			return false
+		}
+	}
+	return true
+}
+
+func (cp *CPUDriver) releaseOrphanedClaim(ctx context.Context, claimUID types.UID, info store.ClaimInfo) {
+	logger := klog.FromContext(ctx)
+	// serialized with the prepare and the unprepare of the claims, so the CPUs, the CDI device and the
+	// cache allocation of a claim unprepared and prepared again meanwhile are left alone.
+	cp.settingsMu.Lock()
+	cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claimUID)
+	if !ok {
+		cp.settingsMu.Unlock()
+		return
+	}
+	logger.Info("Releasing the CPUs of the orphaned claim", "cpus", cpus.String(), "ttl", cp.orphanedClaimTTL)
+	cp.journalRelease(logger, JOURNAL_EVENT_ORPHANED_RELEASE, claimUID, info.Namespace, info.Name, cpus)
+	cp.cpuAllocationStore.RemoveResourceClaimAllocation(claimUID)
+	if err := cp.cdiMgr.RemoveDevice(getCDIDeviceName(claimUID)); err != nil {
+		logger.Error(err, "Failed to remove the CDI device of the orphaned claim")
+	}
+	if err := cp.releaseCacheAllocation(claimUID); err != nil {
+		logger.Error(err, "Failed to release the cache allocation of the orphaned claim")
+	}
+	cp.settingsMu.Unlock()
+	cp.claimTracker.Cleanup(logger, claimUID)
+	orphanedClaimsReleased.Inc()
+	cp.eventRecorder.Eventf(claimReference(claimUID, info.Namespace, info.Name), corev1.EventTypeWarning, eventReasonOrphanedClaimReleased,
+		"CPUs %s released on node %s: the consumer pods disappeared without the claim being unprepared", cpus.String(), cp.nodeName)
+
+	updates := cp.getSharedContainerUpdates("")
+	if len(updates) == 0 {
+		return
+	}
+	if _, err := cp.nriPlugin.UpdateContainers(updates); err != nil {
+		logger.Error(err, "Failed to update the shared containers after releasing the orphaned claim")
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/cpuset"
+)
+
+func TestCleanupOrphanedClaims(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	ttl := 5 * time.Minute
+
+	podRef := func(name string, uid types.UID) []resourceapi.ResourceClaimConsumerReference {
+		return []resourceapi.ResourceClaimConsumerReference{{Resource: "pods", Name: name, UID: uid}}
+	}
+	kubeClient := fake.NewClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "running", UID: "running-uid"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "recreated", UID: "new-uid"}},
+	)
+	nriStub := &fakeNRIStub{}
+	cdiMgr := newMockCdiMgr()
+	recorder := record.NewFakeRecorder(10)
+	cp := &CPUDriver{
+		kubeClient:         kubeClient,
+		cpuAllocationStore: store.NewCPUAllocation(topo, cpuset.New()),
+		podConfigStore:     store.NewPodConfig(),
+		claimTracker:       store.NewClaimTracker(),
+		cdiMgr:             cdiMgr,
+		nriPlugin:          nriStub,
+		eventRecorder:      recorder,
+		orphanedClaimTTL:   ttl,
+		orphanedClaims:     make(map[types.UID]time.Time),
+	}
+	cp.podConfigStore.SetContainerState("shared-pod", store.NewContainerState("shared-ctr", "shared-ctr-id"))
+
+	claims := map[types.UID]struct {
+		cpus cpuset.CPUSet
+		info *store.ClaimInfo
+	}{
+		"claim-running":   {cpus: cpuset.New(0), info: &store.ClaimInfo{Namespace: "ns", Name: "running", ReservedFor: podRef("running", "running-uid")}},
+		"claim-deleted":   {cpus: cpuset.New(1), info: &store.ClaimInfo{Namespace: "ns", Name: "deleted", ReservedFor: podRef("deleted", "deleted-uid")}},
+		"claim-recreated": {cpus: cpuset.New(2), info: &store.ClaimInfo{Namespace: "ns", Name: "recreated", ReservedFor: podRef("recreated", "old-uid")}},
+		"claim-recovered": {cpus: cpuset.New(3)},
+	}
+	for claimUID, claim := range claims {
+		cp.cpuAllocationStore.AddResourceClaimAllocation(claimUID, claim.cpus)
+		if claim.info != nil {
+			cp.cpuAllocationStore.SetResourceClaimInfo(claimUID, *claim.info)
+		}
+		_ = cdiMgr.AddDevice(getCDIDeviceName(claimUID), "")
+	}
+
+	start := time.Now()
+	cp.cleanupOrphanedClaims(context.Background(), start)
+	require.Len(t, cp.orphanedClaims, 2)
+	require.True(t, cp.cpuAllocationStore.GetSharedCPUs().Equals(cpuset.New(4, 5, 6, 7)))
+
+	cp.cleanupOrphanedClaims(context.Background(), start.Add(ttl-time.Second))
+	require.Len(t, cp.orphanedClaims, 2)
+	require.True(t, cp.cpuAllocationStore.GetSharedCPUs().Equals(cpuset.New(4, 5, 6, 7)))
+	require.Empty(t, nriStub.updates)
+
+	cp.cleanupOrphanedClaims(context.Background(), start.Add(ttl))
+	require.Empty(t, cp.orphanedClaims)
+	require.True(t, cp.cpuAllocationStore.GetSharedCPUs().Equals(cpuset.New(1, 2, 4, 5, 6, 7)))
+	for claimUID := range claims {
+		_, allocated := cp.cpuAllocationStore.GetResourceClaimAllocation(claimUID)
+		_, hasCDIDevice := cdiMgr.devices[getCDIDeviceName(claimUID)]
+		released := claimUID == "claim-deleted" || claimUID == "claim-recreated"
+		require.Equal(t, !released, allocated, "claim %s", claimUID)
+		require.Equal(t, !released, hasCDIDevice, "claim %s", claimUID)
+	}
+	require.Len(t, recorder.Events, 2)
+	require.Len(t, nriStub.updates, 2)
+	require.Equal(t, "1-2,4-7", nriStub.updates[1].Linux.Resources.Cpu.Cpus)
+}
+
+func TestCleanupOrphanedClaimsPodReappears(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	kubeClient := fake.NewClientset()
+	cp := &CPUDriver{
+		kubeClient:         kubeClient,
+		cpuAllocationStore: store.NewCPUAllocation(topo, cpuset.New()),
+		orphanedClaimTTL:   time.Minute,
+		orphanedClaims:     make(map[types.UID]time.Time),
+	}
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-1", cpuset.New(0))
+	cp.cpuAllocationStore.SetResourceClaimInfo("claim-1", store.ClaimInfo{
+		Namespace:   "ns",
+		Name:        "claim-1",
+		ReservedFor: []resourceapi.ResourceClaimConsumerReference{{Resource: "pods", Name: "pod-1", UID: "pod-uid-1"}},
+	})
+
+	start := time.Now()
+	cp.cleanupOrphanedClaims(context.Background(), start)
+	require.Contains(t, cp.orphanedClaims, types.UID("claim-1"))
+
+	_, err := kubeClient.CoreV1().Pods("ns").Create(context.Background(), &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod-1", UID: "pod-uid-1"}}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	cp.cleanupOrphanedClaims(context.Background(), start.Add(time.Hour))
+	require.Empty(t, cp.orphanedClaims)
+	_, ok := cp.cpuAllocationStore.GetResourceClaimAllocation("claim-1")
+	require.True(t, ok)
+
+	// unprepared claims are forgotten
+	require.NoError(t, kubeClient.CoreV1().Pods("ns").Delete(context.Background(), "pod-1", metav1.DeleteOptions{}))
+	cp.cleanupOrphanedClaims(context.Background(), start)
+	require.Len(t, cp.orphanedClaims, 1)
+	cp.cpuAllocationStore.RemoveResourceClaimAllocation("claim-1")
+	cp.cleanupOrphanedClaims(context.Background(), start.Add(time.Hour))
+	require.Empty(t, cp.orphanedClaims)
+}
+
+func TestReleaseOrphanedClaimWaitsForUnprepare(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	recorder := record.NewFakeRecorder(10)
+	cp := &CPUDriver{
+		cpuAllocationStore: store.NewCPUAllocation(topo, cpuset.New()),
+		podConfigStore:     store.NewPodConfig(),
+		claimTracker:       store.NewClaimTracker(),
+		cdiMgr:             newMockCdiMgr(),
+		nriPlugin:          &fakeNRIStub{},
+		eventRecorder:      recorder,
+	}
+	info := store.ClaimInfo{Namespace: "ns", Name: "claim-1"}
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-1", cpuset.New(1))
+	cp.cpuAllocationStore.SetResourceClaimInfo("claim-1", info)
+
+	// an unprepare of the claim holds settingsMu until its CPUs are freed.
+	cp.settingsMu.RLock()
+	released := make(chan struct{})
+	go func() {
+		cp.releaseOrphanedClaim(context.Background(), "claim-1", info)
+		close(released)
+	}()
+	cp.cpuAllocationStore.RemoveResourceClaimAllocation("claim-1")
+	cp.settingsMu.RUnlock()
+	<-released
+
+	require.Empty(t, recorder.Events)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+const (
+	// pinningVerificationPeriod is how often the none enforcement backend verifies the CPUs of the containers.
+	pinningVerificationPeriod = 30 * time.Second
+	// eventReasonCPUPinningMismatch is the reason of the events recorded on the claims whose container
+	// doesn't run on the CPUs of its claims with the none enforcement backend.
+	eventReasonCPUPinningMismatch = "CPUPinningMismatch"
+)
+
+// verifyPinning checks, with the none enforcement backend, whether something else, e.g. the kubelet
+// CPU Manager, pinned the containers of the claims to the CPUs the driver allocated them. The matching
+// and mismatching containers are counted, and a warning event is recorded on the claims of a container
+// when it starts mismatching.
+func (cp *CPUDriver) verifyPinning(ctx context.Context, runtime containerRuntime) {
+	logger := klog.FromContext(ctx)
+	containers, err := runtime.ListContainers()
+	if err != nil {
+		logger.Error(err, "Failed to list the containers to verify their CPUs")
+		return
+	}
+	infos := cp.cpuAllocationStore.GetResourceClaimInfos()
+	matching, mismatching := 0, map[string]bool{}
+	for _, ctr := range containers {
+		claimAllocations, err := parseDRAEnvToClaimAllocations(ctr.Env)
+		if err != nil || len(claimAllocations) == 0 {
+			continue
+		}
+		expected := cpuset.New()
+		for uid, cpus := range claimAllocations {
+			// The claims moved since the container started are expected on their current CPUs.
+			if current, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(uid); ok {
+				cpus = current
+			}
+			expected = expected.Union(cpus)
+		}
+		actual, err := runtime.ReadCPUs(ctr)
+		if err != nil {
+			// The container stopped since it was listed.
+			logger.V(4).Info("Failed to read the CPUs of the container", "containerID", ctr.ID, "err", err)
+			continue
+		}
+		if actual.Equals(expected) {
+			matching++
+			continue
+		}
+		mismatching[ctr.ID] = true
+		if cp.pinningMismatches[ctr.ID] {
+			continue
+		}
+		logger.Info("Container not pinned to the CPUs of its claims", "pod", klog.KRef(ctr.PodNamespace, ctr.PodName), "container", ctr.Name, "cpus", actual.String(), "claimCPUs", expected.String())
+		for uid := range claimAllocations {
+			info, ok := infos[uid]
+			if !ok || info.Name == "" {
+				continue
+			}
+			cp.eventRecorder.Eventf(claimReference(uid, info.Namespace, info.Name), corev1.EventTypeWarning, eventReasonCPUPinningMismatch,
+				"Container %s of pod %s/%s runs on CPUs %s instead of the CPUs %s of its claims", ctr.Name, ctr.PodNamespace, ctr.PodName, actual.String(), expected.String())
+		}
+	}
+	cp.pinningMismatches = mismatching
+	pinningVerifiedContainers.WithLabelValues("match").Set(float64(matching))
+	pinningVerifiedContainers.WithLabelValues("mismatch").Set(float64(len(mismatching)))
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cgroupfs"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/cpuset"
+)
+
+func TestVerifyPinning(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, err := mockProvider.GetCPUTopology()
+	require.NoError(t, err)
+	recorder := record.NewFakeRecorder(10)
+	driver := &CPUDriver{
+		cpuAllocationStore: store.NewCPUAllocation(topo, cpuset.New()),
+		eventRecorder:      recorder,
+	}
+	driver.cpuAllocationStore.AddResourceClaimAllocation("claim-1", cpuset.New(0, 1))
+	driver.cpuAllocationStore.SetResourceClaimInfo("claim-1", store.ClaimInfo{Namespace: "ns", Name: "claim-1"})
+	// claim-2 was moved to CPUs 4-5 after its container started.
+	driver.cpuAllocationStore.AddResourceClaimAllocation("claim-2", cpuset.New(4, 5))
+	driver.cpuAllocationStore.SetResourceClaimInfo("claim-2", store.ClaimInfo{Namespace: "ns", Name: "claim-2"})
+	runtime := &fakeContainerRuntime{
+		containers: []cgroupfs.Container{
+			{ID: "pinned", Name: "app", PodName: "pod-1", PodNamespace: "ns", Env: []string{cdiEnvVarPrefix + "_claim-1=0-1"}},
+			{ID: "stale", Name: "app", PodName: "pod-2", PodNamespace: "ns", Env: []string{cdiEnvVarPrefix + "_claim-2=2-3"}},
+			{ID: "shared", Name: "app", PodName: "pod-3", PodNamespace: "ns"},
+		},
+		cpus: map[string]string{"pinned": "0-1", "stale": "2-3", "shared": "2-7"},
+	}
+
+	driver.verifyPinning(context.Background(), runtime)
+	require.Equal(t, 1.0, pinningVerifiedContainersValue(t, "match"))
+	require.Equal(t, 1.0, pinningVerifiedContainersValue(t, "mismatch"))
+	require.Len(t, recorder.Events, 1)
+	require.Contains(t, <-recorder.Events, "CPUPinningMismatch Container app of pod ns/pod-2 runs on CPUs 2-3 instead of the CPUs 4-5 of its claims")
+
+	// The mismatch is only reported when it starts.
+	driver.verifyPinning(context.Background(), runtime)
+	require.Empty(t, recorder.Events)
+
+	runtime.cpus["stale"] = "4-5"
+	driver.verifyPinning(context.Background(), runtime)
+	require.Equal(t, 2.0, pinningVerifiedContainersValue(t, "match"))
+	require.Equal(t, 0.0, pinningVerifiedContainersValue(t, "mismatch"))
+	require.Empty(t, recorder.Events)
+}
+
+func pinningVerifiedContainersValue(t *testing.T, result string) float64 {
+	m := &dto.Metric{}
+	require.NoError(t, pinningVerifiedContainers.WithLabelValues(result).Write(m))
+	return m.Gauge.GetValue()
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+// There is no NRI on Windows to update the containers, so the driver only publishes the CPU topology
+// and keeps accounting for the claims. Enforcing the claims would need the kubelet or the runtime to
+// place the containers in job objects bound to the CPUs of the claim.
+const publishOnly = true
@@ -0,0 +1,193 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/purevalidate"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+const (
+	// POD_FAILURE_POLICY_KEEP keeps the CPUs of a claim reserved while its consumer pods are failing.
+	POD_FAILURE_POLICY_KEEP = purevalidate.OnPodFailureKeep
+	// POD_FAILURE_POLICY_RELEASE gives the CPUs of a claim back to the shared pool while its consumer pods are failing.
+	POD_FAILURE_POLICY_RELEASE = purevalidate.OnPodFailureRelease
+)
+
+const (
+	// failedPodsCheckPeriod is how often the consumer pods of the claims are checked for failures.
+	failedPodsCheckPeriod = 30 * time.Second
+	// eventReasonFailedPodCPUsReleased is recorded on claims whose CPUs were released because their pods were failing.
+	eventReasonFailedPodCPUsReleased = "FailedPodCPUsReleased"
+	// eventReasonFailedPodCPUsReacquired is recorded on released claims whose CPUs were taken back by a restarting container.
+	eventReasonFailedPodCPUsReacquired = "FailedPodCPUsReacquired"
+	// crashLoopBackOff is the waiting reason of the containers the kubelet backs off restarting.
+	crashLoopBackOff = "CrashLoopBackOff"
+)
+
+// releasesOnPodFailure returns true if the claim releases its CPUs when its consumer pods are failing.
+func (config *ClaimConfig) releasesOnPodFailure() (bool, error) {
+	if err := purevalidate.ValidateOnPodFailure(config.OnPodFailure); err != nil {
+		return false, err
+	}
+	return config.OnPodFailure == POD_FAILURE_POLICY_RELEASE, nil
+}
+
+// releaseFailedPodClaims releases the CPUs of the prepared claims with the release policy whose consumer
+// pods are all failed, or crash looping, for longer than the threshold. The claims stay prepared: their
+// CPUs are taken back when one of their containers is created again, e.g. once the kubelet back-off expires.
+func (cp *CPUDriver) releaseFailedPodClaims(ctx context.Context, now time.Time) {
+	logger := klog.FromContext(ctx)
+	infos := cp.cpuAllocationStore.GetResourceClaimInfos()
+	for claimUID := range cp.failingClaims {
+		if _, ok := infos[claimUID]; !ok {
+			// unprepared or released in the meantime
+			delete(cp.failingClaims, claimUID)
+		}
+	}
+
+	for claimUID, info := range infos {
+		if !info.ReleaseOnPodFailure || !cp.areConsumersFailing(ctx, info) {
+			delete(cp.failingClaims, claimUID)
+			continue
+		}
+		since, ok := cp.failingClaims[claimUID]
+		if !ok {
+			logger.Info("Claim is failing: all its consumer pods are failed or crash looping", "claim", klog.KRef(info.Namespace, info.Name), "claimUID", claimUID)
+			cp.failingClaims[claimUID] = now
+			continue
+		}
+		if now.Sub(since) < cp.podFailureThreshold {
+			continue
+		}
+		cp.releaseFailedPodClaim(klog.LoggerWithValues(logger, "claim", klog.KRef(info.Namespace, info.Name), "claimUID", claimUID), claimUID, info)
+		delete(cp.failingClaims, claimUID)
+	}
+}
+
+// areConsumersFailing returns true if all the pods the claim was reserved for are failed or have a
+// container in CrashLoopBackOff. Pods which can't be read are treated as healthy.
+func (cp *CPUDriver) areConsumersFailing(ctx context.Context, info store.ClaimInfo) bool {
+	if len(info.ReservedFor) == 0 {
+		return false
+	}
+	for _, consumer := range info.ReservedFor {
+		if consumer.Resource != "pods" || consumer.APIGroup != "" {
+			return false
+		}
+		pod, err := cp.kubeClient.CoreV1().Pods(info.Namespace).Get(ctx, consumer.Name, metav1.GetOptions{})
+		if err != nil {
+			klog.FromContext(ctx).V(4).Info("Failed to get the consumer pod of the claim", "claim", klog.KRef(info.Namespace, info.Name), "pod", klog.KRef(info.Namespace, consumer.Name), "err", err)
+			return false
+		}
+		if pod.UID != consumer.UID || !isPodFailing(pod) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPodFailing returns true if the pod failed or one of its containers is in CrashLoopBackOff.
+func isPodFailing(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodFailed {
+		return true
+	}
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			if status.State.Waiting != nil && status.State.Waiting.Reason == crashLoopBackOff {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// releaseFailedPodClaim gives the CPUs of a claim back to the shared pool, remembering them until the
+// claim is unprepared or its CPUs are taken back.
+func (cp *CPUDriver) releaseFailedPodClaim(logger klog.Logger, claimUID types.UID, info store.ClaimInfo) {
+	// serialized with the prepare and the unprepare of the claims, so the CPUs of a claim unprepared
+	// meanwhile aren't remembered, nor the CPUs of a claim prepared meanwhile handed out twice.
+	cp.settingsMu.Lock()
+	cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claimUID)
+	if !ok {
+		cp.settingsMu.Unlock()
+		return
+	}
+	logger.Info("Releasing the CPUs of the claim whose pods are failing", "cpus", cpus.String(), "threshold", cp.podFailureThreshold)
+	cp.releasedClaimsMu.Lock()
+	cp.releasedClaims[claimUID] = store.ClaimAllocation{ClaimUID: claimUID, ClaimInfo: info, CPUs: cpus}
+	cp.releasedClaimsMu.Unlock()
+	cp.cpuAllocationStore.RemoveResourceClaimAllocation(claimUID)
+	cp.settingsMu.Unlock()
+	cp.eventRecorder.Eventf(claimReference(claimUID, info.Namespace, info.Name), corev1.EventTypeWarning, eventReasonFailedPodCPUsReleased,
+		"CPUs %s released to the shared pool on node %s: the consumer pods are failing for more than %v", cpus.String(), cp.nodeName, cp.podFailureThreshold)
+
+	updates := cp.getSharedContainerUpdates("")
+	if len(updates) == 0 {
+		return
+	}
+	if _, err := cp.nriPlugin.UpdateContainers(updates); err != nil {
+		logger.Error(err, "Failed to update the shared containers after releasing the claim")
+	}
+}
+
+// reacquireReleasedClaims takes back the CPUs of the released claims of a container being created. It
+// fails if any of those CPUs was allocated to another claim meanwhile: the claim must then be prepared
+// again, e.g. by recreating the pod.
+func (cp *CPUDriver) reacquireReleasedClaims(logger klog.Logger, claimAllocations map[types.UID]cpuset.CPUSet) error {
+	// serialized with the prepare of the claims, which could otherwise be allocated the released CPUs
+	// between the check and the allocation below, and with their unprepare.
+	cp.settingsMu.Lock()
+	defer cp.settingsMu.Unlock()
+	cp.releasedClaimsMu.Lock()
+	defer cp.releasedClaimsMu.Unlock()
+	for claimUID := range claimAllocations {
+		released, ok := cp.releasedClaims[claimUID]
+		if !ok {
+			continue
+		}
+		if others := cp.cpuAllocationStore.GetClaimAllocationsUsing(released.CPUs); len(others) > 0 {
+			return fmt.Errorf("CPUs %s of claim %s/%s, released while its pods were failing, are allocated to claim %s/%s", released.CPUs.String(), released.Namespace, released.Name, others[0].Namespace, others[0].Name)
+		}
+		if !released.CPUs.IsSubsetOf(cp.cpuAllocationStore.GetAllocatableCPUs()) {
+			return fmt.Errorf("CPUs %s of claim %s/%s, released while its pods were failing, can't be allocated anymore", released.CPUs.String(), released.Namespace, released.Name)
+		}
+		cp.cpuAllocationStore.AddResourceClaimAllocation(claimUID, released.CPUs)
+		cp.cpuAllocationStore.SetResourceClaimInfo(claimUID, released.ClaimInfo)
+		delete(cp.releasedClaims, claimUID)
+		logger.Info("Reacquired the CPUs of the claim for a restarting container", "claim", klog.KRef(released.Namespace, released.Name), "claimUID", claimUID, "cpus", released.CPUs.String())
+		cp.eventRecorder.Eventf(claimReference(claimUID, released.Namespace, released.Name), corev1.EventTypeNormal, eventReasonFailedPodCPUsReacquired,
+			"CPUs %s taken back from the shared pool on node %s for a restarting container", released.CPUs.String(), cp.nodeName)
+	}
+	return nil
+}
+
+// forgetReleasedClaim drops the CPUs remembered for a released claim being unprepared.
+func (cp *CPUDriver) forgetReleasedClaim(claimUID types.UID) {
+	cp.releasedClaimsMu.Lock()
+	defer cp.releasedClaimsMu.Unlock()
+	delete(cp.releasedClaims, claimUID)
+}
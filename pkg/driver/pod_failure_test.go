@@ -0,0 +1,206 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+func TestIsPodFailing(t *testing.T) {
+	crashLooping := corev1.ContainerStatus{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: crashLoopBackOff}}}
+	pulling := corev1.ContainerStatus{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}}
+	testCases := []struct {
+		name     string
+		status   corev1.PodStatus
+		expected bool
+	}{
+		{name: "running", status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		{name: "failed", status: corev1.PodStatus{Phase: corev1.PodFailed}, expected: true},
+		{name: "crash looping", status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{crashLooping}}, expected: true},
+		{name: "crash looping init container", status: corev1.PodStatus{Phase: corev1.PodPending, InitContainerStatuses: []corev1.ContainerStatus{crashLooping}}, expected: true},
+		{name: "creating", status: corev1.PodStatus{Phase: corev1.PodPending, ContainerStatuses: []corev1.ContainerStatus{pulling}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, isPodFailing(&corev1.Pod{Status: tc.status}))
+		})
+	}
+}
+
+func TestReleaseFailedPodClaims(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	threshold := 5 * time.Minute
+
+	podRef := func(name string, uid types.UID) []resourceapi.ResourceClaimConsumerReference {
+		return []resourceapi.ResourceClaimConsumerReference{{Resource: "pods", Name: name, UID: uid}}
+	}
+	failed := corev1.PodStatus{Phase: corev1.PodFailed}
+	kubeClient := fake.NewClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "running", UID: "running-uid"}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "failed", UID: "failed-uid"}, Status: failed},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "kept", UID: "kept-uid"}, Status: failed},
+	)
+	nriStub := &fakeNRIStub{}
+	recorder := record.NewFakeRecorder(10)
+	cp := &CPUDriver{
+		kubeClient:          kubeClient,
+		cpuAllocationStore:  store.NewCPUAllocation(topo, cpuset.New()),
+		podConfigStore:      store.NewPodConfig(),
+		claimTracker:        store.NewClaimTracker(),
+		nriPlugin:           nriStub,
+		eventRecorder:       recorder,
+		podFailureThreshold: threshold,
+		failingClaims:       make(map[types.UID]time.Time),
+		releasedClaims:      make(map[types.UID]store.ClaimAllocation),
+	}
+	cp.podConfigStore.SetContainerState("shared-pod", store.NewContainerState("shared-ctr", "shared-ctr-id"))
+
+	claims := map[types.UID]struct {
+		cpus cpuset.CPUSet
+		info store.ClaimInfo
+	}{
+		"claim-running": {cpus: cpuset.New(0), info: store.ClaimInfo{Namespace: "ns", Name: "running", ReservedFor: podRef("running", "running-uid"), ReleaseOnPodFailure: true}},
+		"claim-failed":  {cpus: cpuset.New(1, 2), info: store.ClaimInfo{Namespace: "ns", Name: "failed", ReservedFor: podRef("failed", "failed-uid"), ReleaseOnPodFailure: true}},
+		"claim-kept":    {cpus: cpuset.New(3), info: store.ClaimInfo{Namespace: "ns", Name: "kept", ReservedFor: podRef("kept", "kept-uid")}},
+	}
+	for claimUID, claim := range claims {
+		cp.cpuAllocationStore.AddResourceClaimAllocation(claimUID, claim.cpus)
+		cp.cpuAllocationStore.SetResourceClaimInfo(claimUID, claim.info)
+	}
+
+	start := time.Now()
+	cp.releaseFailedPodClaims(context.Background(), start)
+	require.Len(t, cp.failingClaims, 1)
+	cp.releaseFailedPodClaims(context.Background(), start.Add(threshold-time.Second))
+	require.True(t, cp.cpuAllocationStore.GetSharedCPUs().Equals(cpuset.New(4, 5, 6, 7)))
+	require.Empty(t, nriStub.updates)
+
+	cp.releaseFailedPodClaims(context.Background(), start.Add(threshold))
+	require.Empty(t, cp.failingClaims)
+	require.True(t, cp.cpuAllocationStore.GetSharedCPUs().Equals(cpuset.New(1, 2, 4, 5, 6, 7)))
+	require.Contains(t, cp.releasedClaims, types.UID("claim-failed"))
+	require.Len(t, recorder.Events, 1)
+	require.Len(t, nriStub.updates, 1)
+	require.Equal(t, "1-2,4-7", nriStub.updates[0].Linux.Resources.Cpu.Cpus)
+
+	// the restarting container takes the CPUs back.
+	pod := &api.PodSandbox{Id: "pod-id", Name: "failed", Namespace: "ns", Uid: "failed-uid"}
+	ctr := &api.Container{Id: "ctr-id", PodSandboxId: pod.Id, Name: "ctr", Env: []string{fmt.Sprintf("%s_%s=%s", cdiEnvVarPrefix, "claim-failed", "1-2")}}
+	adjust, _, err := cp.CreateContainer(context.Background(), pod, ctr)
+	require.NoError(t, err)
+	require.Equal(t, "1-2", adjust.Linux.Resources.Cpu.Cpus)
+	cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation("claim-failed")
+	require.True(t, ok)
+	require.True(t, cpus.Equals(cpuset.New(1, 2)))
+	require.True(t, cp.cpuAllocationStore.GetResourceClaimInfos()["claim-failed"].ReleaseOnPodFailure)
+	require.Empty(t, cp.releasedClaims)
+	require.Len(t, recorder.Events, 2)
+}
+
+func TestReacquireReleasedClaimsConflict(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cp := &CPUDriver{
+		cpuAllocationStore: store.NewCPUAllocation(topo, cpuset.New()),
+		releasedClaims: map[types.UID]store.ClaimAllocation{
+			"claim-1": {ClaimUID: "claim-1", ClaimInfo: store.ClaimInfo{Namespace: "ns", Name: "claim-1"}, CPUs: cpuset.New(1, 2)},
+		},
+	}
+	cp.cpuAllocationStore.AddResourceClaimAllocation("claim-2", cpuset.New(2))
+	cp.cpuAllocationStore.SetResourceClaimInfo("claim-2", store.ClaimInfo{Namespace: "ns", Name: "claim-2"})
+
+	err := cp.reacquireReleasedClaims(klog.Background(), map[types.UID]cpuset.CPUSet{"claim-1": cpuset.New(1, 2)})
+	require.ErrorContains(t, err, "ns/claim-2")
+	_, ok := cp.cpuAllocationStore.GetResourceClaimAllocation("claim-1")
+	require.False(t, ok)
+	require.Contains(t, cp.releasedClaims, types.UID("claim-1"))
+
+	// unprepared claims are forgotten
+	cp.forgetReleasedClaim("claim-1")
+	require.NoError(t, cp.reacquireReleasedClaims(klog.Background(), map[types.UID]cpuset.CPUSet{"claim-1": cpuset.New(1, 2)}))
+}
+
+func TestReleasedClaimsWaitForPrepare(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	newDriver := func() *CPUDriver {
+		return &CPUDriver{
+			cpuAllocationStore: store.NewCPUAllocation(topo, cpuset.New()),
+			podConfigStore:     store.NewPodConfig(),
+			nriPlugin:          &fakeNRIStub{},
+			eventRecorder:      record.NewFakeRecorder(10),
+			releasedClaims:     make(map[types.UID]store.ClaimAllocation),
+		}
+	}
+	info := store.ClaimInfo{Namespace: "ns", Name: "claim-1"}
+
+	t.Run("reacquire", func(t *testing.T) {
+		cp := newDriver()
+		cp.releasedClaims["claim-1"] = store.ClaimAllocation{ClaimUID: "claim-1", ClaimInfo: info, CPUs: cpuset.New(1, 2)}
+
+		// a prepare allocating the released CPUs holds settingsMu until its allocation is committed.
+		cp.settingsMu.RLock()
+		reacquired := make(chan error)
+		go func() {
+			reacquired <- cp.reacquireReleasedClaims(klog.Background(), map[types.UID]cpuset.CPUSet{"claim-1": cpuset.New(1, 2)})
+		}()
+		cp.cpuAllocationStore.AddResourceClaimAllocation("claim-2", cpuset.New(2))
+		cp.cpuAllocationStore.SetResourceClaimInfo("claim-2", store.ClaimInfo{Namespace: "ns", Name: "claim-2"})
+		cp.settingsMu.RUnlock()
+
+		require.ErrorContains(t, <-reacquired, "ns/claim-2")
+		_, ok := cp.cpuAllocationStore.GetResourceClaimAllocation("claim-1")
+		require.False(t, ok)
+	})
+
+	t.Run("release", func(t *testing.T) {
+		cp := newDriver()
+		cp.cpuAllocationStore.AddResourceClaimAllocation("claim-1", cpuset.New(1, 2))
+		cp.cpuAllocationStore.SetResourceClaimInfo("claim-1", info)
+
+		// an unprepare of the claim holds settingsMu until its CPUs are freed.
+		cp.settingsMu.RLock()
+		released := make(chan struct{})
+		go func() {
+			cp.releaseFailedPodClaim(klog.Background(), "claim-1", info)
+			close(released)
+		}()
+		cp.forgetReleasedClaim("claim-1")
+		cp.cpuAllocationStore.RemoveResourceClaimAllocation("claim-1")
+		cp.settingsMu.RUnlock()
+		<-released
+
+		require.Empty(t, cp.releasedClaims)
+	})
+}
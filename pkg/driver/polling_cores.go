@@ -0,0 +1,106 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+// pollingNICAnnotation is the pod annotation holding the PCI address of the NIC the polling cores are
+// placed next to, when the claim configuration doesn't set it.
+const pollingNICAnnotation = "dra.cpu/polling-nic"
+
+// pollingNUMANodes returns the NUMA nodes the polling cores of the claim must be taken from: the one of
+// the NIC given by PCI address in the claim configuration or in the pollingNICAnnotation of the claim pods,
+// or the NUMA nodes of the devices the claim is aligned with. Any NUMA node is fine if the platform doesn't
+// report the NUMA affinity of the NIC, which is represented by an empty set.
+func (cp *CPUDriver) pollingNUMANodes(ctx context.Context, claim *resourceapi.ResourceClaim, config *ClaimConfig, alignedNUMANodes cpuset.CPUSet) (cpuset.CPUSet, error) {
+	address := config.PollingNIC
+	if address == "" {
+		for _, consumer := range claim.Status.ReservedFor {
+			if consumer.Resource != "pods" || consumer.APIGroup != "" {
+				continue
+			}
+			pod, err := cp.kubeClient.CoreV1().Pods(claim.Namespace).Get(ctx, consumer.Name, metav1.GetOptions{})
+			if err != nil {
+				return cpuset.New(), fmt.Errorf("failed to get pod %s/%s: %w", claim.Namespace, consumer.Name, err)
+			}
+			if value, ok := pod.Annotations[pollingNICAnnotation]; ok {
+				address = value
+				break
+			}
+		}
+	}
+	if address == "" {
+		if alignedNUMANodes.Size() == 0 {
+			return cpuset.New(), fmt.Errorf("claim %s/%s asks for %d polling cores, but no NIC is set with pollingNIC, the %s pod annotation or alignWithClaim", claim.Namespace, claim.Name, config.PollingCores, pollingNICAnnotation)
+		}
+		return alignedNUMANodes, nil
+	}
+	numaNode, err := cpuinfo.PCIDeviceNUMANode(address)
+	if err != nil {
+		return cpuset.New(), fmt.Errorf("claim %s/%s polling NIC: %w", claim.Namespace, claim.Name, err)
+	}
+	if numaNode < 0 {
+		klog.FromContext(ctx).Info("NUMA node of the NIC unknown, the polling cores of the claim can be on any NUMA node", "nic", address)
+		return cpuset.New(), nil
+	}
+	klog.FromContext(ctx).Info("NIC of the claim is on the NUMA node", "nic", address, "numaNode", numaNode)
+	return cpuset.New(numaNode), nil
+}
+
+// takePollingCores returns the CPUs of numCores full physical cores out of the available CPUs, restricted
+// to the given NUMA nodes if any. Only cores whose threads are all available are taken, so no other
+// workload ever runs on the SMT siblings of a polling CPU.
+func (cp *CPUDriver) takePollingCores(availableCPUs, numaNodes cpuset.CPUSet, numCores int) (cpuset.CPUSet, error) {
+	topo := cp.cpuTopology
+	candidates := availableCPUs
+	if numaNodes.Size() > 0 {
+		candidates = candidates.Intersection(topo.CPUDetails.CPUsInNUMANodes(numaNodes.List()...))
+	}
+	pollingCPUs := cpuset.New()
+	visited := cpuset.New()
+	taken := 0
+	for _, cpuID := range candidates.List() {
+		if taken == numCores {
+			break
+		}
+		if visited.Contains(cpuID) {
+			continue
+		}
+		// core IDs are only unique within a socket
+		info := topo.CPUDetails[cpuID]
+		coreCPUs := topo.CPUDetails.CPUsInCores(info.CoreID).Intersection(topo.CPUDetails.CPUsInSockets(info.SocketID))
+		visited = visited.Union(coreCPUs)
+		if !coreCPUs.IsSubsetOf(candidates) {
+			continue
+		}
+		pollingCPUs = pollingCPUs.Union(coreCPUs)
+		taken++
+	}
+	if taken < numCores {
+		return cpuset.New(), fmt.Errorf("not enough free full cores for %d polling cores, found %d in %s", numCores, taken, candidates.String())
+	}
+	return pollingCPUs, nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/cpuset"
+	"k8s.io/utils/ptr"
+)
+
+func TestPrepareResourceClaimsPollingCores(t *testing.T) {
+	// NUMA node 0 has the cores (0,4) and (1,5), NUMA node 1 has the cores (2,6) and (3,7).
+	testCases := []struct {
+		name                string
+		podAnnotations      map[string]string
+		nicNUMANode         string
+		opaqueConfig        []string
+		numCPUs             int64
+		allocatedCPUs       cpuset.CPUSet
+		expectedError       bool
+		expectedCPUs        cpuset.CPUSet
+		expectedPollingCPUs cpuset.CPUSet
+	}{
+		{
+			name:                "polling core on the NUMA node of the NIC",
+			nicNUMANode:         "1",
+			opaqueConfig:        []string{`{"pollingCores": 1, "pollingNIC": "0000:3b:00.0"}`},
+			numCPUs:             4,
+			expectedCPUs:        cpuset.New(0, 2, 4, 6),
+			expectedPollingCPUs: cpuset.New(2, 6),
+		},
+		{
+			name:                "NIC from the pod annotation",
+			podAnnotations:      map[string]string{pollingNICAnnotation: "0000:3b:00.0"},
+			nicNUMANode:         "1",
+			opaqueConfig:        []string{`{"pollingCores": 1}`},
+			numCPUs:             2,
+			expectedCPUs:        cpuset.New(2, 6),
+			expectedPollingCPUs: cpuset.New(2, 6),
+		},
+		{
+			name:                "NUMA node of the aligned device",
+			opaqueConfig:        []string{`{"pollingCores": 1, "alignWithDriver": "dra.net"}`},
+			numCPUs:             2,
+			expectedCPUs:        cpuset.New(2, 6),
+			expectedPollingCPUs: cpuset.New(2, 6),
+		},
+		{
+			name:                "NUMA node of the NIC unknown",
+			nicNUMANode:         "-1",
+			opaqueConfig:        []string{`{"pollingCores": 1, "pollingNIC": "0000:3b:00.0"}`},
+			numCPUs:             2,
+			expectedCPUs:        cpuset.New(0, 4),
+			expectedPollingCPUs: cpuset.New(0, 4),
+		},
+		{
+			name:                "siblings of allocated CPUs are never polling cores",
+			nicNUMANode:         "1",
+			opaqueConfig:        []string{`{"pollingCores": 1, "pollingNIC": "0000:3b:00.0"}`},
+			numCPUs:             2,
+			allocatedCPUs:       cpuset.New(2),
+			expectedCPUs:        cpuset.New(3, 7),
+			expectedPollingCPUs: cpuset.New(3, 7),
+		},
+		{
+			name:          "no full core left on the NUMA node of the NIC",
+			nicNUMANode:   "1",
+			opaqueConfig:  []string{`{"pollingCores": 1, "pollingNIC": "0000:3b:00.0"}`},
+			numCPUs:       2,
+			allocatedCPUs: cpuset.New(2, 3),
+			expectedError: true,
+		},
+		{
+			name:          "polling cores exceed the request",
+			nicNUMANode:   "1",
+			opaqueConfig:  []string{`{"pollingCores": 2, "pollingNIC": "0000:3b:00.0"}`},
+			numCPUs:       2,
+			expectedError: true,
+		},
+		{
+			name:          "no NIC",
+			opaqueConfig:  []string{`{"pollingCores": 1}`},
+			numCPUs:       2,
+			expectedError: true,
+		},
+		{
+			name:          "unknown NIC",
+			opaqueConfig:  []string{`{"pollingCores": 1, "pollingNIC": "0000:5e:00.0"}`},
+			numCPUs:       2,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hostRoot := t.TempDir()
+			t.Setenv("HOST_ROOT", hostRoot)
+			if tc.nicNUMANode != "" {
+				nicDir := filepath.Join(hostRoot, "sys/bus/pci/devices/0000:3b:00.0")
+				require.NoError(t, os.MkdirAll(nicDir, 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(nicDir, "numa_node"), []byte(tc.nicNUMANode+"\n"), 0644))
+			}
+
+			claim := withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevsocket000": tc.numCPUs}), testDriverName, tc.opaqueConfig...)
+			claim.Namespace = "ns"
+			claim.Status.ReservedFor = []resourceapi.ResourceClaimConsumerReference{{Resource: "pods", Name: "pod-1"}}
+			nicNUMANode := int64(1)
+			kubeClient := fake.NewClientset(
+				claim.DeepCopy(),
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod-1", Annotations: tc.podAnnotations},
+					Status: corev1.PodStatus{
+						ResourceClaimStatuses: []corev1.PodResourceClaimStatus{
+							{Name: "cpu", ResourceClaimName: ptr.To("claim-1")},
+							{Name: "nic", ResourceClaimName: ptr.To("pod-1-nic")},
+						},
+					},
+				},
+				&resourceapi.ResourceClaim{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod-1-nic"},
+					Status: resourceapi.ResourceClaimStatus{
+						Allocation: &resourceapi.AllocationResult{
+							Devices: resourceapi.DeviceAllocationResult{
+								Results: []resourceapi.DeviceRequestAllocationResult{{Driver: "dra.net", Pool: testNodeName, Device: "eth0"}},
+							},
+						},
+					},
+				},
+				&resourceapi.ResourceSlice{
+					ObjectMeta: metav1.ObjectMeta{Name: "dra.net-slice"},
+					Spec: resourceapi.ResourceSliceSpec{
+						Driver:   "dra.net",
+						NodeName: ptr.To(testNodeName),
+						Pool:     resourceapi.ResourcePool{Name: testNodeName},
+						Devices: []resourceapi.Device{{
+							Name:       "eth0",
+							Attributes: map[resourceapi.QualifiedName]resourceapi.DeviceAttribute{"dra.net/numaNode": {IntValue: &nicNUMANode}},
+						}},
+					},
+				},
+			)
+			mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_SNC2_8CPUs_HT}
+			topo, _ := mockProvider.GetCPUTopology()
+			cp := &CPUDriver{
+				driverName:           testDriverName,
+				nodeName:             testNodeName,
+				kubeClient:           kubeClient,
+				cpuDeviceMode:        CPU_DEVICE_MODE_GROUPED,
+				cpuDeviceGroupBy:     GROUP_BY_SOCKET,
+				cpuTopology:          topo,
+				deviceNameToSocketID: map[string]int{"cpudevsocket000": 0},
+				cpuAllocationStore:   store.NewCPUAllocation(topo, cpuset.New()),
+				podConfigStore:       store.NewPodConfig(),
+				claimTracker:         store.NewClaimTracker(),
+				cdiMgr:               newMockCdiMgr(),
+			}
+			if tc.allocatedCPUs.Size() > 0 {
+				cp.cpuAllocationStore.AddResourceClaimAllocation("claim-0", tc.allocatedCPUs)
+			}
+
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			if tc.expectedError {
+				require.Error(t, results[claim.UID].Err)
+				_, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+				require.False(t, ok)
+				return
+			}
+			require.NoError(t, results[claim.UID].Err)
+			cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+			require.True(t, ok)
+			require.True(t, tc.expectedCPUs.Equals(cpus), "expected %s got %s", tc.expectedCPUs.String(), cpus.String())
+			info := cp.cpuAllocationStore.GetResourceClaimInfos()[claim.UID]
+			require.True(t, tc.expectedPollingCPUs.Equals(info.PollingCPUs), "expected polling CPUs %s got %s", tc.expectedPollingCPUs.String(), info.PollingCPUs.String())
+
+			updated, err := kubeClient.ResourceV1().ResourceClaims("ns").Get(context.Background(), "claim-1", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Len(t, updated.Status.Devices, 1)
+			require.Equal(t, "cpudevsocket000", updated.Status.Devices[0].Device)
+			status := ClaimAllocationStatus{}
+			require.NoError(t, json.Unmarshal(updated.Status.Devices[0].Data.Raw, &status))
+			require.Equal(t, tc.expectedCPUs.String(), status.CPUs)
+			require.Equal(t, tc.expectedPollingCPUs.String(), status.PollingCPUs)
+		})
+	}
+}
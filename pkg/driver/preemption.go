@@ -0,0 +1,200 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/go-logr/logr"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+const (
+	// eventReasonPreempted is recorded on claims whose CPUs were migrated to make room for a higher-priority claim.
+	eventReasonPreempted = "CPUsPreempted"
+	// eventReasonPreempting is recorded on claims which got their CPUs by migrating lower-priority claims.
+	eventReasonPreempting = "PreemptedLowerPriorityClaims"
+)
+
+// hasFullCores returns true if the CPUs include as many full physical cores as a request
+// of numCPUs can use, so the claim does not share any core with other workloads.
+func (cp *CPUDriver) hasFullCores(cpus cpuset.CPUSet, numCPUs int) bool {
+	topo := cp.cpuTopology
+	if topo.CPUsPerCore() == 0 {
+		return true
+	}
+	// core IDs are only unique within a socket
+	type coreKey struct{ socketID, coreID int }
+	fullCores := map[coreKey]bool{}
+	for _, cpuID := range cpus.List() {
+		info := topo.CPUDetails[cpuID]
+		coreCPUs := topo.CPUDetails.CPUsInCores(info.CoreID).Intersection(topo.CPUDetails.CPUsInSockets(info.SocketID))
+		if coreCPUs.IsSubsetOf(cpus) {
+			fullCores[coreKey{info.SocketID, info.CoreID}] = true
+		}
+	}
+	return len(fullCores) >= numCPUs/topo.CPUsPerCore()
+}
+
+// takeCPUs picks numCPUs out of the available CPUs of a grouped device.
+func (cp *CPUDriver) takeCPUs(logger logr.Logger, strategy cpumanager.Strategy, availableCPUs cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, error) {
+	if cp.cpuDeviceGroupBy != GROUP_BY_NUMA_NODE && cp.confineToNUMANode {
+		confined, err := confineToSingleNUMANode(cp.cpuTopology, availableCPUs, numCPUs)
+		if err != nil {
+			return cpuset.New(), err
+		}
+		availableCPUs = confined
+	}
+	return strategy.Take(logger, cp.cpuTopology, availableCPUs, numCPUs)
+}
+
+// preemptLowerPriorityClaims tries to give numCPUs full cores out of the device CPUs to the claim
+// by migrating the allocations of lower-priority claims of the same device to other CPUs.
+// Lower-priority claims keep their CPU count, but may end up sharing cores with each other.
+// The smallest set of preempted claims is chosen, lowest priority first.
+// It returns the CPUs for the claim and true if the preemption succeeded.
+func (cp *CPUDriver) preemptLowerPriorityClaims(ctx context.Context, claim *resourceapi.ResourceClaim, priority int32, strategy cpumanager.Strategy, deviceCPUs cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, bool) {
+	logger := klog.FromContext(ctx)
+	pool := cp.cpuAllocationStore.GetSharedCPUs().Intersection(deviceCPUs)
+	victims := []store.ClaimAllocation{}
+	for _, candidate := range cp.cpuAllocationStore.GetPreemptibleClaimAllocations(deviceCPUs, priority) {
+		pool = pool.Union(candidate.CPUs)
+		victims = append(victims, candidate)
+		cpus, err := cp.takeCPUs(logger, strategy, pool, numCPUs)
+		if err != nil || !cp.hasFullCores(cpus, numCPUs) {
+			continue
+		}
+		migrations, err := cp.migrateClaimAllocations(logger, pool.Difference(cpus), victims)
+		if err != nil {
+			logger.V(4).Info("Cannot migrate the lower-priority claims", "victims", len(victims), "err", err)
+			continue
+		}
+		cp.applyClaimMigrations(logger, claim, priority, migrations)
+		return cpus, true
+	}
+	logger.Info("No lower-priority claims can be preempted to get CPUs on full cores", "priority", priority, "cpus", numCPUs)
+	return cpuset.New(), false
+}
+
+// claimMigration describes the move of a claim allocation to new CPUs.
+type claimMigration struct {
+	store.ClaimAllocation
+	newCPUs cpuset.CPUSet
+}
+
+// migrateClaimAllocations assigns the victims the same number of CPUs out of the available ones.
+// Higher-priority victims pick their CPUs first.
+func (cp *CPUDriver) migrateClaimAllocations(logger logr.Logger, availableCPUs cpuset.CPUSet, victims []store.ClaimAllocation) ([]claimMigration, error) {
+	migrations := []claimMigration{}
+	for i := len(victims) - 1; i >= 0; i-- {
+		cpus, err := cpumanager.TakeByTopologyNUMAPacked(logger, cp.cpuTopology, availableCPUs, victims[i].CPUs.Size(), cpumanager.CPUSortingStrategyPacked, true)
+		if err != nil {
+			return nil, fmt.Errorf("claim %s: %w", victims[i].ClaimUID, err)
+		}
+		availableCPUs = availableCPUs.Difference(cpus)
+		migrations = append(migrations, claimMigration{ClaimAllocation: victims[i], newCPUs: cpus})
+	}
+	return migrations, nil
+}
+
+// applyClaimMigrations moves the preempted claims to their new CPUs and records the preemption events.
+func (cp *CPUDriver) applyClaimMigrations(logger logr.Logger, claim *resourceapi.ResourceClaim, priority int32, migrations []claimMigration) {
+	preempted := []string{}
+	for _, migration := range migrations {
+		if migration.newCPUs.Equals(migration.CPUs) {
+			continue
+		}
+		logger.Info("Preempting lower-priority claim", "preemptedClaim", klog.KRef(migration.Namespace, migration.Name), "preemptedClaimUID", migration.ClaimUID,
+			"preemptedPriority", migration.Priority, "cpus", migration.CPUs.String(), "newCPUs", migration.newCPUs.String(), "priority", priority)
+		preempted = append(preempted, fmt.Sprintf("%s/%s", migration.Namespace, migration.Name))
+		if migration.Name != "" {
+			cp.eventRecorder.Eventf(claimReference(migration.ClaimUID, migration.Namespace, migration.Name), corev1.EventTypeWarning, eventReasonPreempted,
+				"CPUs %s migrated to %s on node %s to make room for claim %s/%s with priority %d", migration.CPUs.String(), migration.newCPUs.String(), cp.nodeName, claim.Namespace, claim.Name, priority)
+		}
+	}
+	if len(preempted) == 0 {
+		return
+	}
+	cp.eventRecorder.Eventf(claimReference(claim.UID, claim.Namespace, claim.Name), corev1.EventTypeNormal, eventReasonPreempting,
+		"Migrated the CPUs of lower-priority claims %v on node %s to get full cores", preempted, cp.nodeName)
+	cp.moveClaims(logger, migrations)
+}
+
+// moveClaims moves the claims to their new CPUs: it updates the allocation store, the CDI spec
+// and the cpuset of the running containers owning the claims.
+// Running containers keep the DRA environment variable they were started with; the new
+// allocation is recovered from the CDI spec only for containers created afterwards.
+func (cp *CPUDriver) moveClaims(logger logr.Logger, migrations []claimMigration) {
+	for _, migration := range migrations {
+		if migration.newCPUs.Equals(migration.CPUs) {
+			continue
+		}
+		cp.cpuAllocationStore.AddResourceClaimAllocation(migration.ClaimUID, migration.newCPUs)
+		envVar := claimCDIEnv(migration.ClaimUID, migration.newCPUs, migration.PollingCPUs.Intersection(migration.newCPUs))
+		if err := cp.cdiMgr.AddDevice(getCDIDeviceName(migration.ClaimUID), envVar...); err != nil {
+			logger.Error(err, "Failed to update the CDI device of the moved claim", "movedClaim", klog.KRef(migration.Namespace, migration.Name), "movedClaimUID", migration.ClaimUID)
+		}
+		if err := cp.moveCacheAllocation(migration.ClaimUID, migration.newCPUs); err != nil {
+			logger.Error(err, "Failed to move the cache allocation of the moved claim", "movedClaim", klog.KRef(migration.Namespace, migration.Name), "movedClaimUID", migration.ClaimUID)
+		}
+	}
+
+	updates := []*api.ContainerUpdate{}
+	for _, migration := range migrations {
+		owner, ok := cp.claimTracker.FindOwner(logger, migration.ClaimUID)
+		if !ok {
+			// the container using the claim is not started yet, it will get the new CPUs from the CDI spec.
+			continue
+		}
+		state := cp.podConfigStore.GetContainerState(owner.PodUID, owner.ContainerName)
+		if state == nil {
+			continue
+		}
+		containerCPUs := cpuset.New()
+		for _, claimUID := range state.ResourceClaimUIDs() {
+			cpus, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(claimUID)
+			containerCPUs = containerCPUs.Union(cpus)
+		}
+		update := &api.ContainerUpdate{ContainerId: string(state.ContainerUID())}
+		update.SetLinuxCPUSetCPUs(containerCPUs.String())
+		updates = append(updates, update)
+	}
+	if len(updates) == 0 {
+		return
+	}
+	if _, err := cp.nriPlugin.UpdateContainers(updates); err != nil {
+		logger.Error(err, "Failed to update the containers of the moved claims")
+	}
+}
+
+func claimReference(uid types.UID, namespace, name string) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: resourceapi.SchemeGroupVersion.String(),
+		Kind:       "ResourceClaim",
+		Namespace:  namespace,
+		Name:       name,
+		UID:        uid,
+	}
+}
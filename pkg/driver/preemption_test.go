@@ -0,0 +1,230 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/containerd/nri/pkg/stub"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+type fakeNRIStub struct {
+	stub.Stub
+	updates []*api.ContainerUpdate
+}
+
+func (f *fakeNRIStub) UpdateContainers(updates []*api.ContainerUpdate) ([]*api.ContainerUpdate, error) {
+	f.updates = append(f.updates, updates...)
+	return nil, nil
+}
+
+func TestPrepareResourceClaimsPreemption(t *testing.T) {
+	// cores are (0,2) and (1,3): the two low-priority claims leave no full core free.
+	lowPriorityClaims := map[types.UID]store.ClaimInfo{
+		"claim-low-1": {Namespace: "ns", Name: "low-1", Priority: 1},
+		"claim-low-2": {Namespace: "ns", Name: "low-2", Priority: 5},
+	}
+	lowPriorityCPUs := map[types.UID]cpuset.CPUSet{
+		"claim-low-1": cpuset.New(0),
+		"claim-low-2": cpuset.New(1),
+	}
+
+	testCases := []struct {
+		name                string
+		opaqueConfig        []string
+		expectedCPUs        cpuset.CPUSet
+		expectedMigrations  map[types.UID]cpuset.CPUSet
+		expectedEventsCount int
+	}{
+		{
+			name:         "no priority, no preemption",
+			expectedCPUs: cpuset.New(2, 3),
+		},
+		{
+			name:         "priority not higher than the allocated claims",
+			opaqueConfig: []string{`{"priority": 1}`},
+			expectedCPUs: cpuset.New(2, 3),
+		},
+		{
+			name:                "lowest priority claim is preempted",
+			opaqueConfig:        []string{`{"priority": 10}`},
+			expectedCPUs:        cpuset.New(0, 2),
+			expectedMigrations:  map[types.UID]cpuset.CPUSet{"claim-low-1": cpuset.New(3)},
+			expectedEventsCount: 2,
+		},
+		{
+			name:                "only lower priority claims are preempted",
+			opaqueConfig:        []string{`{"priority": 2}`},
+			expectedCPUs:        cpuset.New(0, 2),
+			expectedMigrations:  map[types.UID]cpuset.CPUSet{"claim-low-1": cpuset.New(3)},
+			expectedEventsCount: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_4CPUS_HT}
+			topo, _ := mockProvider.GetCPUTopology()
+			nriStub := &fakeNRIStub{}
+			recorder := record.NewFakeRecorder(10)
+			cp := &CPUDriver{
+				driverName:             testDriverName,
+				cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+				cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+				cpuTopology:            topo,
+				deviceNameToNUMANodeID: map[string]int{"cpudevnuma0": 0},
+				cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+				podConfigStore:         store.NewPodConfig(),
+				claimTracker:           store.NewClaimTracker(),
+				cdiMgr:                 newMockCdiMgr(),
+				nriPlugin:              nriStub,
+				eventRecorder:          recorder,
+			}
+			for claimUID, info := range lowPriorityClaims {
+				cp.cpuAllocationStore.AddResourceClaimAllocation(claimUID, lowPriorityCPUs[claimUID])
+				cp.cpuAllocationStore.SetResourceClaimInfo(claimUID, info)
+				podUID := types.UID("pod-" + claimUID)
+				require.NoError(t, cp.claimTracker.SetOwner(klog.Background(), claimUID, podUID, "ctr"))
+				cp.podConfigStore.SetContainerState(podUID, store.NewContainerState("ctr", types.UID("ctr-"+claimUID), claimUID))
+			}
+
+			claim := withOpaqueConfig(testClaim("claim-high", testDriverName, testNodeName, map[string]int64{"cpudevnuma0": 2}), testDriverName, tc.opaqueConfig...)
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			require.NoError(t, results[claim.UID].Err)
+
+			cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+			require.True(t, ok)
+			require.True(t, tc.expectedCPUs.Equals(cpus), "expected %s got %s", tc.expectedCPUs.String(), cpus.String())
+
+			expectedUpdates := []*api.ContainerUpdate{}
+			for claimUID, expectedCPUs := range lowPriorityCPUs {
+				if migrated, ok := tc.expectedMigrations[claimUID]; ok {
+					expectedCPUs = migrated
+					update := &api.ContainerUpdate{ContainerId: "ctr-" + string(claimUID)}
+					update.SetLinuxCPUSetCPUs(migrated.String())
+					expectedUpdates = append(expectedUpdates, update)
+					require.Equal(t, []string{fmt.Sprintf("%s_%s=%s", cdiEnvVarPrefix, claimUID, migrated.String())}, cp.cdiMgr.(*mockCdiMgr).devices[getCDIDeviceName(claimUID)])
+				}
+				got, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(claimUID)
+				require.True(t, expectedCPUs.Equals(got), "claim %s: expected %s got %s", claimUID, expectedCPUs.String(), got.String())
+			}
+			if len(expectedUpdates) == 0 {
+				require.Empty(t, nriStub.updates)
+			} else {
+				require.Equal(t, expectedUpdates, nriStub.updates)
+			}
+			require.Len(t, recorder.Events, tc.expectedEventsCount)
+		})
+	}
+}
+
+func TestHasFullCoresPerSocketCoreIDs(t *testing.T) {
+	// the cores 0 of both sockets are (0,4) and (2,6).
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_PerSocketCoreIDs_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cp := &CPUDriver{cpuTopology: topo}
+
+	testCases := []struct {
+		cpus     cpuset.CPUSet
+		numCPUs  int
+		expected bool
+	}{
+		{cpus: cpuset.New(0, 4), numCPUs: 2, expected: true},
+		{cpus: cpuset.New(0, 4, 1, 5), numCPUs: 4, expected: true},
+		{cpus: cpuset.New(0, 2), numCPUs: 2, expected: false},
+		{cpus: cpuset.New(0, 4, 2), numCPUs: 4, expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.cpus.String(), func(t *testing.T) {
+			require.Equal(t, tc.expected, cp.hasFullCores(tc.cpus, tc.numCPUs))
+		})
+	}
+}
+
+func TestPrepareResourceClaimsPreemptionConfinedToNUMANode(t *testing.T) {
+	// NUMA node 0 has the CPUs 0,1,4,5 and NUMA node 1 the CPUs 2,3,6,7: with the low-priority claims
+	// on CPUs 0 and 2, no single NUMA node has 4 free CPUs.
+	testCases := []struct {
+		name          string
+		opaqueConfig  []string
+		expectedCPUs  cpuset.CPUSet
+		expectedError string
+	}{
+		{
+			name:          "no priority",
+			expectedError: "no single NUMA node has 4 free CPUs",
+		},
+		{
+			name:          "priority not higher than the allocated claims",
+			opaqueConfig:  []string{`{"priority": 1}`},
+			expectedError: "no single NUMA node has 4 free CPUs",
+		},
+		{
+			name:         "preemption makes room on a single NUMA node",
+			opaqueConfig: []string{`{"priority": 10}`},
+			expectedCPUs: cpuset.New(0, 1, 4, 5),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_SNC2_8CPUs_HT}
+			topo, _ := mockProvider.GetCPUTopology()
+			cp := &CPUDriver{
+				driverName:           testDriverName,
+				cpuDeviceMode:        CPU_DEVICE_MODE_GROUPED,
+				cpuDeviceGroupBy:     GROUP_BY_SOCKET,
+				confineToNUMANode:    true,
+				cpuTopology:          topo,
+				deviceNameToSocketID: map[string]int{"cpudevsocket0": 0},
+				cpuAllocationStore:   store.NewCPUAllocation(topo, cpuset.New()),
+				podConfigStore:       store.NewPodConfig(),
+				claimTracker:         store.NewClaimTracker(),
+				cdiMgr:               newMockCdiMgr(),
+				nriPlugin:            &fakeNRIStub{},
+				eventRecorder:        record.NewFakeRecorder(10),
+			}
+			for i, cpu := range []int{0, 2} {
+				claimUID := types.UID(fmt.Sprintf("claim-low-%d", i))
+				cp.cpuAllocationStore.AddResourceClaimAllocation(claimUID, cpuset.New(cpu))
+				cp.cpuAllocationStore.SetResourceClaimInfo(claimUID, store.ClaimInfo{Namespace: "ns", Name: string(claimUID), Priority: 1})
+			}
+
+			claim := withOpaqueConfig(testClaim("claim-high", testDriverName, testNodeName, map[string]int64{"cpudevsocket0": 4}), testDriverName, tc.opaqueConfig...)
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, results[claim.UID].Err, tc.expectedError)
+				return
+			}
+			require.NoError(t, results[claim.UID].Err)
+			cpus, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+			require.Equal(t, tc.expectedCPUs.String(), cpus.String())
+		})
+	}
+}
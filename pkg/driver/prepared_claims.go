@@ -0,0 +1,122 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+	cdiparser "tags.cncf.io/container-device-interface/pkg/parser"
+)
+
+// preparedClaimCPUs returns the CPUs of a claim already prepared, so retries of the kubelet, e.g.
+// after a timeout, get the same CPUs back instead of a second allocation. The claim is prepared if
+// its CDI device is written with the CPUs the allocation store holds for it or, after a restart of
+// the driver, with CPUs no other claim holds, which are then restored in the store.
+func (cp *CPUDriver) preparedClaimCPUs(ctx context.Context, claim *resourceapi.ResourceClaim) (cpuset.CPUSet, bool) {
+	logger := klog.FromContext(ctx)
+	spec, err := cp.cdiMgr.GetSpec()
+	if err != nil {
+		logger.Error(err, "Failed to read the CDI spec, preparing the claim again")
+		return cpuset.New(), false
+	}
+	deviceName := getCDIDeviceName(claim.UID)
+	var cdiCPUs, pollingCPUs cpuset.CPUSet
+	found := false
+	for _, device := range spec.Devices {
+		if device.Name != deviceName {
+			continue
+		}
+		allocations, err := parseDRAEnvToClaimAllocations(device.ContainerEdits.Env)
+		if err != nil {
+			logger.Error(err, "Invalid CDI device, preparing the claim again", "cdiDevice", deviceName)
+			return cpuset.New(), false
+		}
+		cdiCPUs, found = allocations[claim.UID]
+		pollingCPUs, err = parsePollingCPUs(device.ContainerEdits.Env, claim.UID)
+		if err != nil {
+			logger.Error(err, "Invalid CDI device, preparing the claim again", "cdiDevice", deviceName)
+			return cpuset.New(), false
+		}
+	}
+	if !found {
+		return cpuset.New(), false
+	}
+
+	if cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID); ok {
+		if !cpus.Equals(cdiCPUs) {
+			logger.Info("CDI device out of sync with the allocation, preparing the claim again", "cdiCPUs", cdiCPUs.String(), "cpus", cpus.String())
+			return cpuset.New(), false
+		}
+		return cpus, true
+	}
+	for _, allocation := range cp.cpuAllocationStore.GetClaimAllocationsUsing(cdiCPUs) {
+		logger.Info("CPUs of the CDI device allocated to another claim, preparing the claim again", "cpus", cdiCPUs.String(), "otherClaimUID", allocation.ClaimUID)
+		return cpuset.New(), false
+	}
+	claimConfig, err := cp.getClaimConfig(claim)
+	if err != nil {
+		return cpuset.New(), false
+	}
+	strategy, err := cp.placementStrategy(claimConfig)
+	if err != nil {
+		return cpuset.New(), false
+	}
+	claimInfo, err := newClaimInfo(claim, claimConfig, pollingCPUs, cp.bindsMemoryNodes(strategy))
+	if err != nil {
+		logger.Error(err, "Invalid claim config, preparing the claim again")
+		return cpuset.New(), false
+	}
+	if err := cp.applyCacheAllocation(ctx, claim, claimConfig, cdiCPUs); err != nil {
+		logger.Error(err, "Failed to apply the cache allocation of the claim, preparing the claim again")
+		return cpuset.New(), false
+	}
+	// the CPUs of a claim released while its pods were failing are taken back as well.
+	logger.Info("Restoring the CPUs of the claim prepared before the driver restarted", "cpus", cdiCPUs.String(), "pollingCPUs", pollingCPUs.String())
+	cp.forgetReleasedClaim(claim.UID)
+	cp.cpuAllocationStore.AddResourceClaimAllocation(claim.UID, cdiCPUs)
+	cp.cpuAllocationStore.SetResourceClaimInfo(claim.UID, claimInfo)
+	return cdiCPUs, true
+}
+
+// parsePollingCPUs returns the polling CPUs of a claim recorded in the environment of its CDI device.
+func parsePollingCPUs(envs []string, claimUID types.UID) (cpuset.CPUSet, error) {
+	prefix := fmt.Sprintf("%s_%s=", cdiPollingEnvVarPrefix, claimUID)
+	for _, env := range envs {
+		if value, ok := strings.CutPrefix(env, prefix); ok {
+			cpus, err := cpuset.Parse(value)
+			if err != nil {
+				return cpuset.New(), fmt.Errorf("failed to parse cpuset value %q from env %q: %w", value, env, err)
+			}
+			return cpus, nil
+		}
+	}
+	return cpuset.New(), nil
+}
+
+// preparedResult returns the prepare result of a claim already prepared with the given CPUs.
+func (cp *CPUDriver) preparedResult(ctx context.Context, claim *resourceapi.ResourceClaim, cpus cpuset.CPUSet) kubeletplugin.PrepareResult {
+	qualifiedName := cdiparser.QualifiedName(cdiVendor, cdiClass, getCDIDeviceName(claim.UID))
+	klog.FromContext(ctx).Info("Claim already prepared", "cdiDevice", qualifiedName, "cpus", cpus.String())
+	return kubeletplugin.PrepareResult{Devices: cp.preparedDevices(claim, qualifiedName)}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin"
+	"k8s.io/utils/cpuset"
+)
+
+func TestPrepareResourceClaimsRetries(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_SNC2_8CPUs_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cdiMgr := newMockCdiMgr()
+	newDriver := func() *CPUDriver {
+		return &CPUDriver{
+			driverName:             testDriverName,
+			cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+			cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+			cpuTopology:            topo,
+			deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0},
+			cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+			cdiMgr:                 cdiMgr,
+		}
+	}
+	claim := testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma000": 2})
+	prepare := func(cp *CPUDriver) kubeletplugin.PrepareResult {
+		results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+		require.NoError(t, err)
+		require.NoError(t, results[claim.UID].Err)
+		return results[claim.UID]
+	}
+
+	cp := newDriver()
+	first := prepare(cp)
+	cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+	require.True(t, ok)
+	require.Equal(t, 2, cpus.Size())
+
+	// the kubelet retries the claims whose prepare timed out, possibly many times.
+	for range 20 {
+		require.Equal(t, first.Devices, prepare(cp).Devices)
+	}
+	retriedCPUs, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+	require.True(t, cpus.Equals(retriedCPUs), "expected %s got %s", cpus, retriedCPUs)
+	require.True(t, topo.CPUDetails.CPUs().Difference(cpus).Equals(cp.cpuAllocationStore.GetSharedCPUs()))
+
+	// after a restart, the CPUs are taken back from the CDI spec.
+	restarted := newDriver()
+	require.Equal(t, first.Devices, prepare(restarted).Devices)
+	restoredCPUs, ok := restarted.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+	require.True(t, ok)
+	require.True(t, cpus.Equals(restoredCPUs), "expected %s got %s", cpus, restoredCPUs)
+	require.Equal(t, claim.Name, restarted.cpuAllocationStore.GetResourceClaimInfos()[claim.UID].Name)
+
+	// CPUs of the CDI spec allocated meanwhile to another claim are not handed out twice.
+	conflicting := newDriver()
+	conflicting.cpuAllocationStore.AddResourceClaimAllocation("claim-2", cpus)
+	prepare(conflicting)
+	newCPUs, _ := conflicting.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+	require.Equal(t, 2, newCPUs.Size())
+	require.True(t, newCPUs.Intersection(cpus).IsEmpty(), "CPUs %s handed out twice", newCPUs.Intersection(cpus))
+	require.Equal(t, []string{cdiEnvVarPrefix + "_claim-1=" + newCPUs.String()}, cdiMgr.devices[getCDIDeviceName(claim.UID)])
+}
+
+func TestPrepareResourceClaimsRetryRestoresClaimInfo(t *testing.T) {
+	hostRoot := t.TempDir()
+	t.Setenv("HOST_ROOT", hostRoot)
+	nicDir := filepath.Join(hostRoot, "sys/bus/pci/devices/0000:3b:00.0")
+	require.NoError(t, os.MkdirAll(nicDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(nicDir, "numa_node"), []byte("1\n"), 0644))
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_SNC2_8CPUs_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cdiMgr := newMockCdiMgr()
+	newDriver := func(claim *resourceapi.ResourceClaim) *CPUDriver {
+		return &CPUDriver{
+			driverName:           testDriverName,
+			nodeName:             testNodeName,
+			kubeClient:           fake.NewClientset(claim.DeepCopy()),
+			cpuDeviceMode:        CPU_DEVICE_MODE_GROUPED,
+			cpuDeviceGroupBy:     GROUP_BY_SOCKET,
+			cpuTopology:          topo,
+			deviceNameToSocketID: map[string]int{"cpudevsocket000": 0},
+			cpuAllocationStore:   store.NewCPUAllocation(topo, cpuset.New()),
+			cdiMgr:               cdiMgr,
+		}
+	}
+	claim := withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevsocket000": 4}), testDriverName,
+		`{"priority": 5, "pollingCores": 1, "pollingNIC": "0000:3b:00.0", "onPodFailure": "release", "cpuBandwidth": "claim", "strategy": "spread-numa"}`)
+	claim.Namespace = "ns"
+	claim.Status.ReservedFor = []resourceapi.ResourceClaimConsumerReference{{Resource: "pods", Name: "pod-1", UID: "pod-1"}}
+
+	cp := newDriver(claim)
+	results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err)
+	require.NoError(t, results[claim.UID].Err)
+	info := cp.cpuAllocationStore.GetResourceClaimInfos()[claim.UID]
+	require.Equal(t, 2, info.PollingCPUs.Size())
+
+	// after a restart, the info of the claim is restored with the CPUs of the CDI spec.
+	restarted := newDriver(claim)
+	results, err = restarted.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err)
+	require.NoError(t, results[claim.UID].Err)
+	restoredInfo := restarted.cpuAllocationStore.GetResourceClaimInfos()[claim.UID]
+	require.Equal(t, info.Namespace, restoredInfo.Namespace)
+	require.Equal(t, info.Name, restoredInfo.Name)
+	require.Equal(t, info.Priority, restoredInfo.Priority)
+	require.Equal(t, info.ReservedFor, restoredInfo.ReservedFor)
+	require.True(t, info.PollingCPUs.Equals(restoredInfo.PollingCPUs), "expected polling CPUs %s got %s", info.PollingCPUs.String(), restoredInfo.PollingCPUs.String())
+	require.Equal(t, info.ReleaseOnPodFailure, restoredInfo.ReleaseOnPodFailure)
+	require.Equal(t, info.CPUBandwidthFromClaim, restoredInfo.CPUBandwidthFromClaim)
+	require.Equal(t, info.BindMemoryNodes, restoredInfo.BindMemoryNodes)
+	require.Equal(t, info, restoredInfo)
+
+	// an invalid config isn't restored as the default policies, preparing the claim fails instead.
+	invalid := claim.DeepCopy()
+	invalid.Status.Allocation.Devices.Config = nil
+	invalid = withOpaqueConfig(invalid, testDriverName, `{"onPodFailure": "restart"}`)
+	restarted = newDriver(invalid)
+	results, err = restarted.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{invalid})
+	require.NoError(t, err)
+	require.Error(t, results[claim.UID].Err)
+	_, ok := restarted.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+	require.False(t, ok)
+}
+
+func TestPrepareResourceClaimsRetryAppliesCacheAllocation(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_SingleSocket_SNC2_8CPUs_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	resctrlMgr, resctrlPath := newFakeResctrl(t)
+	cdiMgr := newMockCdiMgr()
+	newDriver := func() *CPUDriver {
+		return &CPUDriver{
+			driverName:             testDriverName,
+			cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+			cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+			cpuTopology:            topo,
+			deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0},
+			cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+			cdiMgr:                 cdiMgr,
+			resctrl:                resctrlMgr,
+		}
+	}
+	claim := withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma000": 2}), testDriverName, `{"cacheWays": 4}`)
+	groupPath := filepath.Join(resctrlPath, "dracpu-claim-1")
+	results, err := newDriver().PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err)
+	require.NoError(t, results[claim.UID].Err)
+	require.NoError(t, os.RemoveAll(groupPath))
+
+	// after a restart, the resctrl group of the claim restored from the CDI spec is written again.
+	restarted := newDriver()
+	results, err = restarted.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err)
+	require.NoError(t, results[claim.UID].Err)
+	cpus, _ := restarted.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+	groupCPUs, err := os.ReadFile(filepath.Join(groupPath, "cpus_list"))
+	require.NoError(t, err)
+	require.Equal(t, cpus.String()+"\n", string(groupCPUs))
+}
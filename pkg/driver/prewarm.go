@@ -0,0 +1,223 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/cpuset"
+)
+
+const (
+	// prewarmQueueSize bounds the pods waiting for the pre-computation of their claims, the pods
+	// beyond it are skipped and their claims placed when the kubelet prepares them.
+	prewarmQueueSize = 128
+	// prewarmedPlacementTTL is how long the placements of a claim the kubelet never prepares are kept.
+	prewarmedPlacementTTL = 10 * time.Minute
+)
+
+// prewarmedClaim are the placements pre-computed for a claim, by placementKey.
+type prewarmedClaim struct {
+	resourceVersion string
+	computed        time.Time
+	placements      map[string]cpuset.CPUSet
+}
+
+// placementCache holds the placements pre-computed for the claims allocated to the node before the kubelet
+// prepares them. A placement is only used if the strategy, the free CPUs of the device and the number of CPUs
+// are the ones it was computed from, so it is the placement the strategy would pick at prepare time.
+type placementCache struct {
+	mu     sync.Mutex
+	claims map[types.UID]*prewarmedClaim
+}
+
+func newPlacementCache() *placementCache {
+	return &placementCache{claims: map[types.UID]*prewarmedClaim{}}
+}
+
+func placementKey(strategy cpumanager.Strategy, availableCPUs cpuset.CPUSet, numCPUs int) string {
+	return fmt.Sprintf("%s/%s/%d", strategy.Name(), availableCPUs.String(), numCPUs)
+}
+
+// computed reports whether the placements of the claim at that resource version were pre-computed.
+func (c *placementCache) computed(claim *resourceapi.ResourceClaim) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prewarmed, ok := c.claims[claim.UID]
+	return ok && prewarmed.resourceVersion == claim.ResourceVersion
+}
+
+// start drops the placements of the claim, before they are pre-computed again, and those expired.
+func (c *placementCache) start(claim *resourceapi.ResourceClaim, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for uid, prewarmed := range c.claims {
+		if now.Sub(prewarmed.computed) > prewarmedPlacementTTL {
+			delete(c.claims, uid)
+		}
+	}
+	c.claims[claim.UID] = &prewarmedClaim{resourceVersion: claim.ResourceVersion, computed: now, placements: map[string]cpuset.CPUSet{}}
+}
+
+func (c *placementCache) add(claimUID types.UID, key string, cpus cpuset.CPUSet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if prewarmed, ok := c.claims[claimUID]; ok {
+		prewarmed.placements[key] = cpus
+	}
+}
+
+// get returns the placement of the key and whether the claim was pre-computed at all.
+func (c *placementCache) get(claimUID types.UID, key string) (cpuset.CPUSet, bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prewarmed, ok := c.claims[claimUID]
+	if !ok {
+		return cpuset.New(), false, false
+	}
+	cpus, found := prewarmed.placements[key]
+	return cpus, found, true
+}
+
+func (c *placementCache) forget(claimUID types.UID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.claims, claimUID)
+}
+
+// takePlacement picks numCPUs out of the available CPUs of a grouped device with the strategy, reusing the
+// CPUs of a claim restored after a reboot, or the placement pre-computed for the claim when the free CPUs
+// did not change since. The simulations of the pre-computation record their placements.
+func (cp *CPUDriver) takePlacement(logger logr.Logger, claimUID types.UID, strategy cpumanager.Strategy, availableCPUs cpuset.CPUSet, numCPUs int) (cpuset.CPUSet, error) {
+	if cpus, ok := cp.restoredPlacement(claimUID, availableCPUs, numCPUs); ok {
+		logger.Info("Reusing the CPUs the claim had before the node rebooted", "cpus", cpus.String())
+		return cpus, nil
+	}
+	if cp.placements == nil {
+		return strategy.Take(logger, cp.cpuTopology, availableCPUs, numCPUs)
+	}
+	key := placementKey(strategy, availableCPUs, numCPUs)
+	if !cp.dryRun {
+		cpus, found, prewarmed := cp.placements.get(claimUID, key)
+		if found {
+			prewarmedPlacements.WithLabelValues("hit").Inc()
+			logger.V(2).Info("Using the pre-computed placement", "cpus", cpus.String())
+			return cpus, nil
+		}
+		if prewarmed {
+			prewarmedPlacements.WithLabelValues("stale").Inc()
+			logger.V(2).Info("Pre-computed placement stale, the free CPUs changed since")
+		}
+		return strategy.Take(logger, cp.cpuTopology, availableCPUs, numCPUs)
+	}
+	cpus, err := strategy.Take(logger, cp.cpuTopology, availableCPUs, numCPUs)
+	if err == nil {
+		cp.placements.add(claimUID, key, cpus)
+	}
+	return cpus, err
+}
+
+// startClaimPrewarmer watches the pods bound to the node and pre-computes the placements of their claims
+// allocated to the node, while their images are pulled, so the kubelet prepare doesn't wait for them.
+func (cp *CPUDriver) startClaimPrewarmer(ctx context.Context) {
+	logger := klog.FromContext(ctx)
+	queue := make(chan *corev1.Pod, prewarmQueueSize)
+	factory := informers.NewSharedInformerFactoryWithOptions(cp.kubeClient, 0, informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", cp.nodeName).String()
+	}))
+	enqueue := func(obj any) {
+		pod, ok := obj.(*corev1.Pod)
+		if !ok || pod.Status.Phase != corev1.PodPending || len(pod.Status.ResourceClaimStatuses) == 0 {
+			return
+		}
+		select {
+		case queue <- pod:
+		default:
+			logger.V(4).Info("Pre-computation queue full, skipping the claims of the pod", "pod", klog.KObj(pod))
+		}
+	}
+	if _, err := factory.Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    enqueue,
+		UpdateFunc: func(_, obj any) { enqueue(obj) },
+	}); err != nil {
+		logger.Error(err, "Failed to watch the pods of the node, the claims are not pre-computed")
+		return
+	}
+	factory.Start(ctx.Done())
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case pod := <-queue:
+				cp.prewarmPodClaims(ctx, pod)
+			}
+		}
+	}()
+}
+
+// prewarmPodClaims pre-computes the placements of the claims of the pod allocated to the node and not
+// prepared yet, in a simulation of their prepare.
+func (cp *CPUDriver) prewarmPodClaims(ctx context.Context, pod *corev1.Pod) {
+	logger := klog.LoggerWithValues(klog.FromContext(ctx), "pod", klog.KObj(pod))
+	for _, status := range pod.Status.ResourceClaimStatuses {
+		if status.ResourceClaimName == nil {
+			continue
+		}
+		claim, err := cp.kubeClient.ResourceV1().ResourceClaims(pod.Namespace).Get(ctx, *status.ResourceClaimName, metav1.GetOptions{})
+		if err != nil {
+			logger.V(4).Info("Failed to get the claim to pre-compute", "claim", klog.KRef(pod.Namespace, *status.ResourceClaimName), "err", err)
+			continue
+		}
+		if !cp.allocatedToNode(claim) || cp.placements.computed(claim) {
+			continue
+		}
+		if _, prepared := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID); prepared {
+			continue
+		}
+		cp.placements.start(claim, time.Now())
+		claimLogger := klog.LoggerWithValues(logger, "claim", klog.KObj(claim), "claimUID", claim.UID)
+		simulated := cp.SimulatePrepare(klog.NewContext(ctx, klog.LoggerWithValues(claimLogger, "prewarm", true)), claim)
+		claimLogger.V(4).Info("Pre-computed the placement of the claim", "cpus", simulated.CPUs, "error", simulated.Error)
+	}
+}
+
+// allocatedToNode returns true if devices of the driver on the node are allocated to the claim.
+func (cp *CPUDriver) allocatedToNode(claim *resourceapi.ResourceClaim) bool {
+	if claim.Status.Allocation == nil {
+		return false
+	}
+	for _, result := range claim.Status.Allocation.Devices.Results {
+		if result.Driver == cp.driverName && result.Pool == cp.nodeName {
+			return true
+		}
+	}
+	return false
+}
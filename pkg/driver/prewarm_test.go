@@ -0,0 +1,109 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/cpuset"
+	"k8s.io/utils/ptr"
+)
+
+func TestPrewarmPodClaims(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+
+	testCases := []struct {
+		name string
+		// otherClaimCPUs are allocated to another claim between the pre-computation and the prepare.
+		otherClaimCPUs cpuset.CPUSet
+		expectedResult string
+		expectedCPUs   cpuset.CPUSet
+	}{
+		{
+			name:           "pre-computed placement used",
+			expectedResult: "hit",
+			expectedCPUs:   cpuset.New(0, 4),
+		},
+		{
+			name:           "free CPUs changed since",
+			otherClaimCPUs: cpuset.New(0, 4),
+			expectedResult: "stale",
+			expectedCPUs:   cpuset.New(1, 5),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claim := testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma000": 2})
+			claim.Namespace = "ns"
+			other := testClaim("claim-2", testDriverName, "other-node", map[string]int64{"cpudevnuma000": 2})
+			other.Namespace = "ns"
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "ns"},
+				Spec:       corev1.PodSpec{NodeName: testNodeName},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodPending,
+					ResourceClaimStatuses: []corev1.PodResourceClaimStatus{
+						{Name: "cpus", ResourceClaimName: ptr.To(claim.Name)},
+						{Name: "remote", ResourceClaimName: ptr.To(other.Name)},
+						{Name: "missing", ResourceClaimName: ptr.To("missing")},
+					},
+				},
+			}
+			cp := &CPUDriver{
+				driverName:             testDriverName,
+				nodeName:               testNodeName,
+				kubeClient:             fake.NewClientset(claim, other),
+				cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+				cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+				cpuTopology:            topo,
+				deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0, "cpudevnuma001": 1},
+				cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+				cdiMgr:                 newMockCdiMgr(),
+				placements:             newPlacementCache(),
+			}
+
+			cp.prewarmPodClaims(context.Background(), pod)
+			require.True(t, cp.placements.computed(claim))
+			require.False(t, cp.placements.computed(other), "claims of other nodes are not pre-computed")
+			_, prepared := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+			require.False(t, prepared, "the pre-computation must not allocate the CPUs")
+
+			if tc.otherClaimCPUs.Size() > 0 {
+				cp.cpuAllocationStore.AddResourceClaimAllocation(types.UID("claim-other"), tc.otherClaimCPUs)
+			}
+			before := counterValue(t, prewarmedPlacements.WithLabelValues(tc.expectedResult))
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			require.NoError(t, results[claim.UID].Err)
+			cpus, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+			require.True(t, tc.expectedCPUs.Equals(cpus), "expected %s, got %s", tc.expectedCPUs, cpus)
+			require.Equal(t, before+1, counterValue(t, prewarmedPlacements.WithLabelValues(tc.expectedResult)))
+			require.False(t, cp.placements.computed(claim), "the placements of a prepared claim are dropped")
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultPublishQPS and DefaultPublishBurst rate limit the ResourceSlice publications of a node.
+	DefaultPublishQPS   = 1.0
+	DefaultPublishBurst = 5
+	// publishResyncJitter spreads the periodic publications of the nodes over 20% of the period.
+	publishResyncJitter = 0.2
+)
+
+// startPublisher starts the loop publishing the resources on request, coalescing the requests made
+// while a publication waits for the rate limiter into a single publication, and republishing them
+// every resyncPeriod, with jitter, if positive.
+func (cp *CPUDriver) startPublisher(ctx context.Context, qps float32, burst int, resyncPeriod time.Duration) {
+	cp.publishRequests = make(chan struct{}, 1)
+	limiter := flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+	go func() {
+		defer limiter.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-cp.publishRequests:
+			}
+			if err := limiter.Wait(ctx); err != nil {
+				return
+			}
+			cp.PublishResources(ctx)
+		}
+	}()
+	if resyncPeriod > 0 {
+		go wait.JitterUntilWithContext(ctx, cp.requestPublish, resyncPeriod, publishResyncJitter, false)
+	}
+}
+
+// requestPublish asks the publisher to publish the resources, or publishes them right away if the
+// publisher is not running. A request made while another one is pending is coalesced with it.
+func (cp *CPUDriver) requestPublish(ctx context.Context) {
+	if cp.publishRequests == nil {
+		cp.PublishResources(ctx)
+		return
+	}
+	select {
+	case cp.publishRequests <- struct{}{}:
+	default:
+		klog.FromContext(ctx).V(4).Info("Publication already pending, coalescing the request")
+		publishCoalesced.Inc()
+	}
+}
+
+// resourceSliceWriteObserver observes the ResourceSlice writes of the kubelet plugin. PublishResources of
+// the plugin only hands the slices to its resourceslice controller, which writes them in the background
+// and retries the failed writes without reporting them to the caller. The outcome of the writes is the
+// publish status of the driver.
+type resourceSliceWriteObserver struct {
+	cp   *CPUDriver
+	next http.RoundTripper
+}
+
+func (o *resourceSliceWriteObserver) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet || !strings.Contains(req.URL.Path, "/apis/resource.k8s.io/") || !strings.Contains(req.URL.Path, "/resourceslices") {
+		return o.next.RoundTrip(req)
+	}
+	start := time.Now()
+	resp, err := o.next.RoundTrip(req)
+	now := time.Now()
+	publishDuration.Observe(now.Sub(start).Seconds())
+	switch {
+	case err != nil:
+		o.cp.recordPublish(now, fmt.Errorf("failed to write ResourceSlice: %w", err))
+	case resp.StatusCode == http.StatusConflict:
+		// the controller writes the slice again with its latest version.
+		publishConflicts.Inc()
+	case resp.StatusCode == http.StatusNotFound && req.Method == http.MethodDelete:
+		// deleted meanwhile, e.g. by the garbage collector.
+		o.cp.recordPublish(now, nil)
+	case resp.StatusCode >= http.StatusBadRequest:
+		o.cp.recordPublish(now, fmt.Errorf("ResourceSlice write rejected by the API server: %s", resp.Status))
+	default:
+		o.cp.recordPublish(now, nil)
+	}
+	return resp, err
+}
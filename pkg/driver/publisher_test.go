@@ -0,0 +1,136 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"k8s.io/dynamic-resource-allocation/resourceslice"
+	"k8s.io/utils/cpuset"
+)
+
+// countingKubeletPlugin counts the publications.
+type countingKubeletPlugin struct {
+	mu        sync.Mutex
+	published int
+}
+
+func (m *countingKubeletPlugin) PublishResources(ctx context.Context, resources resourceslice.DriverResources) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.published++
+	return nil
+}
+
+func (m *countingKubeletPlugin) Stop() {}
+
+func (m *countingKubeletPlugin) publications() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.published
+}
+
+func newPublisherTestDriver(t *testing.T, plugin KubeletPlugin) *CPUDriver {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, err := mockProvider.GetCPUTopology()
+	require.NoError(t, err)
+	return &CPUDriver{
+		nodeName:               testNodeName,
+		draPlugin:              plugin,
+		cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+		cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+		deviceNameToCPUID:      make(map[string]int),
+		deviceNameToSocketID:   make(map[string]int),
+		deviceNameToNUMANodeID: make(map[string]int),
+		cpuTopology:            topo,
+		cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+	}
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	require.NoError(t, counter.Write(m))
+	return m.Counter.GetValue()
+}
+
+func TestPublisherCoalescesRequests(t *testing.T) {
+	plugin := &countingKubeletPlugin{}
+	cp := newPublisherTestDriver(t, plugin)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	coalesced := counterValue(t, publishCoalesced)
+
+	// the burst allows a single publication, the next one waits for the rate limiter.
+	cp.startPublisher(ctx, 5, 1, 0)
+	cp.requestPublish(ctx)
+	require.Eventually(t, func() bool { return plugin.publications() == 1 }, 5*time.Second, 10*time.Millisecond)
+	for range 5 {
+		cp.requestPublish(ctx)
+	}
+	// at most one request waits for the rate limiter and one is pending, the others are coalesced.
+	require.Eventually(t, func() bool { return plugin.publications() >= 2 }, 5*time.Second, 10*time.Millisecond)
+	require.Never(t, func() bool { return plugin.publications() > 3 }, time.Second, 50*time.Millisecond)
+	require.GreaterOrEqual(t, counterValue(t, publishCoalesced)-coalesced, float64(3))
+}
+
+func TestPublisherResync(t *testing.T) {
+	plugin := &countingKubeletPlugin{}
+	cp := newPublisherTestDriver(t, plugin)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cp.startPublisher(ctx, 100, 10, 20*time.Millisecond)
+	require.Eventually(t, func() bool { return plugin.publications() >= 3 }, 5*time.Second, 10*time.Millisecond)
+}
+
+// roundTripperFunc answers the requests of a client.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestResourceSliceWriteObserver(t *testing.T) {
+	statusCode := http.StatusOK
+	observer := &resourceSliceWriteObserver{cp: newPublisherTestDriver(t, &mockKubeletPlugin{}), next: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: statusCode, Body: http.NoBody, Request: req}, nil
+	})}
+	conflicts := counterValue(t, publishConflicts)
+	do := func(method, path string) {
+		req, err := http.NewRequest(method, "https://apiserver"+path, nil)
+		require.NoError(t, err)
+		_, err = observer.RoundTrip(req)
+		require.NoError(t, err)
+	}
+
+	statusCode = http.StatusConflict
+	do(http.MethodPut, "/apis/resource.k8s.io/v1/resourceslices/slice-1")
+	require.Equal(t, float64(1), counterValue(t, publishConflicts)-conflicts)
+	// the watches of the controller and the writes of other resources are not publications.
+	do(http.MethodGet, "/apis/resource.k8s.io/v1/resourceslices")
+	do(http.MethodPatch, "/api/v1/nodes/"+testNodeName+"/status")
+	require.Equal(t, float64(1), counterValue(t, publishConflicts)-conflicts)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/utils/cpuset"
+	"sigs.k8s.io/yaml"
+)
+
+// updateGolden rewrites the golden files with the current output: go test ./pkg/driver -run Golden -update
+var updateGolden = flag.Bool("update", false, "update the golden files of the tests")
+
+// goldenSlice is the part of a published ResourceSlice set by the driver, as users see it in the API.
+type goldenSlice struct {
+	Devices []resourceapi.Device `json:"devices"`
+}
+
+// TestPublishResourcesGolden renders the ResourceSlices of canonical topologies in every device mode and
+// compares them with the files of testdata/resourceslices. The device names and attributes are the API
+// users write their CEL selectors against: a change of the golden files is a change visible to them.
+func TestPublishResourcesGolden(t *testing.T) {
+	topologies := []struct {
+		name     string
+		cpuInfos []cpuinfo.CPUInfo
+		smt      bool
+	}{
+		{name: "2-socket-smt", cpuInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT, smt: true},
+		{name: "1-socket-no-smt", cpuInfos: mockCPUInfos_SingleSocket_4CPUs_HT_Off},
+		{name: "hybrid", cpuInfos: mockCPUInfos_SingleSocket_Hybrid_HT, smt: true},
+		// 2 sockets in NPS4, like an AMD EPYC.
+		{name: "8-numa", cpuInfos: newFakeCPUInfos(2, 4, 1), smt: true},
+	}
+	modes := []struct {
+		name       string
+		deviceMode string
+		groupBy    string
+	}{
+		{name: "individual", deviceMode: CPU_DEVICE_MODE_INDIVIDUAL},
+		{name: "grouped-numanode", deviceMode: CPU_DEVICE_MODE_GROUPED, groupBy: GROUP_BY_NUMA_NODE},
+		{name: "grouped-socket", deviceMode: CPU_DEVICE_MODE_GROUPED, groupBy: GROUP_BY_SOCKET},
+		{name: "grouped-node", deviceMode: CPU_DEVICE_MODE_GROUPED, groupBy: GROUP_BY_NODE},
+	}
+
+	for _, topology := range topologies {
+		for _, mode := range modes {
+			name := topology.name + "-" + mode.name
+			t.Run(name, func(t *testing.T) {
+				mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: topology.cpuInfos}
+				topo, err := mockProvider.GetCPUTopology()
+				require.NoError(t, err)
+				// the mock leaves SMT detection, read from sysfs, to the caller.
+				topo.SMTEnabled = topology.smt
+				mockPlugin := &mockKubeletPlugin{}
+				cp := &CPUDriver{
+					driverName:             testDriverName,
+					nodeName:               testNodeName,
+					draPlugin:              mockPlugin,
+					cpuTopology:            topo,
+					cpuDeviceMode:          mode.deviceMode,
+					cpuDeviceGroupBy:       mode.groupBy,
+					deviceNameToCPUID:      make(map[string]int),
+					deviceNameToSocketID:   make(map[string]int),
+					deviceNameToNUMANodeID: make(map[string]int),
+					cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+				}
+				cp.PublishResources(context.Background())
+				require.NotNil(t, mockPlugin.publishedResources)
+
+				slices := []goldenSlice{}
+				for _, slice := range mockPlugin.publishedResources.Pools[testNodeName].Slices {
+					slices = append(slices, goldenSlice{Devices: slice.Devices})
+				}
+				got, err := yaml.Marshal(slices)
+				require.NoError(t, err)
+
+				goldenFile := filepath.Join("testdata", "resourceslices", name+".yaml")
+				if *updateGolden {
+					require.NoError(t, os.MkdirAll(filepath.Dir(goldenFile), 0755))
+					require.NoError(t, os.WriteFile(goldenFile, got, 0644))
+				}
+				want, err := os.ReadFile(goldenFile)
+				require.NoError(t, err, "missing golden file, run the test with -update to create it")
+				require.Equal(t, string(want), string(got), "ResourceSlices differ from %s, run the test with -update if the change is intended", goldenFile)
+			})
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/containerd/nri/pkg/api"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SANDBOXED_RUNTIME_POLICY_PIN writes the cpuset of the containers of sandboxed pods like any other.
+	SANDBOXED_RUNTIME_POLICY_PIN = "pin"
+	// SANDBOXED_RUNTIME_POLICY_ANNOTATE passes the CPUs of the containers of sandboxed pods to the runtime
+	// in the sandboxedCPUsAnnotation instead of writing their cpuset.
+	SANDBOXED_RUNTIME_POLICY_ANNOTATE = "annotate"
+	// SANDBOXED_RUNTIME_POLICY_REJECT fails to prepare the claims of sandboxed pods.
+	SANDBOXED_RUNTIME_POLICY_REJECT = "reject"
+)
+
+// sandboxedCPUsAnnotation is the OCI annotation of the containers of sandboxed pods holding their CPUs,
+// for the runtimes placing the vCPUs of the sandbox themselves, e.g. kata with enable_vcpus_pinning.
+const sandboxedCPUsAnnotation = "dra.cpu/cpuset.cpus"
+
+// DefaultSandboxedRuntimeHandlers are the runtime handlers of the common VM-based and user space kernel
+// runtimes, whose container cgroups aren't the ones the workload runs in on the host.
+var DefaultSandboxedRuntimeHandlers = []string{"kata", "kata-qemu", "kata-clh", "kata-fc", "kata-dragonball", "runsc", "gvisor"}
+
+// isSandboxedRuntime reports whether the runtime handler of a pod is one of the sandboxed runtimes.
+func (cp *CPUDriver) isSandboxedRuntime(handler string) bool {
+	return handler != "" && slices.Contains(cp.sandboxedRuntimeHandlers, handler)
+}
+
+// checkSandboxedConsumers fails, with the reject policy, if the claim is reserved for a pod whose
+// RuntimeClass uses a sandboxed runtime handler.
+func (cp *CPUDriver) checkSandboxedConsumers(ctx context.Context, claim *resourceapi.ResourceClaim) error {
+	if cp.sandboxedRuntimePolicy != SANDBOXED_RUNTIME_POLICY_REJECT || len(cp.sandboxedRuntimeHandlers) == 0 {
+		return nil
+	}
+	for _, consumer := range claim.Status.ReservedFor {
+		if consumer.Resource != "pods" || consumer.APIGroup != "" {
+			continue
+		}
+		pod, err := cp.kubeClient.CoreV1().Pods(claim.Namespace).Get(ctx, consumer.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get pod %s/%s: %w", claim.Namespace, consumer.Name, err)
+		}
+		if pod.Spec.RuntimeClassName == nil {
+			continue
+		}
+		runtimeClass, err := cp.kubeClient.NodeV1().RuntimeClasses().Get(ctx, *pod.Spec.RuntimeClassName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get runtime class %s of pod %s/%s: %w", *pod.Spec.RuntimeClassName, claim.Namespace, pod.Name, err)
+		}
+		if cp.isSandboxedRuntime(runtimeClass.Handler) {
+			return fmt.Errorf("claim %s/%s is reserved for pod %s running with the sandboxed runtime handler %s of runtime class %s, whose CPUs can't be pinned on node %s", claim.Namespace, claim.Name, pod.Name, runtimeClass.Handler, runtimeClass.Name, cp.nodeName)
+		}
+	}
+	return nil
+}
+
+// isSandboxedPod reports whether the cpuset of the containers of the pod is left to its sandboxed runtime.
+func (cp *CPUDriver) isSandboxedPod(pod *api.PodSandbox) bool {
+	return cp.sandboxedRuntimePolicy != SANDBOXED_RUNTIME_POLICY_PIN && cp.isSandboxedRuntime(pod.GetRuntimeHandler())
+}
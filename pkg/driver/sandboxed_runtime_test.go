@@ -0,0 +1,136 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/cpuset"
+	"k8s.io/utils/ptr"
+)
+
+func TestCheckSandboxedConsumers(t *testing.T) {
+	kubeClient := fake.NewClientset(
+		&nodev1.RuntimeClass{ObjectMeta: metav1.ObjectMeta{Name: "kata"}, Handler: "kata-qemu"},
+		&nodev1.RuntimeClass{ObjectMeta: metav1.ObjectMeta{Name: "fast"}, Handler: "crun"},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "plain"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "crun"}, Spec: corev1.PodSpec{RuntimeClassName: ptr.To("fast")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "vm"}, Spec: corev1.PodSpec{RuntimeClassName: ptr.To("kata")}},
+	)
+	claimFor := func(podName string) *resourceapi.ResourceClaim {
+		claim := testClaim("claim-1", testDriverName, testNodeName, nil)
+		claim.Namespace = "ns"
+		claim.Status.ReservedFor = []resourceapi.ResourceClaimConsumerReference{{Resource: "pods", Name: podName}}
+		return claim
+	}
+
+	testCases := []struct {
+		name          string
+		policy        string
+		pod           string
+		expectedError bool
+	}{
+		{name: "pod without runtime class", policy: SANDBOXED_RUNTIME_POLICY_REJECT, pod: "plain"},
+		{name: "pod with a runtime class not sandboxed", policy: SANDBOXED_RUNTIME_POLICY_REJECT, pod: "crun"},
+		{name: "sandboxed pod rejected", policy: SANDBOXED_RUNTIME_POLICY_REJECT, pod: "vm", expectedError: true},
+		{name: "sandboxed pod annotated", policy: SANDBOXED_RUNTIME_POLICY_ANNOTATE, pod: "vm"},
+		{name: "missing pod is only looked up when rejecting", policy: SANDBOXED_RUNTIME_POLICY_PIN, pod: "missing"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cp := &CPUDriver{
+				nodeName:                 testNodeName,
+				kubeClient:               kubeClient,
+				sandboxedRuntimeHandlers: DefaultSandboxedRuntimeHandlers,
+				sandboxedRuntimePolicy:   tc.policy,
+			}
+			err := cp.checkSandboxedConsumers(context.Background(), claimFor(tc.pod))
+			if tc.expectedError {
+				require.ErrorContains(t, err, "sandboxed runtime handler kata-qemu")
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCreateContainerSandboxed(t *testing.T) {
+	var infos []cpuinfo.CPUInfo
+	for cpuID := range 8 {
+		infos = append(infos, cpuinfo.CPUInfo{CpuID: cpuID, CoreID: cpuID, SocketID: 0, NUMANodeID: 0})
+	}
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: infos}
+	topo, _ := mockProvider.GetCPUTopology()
+	pod := &api.PodSandbox{Id: "pod-id-1", Name: "my-pod", Namespace: "my-ns", Uid: "pod-uid-1", RuntimeHandler: "kata"}
+	sharedCtr := &api.Container{Id: "ctr-id-1", PodSandboxId: pod.Id, Name: "shared"}
+	guaranteedCtr := &api.Container{Id: "ctr-id-2", PodSandboxId: pod.Id, Name: "guaranteed", Env: []string{fmt.Sprintf("%s_claim-1=2-3", cdiEnvVarPrefix)}}
+	newDriver := func(policy string) *CPUDriver {
+		cpuAllocationStore := store.NewCPUAllocation(topo, cpuset.New())
+		cpuAllocationStore.AddResourceClaimAllocation("claim-1", cpuset.New(2, 3))
+		return &CPUDriver{
+			podConfigStore:           store.NewPodConfig(),
+			cpuAllocationStore:       cpuAllocationStore,
+			claimTracker:             store.NewClaimTracker(),
+			sandboxedRuntimeHandlers: DefaultSandboxedRuntimeHandlers,
+			sandboxedRuntimePolicy:   policy,
+		}
+	}
+
+	t.Run("annotate", func(t *testing.T) {
+		cp := newDriver(SANDBOXED_RUNTIME_POLICY_ANNOTATE)
+		adjust, updates, err := cp.CreateContainer(context.Background(), pod, sharedCtr)
+		require.NoError(t, err)
+		require.Empty(t, updates)
+		require.Equal(t, &api.ContainerAdjustment{Annotations: map[string]string{sandboxedCPUsAnnotation: "0-1,4-7"}}, adjust)
+		require.Empty(t, cp.podConfigStore.GetContainersWithSharedCPUs(), "the sandbox must not get the shared CPUs updates")
+
+		adjust, _, err = cp.CreateContainer(context.Background(), pod, guaranteedCtr)
+		require.NoError(t, err)
+		require.Equal(t, &api.ContainerAdjustment{Annotations: map[string]string{sandboxedCPUsAnnotation: "2-3"}}, adjust)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		cp := newDriver(SANDBOXED_RUNTIME_POLICY_REJECT)
+		adjust, _, err := cp.CreateContainer(context.Background(), pod, sharedCtr)
+		require.NoError(t, err)
+		require.Equal(t, &api.ContainerAdjustment{}, adjust)
+
+		_, _, err = cp.CreateContainer(context.Background(), pod, guaranteedCtr)
+		require.Error(t, err)
+	})
+
+	t.Run("pin", func(t *testing.T) {
+		cp := newDriver(SANDBOXED_RUNTIME_POLICY_PIN)
+		adjust, _, err := cp.CreateContainer(context.Background(), pod, guaranteedCtr)
+		require.NoError(t, err)
+		require.Equal(t, &api.ContainerAdjustment{
+			Linux: &api.LinuxContainerAdjustment{Resources: &api.LinuxResources{Cpu: &api.LinuxCPU{Cpus: "2-3"}}},
+		}, adjust)
+	})
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"maps"
+
+	"github.com/containerd/nri/pkg/api"
+	"github.com/containerd/nri/pkg/stub"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// SimulatedPrepare is the outcome of a prepare simulated by SimulatePrepare.
+type SimulatedPrepare struct {
+	// CPUs are the CPUs the claim would get, empty if it can't be prepared.
+	CPUs string `json:"cpus"`
+	// Preempted are the CPUs the lower-priority claims moved to make room for the claim would have, by claim UID.
+	Preempted map[string]string `json:"preempted,omitempty"`
+	// Error is why the claim can't be prepared.
+	Error string `json:"error,omitempty"`
+}
+
+// SimulatePrepare runs the allocator for an allocated claim against the current state of the node without
+// committing anything: the claim is prepared by a copy of the driver, whose allocations, CDI devices,
+// container updates, events and resctrl groups are discarded. Claims already prepared report their CPUs.
+func (cp *CPUDriver) SimulatePrepare(ctx context.Context, claim *resourceapi.ResourceClaim) SimulatedPrepare {
+	sim := cp.simulationDriver()
+	results, err := sim.PrepareResourceClaims(ctx, []*resourceapi.ResourceClaim{claim})
+	if err == nil {
+		err = results[claim.UID].Err
+	}
+	if err != nil {
+		return SimulatedPrepare{Error: err.Error()}
+	}
+	cpus, _ := sim.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+	simulated := SimulatedPrepare{CPUs: cpus.String()}
+	for _, allocation := range cp.cpuAllocationStore.GetClaimAllocationsUsing(cp.cpuTopology.CPUDetails.CPUs()) {
+		after, _ := sim.cpuAllocationStore.GetResourceClaimAllocation(allocation.ClaimUID)
+		if allocation.ClaimUID == claim.UID || allocation.CPUs.Equals(after) {
+			continue
+		}
+		if simulated.Preempted == nil {
+			simulated.Preempted = map[string]string{}
+		}
+		simulated.Preempted[string(allocation.ClaimUID)] = after.String()
+	}
+	return simulated
+}
+
+// simulationDriver returns a driver sharing the settings and a copy of the state of the driver, whose
+// changes are not committed.
+func (cp *CPUDriver) simulationDriver() *CPUDriver {
+	cp.settingsMu.RLock()
+	smtIsolation, cpuPools := cp.smtIsolation, cp.cpuPools
+	cp.settingsMu.RUnlock()
+	cp.devicesMu.RLock()
+	deviceNameToCPUID := maps.Clone(cp.deviceNameToCPUID)
+	deviceNameToSocketID := maps.Clone(cp.deviceNameToSocketID)
+	deviceNameToNUMANodeID := maps.Clone(cp.deviceNameToNUMANodeID)
+	nodeDevicePublished := cp.nodeDevicePublished
+	cp.devicesMu.RUnlock()
+	return &CPUDriver{
+		dryRun:                   true,
+		driverName:               cp.driverName,
+		nodeName:                 cp.nodeName,
+		kubeClient:               cp.kubeClient,
+		nriPlugin:                dryRunNRIStub{},
+		podConfigStore:           cp.podConfigStore,
+		cpuAllocationStore:       cp.cpuAllocationStore.Clone(),
+		cdiMgr:                   dryRunCdiMgr{cp.cdiMgr},
+		cpuTopology:              cp.cpuTopology,
+		deviceNameToCPUID:        deviceNameToCPUID,
+		deviceNameToSocketID:     deviceNameToSocketID,
+		deviceNameToNUMANodeID:   deviceNameToNUMANodeID,
+		nodeDevicePublished:      nodeDevicePublished,
+		reservedCPUs:             cp.reservedCPUs,
+		housekeepingCPUs:         cp.housekeepingCPUs,
+		cpuDeviceMode:            cp.cpuDeviceMode,
+		cpuDeviceGroupBy:         cp.cpuDeviceGroupBy,
+		confineToNUMANode:        cp.confineToNUMANode,
+		allocationStrategy:       cp.allocationStrategy,
+		claimTracker:             cp.claimTracker,
+		eventRecorder:            &record.FakeRecorder{},
+		sandboxedRuntimeHandlers: cp.sandboxedRuntimeHandlers,
+		sandboxedRuntimePolicy:   cp.sandboxedRuntimePolicy,
+		requireCPULimits:         cp.requireCPULimits,
+		smtIsolation:             smtIsolation,
+		cpuPools:                 cpuPools,
+		reportAllocations:        cp.reportAllocations,
+		resctrl:                  cp.resctrl,
+		placements:               cp.placements,
+		restored:                 cp.restored,
+	}
+}
+
+// dryRunCdiMgr reads the CDI spec of the driver and discards its changes.
+type dryRunCdiMgr struct {
+	cdiManager
+}
+
+func (dryRunCdiMgr) AddDevice(string, ...string) error { return nil }
+
+func (dryRunCdiMgr) RemoveDevice(string) error { return nil }
+
+// dryRunNRIStub discards the container updates of the simulated preemptions.
+type dryRunNRIStub struct {
+	stub.Stub
+}
+
+func (dryRunNRIStub) UpdateContainers([]*api.ContainerUpdate) ([]*api.ContainerUpdate, error) {
+	return nil, nil
+}
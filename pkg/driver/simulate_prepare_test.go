@@ -0,0 +1,72 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/cpuset"
+)
+
+func TestSimulatePrepare(t *testing.T) {
+	// NUMA node 0 has CPUs 0,1,4,5, and the other claim holds CPU 0.
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cdiMgr := newMockCdiMgr()
+	recorder := record.NewFakeRecorder(10)
+	cp := &CPUDriver{
+		driverName:             testDriverName,
+		nodeName:               testNodeName,
+		cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+		cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+		cpuTopology:            topo,
+		deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0, "cpudevnuma001": 1},
+		cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+		podConfigStore:         store.NewPodConfig(),
+		claimTracker:           store.NewClaimTracker(),
+		cdiMgr:                 cdiMgr,
+		nriPlugin:              &fakeNRIStub{},
+		eventRecorder:          recorder,
+	}
+	cp.cpuAllocationStore.AddResourceClaimAllocation("other", cpuset.New(0))
+	cp.cpuAllocationStore.SetResourceClaimInfo("other", store.ClaimInfo{Namespace: "ns", Name: "other"})
+	_ = cdiMgr.AddDevice(getCDIDeviceName("other"), fmt.Sprintf("%s_%s=%s", cdiEnvVarPrefix, "other", "0"))
+
+	simulated := cp.SimulatePrepare(context.Background(), testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma000": 2}))
+	require.Equal(t, SimulatedPrepare{CPUs: "1,5"}, simulated)
+
+	simulated = cp.SimulatePrepare(context.Background(), testClaim("claim-2", testDriverName, testNodeName, map[string]int64{"cpudevnuma000": 4}))
+	require.Empty(t, simulated.CPUs)
+	require.NotEmpty(t, simulated.Error)
+
+	// the simulations are not committed.
+	_, ok := cp.cpuAllocationStore.GetResourceClaimAllocation("claim-1")
+	require.False(t, ok)
+	require.True(t, cp.cpuAllocationStore.GetSharedCPUs().Equals(cpuset.New(1, 2, 3, 4, 5, 6, 7)))
+	require.Len(t, cdiMgr.devices, 1)
+	require.Empty(t, recorder.Events)
+
+	// a prepared claim reports its CPUs.
+	simulated = cp.SimulatePrepare(context.Background(), testClaim("other", testDriverName, testNodeName, map[string]int64{"cpudevnuma000": 1}))
+	require.Equal(t, SimulatedPrepare{CPUs: "0"}, simulated)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"fmt"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/utils/cpuset"
+)
+
+const (
+	// SMT_ISOLATION_NONE lets the claims share the hyperthread siblings of the physical cores.
+	SMT_ISOLATION_NONE = "none"
+	// SMT_ISOLATION_CLAIM keeps two claims from ever sharing the hyperthread siblings of a physical core.
+	SMT_ISOLATION_CLAIM = "claim"
+	// SMT_ISOLATION_NAMESPACE keeps the claims of two namespaces, the tenants, from ever sharing the
+	// hyperthread siblings of a physical core. The claims of a namespace can share them.
+	SMT_ISOLATION_NAMESPACE = "namespace"
+)
+
+// smtIsolatedCPUs returns the CPUs of the physical cores running other claims the claim must not share
+// them with under the SMT isolation policy, mitigating the side channels across hyperthreads.
+func (cp *CPUDriver) smtIsolatedCPUs(claim *resourceapi.ResourceClaim) cpuset.CPUSet {
+	if cp.smtIsolation == "" || cp.smtIsolation == SMT_ISOLATION_NONE || cp.cpuTopology.CPUsPerCore() < 2 {
+		return cpuset.New()
+	}
+	topo := cp.cpuTopology
+	isolatedCPUs := cpuset.New()
+	for _, allocation := range cp.cpuAllocationStore.GetClaimAllocationsUsing(cp.cpuTopology.CPUDetails.CPUs()) {
+		if allocation.ClaimUID == claim.UID {
+			continue
+		}
+		if cp.smtIsolation == SMT_ISOLATION_NAMESPACE && allocation.Namespace == claim.Namespace {
+			continue
+		}
+		for _, cpuID := range allocation.CPUs.UnsortedList() {
+			if isolatedCPUs.Contains(cpuID) {
+				continue
+			}
+			// core IDs are only unique within a socket
+			info := topo.CPUDetails[cpuID]
+			isolatedCPUs = isolatedCPUs.Union(topo.CPUDetails.CPUsInCores(info.CoreID).Intersection(topo.CPUDetails.CPUsInSockets(info.SocketID)))
+		}
+	}
+	return isolatedCPUs
+}
+
+// smtIsolationError records the SMT isolation violation of a claim which failed to take numCPUs out of
+// its availableCPUs, but could have with the free CPUs of the deviceIsolatedCPUs, and explains the failure.
+func (cp *CPUDriver) smtIsolationError(claim *resourceapi.ResourceClaim, err error, availableCPUs, deviceIsolatedCPUs cpuset.CPUSet, numCPUs int) error {
+	freeIsolatedCPUs := cp.cpuAllocationStore.GetSharedCPUs().Intersection(deviceIsolatedCPUs)
+	if freeIsolatedCPUs.IsEmpty() || availableCPUs.Size()+freeIsolatedCPUs.Size() < numCPUs {
+		return err
+	}
+	if !cp.dryRun {
+		smtIsolationViolations.Inc()
+	}
+	return fmt.Errorf("claim %s/%s: %w, without the free CPUs %s of the physical cores running other claims, excluded by the %s SMT isolation", claim.Namespace, claim.Name, err, freeIsolatedCPUs.String(), cp.smtIsolation)
+}
+
+// checkSMTIsolation fails if the CPUs allocated to the claim by the scheduler share physical cores with
+// the claims excluded by the SMT isolation policy.
+func (cp *CPUDriver) checkSMTIsolation(claim *resourceapi.ResourceClaim, cpus cpuset.CPUSet) error {
+	shared := cpus.Intersection(cp.smtIsolatedCPUs(claim))
+	if shared.IsEmpty() {
+		return nil
+	}
+	if !cp.dryRun {
+		smtIsolationViolations.Inc()
+	}
+	return fmt.Errorf("claim %s/%s: CPUs %s share physical cores with other claims, which the %s SMT isolation forbids; use a CEL selector on dra.cpu/coreID to get full cores", claim.Namespace, claim.Name, shared.String(), cp.smtIsolation)
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/utils/cpuset"
+)
+
+func TestPrepareResourceClaimsSMTIsolation(t *testing.T) {
+	// NUMA node 0 has the cores 0 (CPUs 0,4) and 1 (CPUs 1,5), and the claim of tenant-a holds CPU 0.
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+
+	testCases := []struct {
+		name          string
+		policy        string
+		deviceMode    string
+		namespace     string
+		devices       map[string]int64
+		expectedCPUs  cpuset.CPUSet
+		expectedError bool
+	}{
+		{
+			name:         "siblings shared without isolation",
+			policy:       SMT_ISOLATION_NONE,
+			deviceMode:   CPU_DEVICE_MODE_GROUPED,
+			namespace:    "tenant-b",
+			devices:      map[string]int64{"cpudevnuma000": 3},
+			expectedCPUs: cpuset.New(1, 4, 5),
+		},
+		{
+			name:         "claim isolation skips the sibling of another claim",
+			policy:       SMT_ISOLATION_CLAIM,
+			deviceMode:   CPU_DEVICE_MODE_GROUPED,
+			namespace:    "tenant-a",
+			devices:      map[string]int64{"cpudevnuma000": 2},
+			expectedCPUs: cpuset.New(1, 5),
+		},
+		{
+			name:          "claim isolation impossible to satisfy",
+			policy:        SMT_ISOLATION_CLAIM,
+			deviceMode:    CPU_DEVICE_MODE_GROUPED,
+			namespace:     "tenant-a",
+			devices:       map[string]int64{"cpudevnuma000": 3},
+			expectedError: true,
+		},
+		{
+			name:         "namespace isolation shares siblings within a tenant",
+			policy:       SMT_ISOLATION_NAMESPACE,
+			deviceMode:   CPU_DEVICE_MODE_GROUPED,
+			namespace:    "tenant-a",
+			devices:      map[string]int64{"cpudevnuma000": 3},
+			expectedCPUs: cpuset.New(1, 4, 5),
+		},
+		{
+			name:          "namespace isolation across tenants",
+			policy:        SMT_ISOLATION_NAMESPACE,
+			deviceMode:    CPU_DEVICE_MODE_GROUPED,
+			namespace:     "tenant-b",
+			devices:       map[string]int64{"cpudevnuma000": 3},
+			expectedError: true,
+		},
+		{
+			name:         "individual claim on a free core",
+			policy:       SMT_ISOLATION_CLAIM,
+			deviceMode:   CPU_DEVICE_MODE_INDIVIDUAL,
+			namespace:    "tenant-b",
+			devices:      map[string]int64{"cpudev001": 1},
+			expectedCPUs: cpuset.New(1),
+		},
+		{
+			name:          "individual claim on the sibling of another claim",
+			policy:        SMT_ISOLATION_CLAIM,
+			deviceMode:    CPU_DEVICE_MODE_INDIVIDUAL,
+			namespace:     "tenant-b",
+			devices:       map[string]int64{"cpudev004": 1},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cp := &CPUDriver{
+				driverName:             testDriverName,
+				cpuDeviceMode:          tc.deviceMode,
+				cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+				cpuTopology:            topo,
+				deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0, "cpudevnuma001": 1},
+				deviceNameToCPUID:      map[string]int{"cpudev001": 1, "cpudev004": 4},
+				cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+				cdiMgr:                 newMockCdiMgr(),
+				smtIsolation:           tc.policy,
+			}
+			cp.cpuAllocationStore.AddResourceClaimAllocation("other", cpuset.New(0))
+			cp.cpuAllocationStore.SetResourceClaimInfo("other", store.ClaimInfo{Namespace: "tenant-a", Name: "other"})
+			violations := smtIsolationViolationsValue(t)
+
+			claim := testClaim("claim-1", testDriverName, testNodeName, tc.devices)
+			claim.Namespace = tc.namespace
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			if tc.expectedError {
+				require.ErrorContains(t, results[claim.UID].Err, "SMT isolation")
+				require.Equal(t, violations+1, smtIsolationViolationsValue(t))
+				_, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+				require.False(t, ok)
+				return
+			}
+			require.NoError(t, results[claim.UID].Err)
+			require.Equal(t, violations, smtIsolationViolationsValue(t))
+			cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+			require.True(t, ok)
+			require.Equal(t, tc.expectedCPUs.String(), cpus.String())
+		})
+	}
+}
+
+func smtIsolationViolationsValue(t *testing.T) float64 {
+	m := &dto.Metric{}
+	require.NoError(t, smtIsolationViolations.Write(m))
+	return m.Counter.GetValue()
+}
+
+func TestSMTIsolatedCPUsPerSocketCoreIDs(t *testing.T) {
+	// the core 0 of socket 0 is (0,4), the core 0 of socket 1 is (2,6).
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_PerSocketCoreIDs_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cp := &CPUDriver{
+		driverName:             testDriverName,
+		cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+		cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+		cpuTopology:            topo,
+		deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0, "cpudevnuma001": 1},
+		cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+		cdiMgr:                 newMockCdiMgr(),
+		smtIsolation:           SMT_ISOLATION_CLAIM,
+	}
+	cp.cpuAllocationStore.AddResourceClaimAllocation("other", cpuset.New(0))
+	cp.cpuAllocationStore.SetResourceClaimInfo("other", store.ClaimInfo{Namespace: "tenant-a", Name: "other"})
+	violations := smtIsolationViolationsValue(t)
+
+	claim := testClaim("claim-1", testDriverName, testNodeName, map[string]int64{"cpudevnuma001": 4})
+	claim.Namespace = "tenant-b"
+	require.Equal(t, cpuset.New(0, 4).String(), cp.smtIsolatedCPUs(claim).String())
+	results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+	require.NoError(t, err)
+	require.NoError(t, results[claim.UID].Err)
+	require.Equal(t, violations, smtIsolationViolationsValue(t))
+	cpus, _ := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+	require.Equal(t, cpuset.New(2, 3, 6, 7).String(), cpus.String())
+}
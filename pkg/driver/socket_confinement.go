@@ -0,0 +1,61 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"fmt"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/utils/cpuset"
+)
+
+// confinesSockets reports whether the claim configuration restricts the sockets of its CPUs.
+func (config *ClaimConfig) confinesSockets() bool {
+	return config.SocketAffinity != nil || config.MaxSockets > 0
+}
+
+// checkSocketConfinement fails if the sockets the CPUs of a claim are on break the socketAffinity or
+// maxSockets parameters of the claim. The scheduler picks the devices, and so the sockets, of a claim:
+// the claims can ask it for a single socket with a matchAttribute constraint on dra.cpu/socketID.
+func checkSocketConfinement(claim *resourceapi.ResourceClaim, config *ClaimConfig, sockets cpuset.CPUSet) error {
+	if config.SocketAffinity != nil && !sockets.Equals(cpuset.New(int(*config.SocketAffinity))) {
+		return fmt.Errorf("claim %s/%s: CPUs allocated on sockets %s, but socketAffinity requires socket %d; use a CEL selector on dra.cpu/socketID", claim.Namespace, claim.Name, sockets.String(), *config.SocketAffinity)
+	}
+	if config.MaxSockets > 0 && sockets.Size() > int(config.MaxSockets) {
+		return fmt.Errorf("claim %s/%s: CPUs allocated on %d sockets %s, more than maxSockets %d; use a matchAttribute constraint on dra.cpu/socketID", claim.Namespace, claim.Name, sockets.Size(), sockets.String(), config.MaxSockets)
+	}
+	return nil
+}
+
+// groupedClaimSockets returns the sockets of the grouped mode devices allocated to a claim.
+func (cp *CPUDriver) groupedClaimSockets(claim *resourceapi.ResourceClaim) cpuset.CPUSet {
+	cp.devicesMu.RLock()
+	defer cp.devicesMu.RUnlock()
+	sockets := []int{}
+	for _, alloc := range claim.Status.Allocation.Devices.Results {
+		if alloc.Driver != cp.driverName {
+			continue
+		}
+		if socketID, ok := cp.deviceNameToSocketID[alloc.Device]; ok {
+			sockets = append(sockets, socketID)
+		}
+		if numaNodeID, ok := cp.deviceNameToNUMANodeID[alloc.Device]; ok {
+			sockets = append(sockets, cp.cpuTopology.CPUDetails.SocketsInNUMANodes(numaNodeID).List()...)
+		}
+	}
+	return cpuset.New(sockets...)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/utils/cpuset"
+)
+
+func TestPrepareResourceClaimsSocketConfinement(t *testing.T) {
+	// 2 sockets of a single NUMA node with 2 cores each: socket 0 has CPUs 0,1,4,5 and socket 1 2,3,6,7.
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: newFakeCPUInfos(2, 1, 2)}
+	topo, _ := mockProvider.GetCPUTopology()
+
+	testCases := []struct {
+		name          string
+		deviceMode    string
+		devices       map[string]int64
+		opaqueConfig  string
+		expectedError bool
+	}{
+		{
+			name:         "grouped claim on a single socket",
+			deviceMode:   CPU_DEVICE_MODE_GROUPED,
+			devices:      map[string]int64{"cpudevnuma001": 2},
+			opaqueConfig: `{"maxSockets": 1, "socketAffinity": 1}`,
+		},
+		{
+			name:          "grouped claim across sockets",
+			deviceMode:    CPU_DEVICE_MODE_GROUPED,
+			devices:       map[string]int64{"cpudevnuma000": 2, "cpudevnuma001": 2},
+			opaqueConfig:  `{"maxSockets": 1}`,
+			expectedError: true,
+		},
+		{
+			name:          "grouped claim on another socket",
+			deviceMode:    CPU_DEVICE_MODE_GROUPED,
+			devices:       map[string]int64{"cpudevnuma000": 2},
+			opaqueConfig:  `{"socketAffinity": 1}`,
+			expectedError: true,
+		},
+		{
+			name:         "individual claim within maxSockets",
+			deviceMode:   CPU_DEVICE_MODE_INDIVIDUAL,
+			devices:      map[string]int64{"cpudev000": 1, "cpudev002": 1},
+			opaqueConfig: `{"maxSockets": 2}`,
+		},
+		{
+			name:          "individual claim across sockets",
+			deviceMode:    CPU_DEVICE_MODE_INDIVIDUAL,
+			devices:       map[string]int64{"cpudev000": 1, "cpudev002": 1},
+			opaqueConfig:  `{"maxSockets": 1}`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cp := &CPUDriver{
+				driverName:             testDriverName,
+				cpuDeviceMode:          tc.deviceMode,
+				cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+				cpuTopology:            topo,
+				deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0, "cpudevnuma001": 1},
+				deviceNameToCPUID:      map[string]int{"cpudev000": 0, "cpudev002": 2},
+				cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+				cdiMgr:                 newMockCdiMgr(),
+			}
+			claim := withOpaqueConfig(testClaim("claim-1", testDriverName, testNodeName, tc.devices), testDriverName, tc.opaqueConfig)
+			results, err := cp.PrepareResourceClaims(context.Background(), []*resourceapi.ResourceClaim{claim})
+			require.NoError(t, err)
+			if tc.expectedError {
+				require.ErrorContains(t, results[claim.UID].Err, "dra.cpu/socketID")
+				_, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(claim.UID)
+				require.False(t, ok)
+				return
+			}
+			require.NoError(t, results[claim.UID].Err)
+		})
+	}
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/dynamic-resource-allocation/kubeletplugin"
+	"k8s.io/dynamic-resource-allocation/resourceslice"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// standaloneSyncPeriod is how often the claims directory of the standalone mode is read.
+const standaloneSyncPeriod = 10 * time.Second
+
+// standalonePlugin stands for the kubelet plugin in the standalone mode, where there is no ResourceSlice
+// to publish: publishing only rebuilds the devices the claims of the files are allocated.
+type standalonePlugin struct{}
+
+func (standalonePlugin) PublishResources(context.Context, resourceslice.DriverResources) error {
+	return nil
+}
+
+func (standalonePlugin) Stop() {}
+
+// StartStandalone starts the driver without an API server nor the DRA support of a kubelet, for the
+// air-gapped edge nodes: the claims are read from the YAML or JSON files of claimsDir, one allocated
+// ResourceClaim each, prepared when their file appears or changes and unprepared when it is removed.
+// The containers consuming a claim reference its CDI device, e.g. with the cdi.k8s.io/ annotations
+// of static pods, and the enforcement backend pins them like with the kubelet. The controllers
+// reading or writing API objects are not started and the events are only logged.
+func StartStandalone(ctx context.Context, config *Config, claimsDir string) (*CPUDriver, error) {
+	ctx = klog.NewContext(ctx, klog.LoggerWithValues(klog.FromContext(ctx), "node", config.NodeName))
+	if config.NodeConfigName != "" || config.PublishNodeStatus || config.ExtendedResourceName != "" || len(config.NFDLabels) > 0 || config.PrewarmClaims {
+		return nil, fmt.Errorf("the standalone mode has no API server for the node config, node status, extended resource, NFD labels and claim prewarming")
+	}
+	plugin, err := newCPUDriver(ctx, nil, config, *config)
+	if err != nil {
+		return nil, err
+	}
+	plugin.standalone = true
+	plugin.draPlugin = standalonePlugin{}
+	plugin.standaloneClaims = map[types.UID][sha256.Size]byte{}
+	eventBroadcaster := record.NewBroadcaster(record.WithContext(ctx))
+	eventBroadcaster.StartStructuredLogging(0)
+	plugin.eventRecorder = eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: config.DriverName, Host: config.NodeName})
+
+	cdiMgr, err := NewCdiManager(klog.FromContext(ctx), config.DriverName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CDI manager: %w", err)
+	}
+	plugin.cdiMgr = cdiMgr
+	if err := plugin.startEnforcementBackend(ctx); err != nil {
+		return nil, err
+	}
+	if plugin.kubeletCheckpointPath != "" {
+		if _, _, err := plugin.syncKubeletCheckpoint(klog.FromContext(ctx)); err != nil {
+			return nil, fmt.Errorf("failed to sync kubelet CPU Manager checkpoint: %w", err)
+		}
+		plugin.startController(ctx, "kubelet-checkpoint", plugin.resyncKubeletCheckpoint, kubeletCheckpointSyncPeriod)
+	}
+	if plugin.restored != nil {
+		plugin.startController(ctx, "restored-claims", func(ctx context.Context) {
+			plugin.expireRestoredClaims(ctx, time.Now())
+		}, restoredClaimsCheckPeriod)
+	}
+	if plugin.cpuHealthCheckPeriod > 0 {
+		plugin.startController(ctx, "cpu-health", plugin.checkCPUHealth, plugin.cpuHealthCheckPeriod)
+	}
+	// the devices the claims are allocated must be known before the first sync.
+	plugin.PublishResources(ctx)
+	plugin.startController(ctx, "standalone-claims", func(ctx context.Context) {
+		plugin.syncStandaloneClaims(ctx, claimsDir)
+	}, standaloneSyncPeriod)
+	return plugin, nil
+}
+
+// standaloneClaimFile is a claim read from a file of the claims directory of the standalone mode.
+type standaloneClaimFile struct {
+	name   string
+	claim  *resourceapi.ResourceClaim
+	digest [sha256.Size]byte
+}
+
+// readStandaloneClaims reads the claims of the YAML and JSON files of the directory, with the digests of
+// their files, and the names of the files which don't hold a valid claim. A claim without UID gets
+// <namespace>-<name>, and without namespace the default one. The consumers of the claims are dropped,
+// there are no pods to look up.
+func readStandaloneClaims(claimsDir string) ([]standaloneClaimFile, []string, error) {
+	entries, err := os.ReadDir(claimsDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the claims directory: %w", err)
+	}
+	var files []standaloneClaimFile
+	var invalid []string
+	uids := sets.New[types.UID]()
+	var errs []string
+	for _, entry := range entries {
+		if entry.IsDir() || !slices.Contains([]string{".yaml", ".yml", ".json"}, filepath.Ext(entry.Name())) {
+			continue
+		}
+		claim, digest, err := readStandaloneClaim(filepath.Join(claimsDir, entry.Name()))
+		if err == nil && uids.Has(claim.UID) {
+			err = fmt.Errorf("claim %s is defined twice", claim.UID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", entry.Name(), err))
+			invalid = append(invalid, entry.Name())
+			continue
+		}
+		uids.Insert(claim.UID)
+		files = append(files, standaloneClaimFile{name: entry.Name(), claim: claim, digest: digest})
+	}
+	if len(errs) > 0 {
+		return files, invalid, fmt.Errorf("invalid claim files: %s", strings.Join(errs, "; "))
+	}
+	return files, invalid, nil
+}
+
+func readStandaloneClaim(path string) (*resourceapi.ResourceClaim, [sha256.Size]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, [sha256.Size]byte{}, err
+	}
+	claim := &resourceapi.ResourceClaim{}
+	if err := yaml.UnmarshalStrict(data, claim); err != nil {
+		return nil, [sha256.Size]byte{}, err
+	}
+	if claim.Name == "" {
+		return nil, [sha256.Size]byte{}, fmt.Errorf("the claim has no name")
+	}
+	if claim.Namespace == "" {
+		claim.Namespace = corev1.NamespaceDefault
+	}
+	if claim.UID == "" {
+		claim.UID = types.UID(claim.Namespace + "-" + claim.Name)
+	}
+	claim.Status.ReservedFor = nil
+	return claim, sha256.Sum256(data), nil
+}
+
+// syncStandaloneClaims prepares the claims of the files not prepared yet, or changed since, and
+// unprepares the prepared claims whose file is gone, including the ones removed while the driver
+// was stopped, found in the CDI spec. A file which doesn't hold a valid claim anymore, e.g. while
+// it is edited, keeps the claim prepared from it. The claims of the files the driver doesn't know
+// are only unprepared once all the files are valid, one of them may be the claim of an invalid file.
+func (cp *CPUDriver) syncStandaloneClaims(ctx context.Context, claimsDir string) {
+	logger := klog.FromContext(ctx)
+	files, invalid, err := readStandaloneClaims(claimsDir)
+	if err != nil {
+		logger.Error(err, "Failed to read the standalone claims", "dir", claimsDir)
+	}
+	if files == nil && invalid == nil && err != nil {
+		return
+	}
+
+	kept := sets.New[types.UID]()
+	for _, file := range files {
+		kept.Insert(file.claim.UID)
+	}
+	unknownInvalid := false
+	claimFiles := map[string]types.UID{}
+	for _, name := range invalid {
+		uid, ok := cp.standaloneFiles[name]
+		if !ok {
+			unknownInvalid = true
+			continue
+		}
+		logger.Info("Claim file invalid, keeping the claim prepared from it", "file", name, "claimUID", uid)
+		kept.Insert(uid)
+		claimFiles[name] = uid
+	}
+
+	var unprepare []kubeletplugin.NamespacedObject
+	infos := cp.cpuAllocationStore.GetResourceClaimInfos()
+	if spec, err := cp.cdiMgr.GetSpec(); err != nil {
+		logger.Error(err, "Failed to read the CDI spec")
+	} else {
+		for _, device := range spec.Devices {
+			uid, ok := strings.CutPrefix(device.Name, getCDIDeviceName(""))
+			if !ok || kept.Has(types.UID(uid)) {
+				continue
+			}
+			if unknownInvalid {
+				logger.Info("Keeping the claim without file until the invalid claim files are fixed", "claimUID", uid)
+				continue
+			}
+			info := infos[types.UID(uid)]
+			unprepare = append(unprepare, kubeletplugin.NamespacedObject{UID: types.UID(uid), NamespacedName: types.NamespacedName{Namespace: info.Namespace, Name: info.Name}})
+		}
+	}
+	var prepare []standaloneClaimFile
+	for _, file := range files {
+		claim := file.claim
+		digest, prepared := cp.standaloneClaims[claim.UID]
+		if prepared && digest == file.digest {
+			claimFiles[file.name] = claim.UID
+			continue
+		}
+		if prepared {
+			logger.Info("Claim file changed, preparing the claim again", "claim", klog.KObj(claim), "claimUID", claim.UID)
+			unprepare = append(unprepare, kubeletplugin.NamespacedObject{UID: claim.UID, NamespacedName: types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name}})
+		}
+		prepare = append(prepare, file)
+	}
+
+	if len(unprepare) > 0 {
+		results, _ := cp.UnprepareResourceClaims(ctx, unprepare)
+		for _, claim := range unprepare {
+			if results[claim.UID] == nil {
+				delete(cp.standaloneClaims, claim.UID)
+			}
+		}
+	}
+	if len(prepare) > 0 {
+		claims := make([]*resourceapi.ResourceClaim, 0, len(prepare))
+		for _, file := range prepare {
+			claims = append(claims, file.claim)
+		}
+		results, _ := cp.PrepareResourceClaims(ctx, claims)
+		for _, file := range prepare {
+			claim := file.claim
+			if err := results[claim.UID].Err; err != nil {
+				logger.Error(err, "Failed to prepare the standalone claim", "claim", klog.KObj(claim), "claimUID", claim.UID)
+				continue
+			}
+			cp.standaloneClaims[claim.UID] = file.digest
+			claimFiles[file.name] = claim.UID
+		}
+	}
+	cp.standaloneFiles = claimFiles
+}
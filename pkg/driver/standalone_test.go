@@ -0,0 +1,137 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/store"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/cpuset"
+	"sigs.k8s.io/yaml"
+)
+
+func TestSyncStandaloneClaims(t *testing.T) {
+	mockProvider := &cpuinfo.MockCPUInfoProvider{CPUInfos: mockCPUInfos_DualSocket_4CPUsPerSocket_HT}
+	topo, _ := mockProvider.GetCPUTopology()
+	cdiMgr := newMockCdiMgr()
+	// a claim whose file was removed while the driver was stopped.
+	cdiMgr.devices[getCDIDeviceName("removed")] = []string{"DRA_CPUSET_removed=4"}
+	cp := &CPUDriver{
+		driverName:             testDriverName,
+		cpuDeviceMode:          CPU_DEVICE_MODE_GROUPED,
+		cpuDeviceGroupBy:       GROUP_BY_NUMA_NODE,
+		cpuTopology:            topo,
+		deviceNameToNUMANodeID: map[string]int{"cpudevnuma000": 0, "cpudevnuma001": 1},
+		cpuAllocationStore:     store.NewCPUAllocation(topo, cpuset.New()),
+		cdiMgr:                 cdiMgr,
+		standalone:             true,
+		standaloneClaims:       map[types.UID][sha256.Size]byte{},
+	}
+	dir := t.TempDir()
+	writeClaim := func(file, name string, devices map[string]int64) {
+		claim := testClaim("", testDriverName, testNodeName, devices)
+		claim.Name = name
+		data, err := yaml.Marshal(claim)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), data, 0o644))
+	}
+	claimCPUs := func(uid types.UID) int {
+		cpus, ok := cp.cpuAllocationStore.GetResourceClaimAllocation(uid)
+		if !ok {
+			return 0
+		}
+		return cpus.Size()
+	}
+
+	writeClaim("app.yaml", "app", map[string]int64{"cpudevnuma000": 2})
+	writeClaim("monitor.json", "monitor", map[string]int64{"cpudevnuma001": 1})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.yaml"), []byte("spec: ["), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not a claim"), 0o644))
+	cp.syncStandaloneClaims(context.Background(), dir)
+	// the claims get the default namespace and a UID made of it and their name.
+	require.Equal(t, 2, claimCPUs("default-app"))
+	require.Equal(t, 1, claimCPUs("default-monitor"))
+	require.Contains(t, cdiMgr.devices, getCDIDeviceName("default-app"))
+	// the claim without file may be the one of invalid.yaml, it is kept until the file is fixed.
+	require.Contains(t, cdiMgr.devices, getCDIDeviceName("removed"))
+	require.NoError(t, os.Remove(filepath.Join(dir, "invalid.yaml")))
+	cp.syncStandaloneClaims(context.Background(), dir)
+	require.NotContains(t, cdiMgr.devices, getCDIDeviceName("removed"))
+	appCPUs, _ := cp.cpuAllocationStore.GetResourceClaimAllocation("default-app")
+
+	// an unchanged file keeps the CPUs of its claim.
+	cp.syncStandaloneClaims(context.Background(), dir)
+	cpus, _ := cp.cpuAllocationStore.GetResourceClaimAllocation("default-app")
+	require.Equal(t, appCPUs.String(), cpus.String())
+
+	// a changed file prepares its claim again.
+	writeClaim("app.yaml", "app", map[string]int64{"cpudevnuma000": 3})
+	cp.syncStandaloneClaims(context.Background(), dir)
+	require.Equal(t, 3, claimCPUs("default-app"))
+	appCPUs, _ = cp.cpuAllocationStore.GetResourceClaimAllocation("default-app")
+
+	// a file being edited keeps the CPUs of its claim, and doesn't release the claims of other files.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("metadata: {name: app"), 0o644))
+	cp.syncStandaloneClaims(context.Background(), dir)
+	cpus, _ = cp.cpuAllocationStore.GetResourceClaimAllocation("default-app")
+	require.Equal(t, appCPUs.String(), cpus.String())
+	require.Contains(t, cdiMgr.devices, getCDIDeviceName("default-app"))
+	require.Equal(t, 1, claimCPUs("default-monitor"))
+	writeClaim("app.yaml", "app", map[string]int64{"cpudevnuma000": 3})
+	cp.syncStandaloneClaims(context.Background(), dir)
+	cpus, _ = cp.cpuAllocationStore.GetResourceClaimAllocation("default-app")
+	require.Equal(t, appCPUs.String(), cpus.String())
+
+	// a removed file unprepares its claim.
+	require.NoError(t, os.Remove(filepath.Join(dir, "monitor.json")))
+	cp.syncStandaloneClaims(context.Background(), dir)
+	require.Equal(t, 0, claimCPUs("default-monitor"))
+	require.NotContains(t, cdiMgr.devices, getCDIDeviceName("default-monitor"))
+	require.Equal(t, 3, claimCPUs("default-app"))
+}
+
+func TestReadStandaloneClaims(t *testing.T) {
+	dir := t.TempDir()
+	contents := map[string]string{
+		"a.yaml":   "metadata: {name: a, namespace: edge, uid: uid-a}\n",
+		"b.yml":    "metadata: {name: b}\n",
+		"dup.yaml": "metadata: {name: other, uid: uid-a}\n",
+		"c.yaml":   "metadata: {}\n",
+	}
+	for file, data := range contents {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(data), 0o644))
+	}
+	files, invalid, err := readStandaloneClaims(dir)
+	require.ErrorContains(t, err, "c.yaml: the claim has no name")
+	require.ErrorContains(t, err, "dup.yaml: claim uid-a is defined twice")
+	require.Equal(t, []string{"c.yaml", "dup.yaml"}, invalid)
+	require.Len(t, files, 2)
+	require.Equal(t, "a.yaml", files[0].name)
+	require.Equal(t, "edge", files[0].claim.Namespace)
+	require.Equal(t, types.UID("uid-a"), files[0].claim.UID)
+	require.Equal(t, types.UID("default-b"), files[1].claim.UID)
+
+	_, _, err = readStandaloneClaims(filepath.Join(dir, "missing"))
+	require.ErrorContains(t, err, "failed to read the claims directory")
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hostfs abstracts the host filesystem the driver reads the CPU topology, the cgroups and the
+// kernel counters from, so the tests can simulate hosts: missing controllers, unreadable files, or
+// kernels not exposing an interface at all.
+package hostfs
+
+import (
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"testing/fstest"
+)
+
+// FS is the subset of the filesystem operations used on the host paths.
+type FS interface {
+	ReadFile(name string) ([]byte, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Stat(name string) (fs.FileInfo, error)
+}
+
+// OS is the real filesystem.
+type OS struct{}
+
+// ReadFile reads the named file.
+func (OS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// ReadDir reads the named directory, sorted by file name.
+func (OS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+// Stat returns the FileInfo of the named file.
+func (OS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// Fake is an in-memory filesystem for the tests, whose absolute paths map to the files added to it.
+// Failures can be injected on the reads of any path, e.g. fs.ErrPermission to simulate an unreadable
+// cgroup file.
+type Fake struct {
+	mu       sync.Mutex
+	files    fstest.MapFS
+	failures map[string]error
+}
+
+// NewFake returns a Fake holding the given files, keyed by absolute path.
+func NewFake(files map[string]string) *Fake {
+	f := &Fake{files: fstest.MapFS{}, failures: map[string]error{}}
+	for name, content := range files {
+		f.AddFile(name, content)
+	}
+	return f
+}
+
+// AddFile adds or replaces a regular file.
+func (f *Fake) AddFile(name, content string) {
+	f.Add(name, &fstest.MapFile{Data: []byte(content), Mode: 0644})
+}
+
+// Add adds or replaces a file of any mode, e.g. fs.ModeDir or fs.ModeSocket.
+func (f *Fake) Add(name string, file *fstest.MapFile) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.files[fakePath(name)] = file
+}
+
+// Remove removes a file, or a directory and everything below it.
+func (f *Fake) Remove(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	prefix := fakePath(name) + "/"
+	for path := range f.files {
+		if path == fakePath(name) || strings.HasPrefix(path, prefix) {
+			delete(f.files, path)
+		}
+	}
+}
+
+// Fail makes the reads of a path fail with err, nil clears the failure. Stat keeps succeeding, like
+// for a file without read permission: Remove simulates a missing file.
+func (f *Fake) Fail(name string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.failures, fakePath(name))
+		return
+	}
+	f.failures[fakePath(name)] = err
+}
+
+// ReadFile reads the named file.
+func (f *Fake) ReadFile(name string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failure("read", name); err != nil {
+		return nil, err
+	}
+	data, err := f.files.ReadFile(fakePath(name))
+	return data, realPath(err, name)
+}
+
+// ReadDir reads the named directory, sorted by file name.
+func (f *Fake) ReadDir(name string) ([]fs.DirEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failure("readdir", name); err != nil {
+		return nil, err
+	}
+	entries, err := f.files.ReadDir(fakePath(name))
+	return entries, realPath(err, name)
+}
+
+// Stat returns the FileInfo of the named file.
+func (f *Fake) Stat(name string) (fs.FileInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, err := f.files.Stat(fakePath(name))
+	return info, realPath(err, name)
+}
+
+func (f *Fake) failure(op, name string) error {
+	if err, ok := f.failures[fakePath(name)]; ok {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return nil
+}
+
+// fakePath maps an absolute path to the unrooted form of the fs.FS paths.
+func fakePath(name string) string {
+	name = strings.Trim(name, "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// realPath reports the path errors with the absolute path the caller used.
+func realPath(err error, name string) error {
+	if pathErr, ok := err.(*fs.PathError); ok {
+		pathErr.Path = name
+	}
+	return err
+}
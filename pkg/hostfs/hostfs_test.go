@@ -0,0 +1,64 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostfs
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake(t *testing.T) {
+	fake := NewFake(map[string]string{
+		"/sys/devices/system/cpu/cpu0/topology/core_id": "0\n",
+		"/sys/devices/system/cpu/cpu1/topology/core_id": "1\n",
+	})
+	fake.Add("/var/run/nri/nri.sock", &fstest.MapFile{Mode: fs.ModeSocket})
+
+	data, err := fake.ReadFile("/sys/devices/system/cpu/cpu1/topology/core_id")
+	require.NoError(t, err)
+	require.Equal(t, "1\n", string(data))
+
+	entries, err := fake.ReadDir("/sys/devices/system/cpu")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "cpu0", entries[0].Name())
+
+	info, err := fake.Stat("/var/run/nri/nri.sock")
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode()&fs.ModeSocket)
+
+	fake.Fail("/sys/devices/system/cpu/cpu0/topology/core_id", fs.ErrPermission)
+	_, err = fake.ReadFile("/sys/devices/system/cpu/cpu0/topology/core_id")
+	require.ErrorIs(t, err, fs.ErrPermission)
+	var pathErr *fs.PathError
+	require.True(t, errors.As(err, &pathErr))
+	require.Equal(t, "/sys/devices/system/cpu/cpu0/topology/core_id", pathErr.Path)
+	fake.Fail("/sys/devices/system/cpu/cpu0/topology/core_id", nil)
+	_, err = fake.ReadFile("/sys/devices/system/cpu/cpu0/topology/core_id")
+	require.NoError(t, err)
+
+	fake.Remove("/sys/devices/system/cpu/cpu1")
+	_, err = fake.Stat("/sys/devices/system/cpu/cpu1/topology/core_id")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	entries, err = fake.ReadDir("/sys/devices/system/cpu")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
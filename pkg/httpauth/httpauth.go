@@ -0,0 +1,164 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package httpauth protects the HTTP endpoints of the driver, like /metrics, with the Kubernetes
+// delegated authentication and authorization: the bearer token of a request is authenticated with a
+// TokenReview, and the user is authorized on the URL path with a SubjectAccessReview, like
+// kube-rbac-proxy does, so scrapers need RBAC rules on the non-resource URLs they get.
+package httpauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// DefaultCacheTTL is how long the decisions are cached by default, to spare the API server a review
+// per scrape.
+const DefaultCacheTTL = 1 * time.Minute
+
+// decision is a cached authorization decision.
+type decision struct {
+	status  int
+	expires time.Time
+}
+
+// Delegating authenticates and authorizes the requests with the API server.
+type Delegating struct {
+	client   kubernetes.Interface
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu sync.Mutex
+	// cache holds the decisions by hash of the token, verb and path, never the tokens themselves.
+	cache map[string]decision
+}
+
+// NewDelegating returns a Delegating reviewing the requests with the given client and caching the
+// decisions for cacheTTL. A zero cacheTTL disables the cache.
+func NewDelegating(client kubernetes.Interface, cacheTTL time.Duration) *Delegating {
+	return &Delegating{
+		client:   client,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+		cache:    map[string]decision{},
+	}
+}
+
+// Wrap returns a handler serving the requests allowed by the API server with next, and rejecting the
+// others with 401 Unauthorized or 403 Forbidden.
+func (d *Delegating) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		status := d.authorize(r.Context(), token, strings.ToLower(r.Method), r.URL.Path)
+		if status != http.StatusOK {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken returns the token of the Authorization header of the request.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// authorize returns the HTTP status of a request of the given verb on the path with the token:
+// 200 if allowed, 401 if the token is not valid, 403 if the user is not allowed, 500 if the API
+// server can't be reached. Errors are not cached.
+func (d *Delegating) authorize(ctx context.Context, token, verb, path string) int {
+	sum := sha256.Sum256([]byte(token + "\x00" + verb + "\x00" + path))
+	key := hex.EncodeToString(sum[:])
+	d.mu.Lock()
+	cached, ok := d.cache[key]
+	d.mu.Unlock()
+	if ok && d.now().Before(cached.expires) {
+		return cached.status
+	}
+
+	status, err := d.review(ctx, token, verb, path)
+	if err != nil {
+		klog.FromContext(ctx).Error(err, "Failed to review the request", "verb", verb, "path", path)
+		return http.StatusInternalServerError
+	}
+	if d.cacheTTL > 0 {
+		now := d.now()
+		d.mu.Lock()
+		for k, c := range d.cache {
+			if !now.Before(c.expires) {
+				delete(d.cache, k)
+			}
+		}
+		d.cache[key] = decision{status: status, expires: now.Add(d.cacheTTL)}
+		d.mu.Unlock()
+	}
+	return status
+}
+
+func (d *Delegating) review(ctx context.Context, token, verb, path string) (int, error) {
+	tokenReview, err := d.client.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("token review: %w", err)
+	}
+	if !tokenReview.Status.Authenticated {
+		return http.StatusUnauthorized, nil
+	}
+	user := tokenReview.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	accessReview, err := d.client.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:                  user.Username,
+			UID:                   user.UID,
+			Groups:                user.Groups,
+			Extra:                 extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{Path: path, Verb: verb},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("subject access review: %w", err)
+	}
+	if !accessReview.Status.Allowed {
+		klog.FromContext(ctx).V(4).Info("User is not allowed to make the request", "user", user.Username, "verb", verb, "path", path, "reason", accessReview.Status.Reason)
+		return http.StatusForbidden, nil
+	}
+	return http.StatusOK, nil
+}
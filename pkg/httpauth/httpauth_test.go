@@ -0,0 +1,101 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeClient returns a client authenticating the "scraper" and "intruder" tokens, and allowing only
+// the scraper user to get /metrics. It counts the reviews.
+func newFakeClient(reviews *int) *fake.Clientset {
+	client := fake.NewClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		switch review.Spec.Token {
+		case "scraper", "intruder":
+			review.Status = authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: review.Spec.Token}}
+		}
+		return true, review, nil
+	})
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attrs := review.Spec.NonResourceAttributes
+		review.Status.Allowed = review.Spec.User == "scraper" && attrs != nil && attrs.Path == "/metrics" && attrs.Verb == "get"
+		return true, review, nil
+	})
+	return client
+}
+
+func TestDelegating(t *testing.T) {
+	reviews := 0
+	d := NewDelegating(newFakeClient(&reviews), DefaultCacheTTL)
+	now := time.Now()
+	d.now = func() time.Time { return now }
+	handler := d.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	get := func(path, authorization string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusUnauthorized, get("/metrics", ""))
+	require.Equal(t, http.StatusUnauthorized, get("/metrics", "Basic c2NyYXBlcg=="))
+	require.Equal(t, http.StatusUnauthorized, get("/metrics", "Bearer invalid"))
+	require.Equal(t, http.StatusForbidden, get("/metrics", "Bearer intruder"))
+	require.Equal(t, http.StatusForbidden, get("/debug", "Bearer scraper"))
+	require.Equal(t, http.StatusOK, get("/metrics", "Bearer scraper"))
+	require.Equal(t, 4, reviews)
+
+	// the decisions are cached until they expire.
+	require.Equal(t, http.StatusOK, get("/metrics", "bearer scraper"))
+	require.Equal(t, http.StatusForbidden, get("/metrics", "Bearer intruder"))
+	require.Equal(t, 4, reviews)
+	now = now.Add(DefaultCacheTTL)
+	require.Equal(t, http.StatusOK, get("/metrics", "Bearer scraper"))
+	require.Equal(t, 5, reviews)
+}
+
+func TestDelegatingReviewError(t *testing.T) {
+	client := fake.NewClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, http.ErrServerClosed
+	})
+	handler := NewDelegating(client, DefaultCacheTTL).Wrap(http.NotFoundHandler())
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer scraper")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+}
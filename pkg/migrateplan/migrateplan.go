@@ -0,0 +1,262 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migrateplan plans the migration of the Guaranteed pods the kubelet CPU Manager static policy
+// pins to dedicated CPUs to ResourceClaims of the dra.cpu driver, for clusters already using static pinning.
+package migrateplan
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/driver"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/cpuset"
+	"sigs.k8s.io/yaml"
+)
+
+// podClaimName is the name of the claim the migrated pods reference in their resourceClaims.
+const podClaimName = "cpus"
+
+// Workload groups the pinned pods of a node created by the same controller, or a single pod without one.
+type Workload struct {
+	Namespace string
+	// Kind and Name identify the controller of the pods, e.g. a Deployment, or the pod itself.
+	Kind string
+	Name string
+	// Containers are the pinned containers of the pods, with their number of exclusive CPUs.
+	Containers []Container
+	// Pods are the pinned pods of the workload on the node.
+	Pods []Pod
+}
+
+// Container is a container pinned by the kubelet.
+type Container struct {
+	Name string
+	CPUs int
+}
+
+// Pod is a pod pinned by the kubelet, with the CPUs of each of its pinned containers.
+type Pod struct {
+	Name string
+	UID  string
+	CPUs map[string]string
+}
+
+// Plan is the migration plan of a node.
+type Plan struct {
+	NodeName   string
+	DriverName string
+	DeviceMode string
+	// PolicyName is the CPU Manager policy of the checkpoint, static for the pinned pods to be migrated.
+	PolicyName string
+	Workloads  []Workload
+	// StaleEntries are the checkpoint entries of pods not running on the node anymore.
+	StaleEntries []string
+}
+
+// New plans the migration of the running Guaranteed pods of a node pinned in the kubelet checkpoint.
+func New(nodeName, driverName, deviceMode string, checkpoint *cpumanager.KubeletCheckpoint, pods []corev1.Pod) (*Plan, error) {
+	if deviceMode != driver.CPU_DEVICE_MODE_GROUPED && deviceMode != driver.CPU_DEVICE_MODE_INDIVIDUAL {
+		return nil, fmt.Errorf("invalid device mode %q, must be %s or %s", deviceMode, driver.CPU_DEVICE_MODE_GROUPED, driver.CPU_DEVICE_MODE_INDIVIDUAL)
+	}
+	plan := &Plan{
+		NodeName:   nodeName,
+		DriverName: driverName,
+		DeviceMode: deviceMode,
+		PolicyName: checkpoint.PolicyName,
+	}
+
+	running := map[string]bool{}
+	workloads := map[string]*Workload{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName != nodeName || pod.Status.Phase != corev1.PodRunning || pod.Status.QOSClass != corev1.PodQOSGuaranteed {
+			continue
+		}
+		podUID := string(pod.UID)
+		running[podUID] = true
+		if len(checkpoint.Entries[podUID]) == 0 {
+			continue
+		}
+		kind, name := workloadOf(&pod)
+		key := pod.Namespace + "/" + kind + "/" + name
+		workload, ok := workloads[key]
+		if !ok {
+			workload = &Workload{Namespace: pod.Namespace, Kind: kind, Name: name}
+			workloads[key] = workload
+		}
+		pinned := Pod{Name: pod.Name, UID: podUID, CPUs: map[string]string{}}
+		for _, container := range pod.Spec.Containers {
+			cpus, ok, err := checkpoint.ContainerCPUs(podUID, container.Name)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			pinned.CPUs[container.Name] = cpus.String()
+			workload.addContainer(container.Name, cpus)
+		}
+		workload.Pods = append(workload.Pods, pinned)
+	}
+	for podUID := range checkpoint.Entries {
+		if !running[podUID] {
+			plan.StaleEntries = append(plan.StaleEntries, podUID)
+		}
+	}
+	sort.Strings(plan.StaleEntries)
+
+	for _, workload := range workloads {
+		sort.Slice(workload.Pods, func(i, j int) bool { return workload.Pods[i].Name < workload.Pods[j].Name })
+		plan.Workloads = append(plan.Workloads, *workload)
+	}
+	sort.Slice(plan.Workloads, func(i, j int) bool {
+		a, b := plan.Workloads[i], plan.Workloads[j]
+		return a.Namespace+"/"+a.Kind+"/"+a.Name < b.Namespace+"/"+b.Kind+"/"+b.Name
+	})
+	return plan, nil
+}
+
+// addContainer records the CPUs of a pinned container, keeping the largest count seen across the pods.
+func (w *Workload) addContainer(name string, cpus cpuset.CPUSet) {
+	for i := range w.Containers {
+		if w.Containers[i].Name == name {
+			w.Containers[i].CPUs = max(w.Containers[i].CPUs, cpus.Size())
+			return
+		}
+	}
+	w.Containers = append(w.Containers, Container{Name: name, CPUs: cpus.Size()})
+}
+
+// workloadOf returns the kind and name of the workload owning the pod: the Deployment of the pod
+// ReplicaSet, the controller of the pod, or the pod itself.
+func workloadOf(pod *corev1.Pod) (string, string) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "Pod", pod.Name
+	}
+	if hash := pod.Labels["pod-template-hash"]; owner.Kind == "ReplicaSet" && hash != "" && strings.HasSuffix(owner.Name, "-"+hash) {
+		return "Deployment", strings.TrimSuffix(owner.Name, "-"+hash)
+	}
+	return owner.Kind, owner.Name
+}
+
+// ClaimTemplateName is the name of the ResourceClaimTemplate of a workload.
+func (w *Workload) ClaimTemplateName() string {
+	return w.Name + "-cpus"
+}
+
+// Manifests returns the DeviceClass of the driver and the ResourceClaimTemplates of the workloads.
+func (p *Plan) Manifests() []any {
+	manifests := []any{&resourceapi.DeviceClass{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "resource.k8s.io/v1", Kind: "DeviceClass"},
+		ObjectMeta: metav1.ObjectMeta{Name: p.DriverName},
+		Spec: resourceapi.DeviceClassSpec{
+			Selectors: []resourceapi.DeviceSelector{{CEL: &resourceapi.CELDeviceSelector{Expression: fmt.Sprintf("device.driver == %q", p.DriverName)}}},
+		},
+	}}
+	for _, workload := range p.Workloads {
+		template := &resourceapi.ResourceClaimTemplate{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "resource.k8s.io/v1", Kind: "ResourceClaimTemplate"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: workload.Namespace, Name: workload.ClaimTemplateName()},
+		}
+		for _, container := range workload.Containers {
+			request := resourceapi.ExactDeviceRequest{DeviceClassName: p.DriverName}
+			if p.DeviceMode == driver.CPU_DEVICE_MODE_GROUPED {
+				request.Capacity = &resourceapi.CapacityRequirements{
+					Requests: map[resourceapi.QualifiedName]resource.Quantity{
+						resourceapi.QualifiedName(p.DriverName + "/cpu"): *resource.NewQuantity(int64(container.CPUs), resource.DecimalSI),
+					},
+				}
+			} else {
+				request.AllocationMode = resourceapi.DeviceAllocationModeExactCount
+				request.Count = int64(container.CPUs)
+			}
+			template.Spec.Spec.Devices.Requests = append(template.Spec.Spec.Devices.Requests, resourceapi.DeviceRequest{
+				Name:    container.Name,
+				Exactly: &request,
+			})
+		}
+		manifests = append(manifests, template)
+	}
+	return manifests
+}
+
+// WriteManifests writes the manifests of the plan as a multi-document YAML stream.
+func (p *Plan) WriteManifests(w io.Writer) error {
+	for i, manifest := range p.Manifests() {
+		data, err := yaml.Marshal(manifest)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSteps writes the step-by-step cutover of the node.
+func (p *Plan) WriteSteps(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Migration plan of node %s from the kubelet CPU Manager %q policy to the %s driver in %s mode\n\n", p.NodeName, p.PolicyName, p.DriverName, p.DeviceMode)
+	if p.PolicyName != "static" {
+		fmt.Fprintf(&b, "The kubelet CPU Manager policy is %q: no pod is pinned by the kubelet, the driver can be deployed right away.\n\n", p.PolicyName)
+	}
+	if len(p.StaleEntries) > 0 {
+		fmt.Fprintf(&b, "The checkpoint has entries for pods not running on the node anymore, ignored: %s\n\n", strings.Join(p.StaleEntries, ", "))
+	}
+	fmt.Fprintf(&b, "1. Deploy the driver on the node with --kubelet-cpu-manager-state=%s and --cpu-device-mode=%s,\n", cpumanager.DefaultKubeletCheckpointPath, p.DeviceMode)
+	fmt.Fprintf(&b, "   so it leaves the CPUs pinned by the kubelet alone while both run side by side.\n")
+	fmt.Fprintf(&b, "2. Apply the DeviceClass and the ResourceClaimTemplates of the manifests.\n")
+	fmt.Fprintf(&b, "3. Add the claim to the pod template of each workload, keeping its CPU requests and limits:\n")
+	if len(p.Workloads) == 0 {
+		fmt.Fprintf(&b, "   no pinned workload runs on the node.\n")
+	}
+	for _, workload := range p.Workloads {
+		fmt.Fprintf(&b, "   - %s %s/%s (pods %s):\n", workload.Kind, workload.Namespace, workload.Name, podNames(workload.Pods))
+		fmt.Fprintf(&b, "       resourceClaims: [{name: %s, resourceClaimTemplateName: %s}]\n", podClaimName, workload.ClaimTemplateName())
+		for _, container := range workload.Containers {
+			fmt.Fprintf(&b, "       container %s: resources.claims: [{name: %s, request: %s}]  # %d CPUs\n", container.Name, podClaimName, container.Name, container.CPUs)
+		}
+	}
+	fmt.Fprintf(&b, "4. Roll out the workloads: the new pods get their CPUs from the driver, and the kubelet\n")
+	fmt.Fprintf(&b, "   releases the pinned CPUs of the old pods, which the driver makes allocatable again.\n")
+	fmt.Fprintf(&b, "5. Once the checkpoint has no entries left, drain the node, stop the kubelet, set its cpuManagerPolicy\n")
+	fmt.Fprintf(&b, "   to none, remove %s and start the kubelet again.\n", cpumanager.DefaultKubeletCheckpointPath)
+	fmt.Fprintf(&b, "6. Remove --kubelet-cpu-manager-state from the driver and uncordon the node.\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func podNames(pods []Pod) string {
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrateplan
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+func testPod(name, uid string, qos corev1.PodQOSClass, owner *metav1.OwnerReference, containers ...string) corev1.Pod {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, UID: types.UID(uid)},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, QOSClass: qos},
+	}
+	if owner != nil {
+		pod.OwnerReferences = []metav1.OwnerReference{*owner}
+		pod.Labels = map[string]string{"pod-template-hash": "5d4f8"}
+	}
+	for _, container := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: container})
+	}
+	return pod
+}
+
+func TestNew(t *testing.T) {
+	replicaSet := &metav1.OwnerReference{Kind: "ReplicaSet", Name: "web-5d4f8", Controller: ptr.To(true)}
+	checkpoint := &cpumanager.KubeletCheckpoint{
+		PolicyName:    "static",
+		DefaultCPUSet: "0,5-7",
+		Entries: map[string]map[string]string{
+			"web-1-uid": {"app": "1-2"},
+			"web-2-uid": {"app": "3-4"},
+			"db-uid":    {"db": "8-11", "sidecar": "12"},
+			"gone-uid":  {"app": "13"},
+		},
+	}
+	pods := []corev1.Pod{
+		testPod("web-2", "web-2-uid", corev1.PodQOSGuaranteed, replicaSet, "app"),
+		testPod("web-1", "web-1-uid", corev1.PodQOSGuaranteed, replicaSet, "app"),
+		testPod("db", "db-uid", corev1.PodQOSGuaranteed, nil, "db", "sidecar"),
+		testPod("burstable", "burstable-uid", corev1.PodQOSBurstable, nil, "app"),
+	}
+
+	plan, err := New("node-1", "dra.cpu", "grouped", checkpoint, pods)
+	require.NoError(t, err)
+	require.Equal(t, []string{"gone-uid"}, plan.StaleEntries)
+	require.Equal(t, []Workload{
+		{
+			Namespace:  "ns",
+			Kind:       "Deployment",
+			Name:       "web",
+			Containers: []Container{{Name: "app", CPUs: 2}},
+			Pods: []Pod{
+				{Name: "web-1", UID: "web-1-uid", CPUs: map[string]string{"app": "1-2"}},
+				{Name: "web-2", UID: "web-2-uid", CPUs: map[string]string{"app": "3-4"}},
+			},
+		},
+		{
+			Namespace:  "ns",
+			Kind:       "Pod",
+			Name:       "db",
+			Containers: []Container{{Name: "db", CPUs: 4}, {Name: "sidecar", CPUs: 1}},
+			Pods:       []Pod{{Name: "db", UID: "db-uid", CPUs: map[string]string{"db": "8-11", "sidecar": "12"}}},
+		},
+	}, plan.Workloads)
+
+	_, err = New("node-1", "dra.cpu", "weird", checkpoint, pods)
+	require.Error(t, err)
+}
+
+func TestWriteManifests(t *testing.T) {
+	plan := &Plan{
+		NodeName:   "node-1",
+		DriverName: "dra.cpu",
+		DeviceMode: "grouped",
+		Workloads:  []Workload{{Namespace: "ns", Kind: "Pod", Name: "db", Containers: []Container{{Name: "db", CPUs: 4}}}},
+	}
+	var out bytes.Buffer
+	require.NoError(t, plan.WriteManifests(&out))
+	require.Equal(t, `apiVersion: resource.k8s.io/v1
+kind: DeviceClass
+metadata:
+  name: dra.cpu
+spec:
+  selectors:
+  - cel:
+      expression: device.driver == "dra.cpu"
+---
+apiVersion: resource.k8s.io/v1
+kind: ResourceClaimTemplate
+metadata:
+  name: db-cpus
+  namespace: ns
+spec:
+  metadata: {}
+  spec:
+    devices:
+      requests:
+      - exactly:
+          capacity:
+            requests:
+              dra.cpu/cpu: "4"
+          deviceClassName: dra.cpu
+        name: db
+`, out.String())
+
+	plan.DeviceMode = "individual"
+	out.Reset()
+	require.NoError(t, plan.WriteManifests(&out))
+	require.Contains(t, out.String(), `      - exactly:
+          allocationMode: ExactCount
+          count: 4
+          deviceClassName: dra.cpu
+        name: db
+`)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nodeconfig reads the DRACPUConfig objects configuring the driver from the API server: the
+// settings of the spec apply to all the nodes, and the overrides to the nodes matching their node
+// selector, so a fleet is configured in one place instead of with the flags of each DaemonSet.
+package nodeconfig
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// GroupVersionResource is the resource of the cluster-scoped DRACPUConfig objects.
+var GroupVersionResource = schema.GroupVersionResource{Group: "dra.cpu", Version: "v1alpha1", Resource: "dracpuconfigs"}
+
+// DRACPUConfig configures the driver on the nodes of a cluster.
+type DRACPUConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DRACPUConfigSpec `json:"spec"`
+}
+
+// DRACPUConfigSpec are the settings of all the nodes, and their overrides for some nodes.
+type DRACPUConfigSpec struct {
+	Settings `json:",inline"`
+	// Overrides apply, in order, to the nodes matching their node selector, each overriding the
+	// settings it sets.
+	Overrides []Override `json:"overrides,omitempty"`
+}
+
+// Override are the settings of the nodes matching the node selector.
+type Override struct {
+	NodeSelector metav1.LabelSelector `json:"nodeSelector"`
+	Settings     `json:",inline"`
+}
+
+// Settings are the driver settings of a DRACPUConfig, each overriding the matching flag when set.
+type Settings struct {
+	ReservedCPUs  *string `json:"reservedCPUs,omitempty"`
+	CPUDeviceMode *string `json:"cpuDeviceMode,omitempty"`
+	GroupBy       *string `json:"groupBy,omitempty"`
+	SMTIsolation  *string `json:"smtIsolation,omitempty"`
+	// CPUPools replace the pools of the --cpu-pools-config file when set, an empty list disabling them.
+	CPUPools []CPUPoolSpec `json:"cpuPools,omitempty"`
+}
+
+// CPUPoolSpec is a named CPU pool, in the format of the --cpu-pools-config file.
+type CPUPoolSpec struct {
+	Name               string   `json:"name"`
+	CPUs               string   `json:"cpus"`
+	Namespaces         []string `json:"namespaces,omitempty"`
+	PriorityClassNames []string `json:"priorityClassNames,omitempty"`
+	DeviceClassNames   []string `json:"deviceClassNames,omitempty"`
+}
+
+// Get returns the DRACPUConfig of the given name.
+func Get(ctx context.Context, client dynamic.Interface, name string) (*DRACPUConfig, error) {
+	obj, err := client.Resource(GroupVersionResource).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	config := &DRACPUConfig{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), config); err != nil {
+		return nil, fmt.Errorf("invalid DRACPUConfig %s: %w", name, err)
+	}
+	return config, nil
+}
+
+// Resolve returns the settings of a node with the given labels: the settings of the spec, overridden
+// by the ones of the overrides whose node selector matches the labels.
+func (c *DRACPUConfig) Resolve(nodeLabels map[string]string) (Settings, error) {
+	settings := c.Spec.Settings
+	for i, override := range c.Spec.Overrides {
+		selector, err := metav1.LabelSelectorAsSelector(&override.NodeSelector)
+		if err != nil {
+			return Settings{}, fmt.Errorf("DRACPUConfig %s override %d: invalid node selector: %w", c.Name, i, err)
+		}
+		if !selector.Matches(labels.Set(nodeLabels)) {
+			continue
+		}
+		settings.merge(override.Settings)
+	}
+	return settings, nil
+}
+
+// merge overrides the settings with the ones set in other.
+func (s *Settings) merge(other Settings) {
+	if other.ReservedCPUs != nil {
+		s.ReservedCPUs = other.ReservedCPUs
+	}
+	if other.CPUDeviceMode != nil {
+		s.CPUDeviceMode = other.CPUDeviceMode
+	}
+	if other.GroupBy != nil {
+		s.GroupBy = other.GroupBy
+	}
+	if other.SMTIsolation != nil {
+		s.SMTIsolation = other.SMTIsolation
+	}
+	if other.CPUPools != nil {
+		s.CPUPools = other.CPUPools
+	}
+}
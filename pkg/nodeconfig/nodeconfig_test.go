@@ -0,0 +1,116 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/utils/ptr"
+)
+
+func newFakeClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{GroupVersionResource: "DRACPUConfigList"}, objects...)
+}
+
+func TestGet(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "dra.cpu/v1alpha1",
+		"kind":       "DRACPUConfig",
+		"metadata":   map[string]any{"name": "default"},
+		"spec": map[string]any{
+			"reservedCPUs": "0-1",
+			"cpuPools":     []any{},
+			"overrides": []any{
+				map[string]any{
+					"nodeSelector": map[string]any{"matchLabels": map[string]any{"pool": "telecom"}},
+					"smtIsolation": "namespace",
+					"cpuPools":     []any{map[string]any{"name": "ran", "cpus": "2-5", "namespaces": []any{"ran"}}},
+				},
+			},
+		},
+	}}
+	client := newFakeClient(obj)
+
+	config, err := Get(context.Background(), client, "default")
+	require.NoError(t, err)
+	require.Equal(t, "default", config.Name)
+	require.Equal(t, ptr.To("0-1"), config.Spec.ReservedCPUs)
+	require.NotNil(t, config.Spec.CPUPools, "an empty list of pools must disable the pools")
+	require.Empty(t, config.Spec.CPUPools)
+	require.Len(t, config.Spec.Overrides, 1)
+	require.Equal(t, []CPUPoolSpec{{Name: "ran", CPUs: "2-5", Namespaces: []string{"ran"}}}, config.Spec.Overrides[0].CPUPools)
+
+	_, err = Get(context.Background(), client, "missing")
+	require.Error(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	config := &DRACPUConfig{Spec: DRACPUConfigSpec{
+		Settings: Settings{ReservedCPUs: ptr.To("0"), SMTIsolation: ptr.To("none")},
+		Overrides: []Override{
+			{
+				NodeSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pool": "telecom"}},
+				Settings:     Settings{ReservedCPUs: ptr.To("0-1"), CPUDeviceMode: ptr.To("individual")},
+			},
+			{
+				NodeSelector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tenant", Operator: metav1.LabelSelectorOpExists}}},
+				Settings:     Settings{ReservedCPUs: ptr.To("0-3"), SMTIsolation: ptr.To("namespace")},
+			},
+		},
+	}}
+	testCases := []struct {
+		name     string
+		labels   map[string]string
+		expected Settings
+	}{
+		{
+			name:     "no override",
+			labels:   map[string]string{"pool": "batch"},
+			expected: Settings{ReservedCPUs: ptr.To("0"), SMTIsolation: ptr.To("none")},
+		},
+		{
+			name:     "one override",
+			labels:   map[string]string{"pool": "telecom"},
+			expected: Settings{ReservedCPUs: ptr.To("0-1"), CPUDeviceMode: ptr.To("individual"), SMTIsolation: ptr.To("none")},
+		},
+		{
+			name:     "later overrides win",
+			labels:   map[string]string{"pool": "telecom", "tenant": "a"},
+			expected: Settings{ReservedCPUs: ptr.To("0-3"), CPUDeviceMode: ptr.To("individual"), SMTIsolation: ptr.To("namespace")},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			settings, err := config.Resolve(tc.labels)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, settings)
+		})
+	}
+
+	invalid := &DRACPUConfig{Spec: DRACPUConfigSpec{Overrides: []Override{{
+		NodeSelector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "pool", Operator: "Near"}}},
+	}}}}
+	_, err := invalid.Resolve(nil)
+	require.ErrorContains(t, err, "invalid node selector")
+}
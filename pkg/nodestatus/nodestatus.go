@@ -0,0 +1,109 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nodestatus writes the DRACPUNodeStatus objects summarizing the CPU allocation of each node,
+// so `kubectl get dracpunodestatuses` gives a fleet-wide view of the driver without scraping metrics.
+package nodestatus
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// GroupVersionResource is the resource of the cluster-scoped DRACPUNodeStatus objects, named after their node.
+var GroupVersionResource = schema.GroupVersionResource{Group: "dra.cpu", Version: "v1alpha1", Resource: "dracpunodestatuses"}
+
+// DRACPUNodeStatus is the CPU allocation summary of a node.
+type DRACPUNodeStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status NodeStatus `json:"status"`
+}
+
+// NodeStatus is the state of the driver and of the CPUs of a node.
+type NodeStatus struct {
+	DriverVersion   string       `json:"driverVersion,omitempty"`
+	DeviceMode      string       `json:"deviceMode"`
+	LastUpdateTime  metav1.Time  `json:"lastUpdateTime"`
+	LastPublishTime *metav1.Time `json:"lastPublishTime,omitempty"`
+	// LastError is the last error publishing the resources or syncing the kubelet checkpoint, if any.
+	LastError string `json:"lastError,omitempty"`
+	// AllocatableCPUs are the CPUs claims can get, allocated or free.
+	AllocatableCPUs int `json:"allocatableCPUs"`
+	AllocatedCPUs   int `json:"allocatedCPUs"`
+	FreeCPUs        int `json:"freeCPUs"`
+	// CPUsPerCore is the number of hyperthreads of each physical core, 1 without SMT.
+	CPUsPerCore int              `json:"cpusPerCore"`
+	NUMANodes   []NUMANodeStatus `json:"numaNodes"`
+	Claims      []ClaimStatus    `json:"claims"`
+}
+
+// NUMANodeStatus is the allocation of the CPUs of a NUMA node. The allocated cores run at least one
+// CPU allocated to a claim, and all the CPUs of the free cores are free.
+type NUMANodeStatus struct {
+	ID             int `json:"id"`
+	SocketID       int `json:"socketID"`
+	AllocatedCPUs  int `json:"allocatedCPUs"`
+	FreeCPUs       int `json:"freeCPUs"`
+	AllocatedCores int `json:"allocatedCores"`
+	FreeCores      int `json:"freeCores"`
+}
+
+// ClaimStatus is a claim prepared on the node.
+type ClaimStatus struct {
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	UID       types.UID `json:"uid"`
+	CPUs      string    `json:"cpus"`
+}
+
+// Update creates the DRACPUNodeStatus, or replaces the status of the existing one.
+func Update(ctx context.Context, client dynamic.Interface, status *DRACPUNodeStatus) error {
+	status.APIVersion = GroupVersionResource.GroupVersion().String()
+	status.Kind = "DRACPUNodeStatus"
+	resource := client.Resource(GroupVersionResource)
+	current, err := resource.Get(ctx, status.Name, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get DRACPUNodeStatus %s: %w", status.Name, err)
+	}
+	exists := err == nil
+	if exists {
+		status.ResourceVersion = current.GetResourceVersion()
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(status)
+	if err != nil {
+		return fmt.Errorf("failed to convert DRACPUNodeStatus %s: %w", status.Name, err)
+	}
+	obj := &unstructured.Unstructured{Object: content}
+	if exists {
+		_, err = resource.Update(ctx, obj, metav1.UpdateOptions{})
+	} else {
+		_, err = resource.Create(ctx, obj, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write DRACPUNodeStatus %s: %w", status.Name, err)
+	}
+	return nil
+}
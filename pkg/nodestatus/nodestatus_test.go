@@ -0,0 +1,46 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodestatus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestUpdate(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{GroupVersionResource: "DRACPUNodeStatusList"})
+	ctx := context.Background()
+
+	status := &DRACPUNodeStatus{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Status: NodeStatus{DeviceMode: "grouped", FreeCPUs: 8}}
+	require.NoError(t, Update(ctx, client, status))
+	status = &DRACPUNodeStatus{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Status: NodeStatus{DeviceMode: "grouped", FreeCPUs: 6, Claims: []ClaimStatus{{Namespace: "ns", Name: "claim", UID: "uid", CPUs: "0-1"}}}}
+	require.NoError(t, Update(ctx, client, status))
+
+	obj, err := client.Resource(GroupVersionResource).Get(ctx, "node-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "dra.cpu/v1alpha1", obj.GetAPIVersion())
+	var got DRACPUNodeStatus
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), &got))
+	require.Equal(t, 6, got.Status.FreeCPUs)
+	require.Equal(t, []ClaimStatus{{Namespace: "ns", Name: "claim", UID: "uid", CPUs: "0-1"}}, got.Status.Claims)
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package preflight checks that a node is set up for the driver: the cgroup cpuset controller,
+// the NRI socket of the container runtime, the kubelet plugin registration and the DRA API,
+// and that no other agent manages the CPUs.
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/hostfs"
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/api/resource/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	// StatusPass is a check the node passes.
+	StatusPass Status = "pass"
+	// StatusWarn is a check the driver can run with, but that may disturb the CPUs pinning.
+	StatusWarn Status = "warn"
+	// StatusFail is a check the driver can't work without.
+	StatusFail Status = "fail"
+	// StatusSkip is a check that couldn't be run, e.g. without access to the apiserver.
+	StatusSkip Status = "skip"
+)
+
+// NodeConditionType is the node condition reporting the failed checks, True when there is any.
+const NodeConditionType corev1.NodeConditionType = "DRACPUMisconfigured"
+
+// conflictingAgents are the daemons that also move processes or interrupts across CPUs.
+var conflictingAgents = []string{"tuned", "irqbalance"}
+
+// Config points the checks at the host paths, overridden by the tests.
+type Config struct {
+	// CgroupRoot is the mount point of the cgroup hierarchy, e.g. /sys/fs/cgroup.
+	CgroupRoot string
+	// NRISocketPath is the NRI socket of the container runtime.
+	NRISocketPath string
+	// NRIOptional is set when the enforcement backend can pin the containers without NRI, a missing
+	// socket is then only a warning.
+	NRIOptional bool
+	// PluginRegistryPath is the directory the kubelet watches for plugin registrations.
+	PluginRegistryPath string
+	// ProcRoot is the procfs of the host PID namespace.
+	ProcRoot string
+	// KubeletCheckpointPath is the kubelet CPU Manager checkpoint.
+	KubeletCheckpointPath string
+	// KubeletCoexistence is set when the driver coexists with the kubelet CPU Manager static policy.
+	KubeletCoexistence bool
+	// KubeClient checks the DRA API is served, skipped if nil.
+	KubeClient kubernetes.Interface
+	// FS is the filesystem the host paths are read from, the real one if nil.
+	FS hostfs.FS
+}
+
+// Result is the outcome of a check with the details to act on it.
+type Result struct {
+	Name    string
+	Status  Status
+	Message string
+}
+
+// Run runs all the checks.
+func Run(ctx context.Context, config Config) []Result {
+	fsys := config.FS
+	if fsys == nil {
+		fsys = hostfs.OS{}
+	}
+	cgroupResult, cgroupV2 := checkCgroupMode(fsys, config.CgroupRoot)
+	return []Result{
+		cgroupResult,
+		checkCpusetController(fsys, config.CgroupRoot, cgroupV2),
+		checkNRISocket(fsys, config.NRISocketPath, config.NRIOptional),
+		checkPluginRegistry(fsys, config.PluginRegistryPath),
+		checkDRAAPI(ctx, config.KubeClient),
+		checkKubeletCPUManager(fsys, config.KubeletCheckpointPath, config.KubeletCoexistence),
+		checkConflictingAgents(fsys, config.ProcRoot),
+	}
+}
+
+// Failed returns the failed checks.
+func Failed(results []Result) []Result {
+	var failed []Result
+	for _, result := range results {
+		if result.Status == StatusFail {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// PrintReport writes one line per check.
+func PrintReport(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, result := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", strings.ToUpper(string(result.Status)), result.Name, result.Message)
+	}
+	return tw.Flush()
+}
+
+func checkCgroupMode(fsys hostfs.FS, root string) (Result, bool) {
+	result := Result{Name: "cgroup-mode"}
+	if _, err := fsys.Stat(filepath.Join(root, "cgroup.controllers")); err == nil {
+		result.Status, result.Message = StatusPass, "cgroup v2 unified hierarchy"
+		return result, true
+	}
+	if _, err := fsys.Stat(filepath.Join(root, "cpuset")); err == nil {
+		result.Status, result.Message = StatusPass, "cgroup v1 hierarchies"
+		return result, false
+	}
+	result.Status, result.Message = StatusFail, fmt.Sprintf("no cgroup hierarchy found at %s", root)
+	return result, false
+}
+
+func checkCpusetController(fsys hostfs.FS, root string, cgroupV2 bool) Result {
+	result := Result{Name: "cpuset-controller"}
+	if !cgroupV2 {
+		if _, err := fsys.Stat(filepath.Join(root, "cpuset", "cpuset.cpus")); err != nil {
+			result.Status, result.Message = StatusFail, fmt.Sprintf("cgroup v1 cpuset controller not mounted: %v", err)
+			return result
+		}
+		result.Status, result.Message = StatusPass, "cgroup v1 cpuset controller mounted"
+		return result
+	}
+	data, err := fsys.ReadFile(filepath.Join(root, "cgroup.controllers"))
+	if err != nil {
+		result.Status, result.Message = StatusFail, err.Error()
+		return result
+	}
+	if !slices.Contains(strings.Fields(string(data)), "cpuset") {
+		result.Status, result.Message = StatusFail, "cpuset controller not available in cgroup.controllers"
+		return result
+	}
+	result.Status, result.Message = StatusPass, "cpuset controller available"
+	return result
+}
+
+func checkNRISocket(fsys hostfs.FS, path string, optional bool) Result {
+	result := Result{Name: "nri-socket"}
+	info, err := fsys.Stat(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && optional:
+		result.Status, result.Message = StatusWarn, fmt.Sprintf("%s not found: NRI is not enabled in the container runtime, the containers are pinned without it", path)
+	case errors.Is(err, os.ErrNotExist):
+		result.Status, result.Message = StatusFail, fmt.Sprintf("%s not found: NRI is not enabled in the container runtime", path)
+	case err != nil:
+		result.Status, result.Message = StatusFail, err.Error()
+	case info.Mode()&os.ModeSocket == 0:
+		result.Status, result.Message = StatusFail, fmt.Sprintf("%s is not a socket", path)
+	default:
+		result.Status, result.Message = StatusPass, fmt.Sprintf("%s available", path)
+	}
+	return result
+}
+
+func checkPluginRegistry(fsys hostfs.FS, path string) Result {
+	result := Result{Name: "kubelet-plugin-registration"}
+	info, err := fsys.Stat(path)
+	switch {
+	case err != nil:
+		result.Status, result.Message = StatusFail, fmt.Sprintf("kubelet plugin registry unavailable: %v", err)
+	case !info.IsDir():
+		result.Status, result.Message = StatusFail, fmt.Sprintf("%s is not a directory", path)
+	default:
+		result.Status, result.Message = StatusPass, fmt.Sprintf("%s available", path)
+	}
+	return result
+}
+
+// checkDRAAPI checks the apiserver serves the ResourceSlices the driver publishes, which the
+// DynamicResourceAllocation feature gate also requires for the kubelet to call the driver.
+func checkDRAAPI(_ context.Context, kubeClient kubernetes.Interface) Result {
+	result := Result{Name: "dra-api"}
+	if kubeClient == nil {
+		result.Status, result.Message = StatusSkip, "no apiserver access"
+		return result
+	}
+	groupVersion := resourceapi.SchemeGroupVersion.String()
+	resources, err := kubeClient.Discovery().ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		result.Status, result.Message = StatusFail, fmt.Sprintf("%s not served, is the DynamicResourceAllocation feature gate enabled? %v", groupVersion, err)
+		return result
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == "resourceslices" {
+			result.Status, result.Message = StatusPass, fmt.Sprintf("%s served", groupVersion)
+			return result
+		}
+	}
+	result.Status, result.Message = StatusFail, fmt.Sprintf("%s doesn't serve resourceslices", groupVersion)
+	return result
+}
+
+func checkKubeletCPUManager(fsys hostfs.FS, path string, coexistence bool) Result {
+	result := Result{Name: "kubelet-cpu-manager"}
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		result.Status, result.Message = StatusSkip, err.Error()
+		return result
+	}
+	checkpoint := &cpumanager.KubeletCheckpoint{}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		result.Status, result.Message = StatusFail, fmt.Sprintf("invalid kubelet CPU Manager checkpoint %s: %v", path, err)
+		return result
+	}
+	switch {
+	case checkpoint.PolicyName != "static":
+		result.Status, result.Message = StatusPass, fmt.Sprintf("kubelet CPU Manager policy %q", checkpoint.PolicyName)
+	case coexistence:
+		result.Status, result.Message = StatusPass, "kubelet CPU Manager static policy, coexistence enabled"
+	default:
+		result.Status, result.Message = StatusFail, "kubelet CPU Manager static policy pins CPUs the driver also allocates, set --kubelet-cpu-manager-state"
+	}
+	return result
+}
+
+func checkConflictingAgents(fsys hostfs.FS, procRoot string) Result {
+	result := Result{Name: "conflicting-agents"}
+	entries, err := fsys.ReadDir(procRoot)
+	if err != nil {
+		result.Status, result.Message = StatusSkip, err.Error()
+		return result
+	}
+	var found []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.TrimLeft(entry.Name(), "0123456789") != "" {
+			continue
+		}
+		comm, err := fsys.ReadFile(filepath.Join(procRoot, entry.Name(), "comm"))
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSpace(string(comm))
+		if slices.Contains(conflictingAgents, name) && !slices.Contains(found, name) {
+			found = append(found, name)
+		}
+	}
+	if len(found) > 0 {
+		slices.Sort(found)
+		result.Status, result.Message = StatusWarn, fmt.Sprintf("%s running, may move the pinned workloads or their interrupts", strings.Join(found, ", "))
+		return result
+	}
+	result.Status, result.Message = StatusPass, "none running"
+	return result
+}
+
+// SetNodeCondition reports the failed checks in the NodeConditionType condition of the node.
+func SetNodeCondition(ctx context.Context, kubeClient kubernetes.Interface, nodeName string, results []Result) error {
+	node, err := kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	}
+	now := metav1.Now()
+	condition := corev1.NodeCondition{
+		Type:               NodeConditionType,
+		Status:             corev1.ConditionFalse,
+		Reason:             "PreflightPassed",
+		Message:            "all the dra.cpu preflight checks passed",
+		LastHeartbeatTime:  now,
+		LastTransitionTime: now,
+	}
+	if failed := Failed(results); len(failed) > 0 {
+		messages := []string{}
+		for _, result := range failed {
+			messages = append(messages, result.Name+": "+result.Message)
+		}
+		condition.Status = corev1.ConditionTrue
+		condition.Reason = "PreflightFailed"
+		condition.Message = strings.Join(messages, "; ")
+	}
+	for _, existing := range node.Status.Conditions {
+		if existing.Type == NodeConditionType && existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+	}
+	patch, err := json.Marshal(map[string]any{
+		"status": map[string]any{
+			"conditions": []corev1.NodeCondition{condition},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := kubeClient.CoreV1().Nodes().PatchStatus(ctx, nodeName, patch); err != nil {
+		return fmt.Errorf("failed to patch node %s status: %w", nodeName, err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/hostfs"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestRun(t *testing.T) {
+	root := t.TempDir()
+	cgroupRoot := filepath.Join(root, "cgroup")
+	writeFile(t, filepath.Join(cgroupRoot, "cgroup.controllers"), "cpuset cpu io memory pids\n")
+	registryPath := filepath.Join(root, "plugins_registry")
+	require.NoError(t, os.Mkdir(registryPath, 0755))
+	procRoot := filepath.Join(root, "proc")
+	writeFile(t, filepath.Join(procRoot, "1", "comm"), "systemd\n")
+	writeFile(t, filepath.Join(procRoot, "42", "comm"), "irqbalance\n")
+	writeFile(t, filepath.Join(procRoot, "self", "comm"), "tuned\n")
+	checkpointPath := filepath.Join(root, "cpu_manager_state")
+	writeFile(t, checkpointPath, `{"policyName":"static","defaultCpuSet":"0-7","checksum":1}`)
+
+	socketPath := filepath.Join(root, "nri.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	kubeClient := fake.NewClientset()
+	kubeClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "resource.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "resourceslices"}}},
+	}
+
+	config := Config{
+		CgroupRoot:            cgroupRoot,
+		NRISocketPath:         socketPath,
+		PluginRegistryPath:    registryPath,
+		ProcRoot:              procRoot,
+		KubeletCheckpointPath: checkpointPath,
+		KubeClient:            kubeClient,
+	}
+	statuses := func(results []Result) map[string]Status {
+		statuses := map[string]Status{}
+		for _, result := range results {
+			statuses[result.Name] = result.Status
+		}
+		return statuses
+	}
+
+	results := Run(context.Background(), config)
+	require.Equal(t, map[string]Status{
+		"cgroup-mode":                 StatusPass,
+		"cpuset-controller":           StatusPass,
+		"nri-socket":                  StatusPass,
+		"kubelet-plugin-registration": StatusPass,
+		"dra-api":                     StatusPass,
+		"kubelet-cpu-manager":         StatusFail,
+		"conflicting-agents":          StatusWarn,
+	}, statuses(results))
+	require.Len(t, Failed(results), 1)
+
+	var report strings.Builder
+	require.NoError(t, PrintReport(&report, results))
+	require.Contains(t, report.String(), "WARN  conflicting-agents           irqbalance running")
+
+	config.KubeletCoexistence = true
+	config.NRISocketPath = filepath.Join(root, "missing.sock")
+	config.KubeClient = nil
+	config.ProcRoot = filepath.Join(root, "missing")
+	writeFile(t, filepath.Join(cgroupRoot, "cgroup.controllers"), "cpu io memory pids\n")
+	require.Equal(t, map[string]Status{
+		"cgroup-mode":                 StatusPass,
+		"cpuset-controller":           StatusFail,
+		"nri-socket":                  StatusFail,
+		"kubelet-plugin-registration": StatusPass,
+		"dra-api":                     StatusSkip,
+		"kubelet-cpu-manager":         StatusPass,
+		"conflicting-agents":          StatusSkip,
+	}, statuses(Run(context.Background(), config)))
+
+	config.NRIOptional = true
+	require.Equal(t, StatusWarn, statuses(Run(context.Background(), config))["nri-socket"])
+}
+
+func TestSetNodeCondition(t *testing.T) {
+	kubeClient := fake.NewClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	nodeCondition := func() corev1.NodeCondition {
+		node, err := kubeClient.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Len(t, node.Status.Conditions, 1)
+		return node.Status.Conditions[0]
+	}
+
+	failed := []Result{
+		{Name: "cgroup-mode", Status: StatusPass},
+		{Name: "nri-socket", Status: StatusFail, Message: "/var/run/nri/nri.sock not found"},
+	}
+	require.NoError(t, SetNodeCondition(context.Background(), kubeClient, "node-1", failed))
+	condition := nodeCondition()
+	require.Equal(t, NodeConditionType, condition.Type)
+	require.Equal(t, corev1.ConditionTrue, condition.Status)
+	require.Equal(t, "PreflightFailed", condition.Reason)
+	require.Equal(t, "nri-socket: /var/run/nri/nri.sock not found", condition.Message)
+
+	require.NoError(t, SetNodeCondition(context.Background(), kubeClient, "node-1", failed[:1]))
+	condition = nodeCondition()
+	require.Equal(t, corev1.ConditionFalse, condition.Status)
+	require.Equal(t, "PreflightPassed", condition.Reason)
+}
+
+func TestRunUnreadableCgroups(t *testing.T) {
+	fake := hostfs.NewFake(map[string]string{
+		"/sys/fs/cgroup/cgroup.controllers": "cpuset cpu memory\n",
+		"/proc/1/comm":                      "systemd\n",
+	})
+	fake.Add("/var/run/nri/nri.sock", &fstest.MapFile{Mode: fs.ModeSocket})
+	fake.Add("/var/lib/kubelet/plugins_registry", &fstest.MapFile{Mode: fs.ModeDir})
+	config := Config{
+		CgroupRoot:            "/sys/fs/cgroup",
+		NRISocketPath:         "/var/run/nri/nri.sock",
+		PluginRegistryPath:    "/var/lib/kubelet/plugins_registry",
+		ProcRoot:              "/proc",
+		KubeletCheckpointPath: "/var/lib/kubelet/cpu_manager_state",
+		FS:                    fake,
+	}
+	require.Empty(t, Failed(Run(context.Background(), config)))
+
+	fake.Fail("/sys/fs/cgroup/cgroup.controllers", fs.ErrPermission)
+	failed := Failed(Run(context.Background(), config))
+	require.Len(t, failed, 1)
+	require.Equal(t, "cpuset-controller", failed[0].Name)
+	require.Contains(t, failed[0].Message, "permission denied")
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package purevalidate validates the opaque parameters of the dra.cpu driver in ResourceClaims,
+// ResourceClaimTemplates and DeviceClasses given as unstructured objects, with pure functions and
+// without client-go, so policy engines embedding Go, like Kyverno or Gatekeeper, reject at admission
+// the parameters the driver fails to prepare the claims with. The driver applies the same validators.
+// It also rejects the device requests of the driver combining fields nonsensically, see ValidateRequests.
+package purevalidate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpumanager"
+)
+
+// DriverName is the default name of the driver, the one of the opaque configurations to validate.
+const DriverName = "dra.cpu"
+
+const (
+	// PerformanceHintFastest picks the fastest cores of the node.
+	PerformanceHintFastest = "fastest"
+	// OnPodFailureKeep keeps the CPUs of a claim reserved while its consumer pods are failing.
+	OnPodFailureKeep = "keep"
+	// OnPodFailureRelease gives the CPUs of a claim back to the shared pool while its consumer pods are failing.
+	OnPodFailureRelease = "release"
+	// CPUBandwidthPod leaves the CPU quota and weight of the containers to the requests and limits of the pod.
+	CPUBandwidthPod = "pod"
+	// CPUBandwidthClaim sets the CPU quota and weight of the containers of the claim from the number of its CPUs.
+	CPUBandwidthClaim = "claim"
+)
+
+// Parameters are the opaque parameters of the driver, see the claim configuration of the README.
+type Parameters struct {
+	Priority                  int32  `json:"priority,omitempty"`
+	Strategy                  string `json:"strategy,omitempty"`
+	AlignWithClaim            string `json:"alignWithClaim,omitempty"`
+	AlignWithDriver           string `json:"alignWithDriver,omitempty"`
+	PollingCores              int32  `json:"pollingCores,omitempty"`
+	PollingNIC                string `json:"pollingNIC,omitempty"`
+	EmulatorThreadCPUs        int32  `json:"emulatorThreadCPUs,omitempty"`
+	ReportAllocation          bool   `json:"reportAllocation,omitempty"`
+	PerformanceHint           string `json:"performanceHint,omitempty"`
+	Contiguous                bool   `json:"contiguous,omitempty"`
+	NUMAAffinityWithClaim     string `json:"numaAffinityWithClaim,omitempty"`
+	NUMAAntiAffinityWithClaim string `json:"numaAntiAffinityWithClaim,omitempty"`
+	SocketAffinity            *int32 `json:"socketAffinity,omitempty"`
+	MaxSockets                int32  `json:"maxSockets,omitempty"`
+	CacheWays                 int32  `json:"cacheWays,omitempty"`
+	MemoryBandwidthPercent    int32  `json:"memoryBandwidthPercent,omitempty"`
+	OnPodFailure              string `json:"onPodFailure,omitempty"`
+	UseHousekeepingCPUs       bool   `json:"useHousekeepingCPUs,omitempty"`
+	CPUBandwidth              string `json:"cpuBandwidth,omitempty"`
+}
+
+// ValidateStrategy fails on an unknown placement strategy, empty is the driver default.
+func ValidateStrategy(strategy string) error {
+	if strategy == "" || slices.Contains(cpumanager.Strategies, strategy) {
+		return nil
+	}
+	return fmt.Errorf("unknown placement strategy %q, must be one of: %s", strategy, strings.Join(cpumanager.Strategies, ", "))
+}
+
+// ValidatePerformanceHint fails on an unknown performance hint, empty is none.
+func ValidatePerformanceHint(hint string) error {
+	if hint == "" || hint == PerformanceHintFastest {
+		return nil
+	}
+	return fmt.Errorf("unknown performance hint %q, must be %q", hint, PerformanceHintFastest)
+}
+
+// ValidateOnPodFailure fails on an unknown onPodFailure policy, empty is keep.
+func ValidateOnPodFailure(policy string) error {
+	switch policy {
+	case "", OnPodFailureKeep, OnPodFailureRelease:
+		return nil
+	}
+	return fmt.Errorf("unknown onPodFailure policy %q, must be %q or %q", policy, OnPodFailureKeep, OnPodFailureRelease)
+}
+
+// ValidateCPUBandwidth fails on an unknown cpuBandwidth policy, empty is pod.
+func ValidateCPUBandwidth(policy string) error {
+	switch policy {
+	case "", CPUBandwidthPod, CPUBandwidthClaim:
+		return nil
+	}
+	return fmt.Errorf("unknown cpuBandwidth policy %q, must be %q or %q", policy, CPUBandwidthPod, CPUBandwidthClaim)
+}
+
+// ValidateCacheAllocation fails on a negative share of the cache or memory bandwidth of a claim asking for one.
+func ValidateCacheAllocation(cacheWays, memoryBandwidthPercent int32) error {
+	if (cacheWays > 0 || memoryBandwidthPercent > 0) && (cacheWays < 0 || memoryBandwidthPercent < 0) {
+		return errors.New("cacheWays and memoryBandwidthPercent can't be negative")
+	}
+	return nil
+}
+
+// Validate returns the violations of the parameters, whatever the device mode of the node: the strategy
+// is only used, and validated by the driver, in grouped mode.
+func (p *Parameters) Validate() error {
+	return errors.Join(
+		ValidateStrategy(p.Strategy),
+		ValidatePerformanceHint(p.PerformanceHint),
+		ValidateOnPodFailure(p.OnPodFailure),
+		ValidateCPUBandwidth(p.CPUBandwidth),
+		ValidateCacheAllocation(p.CacheWays, p.MemoryBandwidthPercent),
+	)
+}
+
+// ValidateParameters decodes and validates the raw opaque parameters of the driver, the unknown fields
+// are rejected like the driver does.
+func ValidateParameters(raw []byte) error {
+	params := &Parameters{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(params); err != nil {
+		return fmt.Errorf("invalid opaque configuration: %w", err)
+	}
+	return params.Validate()
+}
+
+// Validate returns the violations of the opaque parameters of the driver in an unstructured
+// ResourceClaim, ResourceClaimTemplate or DeviceClass of the resource.k8s.io API group, e.g. the
+// object of an admission request, each prefixed with the path of its configuration. The other
+// kinds have none.
+func Validate(obj map[string]any, driverName string) []error {
+	var configPaths [][]string
+	switch kind, _ := obj["kind"].(string); kind {
+	case "ResourceClaim":
+		configPaths = [][]string{{"spec", "devices", "config"}, {"status", "allocation", "devices", "config"}}
+	case "ResourceClaimTemplate":
+		configPaths = [][]string{{"spec", "spec", "devices", "config"}}
+	case "DeviceClass":
+		configPaths = [][]string{{"spec", "config"}}
+	}
+	var errs []error
+	for _, configPath := range configPaths {
+		configs, _ := nested(obj, configPath...).([]any)
+		for i, config := range configs {
+			path := fmt.Sprintf("%s[%d].opaque", strings.Join(configPath, "."), i)
+			opaque, _ := nested(config, "opaque").(map[string]any)
+			if opaque == nil || opaque["driver"] != driverName {
+				continue
+			}
+			raw, err := json.Marshal(opaque["parameters"])
+			if err == nil {
+				err = ValidateParameters(raw)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s.parameters: %w", path, err))
+			}
+		}
+	}
+	return errs
+}
+
+// nested returns the value of the fields of an unstructured object, nil if one is missing.
+func nested(obj any, fields ...string) any {
+	for _, field := range fields {
+		m, ok := obj.(map[string]any)
+		if !ok {
+			return nil
+		}
+		obj = m[field]
+	}
+	return obj
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package purevalidate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateParameters(t *testing.T) {
+	testCases := []struct {
+		name    string
+		raw     string
+		wantErr []string
+	}{
+		{name: "empty", raw: `{}`},
+		{name: "valid", raw: `{"strategy": "spread-numa", "performanceHint": "fastest", "onPodFailure": "release", "cpuBandwidth": "claim", "cacheWays": 2}`},
+		{name: "unknown field", raw: `{"priorty": 1}`, wantErr: []string{`unknown field "priorty"`}},
+		{name: "wrong type", raw: `{"priority": "high"}`, wantErr: []string{"invalid opaque configuration"}},
+		{
+			name: "every violation",
+			raw:  `{"strategy": "random", "performanceHint": "slowest", "onPodFailure": "restart", "cpuBandwidth": "burst", "cacheWays": 2, "memoryBandwidthPercent": -1}`,
+			wantErr: []string{
+				"unknown placement strategy",
+				"unknown performance hint",
+				"unknown onPodFailure policy",
+				"unknown cpuBandwidth policy",
+				"can't be negative",
+			},
+		},
+		{name: "negative cache ways without cache allocation", raw: `{"cacheWays": -1}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateParameters([]byte(tc.raw))
+			if len(tc.wantErr) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			for _, want := range tc.wantErr {
+				require.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	unstructured := func(t *testing.T, data string) map[string]any {
+		obj := map[string]any{}
+		require.NoError(t, json.Unmarshal([]byte(data), &obj))
+		return obj
+	}
+	testCases := []struct {
+		name    string
+		obj     string
+		wantErr []string
+	}{
+		{
+			name: "claim",
+			obj: `{"kind": "ResourceClaim", "spec": {"devices": {"config": [
+				{"opaque": {"driver": "gpu.example.com", "parameters": {"strategy": "random"}}},
+				{"opaque": {"driver": "dra.cpu", "parameters": {"strategy": "packed"}}},
+				{"opaque": {"driver": "dra.cpu", "parameters": {"strategy": "random"}}}
+			]}}}`,
+			wantErr: []string{`spec.devices.config[2].opaque.parameters: unknown placement strategy "random"`},
+		},
+		{
+			name: "allocated claim",
+			obj: `{"kind": "ResourceClaim", "status": {"allocation": {"devices": {"config": [
+				{"source": "FromClass", "opaque": {"driver": "dra.cpu", "parameters": {"onPodFailure": "restart"}}}
+			]}}}}`,
+			wantErr: []string{`status.allocation.devices.config[0].opaque.parameters: unknown onPodFailure policy "restart"`},
+		},
+		{
+			name: "claim template",
+			obj: `{"kind": "ResourceClaimTemplate", "spec": {"spec": {"devices": {"config": [
+				{"opaque": {"driver": "dra.cpu", "parameters": {"cpuBandwidth": "burst"}}}
+			]}}}}`,
+			wantErr: []string{`spec.spec.devices.config[0].opaque.parameters: unknown cpuBandwidth policy "burst"`},
+		},
+		{
+			name:    "device class",
+			obj:     `{"kind": "DeviceClass", "spec": {"config": [{"opaque": {"driver": "dra.cpu", "parameters": {"pollingCore": 1}}}]}}`,
+			wantErr: []string{`spec.config[0].opaque.parameters: invalid opaque configuration: json: unknown field "pollingCore"`},
+		},
+		{name: "other kind", obj: `{"kind": "Pod", "spec": {"config": [{"opaque": {"driver": "dra.cpu", "parameters": {"strategy": "random"}}}]}}`},
+		{name: "no config", obj: `{"kind": "ResourceClaim", "spec": {"devices": {"requests": [{"name": "cpus"}]}}}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := Validate(unstructured(t, tc.obj), DriverName)
+			require.Len(t, errs, len(tc.wantErr))
+			for i, want := range tc.wantErr {
+				require.ErrorContains(t, errs[i], want)
+			}
+		})
+	}
+}
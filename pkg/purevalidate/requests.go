@@ -0,0 +1,113 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package purevalidate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Reasons of the violations of the device requests of the driver.
+const (
+	// ReasonAdminAccessExclusive rejects the admin access to CPUs, which would be shared with the
+	// claims they are pinned exclusively to.
+	ReasonAdminAccessExclusive = "AdminAccessExclusive"
+	// ReasonAllDevicesWithCount rejects a count on a request of all the devices.
+	ReasonAllDevicesWithCount = "AllDevicesWithCount"
+	// ReasonCapacityWithCount rejects a capacity request of several devices outside of grouped
+	// mode, where the devices are single CPUs without consumable capacity.
+	ReasonCapacityWithCount = "CapacityWithCount"
+)
+
+// RequestError is a violation of a device request, its Reason tells the rules apart.
+type RequestError struct {
+	// Path is the path of the request in the object.
+	Path string
+	// Reason is one of the Reason constants.
+	Reason  string
+	Message string
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", e.Path, e.Message, e.Reason)
+}
+
+// ValidateRequests returns the violations, as RequestErrors, of the device requests of an unstructured
+// ResourceClaim or ResourceClaimTemplate asking for the devices of the driver through one of its
+// deviceClassNames, each subrequest of a request with alternatives on its own. groupedMode is the
+// device mode of the nodes. The other kinds have none.
+func ValidateRequests(obj map[string]any, deviceClassNames []string, groupedMode bool) []error {
+	var requestsPath []string
+	switch kind, _ := obj["kind"].(string); kind {
+	case "ResourceClaim":
+		requestsPath = []string{"spec", "devices", "requests"}
+	case "ResourceClaimTemplate":
+		requestsPath = []string{"spec", "spec", "devices", "requests"}
+	default:
+		return nil
+	}
+	var errs []error
+	requests, _ := nested(obj, requestsPath...).([]any)
+	for i, request := range requests {
+		path := fmt.Sprintf("%s[%d]", strings.Join(requestsPath, "."), i)
+		if exactly, ok := nested(request, "exactly").(map[string]any); ok {
+			errs = append(errs, validateRequest(path+".exactly", exactly, deviceClassNames, groupedMode)...)
+		}
+		subRequests, _ := nested(request, "firstAvailable").([]any)
+		for j, subRequest := range subRequests {
+			if subRequest, ok := subRequest.(map[string]any); ok {
+				errs = append(errs, validateRequest(fmt.Sprintf("%s.firstAvailable[%d]", path, j), subRequest, deviceClassNames, groupedMode)...)
+			}
+		}
+	}
+	return errs
+}
+
+// validateRequest returns the violations of an exact request or a subrequest.
+func validateRequest(path string, request map[string]any, deviceClassNames []string, groupedMode bool) []error {
+	if deviceClass, _ := request["deviceClassName"].(string); !slices.Contains(deviceClassNames, deviceClass) {
+		return nil
+	}
+	var errs []error
+	if adminAccess, _ := request["adminAccess"].(bool); adminAccess {
+		errs = append(errs, &RequestError{Path: path, Reason: ReasonAdminAccessExclusive,
+			Message: "adminAccess can't be requested on CPUs, they are pinned exclusively to the containers of their claim"})
+	}
+	count, hasCount := requestCount(request)
+	if mode, _ := request["allocationMode"].(string); mode == "All" && hasCount {
+		errs = append(errs, &RequestError{Path: path, Reason: ReasonAllDevicesWithCount,
+			Message: "count can't be set when allocationMode is All"})
+	}
+	if capacity, _ := nested(request, "capacity", "requests").(map[string]any); len(capacity) > 0 && !groupedMode && count > 1 {
+		errs = append(errs, &RequestError{Path: path, Reason: ReasonCapacityWithCount,
+			Message: "capacity can't be requested on several CPUs outside of grouped mode"})
+	}
+	return errs
+}
+
+// requestCount returns the count of a request and whether it is set, whether the object was decoded
+// from JSON, with float64 numbers, or converted from a typed one, with int64 numbers.
+func requestCount(request map[string]any) (int64, bool) {
+	switch count := request["count"].(type) {
+	case int64:
+		return count, true
+	case float64:
+		return int64(count), true
+	}
+	return 0, false
+}
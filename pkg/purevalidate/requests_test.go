@@ -0,0 +1,112 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package purevalidate
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRequests(t *testing.T) {
+	deviceClassNames := []string{"dra.cpu"}
+	testCases := []struct {
+		name        string
+		obj         string
+		groupedMode bool
+		wantReasons []string
+	}{
+		{
+			name: "valid claim",
+			obj: `{"kind": "ResourceClaim", "spec": {"devices": {"requests": [
+				{"name": "cpus", "exactly": {"deviceClassName": "dra.cpu", "count": 4}}
+			]}}}`,
+		},
+		{
+			name: "admin access",
+			obj: `{"kind": "ResourceClaim", "spec": {"devices": {"requests": [
+				{"name": "cpus", "exactly": {"deviceClassName": "dra.cpu", "adminAccess": true}}
+			]}}}`,
+			wantReasons: []string{ReasonAdminAccessExclusive},
+		},
+		{
+			name: "admin access to the devices of another driver",
+			obj: `{"kind": "ResourceClaim", "spec": {"devices": {"requests": [
+				{"name": "gpus", "exactly": {"deviceClassName": "gpu.example.com", "adminAccess": true}}
+			]}}}`,
+		},
+		{
+			name: "all devices with count",
+			obj: `{"kind": "ResourceClaimTemplate", "spec": {"spec": {"devices": {"requests": [
+				{"name": "cpus", "exactly": {"deviceClassName": "dra.cpu", "allocationMode": "All", "count": 2}}
+			]}}}}`,
+			wantReasons: []string{ReasonAllDevicesWithCount},
+		},
+		{
+			name: "capacity with count in individual mode",
+			obj: `{"kind": "ResourceClaim", "spec": {"devices": {"requests": [
+				{"name": "cpus", "firstAvailable": [
+					{"name": "small", "deviceClassName": "dra.cpu", "count": 1, "capacity": {"requests": {"dra.cpu/cpu": "2"}}},
+					{"name": "large", "deviceClassName": "dra.cpu", "count": 2, "capacity": {"requests": {"dra.cpu/cpu": "2"}}}
+				]}
+			]}}}`,
+			wantReasons: []string{ReasonCapacityWithCount},
+		},
+		{
+			name: "capacity with count in grouped mode",
+			obj: `{"kind": "ResourceClaim", "spec": {"devices": {"requests": [
+				{"name": "cpus", "exactly": {"deviceClassName": "dra.cpu", "count": 2, "capacity": {"requests": {"dra.cpu/cpu": "2"}}}}
+			]}}}`,
+			groupedMode: true,
+		},
+		{
+			name: "every violation",
+			obj: `{"kind": "ResourceClaim", "spec": {"devices": {"requests": [
+				{"name": "cpus", "exactly": {"deviceClassName": "dra.cpu", "adminAccess": true, "allocationMode": "All", "count": 2, "capacity": {"requests": {"dra.cpu/cpu": "2"}}}}
+			]}}}`,
+			wantReasons: []string{ReasonAdminAccessExclusive, ReasonAllDevicesWithCount, ReasonCapacityWithCount},
+		},
+		{
+			name: "device class",
+			obj:  `{"kind": "DeviceClass", "spec": {"selectors": []}}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(tc.obj), &obj))
+			var reasons []string
+			for _, err := range ValidateRequests(obj, deviceClassNames, tc.groupedMode) {
+				var requestErr *RequestError
+				require.True(t, errors.As(err, &requestErr))
+				reasons = append(reasons, requestErr.Reason)
+			}
+			require.Equal(t, tc.wantReasons, reasons)
+		})
+	}
+}
+
+func TestValidateRequestsPath(t *testing.T) {
+	obj := map[string]any{"kind": "ResourceClaim", "spec": map[string]any{"devices": map[string]any{"requests": []any{
+		map[string]any{"name": "cpus", "exactly": map[string]any{"deviceClassName": "dra.cpu", "allocationMode": "All", "count": int64(1)}},
+	}}}}
+	errs := ValidateRequests(obj, []string{"dra.cpu"}, true)
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0], "spec.devices.requests[0].exactly: count can't be set when allocationMode is All (AllDevicesWithCount)")
+}
@@ -0,0 +1,363 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package resctrl programs the Linux resctrl filesystem, the interface of the cache allocation (Intel
+// RDT CAT, AMD PQoS L3 allocation) and memory bandwidth allocation features, so the exclusive CPUs of
+// a claim get their own share of the last level cache and of the memory bandwidth, and its monitoring
+// (Intel RDT CMT and MBM, AMD PQoS monitoring) to report the cache and memory bandwidth claims use.
+package resctrl
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"k8s.io/utils/cpuset"
+)
+
+// DefaultPath is where the resctrl filesystem is usually mounted.
+const DefaultPath = "/sys/fs/resctrl"
+
+// groupPrefix is the prefix of the names of the resctrl groups created by the driver.
+const groupPrefix = "dracpu-"
+
+// Capabilities are the allocation features supported by the host.
+type Capabilities struct {
+	// L3CAT reports whether the last level cache ways can be allocated.
+	L3CAT bool
+	// L3CacheWays is the number of ways of the last level cache, the bits of its capacity bitmask.
+	L3CacheWays int
+	// L3Domains are the IDs of the last level caches, one per domain of the L3 schemata.
+	L3Domains []int
+	// MBA reports whether the memory bandwidth can be allocated.
+	MBA bool
+	// MBAMinBandwidth is the minimum memory bandwidth percentage that can be allocated.
+	MBAMinBandwidth int
+	// MBAGranularity is the step, in percents, of the memory bandwidth allocations.
+	MBAGranularity int
+	// MBADomains are the IDs of the memory bandwidth domains of the MB schemata.
+	MBADomains []int
+	// NumCLOSIDs is the number of resctrl groups, default one included, the host supports.
+	NumCLOSIDs int
+	// L3Monitoring reports whether the last level cache occupancy or memory bandwidth can be monitored.
+	L3Monitoring bool
+	// MonitoringFeatures are the events that can be monitored, e.g. llc_occupancy or mbm_total_bytes.
+	MonitoringFeatures []string
+}
+
+// Monitoring are the monitoring counters of a group, summed over the last level caches.
+type Monitoring struct {
+	// LLCOccupancyBytes is the last level cache used by the tasks of the group.
+	LLCOccupancyBytes uint64
+	// MBMTotalBytes is the cumulative memory traffic of the tasks of the group.
+	MBMTotalBytes uint64
+	// MBMLocalBytes is the cumulative memory traffic of the tasks of the group to their local NUMA node.
+	MBMLocalBytes uint64
+}
+
+// Allocation is the share of the cache and memory bandwidth given to a group.
+type Allocation struct {
+	// CacheWays is the number of last level cache ways, zero leaves the cache unrestricted.
+	CacheWays int
+	// MemoryBandwidthPercent is the memory bandwidth limit, zero leaves it unrestricted.
+	MemoryBandwidthPercent int
+}
+
+// Manager creates and removes the resctrl groups of a resctrl filesystem.
+type Manager struct {
+	path string
+	caps Capabilities
+}
+
+// New returns a Manager for the resctrl filesystem mounted at path, after reading its capabilities.
+func New(path string) (*Manager, error) {
+	if _, err := os.Stat(filepath.Join(path, "info")); err != nil {
+		return nil, fmt.Errorf("resctrl filesystem not mounted at %s: %w", path, err)
+	}
+	caps, err := readCapabilities(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{path: path, caps: caps}, nil
+}
+
+// Capabilities returns the allocation features supported by the host.
+func (m *Manager) Capabilities() Capabilities {
+	return m.caps
+}
+
+func readCapabilities(path string) (Capabilities, error) {
+	var caps Capabilities
+	schemata, err := os.ReadFile(filepath.Join(path, "schemata"))
+	if err != nil {
+		return caps, fmt.Errorf("failed to read the default resctrl schemata: %w", err)
+	}
+	domains, err := parseSchemata(string(schemata))
+	if err != nil {
+		return caps, err
+	}
+	if mask, err := readString(path, "info/L3/cbm_mask"); err == nil {
+		cbm, err := strconv.ParseUint(mask, 16, 64)
+		if err != nil {
+			return caps, fmt.Errorf("invalid L3 capacity bitmask %q: %w", mask, err)
+		}
+		caps.L3CAT = true
+		caps.L3CacheWays = bits.OnesCount64(cbm)
+		caps.L3Domains = domains["L3"]
+		if caps.NumCLOSIDs, err = readInt(path, "info/L3/num_closids"); err != nil {
+			return caps, err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return caps, err
+	}
+	if minBandwidth, err := readInt(path, "info/MB/min_bandwidth"); err == nil {
+		caps.MBA = true
+		caps.MBAMinBandwidth = minBandwidth
+		caps.MBADomains = domains["MB"]
+		if caps.MBAGranularity, err = readInt(path, "info/MB/bandwidth_gran"); err != nil {
+			return caps, err
+		}
+		numCLOSIDs, err := readInt(path, "info/MB/num_closids")
+		if err != nil {
+			return caps, err
+		}
+		// the groups are limited by the resource supporting the fewest of them.
+		if caps.NumCLOSIDs == 0 || numCLOSIDs < caps.NumCLOSIDs {
+			caps.NumCLOSIDs = numCLOSIDs
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return caps, err
+	}
+	if features, err := readString(path, "info/L3_MON/mon_features"); err == nil {
+		caps.L3Monitoring = true
+		caps.MonitoringFeatures = strings.Fields(features)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return caps, err
+	}
+	return caps, nil
+}
+
+// parseSchemata returns the domain IDs of each resource of a schemata file, e.g. "L3:0=7ff;1=7ff".
+func parseSchemata(schemata string) (map[string][]int, error) {
+	domains := map[string][]int{}
+	for _, line := range strings.Split(schemata, "\n") {
+		resource, values, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		resource = strings.TrimSpace(resource)
+		for _, value := range strings.Split(values, ";") {
+			id, _, ok := strings.Cut(strings.TrimSpace(value), "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid resctrl schemata line %q", line)
+			}
+			domainID, err := strconv.Atoi(id)
+			if err != nil {
+				return nil, fmt.Errorf("invalid resctrl schemata line %q: %w", line, err)
+			}
+			domains[resource] = append(domains[resource], domainID)
+		}
+	}
+	return domains, nil
+}
+
+func readString(path, name string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(path, name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func readInt(path, name string) (int, error) {
+	content, err := readString(path, name)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.Atoi(content)
+	if err != nil {
+		return 0, fmt.Errorf("invalid resctrl %s %q: %w", name, content, err)
+	}
+	return value, nil
+}
+
+// Validate fails if the host can't give the allocation to a group.
+func (m *Manager) Validate(alloc Allocation) error {
+	if alloc.CacheWays > 0 {
+		if !m.caps.L3CAT {
+			return fmt.Errorf("the last level cache allocation is not supported")
+		}
+		if alloc.CacheWays > m.caps.L3CacheWays {
+			return fmt.Errorf("%d cache ways requested, the last level cache has %d", alloc.CacheWays, m.caps.L3CacheWays)
+		}
+	}
+	if alloc.MemoryBandwidthPercent > 0 {
+		if !m.caps.MBA {
+			return fmt.Errorf("the memory bandwidth allocation is not supported")
+		}
+		if alloc.MemoryBandwidthPercent < m.caps.MBAMinBandwidth || alloc.MemoryBandwidthPercent > 100 {
+			return fmt.Errorf("memory bandwidth of %d%% requested, must be between %d%% and 100%%", alloc.MemoryBandwidthPercent, m.caps.MBAMinBandwidth)
+		}
+	}
+	return nil
+}
+
+// schemata returns the schemata of a group given the allocation. The cache ways are the lowest bits
+// of the capacity bitmask, which must be contiguous, and are shared with the default group.
+func (m *Manager) schemata(alloc Allocation) string {
+	var lines []string
+	if alloc.CacheWays > 0 {
+		mask := strconv.FormatUint(uint64(1)<<alloc.CacheWays-1, 16)
+		lines = append(lines, "L3:"+domainValues(m.caps.L3Domains, mask))
+	}
+	if alloc.MemoryBandwidthPercent > 0 {
+		lines = append(lines, "MB:"+domainValues(m.caps.MBADomains, strconv.Itoa(alloc.MemoryBandwidthPercent)))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func domainValues(domains []int, value string) string {
+	values := make([]string, 0, len(domains))
+	for _, id := range domains {
+		values = append(values, fmt.Sprintf("%d=%s", id, value))
+	}
+	return strings.Join(values, ";")
+}
+
+// groupPath returns the directory of the resctrl group of the given name.
+func (m *Manager) groupPath(name string) string {
+	return filepath.Join(m.path, groupPrefix+name)
+}
+
+// CreateGroup creates, or updates, the resctrl group of the given name, moving the CPUs to it and
+// setting its allocation. The tasks running on the CPUs use the allocation of the group, whatever
+// group they belong to.
+func (m *Manager) CreateGroup(name string, cpus cpuset.CPUSet, alloc Allocation) error {
+	if err := m.Validate(alloc); err != nil {
+		return err
+	}
+	groupPath := m.groupPath(name)
+	if err := os.Mkdir(groupPath, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+		// the kernel returns ENOSPC once all the CLOSIDs are in use.
+		return fmt.Errorf("failed to create resctrl group %s: %w", groupPath, err)
+	}
+	if err := os.WriteFile(filepath.Join(groupPath, "schemata"), []byte(m.schemata(alloc)), 0644); err != nil {
+		return fmt.Errorf("failed to write the schemata of resctrl group %s: %w", groupPath, err)
+	}
+	if err := os.WriteFile(filepath.Join(groupPath, "cpus_list"), []byte(cpus.String()+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write the CPUs of resctrl group %s: %w", groupPath, err)
+	}
+	return nil
+}
+
+// monGroupPath returns the directory of the monitoring group of the given name, in the default group.
+func (m *Manager) monGroupPath(name string) string {
+	return filepath.Join(m.path, "mon_groups", groupPrefix+name)
+}
+
+// CreateMonitoringGroup creates, or updates, a monitoring group of the given name in the default group,
+// monitoring the tasks of the default group running on the CPUs. Groups created with CreateGroup are
+// monitored already.
+func (m *Manager) CreateMonitoringGroup(name string, cpus cpuset.CPUSet) error {
+	if !m.caps.L3Monitoring {
+		return fmt.Errorf("the last level cache monitoring is not supported")
+	}
+	groupPath := m.monGroupPath(name)
+	if err := os.Mkdir(groupPath, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+		// the kernel returns ENOSPC once all the RMIDs are in use.
+		return fmt.Errorf("failed to create resctrl monitoring group %s: %w", groupPath, err)
+	}
+	if err := os.WriteFile(filepath.Join(groupPath, "cpus_list"), []byte(cpus.String()+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write the CPUs of resctrl monitoring group %s: %w", groupPath, err)
+	}
+	return nil
+}
+
+// ReadMonitoring returns the monitoring counters of the group, or monitoring group, of the given name.
+// It reports false if there is no such group.
+func (m *Manager) ReadMonitoring(name string) (Monitoring, bool, error) {
+	var mon Monitoring
+	groupPath := m.groupPath(name)
+	if _, err := os.Stat(groupPath); errors.Is(err, fs.ErrNotExist) {
+		groupPath = m.monGroupPath(name)
+		if _, err := os.Stat(groupPath); errors.Is(err, fs.ErrNotExist) {
+			return mon, false, nil
+		}
+	}
+	domains, err := os.ReadDir(filepath.Join(groupPath, "mon_data"))
+	if err != nil {
+		return mon, false, fmt.Errorf("failed to read the monitoring data of resctrl group %s: %w", groupPath, err)
+	}
+	for _, domain := range domains {
+		if !strings.HasPrefix(domain.Name(), "mon_L3_") {
+			continue
+		}
+		for event, counter := range map[string]*uint64{
+			"llc_occupancy":   &mon.LLCOccupancyBytes,
+			"mbm_total_bytes": &mon.MBMTotalBytes,
+			"mbm_local_bytes": &mon.MBMLocalBytes,
+		} {
+			value, err := readString(groupPath, filepath.Join("mon_data", domain.Name(), event))
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return mon, false, err
+			}
+			// the kernel reports Unavailable while the counter can't be read, e.g. after the RMID was reused.
+			if value == "Unavailable" {
+				continue
+			}
+			count, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return mon, false, fmt.Errorf("invalid resctrl %s counter %q of group %s: %w", event, value, groupPath, err)
+			}
+			*counter += count
+		}
+	}
+	return mon, true, nil
+}
+
+// UpdateGroupCPUs moves the group, or monitoring group, of the given name to other CPUs, keeping its
+// allocation. Updating a group that doesn't exist is not an error.
+func (m *Manager) UpdateGroupCPUs(name string, cpus cpuset.CPUSet) error {
+	for _, groupPath := range []string{m.groupPath(name), m.monGroupPath(name)} {
+		if _, err := os.Stat(groupPath); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(groupPath, "cpus_list"), []byte(cpus.String()+"\n"), 0644); err != nil {
+			return fmt.Errorf("failed to write the CPUs of resctrl group %s: %w", groupPath, err)
+		}
+	}
+	return nil
+}
+
+// RemoveGroup removes the group, or monitoring group, of the given name, giving its CPUs back to the
+// default group.
+// Removing a group that doesn't exist is not an error.
+func (m *Manager) RemoveGroup(name string) error {
+	for _, groupPath := range []string{m.groupPath(name), m.monGroupPath(name)} {
+		if err := os.RemoveAll(groupPath); err != nil {
+			return fmt.Errorf("failed to remove resctrl group %s: %w", groupPath, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resctrl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/cpuset"
+)
+
+// newFakeResctrl lays out a resctrl filesystem in a temporary directory, with the given files.
+func newFakeResctrl(t *testing.T, files map[string]string) string {
+	path := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(path, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(path, name), []byte(content), 0644))
+	}
+	return path
+}
+
+var fakeCATAndMBA = map[string]string{
+	"schemata":               "    L3:0=7ff;1=7ff\n    MB:0=100;1=100\n",
+	"info/L3/cbm_mask":       "7ff\n",
+	"info/L3/num_closids":    "16\n",
+	"info/MB/min_bandwidth":  "10\n",
+	"info/MB/bandwidth_gran": "10\n",
+	"info/MB/num_closids":    "8\n",
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name          string
+		files         map[string]string
+		expectedCaps  Capabilities
+		expectedError bool
+	}{
+		{
+			name:  "cache and memory bandwidth allocation",
+			files: fakeCATAndMBA,
+			expectedCaps: Capabilities{
+				L3CAT:           true,
+				L3CacheWays:     11,
+				L3Domains:       []int{0, 1},
+				MBA:             true,
+				MBAMinBandwidth: 10,
+				MBAGranularity:  10,
+				MBADomains:      []int{0, 1},
+				NumCLOSIDs:      8,
+			},
+		},
+		{
+			name: "cache allocation only",
+			files: map[string]string{
+				"schemata":            "L3:0=fffff\n",
+				"info/L3/cbm_mask":    "fffff\n",
+				"info/L3/num_closids": "16\n",
+			},
+			expectedCaps: Capabilities{L3CAT: true, L3CacheWays: 20, L3Domains: []int{0}, NumCLOSIDs: 16},
+		},
+		{
+			name:          "not mounted",
+			files:         map[string]string{},
+			expectedError: true,
+		},
+		{
+			name: "invalid schemata",
+			files: map[string]string{
+				"schemata":         "L3:0\n",
+				"info/L3/cbm_mask": "fffff\n",
+			},
+			expectedError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := New(newFakeResctrl(t, tc.files))
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCaps, m.Capabilities())
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	m, err := New(newFakeResctrl(t, fakeCATAndMBA))
+	require.NoError(t, err)
+	require.NoError(t, m.Validate(Allocation{CacheWays: 11, MemoryBandwidthPercent: 10}))
+	require.Error(t, m.Validate(Allocation{CacheWays: 12}))
+	require.Error(t, m.Validate(Allocation{MemoryBandwidthPercent: 5}))
+	require.Error(t, m.Validate(Allocation{MemoryBandwidthPercent: 101}))
+
+	m.caps.MBA = false
+	require.Error(t, m.Validate(Allocation{MemoryBandwidthPercent: 50}))
+}
+
+func TestGroups(t *testing.T) {
+	path := newFakeResctrl(t, fakeCATAndMBA)
+	m, err := New(path)
+	require.NoError(t, err)
+
+	require.NoError(t, m.CreateGroup("claim-1", cpuset.New(2, 3, 6, 7), Allocation{CacheWays: 4, MemoryBandwidthPercent: 50}))
+	schemata, err := os.ReadFile(filepath.Join(path, "dracpu-claim-1", "schemata"))
+	require.NoError(t, err)
+	require.Equal(t, "L3:0=f;1=f\nMB:0=50;1=50\n", string(schemata))
+	cpus, err := os.ReadFile(filepath.Join(path, "dracpu-claim-1", "cpus_list"))
+	require.NoError(t, err)
+	require.Equal(t, "2-3,6-7\n", string(cpus))
+
+	// the group is updated in place when the claim is prepared again.
+	require.NoError(t, m.CreateGroup("claim-1", cpuset.New(2, 3), Allocation{CacheWays: 2}))
+	schemata, err = os.ReadFile(filepath.Join(path, "dracpu-claim-1", "schemata"))
+	require.NoError(t, err)
+	require.Equal(t, "L3:0=3;1=3\n", string(schemata))
+
+	require.NoError(t, m.UpdateGroupCPUs("claim-1", cpuset.New(4, 5)))
+	cpus, err = os.ReadFile(filepath.Join(path, "dracpu-claim-1", "cpus_list"))
+	require.NoError(t, err)
+	require.Equal(t, "4-5\n", string(cpus))
+	require.NoError(t, m.UpdateGroupCPUs("claim-2", cpuset.New(0)))
+	require.NoDirExists(t, filepath.Join(path, "dracpu-claim-2"))
+
+	require.Error(t, m.CreateGroup("claim-2", cpuset.New(0), Allocation{CacheWays: 12}))
+	require.NoDirExists(t, filepath.Join(path, "dracpu-claim-2"))
+
+	require.NoError(t, m.RemoveGroup("claim-1"))
+	require.NoDirExists(t, filepath.Join(path, "dracpu-claim-1"))
+	require.NoError(t, m.RemoveGroup("claim-1"))
+}
+
+func TestMonitoring(t *testing.T) {
+	files := map[string]string{
+		"schemata":                 "L3:0=7ff;1=7ff\n",
+		"info/L3/cbm_mask":         "7ff\n",
+		"info/L3/num_closids":      "16\n",
+		"info/L3_MON/mon_features": "llc_occupancy\nmbm_total_bytes\nmbm_local_bytes\n",
+		"mon_groups/.keep":         "",
+	}
+	path := newFakeResctrl(t, files)
+	m, err := New(path)
+	require.NoError(t, err)
+	require.True(t, m.Capabilities().L3Monitoring)
+	require.Equal(t, []string{"llc_occupancy", "mbm_total_bytes", "mbm_local_bytes"}, m.Capabilities().MonitoringFeatures)
+
+	_, ok, err := m.ReadMonitoring("claim-1")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, m.CreateMonitoringGroup("claim-1", cpuset.New(0, 1)))
+	monGroupPath := filepath.Join(path, "mon_groups", "dracpu-claim-1")
+	cpus, err := os.ReadFile(filepath.Join(monGroupPath, "cpus_list"))
+	require.NoError(t, err)
+	require.Equal(t, "0-1\n", string(cpus))
+
+	// the kernel populates the monitoring data of the groups.
+	for name, content := range map[string]string{
+		"mon_data/mon_L3_00/llc_occupancy":   "1048576\n",
+		"mon_data/mon_L3_00/mbm_total_bytes": "4096\n",
+		"mon_data/mon_L3_00/mbm_local_bytes": "1024\n",
+		"mon_data/mon_L3_01/llc_occupancy":   "524288\n",
+		"mon_data/mon_L3_01/mbm_total_bytes": "Unavailable\n",
+		"mon_data/mon_L3_01/mbm_local_bytes": "2048\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(monGroupPath, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(monGroupPath, name), []byte(content), 0644))
+	}
+	mon, ok, err := m.ReadMonitoring("claim-1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, Monitoring{LLCOccupancyBytes: 1572864, MBMTotalBytes: 4096, MBMLocalBytes: 3072}, mon)
+
+	require.NoError(t, m.UpdateGroupCPUs("claim-1", cpuset.New(2, 3)))
+	cpus, err = os.ReadFile(filepath.Join(monGroupPath, "cpus_list"))
+	require.NoError(t, err)
+	require.Equal(t, "2-3\n", string(cpus))
+
+	require.NoError(t, m.RemoveGroup("claim-1"))
+	require.NoDirExists(t, monGroupPath)
+
+	m.caps.L3Monitoring = false
+	require.Error(t, m.CreateMonitoringGroup("claim-2", cpuset.New(0)))
+}
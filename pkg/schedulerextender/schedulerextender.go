@@ -0,0 +1,157 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schedulerextender is a kube-scheduler extender preferring the nodes whose free CPUs are the
+// least fragmented, read from the DRACPUNodeStatus objects the driver publishes. Packing the claims on
+// whole cores of a single NUMA node keeps the large aligned claims schedulable on the other nodes.
+package schedulerextender
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/nodestatus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// MaxScore is the highest score of a node, the MaxExtenderPriority of the kube-scheduler.
+const MaxScore int64 = 10
+
+// ExtenderArgs is the request of the kube-scheduler to the prioritize verb, as defined by
+// k8s.io/kube-scheduler/extender/v1. Nodes is set unless the extender is nodeCacheCapable.
+type ExtenderArgs struct {
+	Pod       *corev1.Pod      `json:"pod"`
+	Nodes     *corev1.NodeList `json:"nodes,omitempty"`
+	NodeNames *[]string        `json:"nodenames,omitempty"`
+}
+
+// HostPriority is the score of a node, as defined by k8s.io/kube-scheduler/extender/v1.
+type HostPriority struct {
+	Host  string `json:"host"`
+	Score int64  `json:"score"`
+}
+
+// Extender scores the nodes from their DRACPUNodeStatus.
+type Extender struct {
+	statuses cache.GenericLister
+}
+
+// New returns an Extender reading the DRACPUNodeStatus objects of the lister, usually backed by a
+// dynamic informer of nodestatus.GroupVersionResource.
+func New(statuses cache.GenericLister) *Extender {
+	return &Extender{statuses: statuses}
+}
+
+// Score is the score of a node from 0, fully fragmented or without free CPUs, to MaxScore. It is the
+// average of the share of the free CPUs on whole free cores and of the share on the NUMA node with
+// the most free CPUs.
+func Score(status *nodestatus.NodeStatus) int64 {
+	if status == nil || status.FreeCPUs <= 0 {
+		return 0
+	}
+	cpusPerCore := max(1, status.CPUsPerCore)
+	freeCoreCPUs, numaFreeCPUs := 0, 0
+	for _, numa := range status.NUMANodes {
+		freeCoreCPUs += numa.FreeCores * cpusPerCore
+		numaFreeCPUs = max(numaFreeCPUs, numa.FreeCPUs)
+	}
+	if len(status.NUMANodes) == 0 {
+		// Without the NUMA breakdown the free CPUs can not be fragmented any further.
+		freeCoreCPUs, numaFreeCPUs = status.FreeCPUs, status.FreeCPUs
+	}
+	coreRatio := min(1, float64(freeCoreCPUs)/float64(status.FreeCPUs))
+	numaRatio := min(1, float64(numaFreeCPUs)/float64(status.FreeCPUs))
+	return int64(math.Round(float64(MaxScore) * (coreRatio + numaRatio) / 2))
+}
+
+// Prioritize scores the nodes of the request. Pods without resource claims get no CPUs from the
+// driver, so all the nodes score 0 and the other scores of the kube-scheduler decide.
+func (e *Extender) Prioritize(args *ExtenderArgs) ([]HostPriority, error) {
+	var nodeNames []string
+	switch {
+	case args.NodeNames != nil:
+		nodeNames = *args.NodeNames
+	case args.Nodes != nil:
+		for _, node := range args.Nodes.Items {
+			nodeNames = append(nodeNames, node.Name)
+		}
+	}
+	scoreNodes := args.Pod != nil && len(args.Pod.Spec.ResourceClaims) > 0
+	priorities := make([]HostPriority, 0, len(nodeNames))
+	for _, name := range nodeNames {
+		priority := HostPriority{Host: name}
+		if scoreNodes {
+			status, err := e.nodeStatus(name)
+			if err != nil {
+				return nil, err
+			}
+			priority.Score = Score(status)
+		}
+		priorities = append(priorities, priority)
+	}
+	return priorities, nil
+}
+
+// nodeStatus is the status published for the node, nil if the driver does not run on the node.
+func (e *Extender) nodeStatus(name string) (*nodestatus.NodeStatus, error) {
+	obj, err := e.statuses.Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get DRACPUNodeStatus %s: %w", name, err)
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected DRACPUNodeStatus %s of type %T", name, obj)
+	}
+	status := &nodestatus.DRACPUNodeStatus{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, status); err != nil {
+		return nil, fmt.Errorf("failed to convert DRACPUNodeStatus %s: %w", name, err)
+	}
+	return &status.Status, nil
+}
+
+// ServePrioritize is the handler of the prioritize verb of the extender.
+func (e *Extender) ServePrioritize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	args := &ExtenderArgs{}
+	if err := json.NewDecoder(r.Body).Decode(args); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode the extender arguments: %v", err), http.StatusBadRequest)
+		return
+	}
+	priorities, err := e.Prioritize(args)
+	if err != nil {
+		klog.FromContext(r.Context()).Error(err, "Failed to prioritize the nodes")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(priorities); err != nil {
+		klog.FromContext(r.Context()).Error(err, "Failed to write the node priorities")
+	}
+}
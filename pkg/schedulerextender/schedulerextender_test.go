@@ -0,0 +1,136 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedulerextender
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/nodestatus"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/ptr"
+)
+
+// packedStatus has all its 8 free CPUs on the 4 whole free cores of NUMA node 0.
+var packedStatus = nodestatus.NodeStatus{
+	FreeCPUs:    8,
+	CPUsPerCore: 2,
+	NUMANodes: []nodestatus.NUMANodeStatus{
+		{ID: 0, FreeCPUs: 8, FreeCores: 4},
+		{ID: 1, AllocatedCPUs: 8, AllocatedCores: 4},
+	},
+}
+
+// fragmentedStatus has its 8 free CPUs spread on the cores of both NUMA nodes, only 1 of them whole.
+var fragmentedStatus = nodestatus.NodeStatus{
+	FreeCPUs:    8,
+	CPUsPerCore: 2,
+	NUMANodes: []nodestatus.NUMANodeStatus{
+		{ID: 0, AllocatedCPUs: 4, FreeCPUs: 4, AllocatedCores: 3, FreeCores: 1},
+		{ID: 1, AllocatedCPUs: 4, FreeCPUs: 4, AllocatedCores: 4},
+	},
+}
+
+func TestScore(t *testing.T) {
+	testCases := []struct {
+		name   string
+		status *nodestatus.NodeStatus
+		want   int64
+	}{
+		{name: "no status", want: 0},
+		{name: "no free CPUs", status: &nodestatus.NodeStatus{CPUsPerCore: 2}, want: 0},
+		{name: "packed", status: &packedStatus, want: MaxScore},
+		// 2/8 CPUs on whole cores, 4/8 on the first NUMA node.
+		{name: "fragmented", status: &fragmentedStatus, want: 4},
+		{name: "no NUMA breakdown", status: &nodestatus.NodeStatus{FreeCPUs: 4}, want: MaxScore},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, Score(tc.status))
+		})
+	}
+}
+
+func newTestExtender(t *testing.T, statuses map[string]nodestatus.NodeStatus) *Extender {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for name, status := range statuses {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&nodestatus.DRACPUNodeStatus{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     status,
+		})
+		require.NoError(t, err)
+		require.NoError(t, indexer.Add(&unstructured.Unstructured{Object: content}))
+	}
+	return New(cache.NewGenericLister(indexer, nodestatus.GroupVersionResource.GroupResource()))
+}
+
+func claimPod() *corev1.Pod {
+	return &corev1.Pod{Spec: corev1.PodSpec{
+		ResourceClaims: []corev1.PodResourceClaim{{Name: "cpus", ResourceClaimTemplateName: ptr.To("cpus")}},
+	}}
+}
+
+func TestPrioritize(t *testing.T) {
+	extender := newTestExtender(t, map[string]nodestatus.NodeStatus{
+		"packed":     packedStatus,
+		"fragmented": fragmentedStatus,
+	})
+	nodeNames := []string{"packed", "fragmented", "no-driver"}
+
+	priorities, err := extender.Prioritize(&ExtenderArgs{Pod: claimPod(), NodeNames: &nodeNames})
+	require.NoError(t, err)
+	require.Equal(t, []HostPriority{
+		{Host: "packed", Score: MaxScore},
+		{Host: "fragmented", Score: 4},
+		{Host: "no-driver", Score: 0},
+	}, priorities)
+
+	nodes := &corev1.NodeList{Items: []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "packed"}}}}
+	priorities, err = extender.Prioritize(&ExtenderArgs{Pod: &corev1.Pod{}, Nodes: nodes})
+	require.NoError(t, err)
+	require.Equal(t, []HostPriority{{Host: "packed", Score: 0}}, priorities, "pods without claims do not score the nodes")
+}
+
+func TestServePrioritize(t *testing.T) {
+	extender := newTestExtender(t, map[string]nodestatus.NodeStatus{"packed": packedStatus})
+	nodeNames := []string{"packed"}
+	body, err := json.Marshal(&ExtenderArgs{Pod: claimPod(), NodeNames: &nodeNames})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	extender.ServePrioritize(rec, httptest.NewRequest(http.MethodPost, "/prioritize", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var priorities []HostPriority
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &priorities))
+	require.Equal(t, []HostPriority{{Host: "packed", Score: MaxScore}}, priorities)
+
+	rec = httptest.NewRecorder()
+	extender.ServePrioritize(rec, httptest.NewRequest(http.MethodGet, "/prioritize", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = httptest.NewRecorder()
+	extender.ServePrioritize(rec, httptest.NewRequest(http.MethodPost, "/prioritize", bytes.NewReader([]byte("{"))))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -17,9 +17,13 @@ limitations under the License.
 package store
 
 import (
+	"maps"
+	"slices"
+	"sort"
 	"sync"
 
 	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
+	resourceapi "k8s.io/api/resource/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/cpuset"
@@ -31,6 +35,37 @@ type CPUAllocation struct {
 	availableCPUs            cpuset.CPUSet
 	reservedCPUs             cpuset.CPUSet
 	resourceClaimAllocations map[types.UID]cpuset.CPUSet
+	resourceClaimInfos       map[types.UID]ClaimInfo
+	// kubeletExclusiveCPUs are the CPUs the kubelet CPU Manager pinned to pods not using claims.
+	kubeletExclusiveCPUs cpuset.CPUSet
+	// kubeletSharedCPUs are the CPUs of the shared pool of the kubelet, all the CPUs unless it pins pods.
+	kubeletSharedCPUs cpuset.CPUSet
+	// degradedCPUs are the CPUs reported as failing or overheating, with the reason.
+	degradedCPUs map[int]string
+}
+
+// ClaimInfo holds the resource claim details recorded when the claim is prepared.
+type ClaimInfo struct {
+	Namespace string
+	Name      string
+	Priority  int32
+	// ReservedFor are the consumers the claim was reserved for, used to detect orphaned claims.
+	ReservedFor []resourceapi.ResourceClaimConsumerReference
+	// PollingCPUs are the CPUs of the claim dedicated to polling a NIC. Claims with polling CPUs are never preempted.
+	PollingCPUs cpuset.CPUSet
+	// ReleaseOnPodFailure gives the CPUs of the claim back to the shared pool while its consumer pods are failing.
+	ReleaseOnPodFailure bool
+	// CPUBandwidthFromClaim sets the CPU quota and weight of the containers of the claim from its CPUs.
+	CPUBandwidthFromClaim bool
+	// BindMemoryNodes restricts the memory of the containers of the claim to the NUMA nodes of its CPUs.
+	BindMemoryNodes bool
+}
+
+// ClaimAllocation is a resource claim allocation which can be preempted.
+type ClaimAllocation struct {
+	ClaimUID types.UID
+	ClaimInfo
+	CPUs cpuset.CPUSet
 }
 
 // NewCPUAllocation creates a new CPUAllocation.
@@ -46,6 +81,25 @@ func NewCPUAllocation(cpuTopology *cpuinfo.CPUTopology, reservedCPUs cpuset.CPUS
 		availableCPUs:            availableCPUs,
 		reservedCPUs:             reservedCPUs,
 		resourceClaimAllocations: make(map[types.UID]cpuset.CPUSet),
+		resourceClaimInfos:       make(map[types.UID]ClaimInfo),
+		kubeletExclusiveCPUs:     cpuset.New(),
+		kubeletSharedCPUs:        allCPUsSet,
+		degradedCPUs:             make(map[int]string),
+	}
+}
+
+// Clone returns a copy of the store, whose changes don't affect the store, e.g. to simulate allocations.
+func (s *CPUAllocation) Clone() *CPUAllocation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &CPUAllocation{
+		availableCPUs:            s.availableCPUs,
+		reservedCPUs:             s.reservedCPUs,
+		resourceClaimAllocations: maps.Clone(s.resourceClaimAllocations),
+		resourceClaimInfos:       maps.Clone(s.resourceClaimInfos),
+		kubeletExclusiveCPUs:     s.kubeletExclusiveCPUs,
+		kubeletSharedCPUs:        s.kubeletSharedCPUs,
+		degradedCPUs:             maps.Clone(s.degradedCPUs),
 	}
 }
 
@@ -55,7 +109,7 @@ func (s *CPUAllocation) AddResourceClaimAllocation(claimUID types.UID, cpus cpus
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.resourceClaimAllocations[claimUID] = cpus
-	klog.Infof("Added allocation for resource claim %s: CPUs %s", claimUID, cpus.String())
+	klog.InfoS("Added allocation for resource claim", "claimUID", claimUID, "cpus", cpus.String())
 }
 
 // RemoveResourceClaimAllocation removes a resource claim allocation from the store.
@@ -64,8 +118,51 @@ func (s *CPUAllocation) RemoveResourceClaimAllocation(claimUID types.UID) {
 	defer s.mu.Unlock()
 	if _, ok := s.resourceClaimAllocations[claimUID]; ok {
 		delete(s.resourceClaimAllocations, claimUID)
-		klog.Infof("Removed allocation for resource claim %s", claimUID)
+		klog.InfoS("Removed allocation for resource claim", "claimUID", claimUID)
 	}
+	delete(s.resourceClaimInfos, claimUID)
+}
+
+// SetResourceClaimInfo records the details of the resource claim owning an allocation.
+func (s *CPUAllocation) SetResourceClaimInfo(claimUID types.UID, info ClaimInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resourceClaimInfos[claimUID] = info
+}
+
+// GetResourceClaimInfos returns the details of all the resource claims with recorded details.
+func (s *CPUAllocation) GetResourceClaimInfos() map[types.UID]ClaimInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	infos := make(map[types.UID]ClaimInfo, len(s.resourceClaimInfos))
+	for claimUID, info := range s.resourceClaimInfos {
+		infos[claimUID] = info
+	}
+	return infos
+}
+
+// GetPreemptibleClaimAllocations returns the allocations fully contained in the given CPUs whose claims
+// have a priority lower than the given one, lowest priority first.
+// Claims without recorded details, e.g. the ones recovered on restart, have priority 0.
+// Claims with polling CPUs are left out, moving them would break their NIC locality.
+func (s *CPUAllocation) GetPreemptibleClaimAllocations(cpus cpuset.CPUSet, priority int32) []ClaimAllocation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	allocations := []ClaimAllocation{}
+	for claimUID, claimCPUs := range s.resourceClaimAllocations {
+		info := s.resourceClaimInfos[claimUID]
+		if info.Priority >= priority || info.PollingCPUs.Size() > 0 || !claimCPUs.IsSubsetOf(cpus) {
+			continue
+		}
+		allocations = append(allocations, ClaimAllocation{ClaimUID: claimUID, ClaimInfo: info, CPUs: claimCPUs})
+	}
+	sort.Slice(allocations, func(i, j int) bool {
+		if allocations[i].Priority != allocations[j].Priority {
+			return allocations[i].Priority < allocations[j].Priority
+		}
+		return allocations[i].ClaimUID < allocations[j].ClaimUID
+	})
+	return allocations
 }
 
 // GetSharedCPUs calculates and returns the set of CPUs not reserved by any resource claim.
@@ -77,7 +174,97 @@ func (s *CPUAllocation) GetSharedCPUs() cpuset.CPUSet {
 	for _, cpus := range s.resourceClaimAllocations {
 		allocatedCPUs = allocatedCPUs.Union(cpus)
 	}
-	return s.availableCPUs.Difference(allocatedCPUs)
+	return s.availableCPUs.Difference(allocatedCPUs).Difference(s.kubeletExclusiveCPUs).Difference(s.degradedCPUSet())
+}
+
+// GetAllocatableCPUs returns the CPUs claims can be allocated, whether they are allocated or not:
+// the CPUs not reserved, pinned by the kubelet CPU Manager or degraded.
+func (s *CPUAllocation) GetAllocatableCPUs() cpuset.CPUSet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.availableCPUs.Difference(s.kubeletExclusiveCPUs).Difference(s.degradedCPUSet())
+}
+
+// SetKubeletExclusiveCPUs records the CPUs the kubelet CPU Manager exclusively assigned to
+// containers outside of DRA. Those CPUs are excluded from the shared pool.
+// It returns true if the set changed.
+func (s *CPUAllocation) SetKubeletExclusiveCPUs(logger klog.Logger, cpus cpuset.CPUSet) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.kubeletExclusiveCPUs.Equals(cpus) {
+		return false
+	}
+	logger.Info("Kubelet exclusive CPUs changed", "oldCPUs", s.kubeletExclusiveCPUs.String(), "cpus", cpus.String())
+	s.kubeletExclusiveCPUs = cpus
+	return true
+}
+
+// GetKubeletExclusiveCPUs returns the CPUs the kubelet CPU Manager exclusively assigned to containers.
+func (s *CPUAllocation) GetKubeletExclusiveCPUs() cpuset.CPUSet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.kubeletExclusiveCPUs
+}
+
+// SetKubeletSharedCPUs records the CPUs of the shared pool of the kubelet, which the containers it doesn't
+// pin run on from its point of view. It returns true if the set changed.
+func (s *CPUAllocation) SetKubeletSharedCPUs(logger klog.Logger, cpus cpuset.CPUSet) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.kubeletSharedCPUs.Equals(cpus) {
+		return false
+	}
+	logger.Info("Kubelet shared CPUs changed", "oldCPUs", s.kubeletSharedCPUs.String(), "cpus", cpus.String())
+	s.kubeletSharedCPUs = cpus
+	return true
+}
+
+// GetKubeletSharedCPUs returns the CPUs of the shared pool of the kubelet.
+func (s *CPUAllocation) GetKubeletSharedCPUs() cpuset.CPUSet {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.kubeletSharedCPUs
+}
+
+// SetDegradedCPUs records the CPUs reported as failing or overheating, mapped to the reason.
+// Those CPUs are excluded from the shared pool. It returns true if the degraded CPUs changed.
+func (s *CPUAllocation) SetDegradedCPUs(logger klog.Logger, reasons map[int]string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if maps.Equal(s.degradedCPUs, reasons) {
+		return false
+	}
+	logger.Info("Degraded CPUs changed", "oldDegradedCPUs", s.degradedCPUs, "degradedCPUs", reasons)
+	s.degradedCPUs = maps.Clone(reasons)
+	return true
+}
+
+// GetDegradedCPUs returns the CPUs reported as failing or overheating, mapped to the reason.
+func (s *CPUAllocation) GetDegradedCPUs() map[int]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.degradedCPUs)
+}
+
+func (s *CPUAllocation) degradedCPUSet() cpuset.CPUSet {
+	return cpuset.New(slices.Collect(maps.Keys(s.degradedCPUs))...)
+}
+
+// GetClaimAllocationsUsing returns the allocations using any of the given CPUs, sorted by claim UID.
+func (s *CPUAllocation) GetClaimAllocationsUsing(cpus cpuset.CPUSet) []ClaimAllocation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	allocations := []ClaimAllocation{}
+	for claimUID, claimCPUs := range s.resourceClaimAllocations {
+		if claimCPUs.Intersection(cpus).IsEmpty() {
+			continue
+		}
+		allocations = append(allocations, ClaimAllocation{ClaimUID: claimUID, ClaimInfo: s.resourceClaimInfos[claimUID], CPUs: claimCPUs})
+	}
+	sort.Slice(allocations, func(i, j int) bool {
+		return allocations[i].ClaimUID < allocations[j].ClaimUID
+	})
+	return allocations
 }
 
 // GetResourceClaimAllocation returns the cpuset for a given resource claim.
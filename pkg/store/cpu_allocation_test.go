@@ -22,6 +22,7 @@ import (
 	"github.com/kubernetes-sigs/dra-driver-cpu/pkg/cpuinfo"
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
 	"k8s.io/utils/cpuset"
 )
 
@@ -112,3 +113,91 @@ func TestCPUAllocationGetSharedCPUs(t *testing.T) {
 	expectedShared = expectedShared.Difference(cpus2)
 	require.True(t, store.GetSharedCPUs().Equals(expectedShared))
 }
+
+func TestCPUAllocationKubeletExclusiveCPUs(t *testing.T) {
+	allCPUs := cpuset.New(0, 1, 2, 3, 4, 5, 6, 7)
+	store := newTestCPUAllocation(allCPUs, cpuset.New(0))
+	store.AddResourceClaimAllocation(types.UID("claim-uid-1"), cpuset.New(1, 2))
+
+	require.True(t, store.GetKubeletExclusiveCPUs().IsEmpty())
+	require.True(t, store.SetKubeletExclusiveCPUs(klog.Background(), cpuset.New(6, 7)))
+	require.False(t, store.SetKubeletExclusiveCPUs(klog.Background(), cpuset.New(6, 7)), "setting the same CPUs twice must not report a change")
+	require.True(t, store.GetKubeletExclusiveCPUs().Equals(cpuset.New(6, 7)))
+	require.True(t, store.GetSharedCPUs().Equals(cpuset.New(3, 4, 5)))
+	require.True(t, store.GetAllocatableCPUs().Equals(cpuset.New(1, 2, 3, 4, 5)))
+
+	require.True(t, store.SetKubeletExclusiveCPUs(klog.Background(), cpuset.New()))
+	require.True(t, store.GetSharedCPUs().Equals(cpuset.New(3, 4, 5, 6, 7)))
+}
+
+func TestCPUAllocationClone(t *testing.T) {
+	allCPUs := cpuset.New(0, 1, 2, 3, 4, 5, 6, 7)
+	store := newTestCPUAllocation(allCPUs, cpuset.New(0))
+	store.AddResourceClaimAllocation(types.UID("claim-uid-1"), cpuset.New(1, 2))
+	store.SetDegradedCPUs(klog.Background(), map[int]string{7: "machine-check"})
+
+	clone := store.Clone()
+	require.True(t, clone.GetSharedCPUs().Equals(cpuset.New(3, 4, 5, 6)))
+	clone.AddResourceClaimAllocation(types.UID("claim-uid-2"), cpuset.New(3))
+	clone.RemoveResourceClaimAllocation(types.UID("claim-uid-1"))
+	clone.SetDegradedCPUs(klog.Background(), map[int]string{})
+	require.True(t, clone.GetSharedCPUs().Equals(cpuset.New(1, 2, 4, 5, 6, 7)))
+	require.True(t, store.GetSharedCPUs().Equals(cpuset.New(3, 4, 5, 6)), "changing the clone must not change the store")
+}
+
+func TestCPUAllocationGetPreemptibleClaimAllocations(t *testing.T) {
+	allCPUs := cpuset.New(0, 1, 2, 3, 4, 5, 6, 7)
+	store := newTestCPUAllocation(allCPUs, cpuset.New())
+	store.AddResourceClaimAllocation(types.UID("claim-high"), cpuset.New(0))
+	store.SetResourceClaimInfo(types.UID("claim-high"), ClaimInfo{Namespace: "ns", Name: "high", Priority: 10})
+	store.AddResourceClaimAllocation(types.UID("claim-low"), cpuset.New(1))
+	store.SetResourceClaimInfo(types.UID("claim-low"), ClaimInfo{Namespace: "ns", Name: "low", Priority: 1})
+	store.AddResourceClaimAllocation(types.UID("claim-recovered"), cpuset.New(2))
+	store.AddResourceClaimAllocation(types.UID("claim-outside"), cpuset.New(3, 4))
+	store.AddResourceClaimAllocation(types.UID("claim-polling"), cpuset.New(5))
+	store.SetResourceClaimInfo(types.UID("claim-polling"), ClaimInfo{Namespace: "ns", Name: "polling", PollingCPUs: cpuset.New(5)})
+
+	allocations := store.GetPreemptibleClaimAllocations(cpuset.New(0, 1, 2, 3, 5), 5)
+	require.Equal(t, []ClaimAllocation{
+		{ClaimUID: types.UID("claim-recovered"), CPUs: cpuset.New(2)},
+		{ClaimUID: types.UID("claim-low"), ClaimInfo: ClaimInfo{Namespace: "ns", Name: "low", Priority: 1}, CPUs: cpuset.New(1)},
+	}, allocations)
+
+	// the claim details are dropped together with the allocation
+	store.RemoveResourceClaimAllocation(types.UID("claim-high"))
+	store.AddResourceClaimAllocation(types.UID("claim-high"), cpuset.New(0))
+	allocations = store.GetPreemptibleClaimAllocations(cpuset.New(0), 5)
+	require.Len(t, allocations, 1)
+	require.Equal(t, int32(0), allocations[0].Priority)
+}
+
+func TestCPUAllocationDegradedCPUs(t *testing.T) {
+	allCPUs := cpuset.New(0, 1, 2, 3, 4, 5, 6, 7)
+	store := newTestCPUAllocation(allCPUs, cpuset.New(0))
+	store.AddResourceClaimAllocation(types.UID("claim-uid-1"), cpuset.New(1, 2))
+
+	require.Empty(t, store.GetDegradedCPUs())
+	require.True(t, store.SetDegradedCPUs(klog.Background(), map[int]string{2: "MachineCheck", 7: "ThermalThrottling"}))
+	require.False(t, store.SetDegradedCPUs(klog.Background(), map[int]string{2: "MachineCheck", 7: "ThermalThrottling"}), "setting the same CPUs twice must not report a change")
+	require.Equal(t, map[int]string{2: "MachineCheck", 7: "ThermalThrottling"}, store.GetDegradedCPUs())
+	require.True(t, store.GetSharedCPUs().Equals(cpuset.New(3, 4, 5, 6)))
+	require.True(t, store.GetAllocatableCPUs().Equals(cpuset.New(1, 3, 4, 5, 6)))
+
+	require.True(t, store.SetDegradedCPUs(klog.Background(), map[int]string{}))
+	require.True(t, store.GetSharedCPUs().Equals(cpuset.New(3, 4, 5, 6, 7)))
+}
+
+func TestCPUAllocationGetClaimAllocationsUsing(t *testing.T) {
+	allCPUs := cpuset.New(0, 1, 2, 3, 4, 5, 6, 7)
+	store := newTestCPUAllocation(allCPUs, cpuset.New())
+	store.AddResourceClaimAllocation(types.UID("claim-b"), cpuset.New(0, 1))
+	store.SetResourceClaimInfo(types.UID("claim-b"), ClaimInfo{Namespace: "ns", Name: "b"})
+	store.AddResourceClaimAllocation(types.UID("claim-a"), cpuset.New(2, 3))
+	store.AddResourceClaimAllocation(types.UID("claim-c"), cpuset.New(4, 5))
+
+	require.Equal(t, []ClaimAllocation{
+		{ClaimUID: types.UID("claim-a"), CPUs: cpuset.New(2, 3)},
+		{ClaimUID: types.UID("claim-b"), ClaimInfo: ClaimInfo{Namespace: "ns", Name: "b"}, CPUs: cpuset.New(0, 1)},
+	}, store.GetClaimAllocationsUsing(cpuset.New(1, 3, 7)))
+	require.Empty(t, store.GetClaimAllocationsUsing(cpuset.New(6, 7)))
+}
@@ -40,6 +40,16 @@ func NewContainerState(containerName string, containerUID types.UID, claimUIDs .
 	}
 }
 
+// ContainerUID returns the runtime ID of the container.
+func (cs *ContainerState) ContainerUID() types.UID {
+	return cs.containerUID
+}
+
+// ResourceClaimUIDs returns the resource claims associated with the container.
+func (cs *ContainerState) ResourceClaimUIDs() []types.UID {
+	return cs.resourceClaimUIDs
+}
+
 // PodCPUAssignments maps a container name to its state.
 type PodCPUAssignments map[string]*ContainerState
 
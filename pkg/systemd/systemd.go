@@ -0,0 +1,112 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package systemd implements the readiness and watchdog notifications and the socket activation of
+// systemd services, for the driver running as a service of the node outside of a DaemonSet, e.g. on
+// edge nodes. Without the environment variables systemd sets, the functions do nothing.
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// StateReady tells systemd the service finished starting.
+	StateReady = "READY=1"
+	// StateStopping tells systemd the service is shutting down.
+	StateStopping = "STOPPING=1"
+	// StateWatchdog resets the watchdog timer of the service.
+	StateWatchdog = "WATCHDOG=1"
+
+	// listenFDsStart is the first file descriptor passed by the socket activation.
+	listenFDsStart = 3
+)
+
+// Notify sends the state to the notification socket of the service, and reports false if the service
+// has none, when it doesn't run under systemd or its unit doesn't set Type=notify.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// a leading @ is an abstract socket.
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to the systemd notification socket: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("failed to notify systemd: %w", err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the timeout of the watchdog of the service, zero if it is disabled or meant
+// for another process. The service must send StateWatchdog more often, usually at half the timeout.
+func WatchdogInterval() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, nil
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid WATCHDOG_USEC %q", usec)
+	}
+	return time.Duration(n) * time.Microsecond, nil
+}
+
+// Listeners returns the listening sockets passed by the socket activation of the service, in the order
+// of its socket units, none if it wasn't activated by a socket. The environment variables of the
+// activation are unset, so they don't leak to the child processes.
+func Listeners() ([]net.Listener, error) {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", os.Getenv("LISTEN_FDS"))
+	}
+	var listeners []net.Listener
+	var errs []error
+	for fd := listenFDsStart; fd < listenFDsStart+n; fd++ {
+		file := os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+		listener, err := net.FileListener(file)
+		// the listener holds a duplicate of the file descriptor.
+		file.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("socket %d is not a listening socket: %w", fd, err))
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, errors.Join(errs...)
+}